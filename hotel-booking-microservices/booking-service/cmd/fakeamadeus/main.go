@@ -0,0 +1,410 @@
+// Command fakeamadeus es un servidor HTTP que imita los endpoints de Amadeus
+// usados por booking-service, para probar el flujo de reservas sin credenciales
+// del sandbox. Los precios son deterministas y las ofertas se guardan en memoria.
+//
+//	go run ./cmd/fakeamadeus
+//	AMADEUS_BASE_URL=http://localhost:9100 AMADEUS_CLIENT_ID=x AMADEUS_CLIENT_SECRET=y go run ./cmd/server
+//
+// FAKE_AMADEUS_REPRICE_PCT=10 hace que la re-cotización de una oferta devuelva
+// un precio 10% mayor, para probar el rechazo por cambio de precio.
+// FAKE_AMADEUS_FAILURE_PCT=30 hace fallar ese porcentaje de las búsquedas con
+// 429 (Retry-After: 1) o 503, para probar reintentos y el circuit breaker.
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"booking-service/internal/models"
+)
+
+type fakeAmadeus struct {
+	repricePct float64
+	failurePct float64
+
+	mu       sync.Mutex
+	offers   map[string]models.AmadeusHotelOffer
+	bookings map[string]string // ID de reserva -> ID de oferta
+	nextID   int
+}
+
+func main() {
+	addr := os.Getenv("FAKE_AMADEUS_ADDR")
+	if addr == "" {
+		addr = ":9100"
+	}
+
+	repricePct, _ := strconv.ParseFloat(os.Getenv("FAKE_AMADEUS_REPRICE_PCT"), 64)
+	failurePct, _ := strconv.ParseFloat(os.Getenv("FAKE_AMADEUS_FAILURE_PCT"), 64)
+
+	fake := &fakeAmadeus{
+		repricePct: repricePct,
+		failurePct: failurePct,
+		offers:     make(map[string]models.AmadeusHotelOffer),
+		bookings:   make(map[string]string),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/security/oauth2/token", fake.token)
+	mux.HandleFunc("/v1/reference-data/locations/hotels/by-city", fake.authorized(fake.hotelsByCity))
+	mux.HandleFunc("/v1/reference-data/locations/hotels/by-hotels", fake.authorized(fake.hotelsByID))
+	mux.HandleFunc("/v3/shopping/hotel-offers", fake.authorized(fake.flaky(fake.hotelOffers)))
+	mux.HandleFunc("/v3/shopping/hotel-offers/", fake.authorized(fake.flaky(fake.hotelOffer)))
+	mux.HandleFunc("/v1/booking/hotel-bookings", fake.authorized(fake.createBooking))
+	mux.HandleFunc("/v1/booking/hotel-bookings/", fake.authorized(fake.cancelBooking))
+
+	log.Printf("🧪 Fake Amadeus escuchando en %s", addr)
+	log.Fatal(http.ListenAndServe(addr, mux))
+}
+
+// token emite un token fijo para cualquier par de credenciales
+func (f *fakeAmadeus) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "client_credentials" || r.PostForm.Get("client_id") == "" {
+		writeError(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.AmadeusTokenResponse{
+		Type:        "amadeusOAuth2Token",
+		Username:    "fake@amadeus.local",
+		AccessToken: "fake-token",
+		TokenType:   "Bearer",
+		ExpiresIn:   1799,
+		State:       "approved",
+	})
+}
+
+// authorized exige el token emitido por token
+func (f *fakeAmadeus) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fake-token" {
+			writeError(w, http.StatusUnauthorized, "invalid access token")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// flaky simula los límites de tasa y caídas de la API real
+func (f *fakeAmadeus) flaky(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if f.failurePct > 0 && rand.Float64()*100 < f.failurePct {
+			if rand.Intn(2) == 0 {
+				w.Header().Set("Retry-After", "1")
+				writeError(w, http.StatusTooManyRequests, "too many requests")
+			} else {
+				writeError(w, http.StatusServiceUnavailable, "service unavailable")
+			}
+			return
+		}
+		next(w, r)
+	}
+}
+
+// hotelsByCity devuelve tres hoteles ficticios por ciudad
+func (f *fakeAmadeus) hotelsByCity(w http.ResponseWriter, r *http.Request) {
+	cityCode := strings.ToUpper(r.URL.Query().Get("cityCode"))
+	if len(cityCode) != 3 {
+		writeError(w, http.StatusBadRequest, "cityCode inválido")
+		return
+	}
+
+	var hotels []models.AmadeusHotel
+	for i := 1; i <= 3; i++ {
+		hotels = append(hotels, models.AmadeusHotel{
+			Type:      "hotel",
+			HotelID:   fmt.Sprintf("FK%s%03d", cityCode, i),
+			ChainCode: "FK",
+			Name:      fmt.Sprintf("FAKE HOTEL %s %d", cityCode, i),
+			Rating:    strconv.Itoa(2 + i),
+			CityCode:  cityCode,
+			Address: models.AmadeusHotelAddress{
+				CountryCode: "AR",
+				CityName:    "FAKE CITY " + cityCode,
+				PostalCode:  fmt.Sprintf("X50%02d", i),
+				Lines:       []string{fmt.Sprintf("AV FICTICIA %d", i*100)},
+			},
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": hotels})
+}
+
+// hotelsByID resuelve cualquier ID con formato de Amadeus (8 caracteres
+// alfanuméricos en mayúsculas)
+func (f *fakeAmadeus) hotelsByID(w http.ResponseWriter, r *http.Request) {
+	var hotels []models.AmadeusHotel
+	for _, hotelID := range strings.Split(r.URL.Query().Get("hotelIds"), ",") {
+		if !validHotelID(hotelID) {
+			continue
+		}
+		hotels = append(hotels, models.AmadeusHotel{
+			Type:      "hotel",
+			HotelID:   hotelID,
+			ChainCode: hotelID[:2],
+			Name:      "FAKE HOTEL " + hotelID,
+			CityCode:  hotelID[2:5],
+			Address:   models.AmadeusHotelAddress{CountryCode: "AR"},
+		})
+	}
+
+	if len(hotels) == 0 {
+		writeError(w, http.StatusBadRequest, "NOTHING FOUND FOR REQUESTED HOTEL IDS")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": hotels})
+}
+
+// validHotelID indica si el ID tiene el formato de Amadeus
+func validHotelID(hotelID string) bool {
+	if len(hotelID) != 8 {
+		return false
+	}
+	for _, r := range hotelID {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// fakeRooms habitaciones cotizadas en cada hotel; la suite no es reembolsable
+var fakeRooms = []struct {
+	code        string
+	category    string
+	description string
+	boardType   string
+	factor      float64
+}{
+	{code: "A1K", category: "STANDARD_ROOM", description: "Habitación estándar con cama king", boardType: "ROOM_ONLY", factor: 1},
+	{code: "S1K", category: "SUITE", description: "Suite con living y desayuno incluido", boardType: "BREAKFAST", factor: 1.8},
+}
+
+// hotelOffers cotiza una habitación estándar y una suite por hotel con precio determinista
+func (f *fakeAmadeus) hotelOffers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	checkIn, errIn := time.Parse("2006-01-02", query.Get("checkInDate"))
+	checkOut, errOut := time.Parse("2006-01-02", query.Get("checkOutDate"))
+	adults, _ := strconv.Atoi(query.Get("adults"))
+	if errIn != nil || errOut != nil || !checkOut.After(checkIn) || adults < 1 {
+		writeError(w, http.StatusBadRequest, "parámetros de búsqueda inválidos")
+		return
+	}
+
+	nights := int(checkOut.Sub(checkIn).Hours() / 24)
+
+	var data []models.AmadeusHotelOffer
+	for _, hotelID := range strings.Split(query.Get("hotelIds"), ",") {
+		if hotelID == "" {
+			continue
+		}
+
+		seed := digest(hotelID, query.Get("checkInDate"), query.Get("checkOutDate"), strconv.Itoa(adults))
+		nightly := 80 + float64(binary.BigEndian.Uint16(seed[:2])%120)
+		deadline := checkIn.AddDate(0, 0, -2).Format("2006-01-02") + "T18:00:00"
+
+		hotelOffer := models.AmadeusHotelOffer{
+			Type:      "hotel-offers",
+			Hotel:     models.AmadeusHotel{Type: "hotel", HotelID: hotelID, Name: "FAKE HOTEL " + hotelID},
+			Available: true,
+		}
+
+		for i, room := range fakeRooms {
+			total := nightly * float64(nights) * (1 + 0.25*float64(adults-1)) * room.factor
+			offerSeed := digest(hex.EncodeToString(seed), room.code)
+
+			offer := models.AmadeusOffer{
+				ID:           strings.ToUpper(hex.EncodeToString(offerSeed[:6])),
+				CheckInDate:  query.Get("checkInDate"),
+				CheckOutDate: query.Get("checkOutDate"),
+				RoomQuantity: 1,
+				RateCode:     "RAC",
+				BoardType:    room.boardType,
+				Room: models.AmadeusRoom{
+					Type:          room.code,
+					TypeCode:      room.code,
+					TypeEstimated: models.AmadeusRoomEstimated{Category: room.category, Beds: 1, BedType: "KING"},
+					Description:   models.AmadeusText{Text: room.description, Lang: "ES"},
+				},
+				Guests: models.AmadeusGuests{Adults: adults},
+				Price: models.AmadeusPrice{
+					Currency: "USD",
+					Base:     strconv.FormatFloat(total*0.9, 'f', 2, 64),
+					Total:    strconv.FormatFloat(total, 'f', 2, 64),
+				},
+			}
+			if i == 0 {
+				offer.Policies = models.AmadeusPolicies{
+					Cancellations: []models.AmadeusCancellation{{Deadline: deadline, Amount: strconv.FormatFloat(nightly, 'f', 2, 64)}},
+					Refundable:    &models.AmadeusRefundable{CancellationRefund: "REFUNDABLE_UP_TO_DEADLINE"},
+				}
+			} else {
+				offer.Policies = models.AmadeusPolicies{
+					Refundable: &models.AmadeusRefundable{CancellationRefund: "NON_REFUNDABLE"},
+				}
+			}
+
+			// La re-cotización devuelve el hotel con una sola oferta, como Amadeus
+			single := hotelOffer
+			single.Offers = []models.AmadeusOffer{offer}
+			f.mu.Lock()
+			f.offers[offer.ID] = single
+			f.mu.Unlock()
+
+			hotelOffer.Offers = append(hotelOffer.Offers, offer)
+		}
+
+		data = append(data, hotelOffer)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": data})
+}
+
+// hotelOffer re-cotiza una oferta emitida antes
+func (f *fakeAmadeus) hotelOffer(w http.ResponseWriter, r *http.Request) {
+	offerID := strings.TrimPrefix(r.URL.Path, "/v3/shopping/hotel-offers/")
+
+	f.mu.Lock()
+	hotelOffer, ok := f.offers[offerID]
+	f.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "offer not found")
+		return
+	}
+
+	if f.repricePct != 0 {
+		offer := hotelOffer.Offers[0]
+		total, _ := strconv.ParseFloat(offer.Price.Total, 64)
+		offer.Price.Total = strconv.FormatFloat(total*(1+f.repricePct/100), 'f', 2, 64)
+		hotelOffer.Offers = []models.AmadeusOffer{offer}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": hotelOffer})
+}
+
+// createBooking valida el payload de reserva como lo hace Amadeus
+func (f *fakeAmadeus) createBooking(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload struct {
+		Data struct {
+			OfferID  string                       `json:"offerId"`
+			Guests   []models.AmadeusBookingGuest `json:"guests"`
+			Payments []models.AmadeusPayment      `json:"payments"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	f.mu.Lock()
+	_, ok := f.offers[payload.Data.OfferID]
+	f.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusBadRequest, "offerId inválido o vencido")
+		return
+	}
+
+	if len(payload.Data.Guests) == 0 {
+		writeError(w, http.StatusBadRequest, "se requiere al menos un huésped")
+		return
+	}
+	for _, guest := range payload.Data.Guests {
+		if guest.Name.FirstName == "" || guest.Name.LastName == "" || guest.Contact.Email == "" {
+			writeError(w, http.StatusBadRequest, "cada huésped requiere nombre, apellido y email")
+			return
+		}
+	}
+
+	if len(payload.Data.Payments) == 0 {
+		writeError(w, http.StatusBadRequest, "se requiere un medio de pago")
+		return
+	}
+	card := payload.Data.Payments[0].Card
+	if card.VendorCode == "" || card.CardNumber == "" || card.ExpiryDate == "" {
+		writeError(w, http.StatusBadRequest, "datos de tarjeta incompletos")
+		return
+	}
+
+	f.mu.Lock()
+	f.nextID++
+	bookingID := fmt.Sprintf("FAKE-%06d", f.nextID)
+	f.bookings[bookingID] = payload.Data.OfferID
+	f.mu.Unlock()
+
+	log.Printf("✅ Reserva %s para la oferta %s (%s %s)", bookingID, payload.Data.OfferID,
+		payload.Data.Guests[0].Name.FirstName, payload.Data.Guests[0].Name.LastName)
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"data": []map[string]interface{}{{
+			"type":                   "hotel-booking",
+			"id":                     bookingID,
+			"providerConfirmationId": strings.TrimPrefix(bookingID, "FAKE-"),
+		}},
+	})
+}
+
+// cancelBooking cancela una reserva creada por createBooking
+func (f *fakeAmadeus) cancelBooking(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	bookingID := strings.TrimPrefix(r.URL.Path, "/v1/booking/hotel-bookings/")
+
+	f.mu.Lock()
+	_, ok := f.bookings[bookingID]
+	delete(f.bookings, bookingID)
+	f.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "booking not found")
+		return
+	}
+
+	log.Printf("🚫 Reserva %s cancelada", bookingID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// digest deriva bytes deterministas a partir de los parámetros de búsqueda
+func digest(parts ...string) []byte {
+	sum := sha1.Sum([]byte(strings.Join(parts, "|")))
+	return sum[:]
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeError responde con el formato de errores de Amadeus
+func writeError(w http.ResponseWriter, status int, detail string) {
+	writeJSON(w, status, map[string]interface{}{
+		"errors": []map[string]interface{}{{
+			"status": status,
+			"title":  http.StatusText(status),
+			"detail": detail,
+		}},
+	})
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+
+	"booking-service/internal/config"
+	"booking-service/internal/services"
+	"booking-service/migrations"
+	"booking-service/pkg/mysql"
+)
+
+// runMigrate ejecuta las migraciones del esquema.
+// Uso: ./main migrate up | ./main migrate down [pasos] | ./main migrate status
+func runMigrate(db *mysql.DB, args []string) {
+	all, err := mysql.LoadMigrations(migrations.FS)
+	if err != nil {
+		log.Fatalf("Error cargando migraciones: %v", err)
+	}
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		applied, err := db.MigrateUp(all)
+		if err != nil {
+			log.Fatalf("Error aplicando migraciones: %v", err)
+		}
+		log.Printf("✅ %d migraciones aplicadas", applied)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				log.Fatalf("Cantidad de pasos inválida: %s", args[1])
+			}
+		}
+		reverted, err := db.MigrateDown(all, steps)
+		if err != nil {
+			log.Fatalf("Error revirtiendo migraciones: %v", err)
+		}
+		log.Printf("✅ %d migraciones revertidas", reverted)
+
+	case "status":
+		statuses, err := db.MigrationsStatus(all)
+		if err != nil {
+			log.Fatalf("Error obteniendo estado de migraciones: %v", err)
+		}
+		for _, status := range statuses {
+			state := "pendiente"
+			if status.Applied {
+				state = "aplicada " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-30s %s\n", status.Version, status.Name, state)
+		}
+
+	default:
+		log.Fatalf("Subcomando de migrate desconocido: %s (usar up, down o status)", command)
+	}
+}
+
+// migrateUp aplica las migraciones pendientes al iniciar el servidor
+func migrateUp(db *mysql.DB) error {
+	all, err := mysql.LoadMigrations(migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	applied, err := db.MigrateUp(all)
+	if err != nil {
+		return err
+	}
+	if applied > 0 {
+		log.Printf("✅ %d migraciones aplicadas", applied)
+	}
+	return nil
+}
+
+// runCreateAdmin crea el administrador inicial o promueve a un usuario existente.
+// Uso: ./main create-admin -email admin@hotel.com -password secreto123
+func runCreateAdmin(bookingService *services.BookingService, cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("create-admin", flag.ExitOnError)
+	email := fs.String("email", cfg.AdminEmail, "email del administrador (ADMIN_EMAIL)")
+	password := fs.String("password", cfg.AdminPassword, "contraseña del administrador (ADMIN_PASSWORD)")
+	firstName := fs.String("first-name", "Admin", "nombre")
+	lastName := fs.String("last-name", "Sistema", "apellido")
+	fs.Parse(args)
+
+	created, err := bookingService.EnsureAdmin(*email, *password, *firstName, *lastName)
+	if err != nil {
+		log.Fatalf("Error creando administrador: %v", err)
+	}
+
+	if created {
+		log.Printf("✅ Administrador %s creado", *email)
+	} else {
+		log.Printf("✅ Usuario %s promovido a administrador", *email)
+	}
+}
+
+// seedAdmin crea el administrador de ADMIN_EMAIL/ADMIN_PASSWORD al iniciar,
+// solo si todavía no hay ningún administrador activo
+func seedAdmin(bookingService *services.BookingService, cfg *config.Config) {
+	if cfg.AdminEmail == "" || cfg.AdminPassword == "" {
+		return
+	}
+
+	hasAdmin, err := bookingService.HasAdmin()
+	if err != nil {
+		log.Printf("⚠️  Warning: No se pudo verificar administradores: %v", err)
+		return
+	}
+	if hasAdmin {
+		return
+	}
+
+	if _, err := bookingService.EnsureAdmin(cfg.AdminEmail, cfg.AdminPassword, "Admin", "Sistema"); err != nil {
+		log.Printf("⚠️  Warning: No se pudo crear el administrador inicial: %v", err)
+		return
+	}
+	log.Printf("✅ Administrador inicial %s creado", cfg.AdminEmail)
+}
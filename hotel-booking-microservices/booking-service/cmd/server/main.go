@@ -1,17 +1,27 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"booking-service/internal/config"
 	"booking-service/internal/handlers"
+	"booking-service/internal/inventory"
+	"booking-service/internal/middleware"
+	"booking-service/internal/models"
 	"booking-service/internal/services"
 	"booking-service/pkg/mysql"
 	"booking-service/pkg/memcached"
 	"booking-service/pkg/amadeus"
+	"booking-service/pkg/hotelservice"
+	"booking-service/pkg/mailer"
+	"booking-service/pkg/oidc"
+	"booking-service/pkg/payments"
+	"booking-service/pkg/rabbitmq"
 )
 
 func main() {
@@ -25,6 +35,18 @@ func main() {
 	}
 	defer db.Close()
 
+	// Migraciones del esquema: subcomando explícito o al iniciar
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(db, os.Args[2:])
+		return
+	}
+
+	if cfg.MigrateOnStart {
+		if err := migrateUp(db); err != nil {
+			log.Fatalf("Error aplicando migraciones: %v", err)
+		}
+	}
+
 	// Conectar a Memcached
 	mc, err := memcached.Connect(cfg.MemcachedURI)
 	if err != nil {
@@ -32,14 +54,26 @@ func main() {
 	}
 
 	// Conectar a Amadeus (corregido)
-	amadeusClient := amadeus.NewClient(cfg.AmadeusBaseURL, cfg.AmadeusClientID, cfg.AmadeusClientSecret)
+	amadeusClient := amadeus.NewClient(cfg.AmadeusBaseURL, cfg.AmadeusClientID, cfg.AmadeusClientSecret, amadeus.Options{
+		Timeout:          time.Duration(cfg.AmadeusTimeoutSeconds) * time.Second,
+		MaxRetries:       cfg.AmadeusMaxRetries,
+		RetryBaseDelay:   time.Duration(cfg.AmadeusRetryBaseMillis) * time.Millisecond,
+		RetryMaxDelay:    time.Duration(cfg.AmadeusRetryMaxMillis) * time.Millisecond,
+		RateLimit:        float64(cfg.AmadeusRateLimit),
+		RateBurst:        cfg.AmadeusRateBurst,
+		BreakerThreshold: cfg.AmadeusBreakerThreshold,
+		BreakerCooldown:  time.Duration(cfg.AmadeusBreakerCooldownSeconds) * time.Second,
+	})
+	if cfg.AmadeusShareToken {
+		amadeusClient.SetTokenCache(mc, memcached.GenerateTokenKey(cfg.AmadeusClientID))
+	}
 	
 	// Verificar que Amadeus está configurado correctamente
 	if cfg.AmadeusClientID == "" || cfg.AmadeusClientSecret == "" {
 		log.Printf("⚠️  Warning: Credenciales de Amadeus no configuradas")
 	} else {
 		// Intentar obtener token para verificar conectividad
-		if err := amadeusClient.GetAccessToken(); err != nil {
+		if err := amadeusClient.GetAccessToken(context.Background()); err != nil {
 			log.Printf("⚠️  Warning: Error obteniendo token de Amadeus: %v", err)
 		} else {
 			log.Printf("✅ Cliente Amadeus configurado correctamente")
@@ -47,13 +81,120 @@ func main() {
 	}
 
 	// Inicializar servicios
-	bookingService := services.NewBookingService(db, mc, amadeusClient, cfg.JWTSecret)
+	// Cliente de hotel-service para validar tipos de habitación
+	hotelClient := hotelservice.NewClient(cfg.HotelServiceURI)
+
+	// Mailer para recuperación de contraseña y verificación de email
+	mail, err := mailer.New(mailer.Config{
+		Driver:    cfg.MailDriver,
+		Host:      cfg.SMTPHost,
+		Port:      cfg.SMTPPort,
+		Username:  cfg.SMTPUsername,
+		Password:  cfg.SMTPPassword,
+		From:      cfg.MailFrom,
+		OutputDir: cfg.MailOutputDir,
+	})
+	if err != nil {
+		log.Fatalf("Error configurando mailer: %v", err)
+	}
+
+	// Login con proveedor externo (OpenID Connect), opcional
+	var oidcProvider *oidc.Provider
+	if cfg.OIDCIssuerURL != "" && cfg.OIDCClientID != "" {
+		oidcProvider = oidc.NewProvider(oidc.Config{
+			Name:         cfg.OIDCProviderName,
+			IssuerURL:    cfg.OIDCIssuerURL,
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+			RedirectURL:  cfg.OIDCRedirectURL,
+			Scopes:       cfg.OIDCScopes,
+		})
+		log.Printf("✅ Login OpenID Connect habilitado (%s)", cfg.OIDCIssuerURL)
+	}
+
+	// Proveedores de inventario, elegidos por hotel según hotel_mappings
+	providers := []inventory.InventoryProvider{
+		inventory.NewAmadeusProvider(amadeusClient, models.AmadeusPayment{
+			Method: cfg.AmadeusPaymentMethod,
+			Card: models.AmadeusPaymentCard{
+				VendorCode: cfg.AmadeusPaymentVendorCode,
+				CardNumber: cfg.AmadeusPaymentCardNumber,
+				ExpiryDate: cfg.AmadeusPaymentCardExpiry,
+			},
+		}),
+		inventory.NewLocalProvider(),
+		inventory.NewSimulatedProvider(),
+	}
+	if !hasProvider(providers, cfg.DefaultInventoryProvider) {
+		log.Fatalf("Proveedor de inventario por defecto desconocido: %s", cfg.DefaultInventoryProvider)
+	}
+	if !hasProvider(providers, cfg.FallbackInventoryProvider) {
+		log.Fatalf("Proveedor de inventario de respaldo desconocido: %s", cfg.FallbackInventoryProvider)
+	}
+
+	// Pasarela de pagos; "none" confirma las reservas sin cobrar
+	paymentGateway, err := payments.New(payments.Config{
+		Driver:        cfg.PaymentGateway,
+		WebhookURL:    cfg.PaymentWebhookURL,
+		WebhookSecret: cfg.PaymentWebhookSecret,
+		WebhookDelay:  time.Duration(cfg.PaymentWebhookDelaySeconds) * time.Second,
+	})
+	if err != nil {
+		log.Fatalf("Error configurando pasarela de pagos: %v", err)
+	}
+
+	bookingService := services.NewBookingService(db, mc, hotelClient, services.Options{
+		JWTSecret:            cfg.JWTSecret,
+		CancellationDeadline: time.Duration(cfg.CancellationDeadlineHours) * time.Hour,
+		DefaultRoomInventory: cfg.DefaultRoomInventory,
+		AccessTokenTTL:       time.Duration(cfg.AccessTokenTTLMinutes) * time.Minute,
+		RefreshTokenTTL:      time.Duration(cfg.RefreshTokenTTLHours) * time.Hour,
+		Mailer:               mail,
+		FrontendURL:          cfg.FrontendURL,
+		LoginMaxAttempts:     cfg.LoginMaxAttempts,
+		LoginIPMaxAttempts:   cfg.LoginIPMaxAttempts,
+		LoginLockout:         time.Duration(cfg.LoginLockoutMinutes) * time.Minute,
+		OIDCProvider:         oidcProvider,
+		SyncMaxAttempts:      cfg.SyncMaxAttempts,
+		SyncBaseBackoff:      time.Duration(cfg.SyncBackoffSeconds) * time.Second,
+		Providers:            providers,
+		DefaultProvider:      cfg.DefaultInventoryProvider,
+		FallbackProvider:     cfg.FallbackInventoryProvider,
+		AmadeusClient:        amadeusClient,
+		PaymentGateway:       paymentGateway,
+		PaymentWebhookSecret: cfg.PaymentWebhookSecret,
+		PaymentPendingTimeout: time.Duration(cfg.PaymentPendingMinutes) * time.Minute,
+		IdempotencyKeyTTL:     time.Duration(cfg.IdempotencyKeyTTLHours) * time.Hour,
+	})
+
+	// Comando de una sola ejecución para crear el primer administrador
+	if len(os.Args) > 1 && os.Args[1] == "create-admin" {
+		runCreateAdmin(bookingService, cfg, os.Args[2:])
+		return
+	}
+
+	seedAdmin(bookingService, cfg)
+
+	// Publicar en RabbitMQ los eventos de reserva guardados en outbox_events
+	publisher := rabbitmq.NewPublisher(cfg.RabbitMQURI, "booking_events")
+	defer publisher.Close()
+	relay := services.NewOutboxRelay(db, publisher, time.Duration(cfg.OutboxPollSeconds)*time.Second, cfg.OutboxBatchSize)
+	go relay.Run(context.Background())
+
+	// Sincronizar reservas con Amadeus desde la cola persistente
+	go bookingService.RunProviderSync(context.Background(), time.Duration(cfg.SyncPollSeconds)*time.Second)
+
+	// Capturar, liberar y reintegrar pagos; cancelar reservas sin pago a tiempo
+	go bookingService.RunPaymentSettlement(context.Background(), time.Duration(cfg.PaymentPollSeconds)*time.Second)
+
+	// Borrar las claves de idempotencia vencidas
+	go bookingService.RunIdempotencyPurge(context.Background(), time.Hour)
 
 	// Inicializar handlers
 	bookingHandler := handlers.NewBookingHandler(bookingService)
 
 	// Configurar rutas
-	router := setupRoutes(bookingHandler)
+	router := setupRoutes(bookingHandler, bookingService, cfg.TrustedProxies)
 
 	// Obtener puerto
 	port := cfg.Port
@@ -64,7 +205,7 @@ func main() {
 	}
 }
 
-func setupRoutes(bookingHandler *handlers.BookingHandler) *gin.Engine {
+func setupRoutes(bookingHandler *handlers.BookingHandler, bookingService *services.BookingService, trustedProxies []string) *gin.Engine {
 	// Configurar Gin
 	if os.Getenv("ENVIRONMENT") == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -72,11 +213,17 @@ func setupRoutes(bookingHandler *handlers.BookingHandler) *gin.Engine {
 
 	router := gin.Default()
 
+	// Solo se confía en X-Forwarded-For de los proxies configurados; si no, la IP
+	// usada para limitar intentos de login podría falsificarse
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		log.Fatalf("Error configurando proxies de confianza: %v", err)
+	}
+
 	// Middleware para CORS
 	router.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, Idempotency-Key")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -104,26 +251,93 @@ func setupRoutes(bookingHandler *handlers.BookingHandler) *gin.Engine {
 		// Rutas públicas (sin autenticación)
 		api.POST("/auth/register", bookingHandler.Register)
 		api.POST("/auth/login", bookingHandler.Login)
+		api.POST("/auth/refresh", bookingHandler.RefreshToken)
+		api.POST("/auth/logout", bookingHandler.Logout)
+		api.POST("/auth/forgot-password", bookingHandler.ForgotPassword)
+		api.POST("/auth/reset-password", bookingHandler.ResetPassword)
+		api.POST("/auth/verify-email", bookingHandler.VerifyEmail)
+		api.GET("/auth/oidc/login", bookingHandler.OIDCLogin)
+		api.GET("/auth/oidc/callback", bookingHandler.OIDCCallback)
 		
 		// Rutas de disponibilidad (públicas)
 		api.GET("/availability/:hotelId", bookingHandler.CheckAvailability)
 
+		// Notificaciones de la pasarela de pagos (firmadas, sin autenticación)
+		api.POST("/payments/webhook", bookingHandler.PaymentWebhook)
+
 		// Rutas protegidas (requieren autenticación)
 		protected := api.Group("")
 		protected.Use(bookingHandler.AuthMiddleware())
 		{
 			// Perfil de usuario
 			protected.GET("/profile", bookingHandler.GetProfile)
+			protected.PUT("/profile", bookingHandler.UpdateProfile)
+			protected.DELETE("/profile", bookingHandler.DeleteProfile)
+			protected.PUT("/profile/password", bookingHandler.ChangePassword)
+			protected.GET("/profile/export", bookingHandler.ExportProfile)
+			protected.POST("/auth/resend-verification", bookingHandler.ResendVerification)
+			protected.GET("/auth/verify", bookingHandler.VerifyToken) // Verificación de tokens desde hotel-service
 			
 			// Reservas
 			bookings := protected.Group("/bookings")
 			{
-				bookings.POST("", bookingHandler.CreateBooking)                    // Crear reserva
+				bookings.POST("", bookingHandler.IdempotencyMiddleware(), bookingHandler.CreateBooking) // Crear reserva (acepta Idempotency-Key)
 				bookings.GET("/my-bookings", bookingHandler.GetBookings)           // NUEVA RUTA - Mis reservas
 				bookings.GET("/:id", bookingHandler.GetBookingByID)                // Obtener reserva por ID
+				bookings.GET("/reference/:reference", bookingHandler.GetBookingByReference) // Obtener reserva por referencia
+				bookings.PUT("/:id", bookingHandler.UpdateBooking)                 // Modificar reserva
+				bookings.POST("/:id/cancel", bookingHandler.CancelBooking)         // Cancelar reserva
+				bookings.POST("/:id/rooms/:roomId/cancel", bookingHandler.CancelBookingRoom) // Cancelar una habitación
+				bookings.POST("/:id/payment", bookingHandler.PayBooking)            // Pagar reserva pendiente
 			}
 		}
+
+		// Rutas de administración (requieren rol admin)
+		admin := api.Group("/admin")
+		admin.Use(middleware.AuthMiddleware(bookingService), middleware.AdminMiddleware())
+		{
+			admin.GET("/inventory/:hotelId", bookingHandler.GetInventory) // Ver inventario
+			admin.PUT("/inventory/:hotelId", bookingHandler.SetInventory) // Cargar inventario
+			admin.GET("/rates/:hotelId", bookingHandler.GetRates)         // Ver calendario de tarifas
+			admin.PUT("/rates/:hotelId", bookingHandler.SetRates)         // Cargar tarifas por noche
+			admin.GET("/pricing-rules/:hotelId", bookingHandler.GetPricingRules)
+			admin.POST("/pricing-rules/:hotelId", bookingHandler.CreatePricingRule)
+			admin.DELETE("/pricing-rules/:hotelId/:ruleId", bookingHandler.DeletePricingRule)
+
+			// Administración de usuarios
+			admin.GET("/users", bookingHandler.ListUsers)                        // Listar usuarios
+			admin.GET("/users/:id", bookingHandler.GetUser)                      // Obtener usuario
+			admin.PUT("/users/:id", bookingHandler.UpdateUser)                   // Editar datos
+			admin.PUT("/users/:id/role", bookingHandler.UpdateUserRole)          // Promover / degradar
+			admin.POST("/users/:id/deactivate", bookingHandler.DeactivateUser)   // Desactivar
+			admin.POST("/users/:id/activate", bookingHandler.ActivateUser)       // Reactivar
+
+			// Sincronización con el proveedor externo
+			admin.GET("/sync-jobs", bookingHandler.ListSyncJobs)
+			admin.POST("/sync-jobs/:id/retry", bookingHandler.RetrySyncJob)
+
+			// Catálogo de hoteles de Amadeus
+			admin.POST("/hotel-imports", bookingHandler.ImportHotels) // Importar hoteles de una ciudad
+
+			// Mapeo de hoteles a su proveedor de inventario
+			admin.GET("/hotel-mappings", bookingHandler.ListHotelMappings)
+			admin.GET("/hotel-mappings/unmapped", bookingHandler.ListUnmappedHotels) // Hoteles activos sin mapeo
+			admin.GET("/hotel-mappings/:id", bookingHandler.GetHotelMapping)
+			admin.POST("/hotel-mappings", bookingHandler.CreateHotelMapping)
+			admin.PUT("/hotel-mappings/:id", bookingHandler.UpdateHotelMapping)
+			admin.DELETE("/hotel-mappings/:id", bookingHandler.DeleteHotelMapping)
+		}
 	}
 
 	return router
-}
\ No newline at end of file
+}
+
+// hasProvider indica si hay un proveedor registrado con ese nombre
+func hasProvider(providers []inventory.InventoryProvider, name string) bool {
+	for _, provider := range providers {
+		if provider.Name() == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,229 @@
+// Command stubidp es un proveedor OpenID Connect mínimo para desarrollo local.
+// Acepta cualquier email que se ingrese en el formulario y lo informa como
+// verificado. No usar fuera de un entorno de desarrollo.
+//
+//	go run ./cmd/stubidp
+//	OIDC_ISSUER_URL=http://localhost:9000 OIDC_CLIENT_ID=booking-service go run ./cmd/server
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"html/template"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const keyID = "stub-1"
+
+// authorization datos asociados a un código emitido
+type authorization struct {
+	ClientID      string
+	RedirectURI   string
+	CodeChallenge string
+	Nonce         string
+	Email         string
+	Name          string
+	ExpiresAt     time.Time
+}
+
+type stubIdP struct {
+	issuer string
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]authorization
+}
+
+var loginForm = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Stub IdP</title></head>
+<body style="font-family: sans-serif; max-width: 400px; margin: 60px auto">
+<h2>🔐 Stub IdP</h2>
+<p>Proveedor de identidad de desarrollo. Ingresá cualquier email.</p>
+<form method="POST" action="/authorize">
+  {{range $k, $v := .Params}}<input type="hidden" name="{{$k}}" value="{{index $v 0}}">{{end}}
+  <p><label>Email<br><input name="email" type="email" required style="width: 100%"></label></p>
+  <p><label>Nombre<br><input name="name" value="Usuario Externo" style="width: 100%"></label></p>
+  <button type="submit">Ingresar</button>
+</form>
+</body></html>`))
+
+func main() {
+	port := getEnv("PORT", "9000")
+	issuer := getEnv("STUB_IDP_ISSUER", "http://localhost:"+port)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		log.Fatalf("Error generando clave: %v", err)
+	}
+
+	idp := &stubIdP{issuer: issuer, key: key, codes: make(map[string]authorization)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/authorize", idp.authorize)
+	mux.HandleFunc("/token", idp.token)
+	mux.HandleFunc("/jwks", idp.jwks)
+
+	log.Printf("🔐 Stub IdP escuchando en :%s (issuer %s)", port, issuer)
+	if err := http.ListenAndServe(":"+port, mux); err != nil {
+		log.Fatalf("Error iniciando servidor: %v", err)
+	}
+}
+
+func (idp *stubIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                idp.issuer,
+		"authorization_endpoint":                idp.issuer + "/authorize",
+		"token_endpoint":                        idp.issuer + "/token",
+		"jwks_uri":                              idp.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+// authorize muestra el formulario (GET) y emite el código (POST)
+func (idp *stubIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "formulario inválido", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		if r.Form.Get("response_type") != "code" || r.Form.Get("code_challenge_method") != "S256" {
+			http.Error(w, "se requiere response_type=code y PKCE S256", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		loginForm.Execute(w, map[string]interface{}{"Params": r.URL.Query()})
+		return
+	}
+
+	redirectURI, err := url.Parse(r.Form.Get("redirect_uri"))
+	if err != nil || redirectURI.Scheme == "" {
+		http.Error(w, "redirect_uri inválido", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+	idp.mu.Lock()
+	idp.codes[code] = authorization{
+		ClientID:      r.Form.Get("client_id"),
+		RedirectURI:   redirectURI.String(),
+		CodeChallenge: r.Form.Get("code_challenge"),
+		Nonce:         r.Form.Get("nonce"),
+		Email:         r.Form.Get("email"),
+		Name:          r.Form.Get("name"),
+		ExpiresAt:     time.Now().Add(time.Minute),
+	}
+	idp.mu.Unlock()
+
+	query := redirectURI.Query()
+	query.Set("code", code)
+	query.Set("state", r.Form.Get("state"))
+	redirectURI.RawQuery = query.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+// token canjea el código validando redirect_uri y el code_verifier de PKCE
+func (idp *stubIdP) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil || r.Form.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	code := r.Form.Get("code")
+	idp.mu.Lock()
+	auth, ok := idp.codes[code]
+	delete(idp.codes, code)
+	idp.mu.Unlock()
+
+	clientID := r.Form.Get("client_id")
+	if user, _, hasBasic := r.BasicAuth(); hasBasic {
+		clientID, _ = url.QueryUnescape(user)
+	}
+
+	sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+	switch {
+	case !ok || time.Now().After(auth.ExpiresAt):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	case auth.RedirectURI != r.Form.Get("redirect_uri") || auth.ClientID != clientID:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	case base64.RawURLEncoding.EncodeToString(sum[:]) != auth.CodeChallenge:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE inválido"})
+		return
+	}
+
+	now := time.Now()
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            idp.issuer,
+		"sub":            "stub|" + auth.Email,
+		"aud":            auth.ClientID,
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          auth.Nonce,
+		"email":          auth.Email,
+		"email_verified": true,
+		"name":           auth.Name,
+	})
+	idToken.Header["kid"] = keyID
+
+	signed, err := idToken.SignedString(idp.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"id_token":     signed,
+		"expires_in":   300,
+	})
+}
+
+func (idp *stubIdP) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kid": keyID,
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(idp.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(idp.key.E)).Bytes()),
+		}},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func randomString() string {
+	raw := make([]byte, 24)
+	rand.Read(raw)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -8,7 +8,9 @@ require (
 	github.com/go-playground/validator/v10 v10.14.0
 	github.com/go-sql-driver/mysql v1.7.1
 	github.com/golang-jwt/jwt/v5 v5.0.0
+	github.com/rabbitmq/amqp091-go v1.10.0
 	golang.org/x/crypto v0.14.0
+	golang.org/x/sync v0.0.0-20220722155255-886fb9371eb4
 )
 
 require (
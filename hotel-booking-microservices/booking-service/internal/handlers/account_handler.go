@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"booking-service/internal/models"
+)
+
+// ForgotPassword envía un enlace de recuperación de contraseña.
+// Siempre responde lo mismo para no revelar qué emails están registrados.
+func (h *BookingHandler) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+
+	// Bind JSON
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de entrada inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Validar datos
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de validación fallidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.bookingService.RequestPasswordReset(req.Email); err != nil {
+		// Se loguea pero no se expone al cliente
+		log.Printf("⚠️ Error enviando recuperación de contraseña: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Si el email está registrado, recibirás un enlace para restablecer tu contraseña",
+	})
+}
+
+// ResetPassword establece una nueva contraseña a partir de un token de recuperación
+func (h *BookingHandler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+
+	// Bind JSON
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de entrada inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Validar datos
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de validación fallidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.bookingService.ResetPassword(req.Token, req.Password); err != nil {
+		h.respondAccountTokenError(c, "Error restableciendo contraseña", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Contraseña actualizada exitosamente. Volvé a iniciar sesión",
+	})
+}
+
+// VerifyEmail confirma el email del usuario con el token recibido por correo
+func (h *BookingHandler) VerifyEmail(c *gin.Context) {
+	var req models.VerifyEmailRequest
+
+	// Bind JSON
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de entrada inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Validar datos
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de validación fallidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.bookingService.VerifyEmail(req.Token); err != nil {
+		h.respondAccountTokenError(c, "Error verificando email", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Email verificado exitosamente",
+	})
+}
+
+// ResendVerification reenvía el email de verificación al usuario autenticado
+func (h *BookingHandler) ResendVerification(c *gin.Context) {
+	user, err := h.bookingService.GetUserByID(h.getUserIDFromContext(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Usuario no encontrado",
+		})
+		return
+	}
+
+	if err := h.bookingService.SendEmailVerification(user); err != nil {
+		if strings.Contains(err.Error(), "ya está verificado") {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "El email ya está verificado",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error enviando verificación",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Email de verificación enviado",
+	})
+}
+
+// respondAccountTokenError traduce errores de tokens enviados por email a códigos HTTP
+func (h *BookingHandler) respondAccountTokenError(c *gin.Context, message string, err error) {
+	if strings.Contains(err.Error(), "token inválido") {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Token inválido o expirado",
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error":   message,
+		"details": err.Error(),
+	})
+}
@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"booking-service/internal/models"
+)
+
+// GetInventory lista el inventario de un hotel (Solo Admin)
+func (h *BookingHandler) GetInventory(c *gin.Context) {
+	hotelID := c.Param("hotelId")
+
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	inventory, err := h.bookingService.GetInventory(hotelID, c.Query("room_type"), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error obteniendo inventario",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  inventory,
+		"count": len(inventory),
+	})
+}
+
+// SetInventory carga la cantidad de habitaciones de un hotel (Solo Admin)
+func (h *BookingHandler) SetInventory(c *gin.Context) {
+	hotelID := c.Param("hotelId")
+
+	var req models.SetInventoryRequest
+
+	// Bind JSON
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de entrada inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Validar datos
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de validación fallidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.bookingService.SetInventory(hotelID, &req); err != nil {
+		switch {
+		case strings.Contains(err.Error(), "fecha inválida"):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		case strings.Contains(err.Error(), "no se puede"):
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error guardando inventario",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Inventario actualizado exitosamente",
+	})
+}
+
+// GetRates lista el calendario de tarifas de un hotel (Solo Admin)
+func (h *BookingHandler) GetRates(c *gin.Context) {
+	hotelID := c.Param("hotelId")
+
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	rates, err := h.bookingService.GetRates(hotelID, c.Query("room_type"), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error obteniendo tarifas",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  rates,
+		"count": len(rates),
+	})
+}
+
+// SetRates carga la tarifa base por noche de un tipo de habitación (Solo Admin)
+func (h *BookingHandler) SetRates(c *gin.Context) {
+	hotelID := c.Param("hotelId")
+
+	var req models.SetRatesRequest
+
+	// Bind JSON
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de entrada inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Validar datos
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de validación fallidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.bookingService.SetRates(hotelID, &req); err != nil {
+		if strings.Contains(err.Error(), "fecha inválida") || strings.Contains(err.Error(), "moneda inválida") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error guardando tarifas",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tarifas actualizadas exitosamente",
+	})
+}
+
+// GetPricingRules lista las reglas de precio de un hotel (Solo Admin)
+func (h *BookingHandler) GetPricingRules(c *gin.Context) {
+	rules, err := h.bookingService.GetPricingRules(c.Param("hotelId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error obteniendo reglas de precio",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  rules,
+		"count": len(rules),
+	})
+}
+
+// CreatePricingRule crea una regla de precio (Solo Admin)
+func (h *BookingHandler) CreatePricingRule(c *gin.Context) {
+	hotelID := c.Param("hotelId")
+
+	var req models.CreatePricingRuleRequest
+
+	// Bind JSON
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de entrada inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Validar datos
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de validación fallidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	rule, err := h.bookingService.CreatePricingRule(hotelID, &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "regla inválida") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error creando regla de precio",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Regla de precio creada exitosamente",
+		"data":    rule,
+	})
+}
+
+// DeletePricingRule desactiva una regla de precio (Solo Admin)
+func (h *BookingHandler) DeletePricingRule(c *gin.Context) {
+	ruleID, err := strconv.Atoi(c.Param("ruleId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "ID de regla inválido",
+		})
+		return
+	}
+
+	if err := h.bookingService.DeletePricingRule(c.Param("hotelId"), ruleID); err != nil {
+		if strings.Contains(err.Error(), "no encontrada") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error eliminando regla de precio",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Regla de precio eliminada exitosamente",
+	})
+}
+
+// parseDateRange lee los parámetros from/to (YYYY-MM-DD). Por defecto se
+// usan los próximos 30 días. Si el formato es inválido responde 400.
+func parseDateRange(c *gin.Context) (time.Time, time.Time, bool) {
+	from := time.Now().Truncate(24 * time.Hour)
+	to := from.AddDate(0, 0, 30)
+
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Formato de fecha inválido para from (usar YYYY-MM-DD)",
+			})
+			return from, to, false
+		}
+		from = parsed
+	}
+
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Formato de fecha inválido para to (usar YYYY-MM-DD)",
+			})
+			return from, to, false
+		}
+		to = parsed
+	}
+
+	return from, to, true
+}
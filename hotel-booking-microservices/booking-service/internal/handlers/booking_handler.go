@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -11,6 +12,7 @@ import (
 
 	"booking-service/internal/models"
 	"booking-service/internal/services"
+	"booking-service/pkg/bookingref"
 )
 
 // BookingHandler maneja las peticiones HTTP de reservas
@@ -95,19 +97,124 @@ func (h *BookingHandler) Login(c *gin.Context) {
 	}
 
 	// Autenticar usuario
-	user, token, err := h.bookingService.LoginUser(&req)
+	auth, err := h.bookingService.LoginUser(&req, c.ClientIP())
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Credenciales inválidas",
+		var locked *services.LoginLockedError
+		if errors.As(err, &locked) {
+			c.Header("Retry-After", strconv.Itoa(locked.RetryAfterSeconds()))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error": "Demasiados intentos fallidos. Intentá nuevamente más tarde",
+				"retry_after": locked.RetryAfterSeconds(),
+			})
+			return
+		}
+		if strings.Contains(err.Error(), "credenciales inválidas") {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Credenciales inválidas",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error iniciando sesión",
+			"details": err.Error(),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Login exitoso",
-		"data": models.AuthResponse{
-			User:  user,
-			Token: token,
+		"data": auth,
+	})
+}
+
+// RefreshToken emite un nuevo access token a partir de un refresh token
+func (h *BookingHandler) RefreshToken(c *gin.Context) {
+	var req models.RefreshTokenRequest
+
+	// Bind JSON
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Datos de entrada inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Validar datos
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Datos de validación fallidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	auth, err := h.bookingService.RefreshTokens(req.RefreshToken)
+	if err != nil {
+		if strings.Contains(err.Error(), "refresh token inválido") {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Refresh token inválido o expirado",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error renovando token",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Token renovado exitosamente",
+		"data": auth,
+	})
+}
+
+// Logout revoca el refresh token de la sesión
+func (h *BookingHandler) Logout(c *gin.Context) {
+	var req models.RefreshTokenRequest
+
+	// Bind JSON
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Datos de entrada inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Validar datos
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Datos de validación fallidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.bookingService.RevokeRefreshToken(req.RefreshToken); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error cerrando sesión",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Sesión cerrada exitosamente",
+	})
+}
+
+// VerifyToken confirma que el access token sigue vigente. Lo usa hotel-service,
+// que valida la firma pero no conoce la versión de tokens actual del usuario.
+func (h *BookingHandler) VerifyToken(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	role, _ := c.Get("userRole")
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"user_id": userID,
+			"role":    role,
 		},
 	})
 }
@@ -203,10 +310,11 @@ func (h *BookingHandler) CheckAvailability(c *gin.Context) {
 		CheckInDate:  checkIn,
 		CheckOutDate: checkOut,
 		Guests:       guests,
+		RoomType:     c.Query("room_type"),
 	}
 
 	// Verificar disponibilidad
-	availability, err := h.bookingService.CheckAvailability(req)
+	availability, err := h.bookingService.CheckAvailability(c.Request.Context(), req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Error verificando disponibilidad",
@@ -266,8 +374,23 @@ func (h *BookingHandler) CreateBooking(c *gin.Context) {
 	}
 
 	// Crear reserva
-	booking, err := h.bookingService.CreateBooking(userID, &req)
+	req.IdempotencyKey = strings.TrimSpace(c.GetHeader(idempotencyHeader))
+	booking, err := h.bookingService.CreateBooking(c.Request.Context(), userID, &req)
 	if err != nil {
+		if strings.Contains(err.Error(), "tipo de habitación inválido") || strings.Contains(err.Error(), "capacidad insuficiente") || strings.Contains(err.Error(), "huéspedes para una reserva") || strings.Contains(err.Error(), "habitaciones inválidas") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		if strings.Contains(err.Error(), "hotel no encontrado") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Hotel no encontrado",
+			})
+			return
+		}
+
 		if strings.Contains(err.Error(), "no disponible") {
 			c.JSON(http.StatusConflict, gin.H{
 				"error": err.Error(),
@@ -275,6 +398,20 @@ func (h *BookingHandler) CreateBooking(c *gin.Context) {
 			return
 		}
 
+		// El cobro no se autorizó: la reserva se canceló o sigue pendiente de pago
+		if strings.Contains(err.Error(), "pago rechazado") || strings.Contains(err.Error(), "error procesando pago") {
+			h.respondPaymentError(c, "Error procesando pago", err)
+			return
+		}
+
+		// La oferta de Amadeus venció o cambió de precio: hay que volver a cotizar
+		if strings.Contains(err.Error(), "la oferta ya no está disponible") || strings.Contains(err.Error(), "el precio de la oferta cambió") {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Error creando reserva",
 			"details": err.Error(),
@@ -282,8 +419,13 @@ func (h *BookingHandler) CreateBooking(c *gin.Context) {
 		return
 	}
 
+	message := "Reserva creada exitosamente"
+	if booking.Status == models.StatusPending {
+		message = "Reserva creada: pendiente de pago"
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
-		"message": "Reserva creada exitosamente",
+		"message": message,
 		"data": booking,
 	})
 }
@@ -353,6 +495,248 @@ func (h *BookingHandler) GetBookingByID(c *gin.Context) {
 	})
 }
 
+// GetBookingByReference obtiene una reserva por su referencia (BK-XXXXX-XXXXX).
+// Acepta la referencia tal como la tipea el usuario: minúsculas, sin guiones o
+// con letras ambiguas.
+func (h *BookingHandler) GetBookingByReference(c *gin.Context) {
+	userID := h.getUserIDFromContext(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Token requerido",
+		})
+		return
+	}
+
+	// Una referencia mal copiada se rechaza sin consultar la base
+	reference := bookingref.Normalize(c.Param("reference"))
+	if !bookingref.Valid(reference) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Referencia de reserva inválida",
+		})
+		return
+	}
+
+	booking, err := h.bookingService.GetBookingByReference(reference)
+	if err != nil {
+		if strings.Contains(err.Error(), "no encontrada") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Reserva no encontrada",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error obteniendo reserva",
+		})
+		return
+	}
+
+	// Verificar que la reserva pertenezca al usuario
+	if booking.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "No tienes acceso a esta reserva",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": booking,
+	})
+}
+
+// CancelBooking cancela una reserva del usuario autenticado
+func (h *BookingHandler) CancelBooking(c *gin.Context) {
+	booking, ok := h.getOwnedBooking(c)
+	if !ok {
+		return
+	}
+
+	var req models.CancelBookingRequest
+
+	// El motivo es opcional, por lo que el body puede venir vacío
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Datos de entrada inválidos",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Datos de validación fallidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	cancelled, err := h.bookingService.CancelBooking(booking.ID, req.Reason)
+	if err != nil {
+		h.respondLifecycleError(c, "Error cancelando reserva", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reserva cancelada exitosamente",
+		"data": cancelled,
+	})
+}
+
+// CancelBookingRoom cancela una sola habitación de la reserva
+func (h *BookingHandler) CancelBookingRoom(c *gin.Context) {
+	booking, ok := h.getOwnedBooking(c)
+	if !ok {
+		return
+	}
+
+	roomID, err := strconv.Atoi(c.Param("roomId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "ID de habitación inválido",
+		})
+		return
+	}
+
+	var req models.CancelBookingRequest
+
+	// El motivo es opcional, por lo que el body puede venir vacío
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Datos de entrada inválidos",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Datos de validación fallidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	updated, err := h.bookingService.CancelBookingRoom(booking.ID, roomID, req.Reason)
+	if err != nil {
+		if strings.Contains(err.Error(), "habitación no encontrada") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Habitación no encontrada",
+			})
+			return
+		}
+		h.respondLifecycleError(c, "Error cancelando habitación", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Habitación cancelada exitosamente",
+		"data": updated,
+	})
+}
+
+// UpdateBooking modifica fechas, huéspedes o tipo de habitación de una reserva
+func (h *BookingHandler) UpdateBooking(c *gin.Context) {
+	booking, ok := h.getOwnedBooking(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateBookingRequest
+
+	// Bind JSON
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Datos de entrada inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Validar datos
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Datos de validación fallidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	updated, err := h.bookingService.ModifyBooking(c.Request.Context(), booking.ID, &req)
+	if err != nil {
+		h.respondLifecycleError(c, "Error modificando reserva", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reserva modificada exitosamente",
+		"data": updated,
+	})
+}
+
+// getOwnedBooking obtiene la reserva de la URL verificando que pertenezca al usuario
+func (h *BookingHandler) getOwnedBooking(c *gin.Context) (*models.Booking, bool) {
+	userID := h.getUserIDFromContext(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Token requerido",
+		})
+		return nil, false
+	}
+
+	bookingID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "ID de reserva inválido",
+		})
+		return nil, false
+	}
+
+	booking, err := h.bookingService.GetBookingByID(bookingID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Reserva no encontrada",
+		})
+		return nil, false
+	}
+
+	if booking.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "No tienes acceso a esta reserva",
+		})
+		return nil, false
+	}
+
+	return booking, true
+}
+
+// respondLifecycleError traduce errores de cancelación/modificación a códigos HTTP
+func (h *BookingHandler) respondLifecycleError(c *gin.Context, message string, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "fecha inválida"), strings.Contains(msg, "tipo de habitación inválido"), strings.Contains(msg, "capacidad insuficiente"):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": msg,
+		})
+	case strings.Contains(msg, "no se puede"), strings.Contains(msg, "plazo"), strings.Contains(msg, "no disponible"):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": msg,
+		})
+	case strings.Contains(msg, "Amadeus"):
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error": message,
+			"details": msg,
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": message,
+			"details": msg,
+		})
+	}
+}
+
 // AuthMiddleware middleware de autenticación
 func (h *BookingHandler) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -379,7 +763,7 @@ func (h *BookingHandler) AuthMiddleware() gin.HandlerFunc {
 		token := parts[1]
 
 		// Validar token
-		userID, err := h.bookingService.ValidateJWTToken(token)
+		claims, err := h.bookingService.ParseAccessToken(token)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "Token inválido",
@@ -388,8 +772,9 @@ func (h *BookingHandler) AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Guardar userID en el contexto
-		c.Set("userID", userID)
+		// Guardar datos del usuario en el contexto
+		c.Set("userID", claims.UserID)
+		c.Set("userRole", claims.Role)
 		c.Next()
 	}
 }
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"booking-service/internal/services"
+	"booking-service/pkg/bookingref"
+)
+
+func TestGetBookingByReferenceRejectsInvalidReference(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Sin base de datos: una referencia inválida no debe llegar a consultarla
+	handler := NewBookingHandler(services.NewBookingService(nil, nil, nil, services.Options{}))
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("userID", 1) })
+	router.GET("/api/bookings/:id", handler.GetBookingByID)
+	router.GET("/api/bookings/reference/:reference", handler.GetBookingByReference)
+
+	ref, err := bookingref.New()
+	if err != nil {
+		t.Fatalf("error generando referencia: %v", err)
+	}
+	code := []byte(ref)
+	last := len(code) - 1
+	if code[last] == '0' {
+		code[last] = '1'
+	} else {
+		code[last] = '0'
+	}
+
+	for _, reference := range []string{string(code), "BK-12345", "no-es-una-referencia"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/bookings/reference/"+reference, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, se esperaba 400: %s", reference, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"booking-service/internal/models"
+)
+
+// ImportHotels importa el catálogo de Amadeus de una ciudad (Solo Admin)
+func (h *BookingHandler) ImportHotels(c *gin.Context) {
+	var req models.HotelImportRequest
+
+	// Bind JSON
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de entrada inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Validar datos
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de validación fallidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// El token del administrador se reenvía a hotel-service, que exige rol admin
+	report, err := h.bookingService.ImportHotels(c.Request.Context(), c.GetHeader("Authorization"), &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "Amadeus") {
+			c.JSON(http.StatusBadGateway, gin.H{
+				"error":   "Error consultando el catálogo de Amadeus",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error importando hoteles",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	message := "Importación completada"
+	if req.DryRun {
+		message = "Simulación de importación completada (sin cambios)"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"data":    report,
+	})
+}
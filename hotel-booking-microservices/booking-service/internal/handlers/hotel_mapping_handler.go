@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"booking-service/internal/models"
+)
+
+// ListHotelMappings lista los mapeos de hoteles (Solo Admin)
+func (h *BookingHandler) ListHotelMappings(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filter := models.HotelMappingFilter{
+		Provider: c.Query("provider"),
+		City:     c.Query("city"),
+		Page:     page,
+		Limit:    limit,
+	}
+
+	mappings, total, err := h.bookingService.ListHotelMappings(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error obteniendo mapeos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  mappings,
+		"count": len(mappings),
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// GetHotelMapping obtiene un mapeo por ID (Solo Admin)
+func (h *BookingHandler) GetHotelMapping(c *gin.Context) {
+	mappingID, ok := h.getMappingIDParam(c)
+	if !ok {
+		return
+	}
+
+	mapping, err := h.bookingService.GetHotelMapping(mappingID)
+	if err != nil {
+		h.respondHotelMappingError(c, "Error obteniendo mapeo", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": mapping,
+	})
+}
+
+// CreateHotelMapping asocia un hotel a su proveedor de inventario (Solo Admin)
+func (h *BookingHandler) CreateHotelMapping(c *gin.Context) {
+	req, ok := h.bindHotelMappingRequest(c)
+	if !ok {
+		return
+	}
+
+	mapping, err := h.bookingService.CreateHotelMapping(c.Request.Context(), req)
+	if err != nil {
+		h.respondHotelMappingError(c, "Error creando mapeo", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Mapeo creado exitosamente",
+		"data":    mapping,
+	})
+}
+
+// UpdateHotelMapping modifica un mapeo existente (Solo Admin)
+func (h *BookingHandler) UpdateHotelMapping(c *gin.Context) {
+	mappingID, ok := h.getMappingIDParam(c)
+	if !ok {
+		return
+	}
+
+	req, ok := h.bindHotelMappingRequest(c)
+	if !ok {
+		return
+	}
+
+	mapping, err := h.bookingService.UpdateHotelMapping(c.Request.Context(), mappingID, req)
+	if err != nil {
+		h.respondHotelMappingError(c, "Error actualizando mapeo", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Mapeo actualizado exitosamente",
+		"data":    mapping,
+	})
+}
+
+// DeleteHotelMapping elimina un mapeo (Solo Admin)
+func (h *BookingHandler) DeleteHotelMapping(c *gin.Context) {
+	mappingID, ok := h.getMappingIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.bookingService.DeleteHotelMapping(mappingID); err != nil {
+		h.respondHotelMappingError(c, "Error eliminando mapeo", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Mapeo eliminado exitosamente",
+	})
+}
+
+// ListUnmappedHotels lista los hoteles activos sin mapeo (Solo Admin)
+func (h *BookingHandler) ListUnmappedHotels(c *gin.Context) {
+	hotels, err := h.bookingService.ListUnmappedHotels(c.Request.Context())
+	if err != nil {
+		h.respondHotelMappingError(c, "Error obteniendo hoteles sin mapeo", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  hotels,
+		"count": len(hotels),
+	})
+}
+
+// bindHotelMappingRequest lee y valida el cuerpo de alta/modificación. Si es
+// inválido responde 400.
+func (h *BookingHandler) bindHotelMappingRequest(c *gin.Context) (*models.HotelMappingRequest, bool) {
+	var req models.HotelMappingRequest
+
+	// Bind JSON
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de entrada inválidos",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+
+	// Validar datos
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de validación fallidos",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+
+	return &req, true
+}
+
+// getMappingIDParam lee el ID de mapeo de la URL. Si es inválido responde 400.
+func (h *BookingHandler) getMappingIDParam(c *gin.Context) (int, bool) {
+	mappingID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "ID de mapeo inválido",
+		})
+		return 0, false
+	}
+	return mappingID, true
+}
+
+// respondHotelMappingError traduce errores de mapeos a códigos HTTP
+func (h *BookingHandler) respondHotelMappingError(c *gin.Context, message string, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "mapeo no encontrado"):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Mapeo no encontrado",
+		})
+	case strings.Contains(msg, "no encontrado en hotel-service"), strings.Contains(msg, "ID externo inválido"),
+		strings.Contains(msg, "proveedor de inventario desconocido"):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": msg,
+		})
+	case strings.Contains(msg, "ya tiene un mapeo"), strings.Contains(msg, "ya está mapeado"):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": msg,
+		})
+	case strings.Contains(msg, "Amadeus"), strings.Contains(msg, "hotel-service"):
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   message,
+			"details": msg,
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": msg,
+		})
+	}
+}
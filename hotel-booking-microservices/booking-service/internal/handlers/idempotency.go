@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// idempotencyHeader header con la clave elegida por el cliente para la petición
+const idempotencyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength largo máximo de la clave (columna idempotency_key)
+const maxIdempotencyKeyLength = 255
+
+// idempotencyRecorder copia la respuesta enviada para guardarla con la clave
+type idempotencyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyRecorder) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
+
+// IdempotencyMiddleware hace idempotente la creación de reservas. Con el header
+// Idempotency-Key, un reintento con el mismo cuerpo repite la respuesta original
+// en lugar de crear otra reserva; con otro cuerpo responde 422 y, mientras la
+// primera petición sigue en curso, 409. Sin el header no cambia nada.
+func (h *BookingHandler) IdempotencyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimSpace(c.GetHeader(idempotencyHeader))
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Idempotency-Key inválida: máximo 255 caracteres",
+			})
+			c.Abort()
+			return
+		}
+
+		userID := h.getUserIDFromContext(c)
+		if userID == 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Token requerido",
+			})
+			c.Abort()
+			return
+		}
+
+		body, err := c.GetRawData()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Cuerpo inválido",
+			})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		record, err := h.bookingService.BeginIdempotentRequest(userID, key, idempotencyHash(c, body))
+		if err != nil {
+			msg := err.Error()
+			switch {
+			case strings.Contains(msg, "reutilizada con otra petición"):
+				c.JSON(http.StatusUnprocessableEntity, gin.H{
+					"error": msg,
+				})
+			case strings.Contains(msg, "petición en curso"):
+				c.Header("Retry-After", "1")
+				c.JSON(http.StatusConflict, gin.H{
+					"error": msg,
+				})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "Error procesando Idempotency-Key",
+					"details": msg,
+				})
+			}
+			c.Abort()
+			return
+		}
+
+		// Reintento de una petición ya resuelta: se repite su resultado
+		if record != nil {
+			c.Header("Idempotent-Replayed", "true")
+			if record.ResponseStatus != nil && record.ResponseBody != nil {
+				c.Data(*record.ResponseStatus, "application/json; charset=utf-8", []byte(*record.ResponseBody))
+				c.Abort()
+				return
+			}
+
+			// El proceso murió después de crear la reserva y antes de guardar la respuesta
+			booking, err := h.bookingService.GetBookingByID(*record.BookingID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "Error obteniendo reserva",
+					"details": err.Error(),
+				})
+				c.Abort()
+				return
+			}
+			c.JSON(http.StatusCreated, gin.H{
+				"message": "Reserva creada exitosamente",
+				"data":    booking,
+			})
+			c.Abort()
+			return
+		}
+
+		recorder := &idempotencyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		if err := h.bookingService.FinishIdempotentRequest(userID, key, recorder.Status(), recorder.body.Bytes()); err != nil {
+			log.Printf("⚠️ Error guardando respuesta idempotente: %v", err)
+		}
+	}
+}
+
+// idempotencyHash identifica la petición: ruta y cuerpo JSON normalizado, así
+// un reintento con otro formato u orden de campos cuenta como la misma petición
+func idempotencyHash(c *gin.Context, body []byte) string {
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err == nil {
+		if normalized, err := json.Marshal(payload); err == nil {
+			body = normalized
+		}
+	}
+
+	sum := sha256.New()
+	sum.Write([]byte(c.Request.Method + " " + c.FullPath() + "\n"))
+	sum.Write(body)
+	return hex.EncodeToString(sum.Sum(nil))
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"booking-service/internal/services"
+)
+
+// oidcStateCookie guarda el state en el navegador que inició el login, para
+// rechazar un callback abierto desde otro navegador (login CSRF). Lax deja que
+// viaje en la redirección del proveedor, que es una navegación de primer nivel.
+const (
+	oidcStateCookie = "oidc_state"
+	oidcCookiePath  = "/api/auth/oidc"
+)
+
+// OIDCLogin redirige al proveedor de identidad externo
+func (h *BookingHandler) OIDCLogin(c *gin.Context) {
+	if !h.bookingService.OIDCEnabled() {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Login externo no configurado",
+		})
+		return
+	}
+
+	// Solo rutas relativas del frontend, para no abrir una redirección arbitraria
+	redirectPath := c.Query("redirect")
+	if !strings.HasPrefix(redirectPath, "/") || strings.HasPrefix(redirectPath, "//") || strings.HasPrefix(redirectPath, "/\\") {
+		redirectPath = ""
+	}
+
+	authURL, state, err := h.bookingService.StartOIDCLogin(redirectPath)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "Error iniciando login externo",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	setOIDCStateCookie(c, state, int(services.OIDCStateTTL.Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback recibe la respuesta del proveedor y vuelve al frontend con los
+// tokens en el fragmento de la URL (no viaja al servidor ni queda en logs)
+func (h *BookingHandler) OIDCCallback(c *gin.Context) {
+	fragment := url.Values{}
+
+	// La cookie sirve para un solo intento
+	browserState, _ := c.Cookie(oidcStateCookie)
+	setOIDCStateCookie(c, "", -1)
+
+	if providerError := c.Query("error"); providerError != "" {
+		fragment.Set("error", providerError)
+		h.redirectOIDCResult(c, fragment)
+		return
+	}
+
+	auth, redirectPath, err := h.bookingService.CompleteOIDCLogin(c.Query("state"), browserState, c.Query("code"))
+	if err != nil {
+		log.Printf("⚠️ Login externo fallido: %v", err)
+		fragment.Set("error", "login_failed")
+		h.redirectOIDCResult(c, fragment)
+		return
+	}
+
+	fragment.Set("token", auth.Token)
+	fragment.Set("refresh_token", auth.RefreshToken)
+	fragment.Set("expires_in", strconv.Itoa(auth.ExpiresIn))
+	if redirectPath != "" {
+		fragment.Set("redirect", redirectPath)
+	}
+	h.redirectOIDCResult(c, fragment)
+}
+
+// redirectOIDCResult redirige a la página de callback del frontend
+func (h *BookingHandler) redirectOIDCResult(c *gin.Context, fragment url.Values) {
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, h.bookingService.FrontendURL()+"/oauth/callback#"+fragment.Encode())
+}
+
+// setOIDCStateCookie escribe la cookie del state (maxAge < 0 la borra). Es
+// HttpOnly y, detrás de HTTPS, Secure.
+func setOIDCStateCookie(c *gin.Context, state string, maxAge int) {
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, maxAge, oidcCookiePath, "", secure, true)
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"booking-service/internal/services"
+	"booking-service/pkg/memcached/memcachedtest"
+	"booking-service/pkg/oidc"
+	"booking-service/pkg/oidc/oidctest"
+)
+
+func newOIDCTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	idp := oidctest.New(t)
+	cache, _ := memcachedtest.New(t)
+	handler := NewBookingHandler(services.NewBookingService(nil, cache, nil, services.Options{
+		FrontendURL: "http://frontend.test",
+		OIDCProvider: oidc.NewProvider(oidc.Config{
+			Name:        "test",
+			IssuerURL:   idp.URL,
+			ClientID:    "booking-service",
+			RedirectURL: "http://localhost:8003/api/auth/oidc/callback",
+		}),
+	}))
+
+	router := gin.New()
+	router.GET("/api/auth/oidc/login", handler.OIDCLogin)
+	router.GET("/api/auth/oidc/callback", handler.OIDCCallback)
+	return router
+}
+
+func TestOIDCLoginSetsStateCookie(t *testing.T) {
+	router := newOIDCTestRouter(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/auth/oidc/login?redirect=/bookings", nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("status = %d, se esperaba 302: %s", w.Code, w.Body.String())
+	}
+
+	location, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("Location inválida: %v", err)
+	}
+	state := location.Query().Get("state")
+
+	cookie := findCookie(w.Result().Cookies(), oidcStateCookie)
+	if cookie == nil {
+		t.Fatalf("no se envió la cookie %s", oidcStateCookie)
+	}
+	if cookie.Value != state || state == "" {
+		t.Fatalf("cookie = %q, se esperaba el state %q", cookie.Value, state)
+	}
+	if !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode || cookie.Path != oidcCookiePath || cookie.MaxAge <= 0 {
+		t.Fatalf("atributos de la cookie inesperados: %+v", cookie)
+	}
+	if cookie.Secure {
+		t.Fatalf("la cookie no debe ser Secure sobre HTTP")
+	}
+
+	// Detrás de un proxy HTTPS la cookie es Secure
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/oidc/login", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if cookie := findCookie(w.Result().Cookies(), oidcStateCookie); cookie == nil || !cookie.Secure {
+		t.Fatalf("se esperaba una cookie Secure: %+v", cookie)
+	}
+}
+
+func TestOIDCCallbackRequiresStateCookie(t *testing.T) {
+	router := newOIDCTestRouter(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/auth/oidc/login", nil))
+	location, _ := url.Parse(w.Header().Get("Location"))
+	state := location.Query().Get("state")
+
+	// Callback abierto desde un navegador que no inició el login
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/oidc/callback?code=abc&state="+url.QueryEscape(state), nil)
+	req.AddCookie(&http.Cookie{Name: oidcStateCookie, Value: "state-del-atacante"})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusFound {
+		t.Fatalf("status = %d, se esperaba 302", w.Code)
+	}
+	if got := w.Header().Get("Location"); !strings.HasPrefix(got, "http://frontend.test/oauth/callback#") || !strings.Contains(got, "error=login_failed") {
+		t.Fatalf("Location = %s, se esperaba el error en el frontend", got)
+	}
+
+	// La cookie se borra después de cada intento
+	if cookie := findCookie(w.Result().Cookies(), oidcStateCookie); cookie == nil || cookie.MaxAge >= 0 {
+		t.Fatalf("se esperaba borrar la cookie: %+v", cookie)
+	}
+}
+
+func findCookie(cookies []*http.Cookie, name string) *http.Cookie {
+	for _, cookie := range cookies {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
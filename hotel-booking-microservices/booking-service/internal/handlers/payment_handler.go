@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"booking-service/internal/models"
+	"booking-service/pkg/payments"
+)
+
+// PayBooking autoriza el pago de una reserva pendiente
+func (h *BookingHandler) PayBooking(c *gin.Context) {
+	booking, ok := h.getOwnedBooking(c)
+	if !ok {
+		return
+	}
+
+	var req models.PayBookingRequest
+
+	// Bind JSON
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de entrada inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Validar datos
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de validación fallidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	paid, err := h.bookingService.AuthorizeBookingPayment(c.Request.Context(), booking.ID, req.PaymentMethod)
+	if err != nil {
+		h.respondPaymentError(c, "Error procesando pago", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": bookingPaymentMessage(paid),
+		"data":    paid,
+	})
+}
+
+// PaymentWebhook recibe las notificaciones asíncronas de la pasarela. La
+// firma se verifica sobre el cuerpo tal cual llegó.
+func (h *BookingHandler) PaymentWebhook(c *gin.Context) {
+	payload, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Cuerpo inválido",
+		})
+		return
+	}
+
+	duplicate, err := h.bookingService.HandlePaymentWebhook(payload, c.GetHeader(payments.SignatureHeader))
+	if err != nil {
+		msg := err.Error()
+		switch {
+		case strings.Contains(msg, "firma inválida"):
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": msg,
+			})
+		case strings.Contains(msg, "evento inválido"):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": msg,
+			})
+		case strings.Contains(msg, "pago no encontrado"), strings.Contains(msg, "pagos deshabilitados"):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": msg,
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error procesando webhook",
+				"details": msg,
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"received":  true,
+		"duplicate": duplicate,
+	})
+}
+
+// bookingPaymentMessage describe el estado de la reserva tras el intento de pago
+func bookingPaymentMessage(booking *models.Booking) string {
+	if booking.Status == models.StatusPending {
+		return "Reserva pendiente de confirmación del pago"
+	}
+	return "Pago autorizado: reserva confirmada"
+}
+
+// respondPaymentError traduce errores de pago a códigos HTTP
+func (h *BookingHandler) respondPaymentError(c *gin.Context, message string, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "pago rechazado"):
+		c.JSON(http.StatusPaymentRequired, gin.H{
+			"error": msg,
+		})
+	case strings.Contains(msg, "pago no encontrado"), strings.Contains(msg, "pagos deshabilitados"):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": msg,
+		})
+	case strings.Contains(msg, "no se puede"):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": msg,
+		})
+	case strings.Contains(msg, "error procesando pago"):
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   message,
+			"details": msg,
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": msg,
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"booking-service/internal/models"
+)
+
+// UpdateProfile actualiza los datos personales del usuario autenticado
+func (h *BookingHandler) UpdateProfile(c *gin.Context) {
+	var req models.UpdateUserRequest
+
+	// Bind JSON
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de entrada inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Validar datos
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de validación fallidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	user, err := h.bookingService.UpdateUser(h.getUserIDFromContext(c), &req)
+	if err != nil {
+		h.respondProfileError(c, "Error actualizando perfil", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Perfil actualizado exitosamente",
+		"data":    user,
+	})
+}
+
+// ChangePassword cambia la contraseña del usuario autenticado
+func (h *BookingHandler) ChangePassword(c *gin.Context) {
+	var req models.ChangePasswordRequest
+
+	// Bind JSON
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de entrada inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Validar datos
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de validación fallidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	auth, err := h.bookingService.ChangePassword(h.getUserIDFromContext(c), req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		h.respondProfileError(c, "Error cambiando contraseña", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Contraseña actualizada exitosamente. Se cerraron las demás sesiones",
+		"data":    auth,
+	})
+}
+
+// ExportProfile descarga el perfil y las reservas del usuario autenticado
+func (h *BookingHandler) ExportProfile(c *gin.Context) {
+	userID := h.getUserIDFromContext(c)
+
+	export, err := h.bookingService.ExportUserData(userID)
+	if err != nil {
+		h.respondProfileError(c, "Error exportando datos", err)
+		return
+	}
+
+	filename := fmt.Sprintf("mis-datos-%d-%s.json", userID, time.Now().Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.JSON(http.StatusOK, export)
+}
+
+// DeleteProfile elimina (anonimiza) la cuenta del usuario autenticado
+func (h *BookingHandler) DeleteProfile(c *gin.Context) {
+	var req models.DeleteAccountRequest
+
+	// Bind JSON
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de entrada inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Validar datos
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de validación fallidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.bookingService.DeleteAccount(h.getUserIDFromContext(c), req.Password); err != nil {
+		h.respondProfileError(c, "Error eliminando cuenta", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cuenta eliminada exitosamente",
+	})
+}
+
+// respondProfileError traduce errores del perfil a códigos HTTP
+func (h *BookingHandler) respondProfileError(c *gin.Context, message string, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "no encontrado"):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Usuario no encontrado",
+		})
+	case strings.Contains(msg, "contraseña actual incorrecta"):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Contraseña actual incorrecta",
+		})
+	case strings.Contains(msg, "no se puede"):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": msg,
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": msg,
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"booking-service/internal/models"
+)
+
+// ListSyncJobs lista los trabajos de sincronización con el proveedor (Solo Admin)
+func (h *BookingHandler) ListSyncJobs(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	filter := models.SyncJobFilter{
+		Status: c.Query("status"),
+		Limit:  limit,
+	}
+	switch filter.Status {
+	case "", models.JobPending, models.JobRunning, models.JobSucceeded, models.JobFailed:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "status debe ser pending, running, succeeded o failed",
+		})
+		return
+	}
+
+	if bookingIDStr := c.Query("booking_id"); bookingIDStr != "" {
+		bookingID, err := strconv.Atoi(bookingIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "booking_id inválido",
+			})
+			return
+		}
+		filter.BookingID = bookingID
+	}
+
+	jobs, err := h.bookingService.ListSyncJobs(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error obteniendo sincronizaciones",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  jobs,
+		"count": len(jobs),
+	})
+}
+
+// RetrySyncJob reencola una sincronización fallida (Solo Admin)
+func (h *BookingHandler) RetrySyncJob(c *gin.Context) {
+	jobID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "ID de sincronización inválido",
+		})
+		return
+	}
+
+	job, err := h.bookingService.RetrySyncJob(jobID)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "no encontrada"):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+		case strings.Contains(err.Error(), "no se puede"):
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error reintentando sincronización",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Sincronización reencolada",
+		"data":    job,
+	})
+}
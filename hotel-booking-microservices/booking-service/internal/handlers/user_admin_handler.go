@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"booking-service/internal/models"
+)
+
+// ListUsers lista los usuarios del sistema (Solo Admin)
+func (h *BookingHandler) ListUsers(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filter := models.UserFilter{
+		Search: c.Query("search"),
+		Role:   c.Query("role"),
+		Page:   page,
+		Limit:  limit,
+	}
+	if activeStr := c.Query("is_active"); activeStr != "" {
+		active, err := strconv.ParseBool(activeStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "is_active debe ser true o false",
+			})
+			return
+		}
+		filter.IsActive = &active
+	}
+
+	users, total, err := h.bookingService.ListUsers(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error obteniendo usuarios",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  users,
+		"count": len(users),
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// GetUser obtiene un usuario por ID (Solo Admin)
+func (h *BookingHandler) GetUser(c *gin.Context) {
+	userID, ok := h.getUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.bookingService.GetUserForAdmin(userID)
+	if err != nil {
+		h.respondUserAdminError(c, "Error obteniendo usuario", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": user,
+	})
+}
+
+// UpdateUser actualiza los datos de un usuario (Solo Admin)
+func (h *BookingHandler) UpdateUser(c *gin.Context) {
+	userID, ok := h.getUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateUserRequest
+
+	// Bind JSON
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de entrada inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Validar datos
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de validación fallidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	user, err := h.bookingService.UpdateUser(userID, &req)
+	if err != nil {
+		h.respondUserAdminError(c, "Error actualizando usuario", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Usuario actualizado exitosamente",
+		"data":    user,
+	})
+}
+
+// UpdateUserRole promueve o degrada a un usuario (Solo Admin)
+func (h *BookingHandler) UpdateUserRole(c *gin.Context) {
+	userID, ok := h.getUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateUserRoleRequest
+
+	// Bind JSON
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de entrada inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Validar datos
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos de validación fallidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	user, err := h.bookingService.SetUserRole(h.getUserIDFromContext(c), userID, req.Role)
+	if err != nil {
+		h.respondUserAdminError(c, "Error actualizando rol", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Rol actualizado exitosamente",
+		"data":    user,
+	})
+}
+
+// DeactivateUser desactiva un usuario y revoca sus sesiones (Solo Admin)
+func (h *BookingHandler) DeactivateUser(c *gin.Context) {
+	h.setUserActive(c, false)
+}
+
+// ActivateUser reactiva un usuario (Solo Admin)
+func (h *BookingHandler) ActivateUser(c *gin.Context) {
+	h.setUserActive(c, true)
+}
+
+// setUserActive resuelve la activación o desactivación de un usuario
+func (h *BookingHandler) setUserActive(c *gin.Context, active bool) {
+	userID, ok := h.getUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.bookingService.SetUserActive(h.getUserIDFromContext(c), userID, active)
+	if err != nil {
+		h.respondUserAdminError(c, "Error actualizando usuario", err)
+		return
+	}
+
+	message := "Usuario desactivado exitosamente"
+	if active {
+		message = "Usuario activado exitosamente"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"data":    user,
+	})
+}
+
+// getUserIDParam lee el ID de usuario de la URL. Si es inválido responde 400.
+func (h *BookingHandler) getUserIDParam(c *gin.Context) (int, bool) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "ID de usuario inválido",
+		})
+		return 0, false
+	}
+	return userID, true
+}
+
+// respondUserAdminError traduce errores de administración de usuarios a códigos HTTP
+func (h *BookingHandler) respondUserAdminError(c *gin.Context, message string, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "no encontrado"):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Usuario no encontrado",
+		})
+	case strings.Contains(msg, "rol inválido"):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": msg,
+		})
+	case strings.Contains(msg, "no se puede"):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": msg,
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": msg,
+		})
+	}
+}
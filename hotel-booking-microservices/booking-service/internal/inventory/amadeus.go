@@ -0,0 +1,197 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"booking-service/internal/models"
+	"booking-service/pkg/amadeus"
+)
+
+// AmadeusProvider cotiza y reserva en la API de Amadeus
+type AmadeusProvider struct {
+	client  *amadeus.Client
+	payment models.AmadeusPayment
+}
+
+// NewAmadeusProvider crea el proveedor de Amadeus. payment es la forma de pago
+// de la agencia enviada en cada reserva.
+func NewAmadeusProvider(client *amadeus.Client, payment models.AmadeusPayment) *AmadeusProvider {
+	return &AmadeusProvider{client: client, payment: payment}
+}
+
+func (p *AmadeusProvider) Name() string { return ProviderAmadeus }
+
+func (p *AmadeusProvider) ExternalSync() bool { return true }
+
+// Availability consulta las ofertas del hotel y devuelve todas, la más barata primero
+func (p *AmadeusProvider) Availability(ctx context.Context, externalHotelID string, req *models.AvailabilityRequest) (*models.AvailabilityResponse, error) {
+	response := newAvailability(req, ProviderAmadeus)
+
+	hotelOffers, err := p.client.GetHotelOffers(ctx, externalHotelID, response.CheckInDate, response.CheckOutDate, req.Guests)
+	if err != nil {
+		return nil, err
+	}
+
+	offers := []models.RoomOffer{}
+	for _, hotelOffer := range hotelOffers {
+		for _, offer := range hotelOffer.Offers {
+			// Convertir precio; las ofertas sin precio válido no se pueden reservar
+			total, err := strconv.ParseFloat(offer.Price.Total, 64)
+			if err != nil {
+				continue
+			}
+
+			rooms := offer.RoomQuantity
+			offers = append(offers, models.RoomOffer{
+				OfferID:            offer.ID,
+				RoomType:           amadeusRoomType(offer.Room),
+				Description:        offer.Room.Description.Text,
+				BoardType:          offer.BoardType,
+				CancellationPolicy: amadeusCancellationPolicy(offer.Policies),
+				CancellationTerms:  amadeusCancellationTerms(offer.Policies, req, total),
+				NightlyPrice:       nightlyAverage(req, total),
+				TotalPrice:         total,
+				Currency:           offer.Price.Currency,
+				RoomsAvailable:     &rooms,
+			})
+		}
+	}
+
+	setOffers(response, offers)
+	return response, nil
+}
+
+// GetOffer vuelve a cotizar la oferta en Amadeus
+func (p *AmadeusProvider) GetOffer(ctx context.Context, offerID string) (*Offer, error) {
+	hotelOffer, err := p.client.GetOffer(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+
+	offer := hotelOffer.Offers[0]
+	total, err := strconv.ParseFloat(offer.Price.Total, 64)
+	if err != nil {
+		return nil, fmt.Errorf("precio de oferta inválido: %s", offer.Price.Total)
+	}
+
+	return &Offer{ID: offer.ID, Total: total, Currency: offer.Price.Currency}, nil
+}
+
+func (p *AmadeusProvider) Book(ctx context.Context, offerID string, guests []models.BookingGuest) (string, error) {
+	return p.client.CreateBooking(ctx, offerID, amadeusGuests(guests), p.payment)
+}
+
+func (p *AmadeusProvider) Cancel(ctx context.Context, providerBookingID string) error {
+	return p.client.CancelBooking(ctx, providerBookingID)
+}
+
+// amadeusGuests convierte los huéspedes al formato de Amadeus. El proveedor
+// exige contacto en cada huésped: si falta se usa el del titular.
+func amadeusGuests(guests []models.BookingGuest) []models.AmadeusBookingGuest {
+	var result []models.AmadeusBookingGuest
+	for _, guest := range guests {
+		contact := models.AmadeusGuestContact{Phone: guest.Phone, Email: guest.Email}
+		if len(result) > 0 {
+			if contact.Phone == "" {
+				contact.Phone = result[0].Contact.Phone
+			}
+			if contact.Email == "" {
+				contact.Email = result[0].Contact.Email
+			}
+		}
+
+		result = append(result, models.AmadeusBookingGuest{
+			Name: models.AmadeusGuestName{
+				Title:     guest.Title,
+				FirstName: strings.ToUpper(guest.FirstName),
+				LastName:  strings.ToUpper(guest.LastName),
+			},
+			Contact: contact,
+		})
+	}
+	return result
+}
+
+// amadeusRoomType nombre legible de la habitación ("DELUXE_ROOM" -> "Deluxe Room")
+func amadeusRoomType(room models.AmadeusRoom) string {
+	category := room.TypeEstimated.Category
+	if category == "" {
+		return room.TypeCode
+	}
+
+	words := strings.Fields(strings.ReplaceAll(strings.ToLower(category), "_", " "))
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// amadeusCancellationPolicy resume la política de cancelación de la oferta
+func amadeusCancellationPolicy(policies models.AmadeusPolicies) string {
+	if policies.Refundable != nil && policies.Refundable.CancellationRefund == "NON_REFUNDABLE" {
+		return "No reembolsable"
+	}
+	if len(policies.Cancellations) == 0 {
+		return ""
+	}
+
+	cancellation := policies.Cancellations[0]
+	if cancellation.Description.Text != "" {
+		return cancellation.Description.Text
+	}
+	if cancellation.Deadline != "" {
+		policy := fmt.Sprintf("Cancelación gratuita hasta %s", cancellation.Deadline)
+		if cancellation.Amount != "" {
+			policy += fmt.Sprintf("; luego se cobran %s", cancellation.Amount)
+		}
+		return policy
+	}
+	return ""
+}
+
+// parseAmadeusDeadline interpreta el límite de cancelación conservando el
+// desplazamiento UTC que informa Amadeus (hora local del hotel). Si no trae
+// desplazamiento se toma en loc.
+func parseAmadeusDeadline(value string, loc *time.Location) (time.Time, error) {
+	if deadline, err := time.Parse(time.RFC3339, value); err == nil {
+		return deadline, nil
+	}
+	return time.ParseInLocation("2006-01-02T15:04:05", value[:min(len(value), 19)], loc)
+}
+
+// amadeusCancellationTerms traduce la política de la oferta al formato propio.
+// El límite de Amadeus es una fecha: se cuenta en días enteros antes del
+// check-in, redondeando hacia arriba para no prometer más que el proveedor. La penalidad es un monto y se expresa como porcentaje del total.
+// Devuelve nil si la oferta no informa una política utilizable.
+func amadeusCancellationTerms(policies models.AmadeusPolicies, req *models.AvailabilityRequest, total float64) *models.CancellationPolicy {
+	if policies.Refundable != nil && policies.Refundable.CancellationRefund == "NON_REFUNDABLE" {
+		return &models.CancellationPolicy{NonRefundable: true, PenaltyPercent: 100}
+	}
+	if len(policies.Cancellations) == 0 || policies.Cancellations[0].Deadline == "" {
+		return nil
+	}
+
+	cancellation := policies.Cancellations[0]
+	deadline, err := parseAmadeusDeadline(cancellation.Deadline, req.CheckInDate.Location())
+	if err != nil {
+		return nil
+	}
+
+	// El check-in empieza a medianoche en la zona del hotel, la misma del límite
+	year, month, day := req.CheckInDate.Date()
+	checkIn := time.Date(year, month, day, 0, 0, 0, 0, deadline.Location())
+
+	terms := &models.CancellationPolicy{PenaltyPercent: 100}
+	if days := int(math.Ceil(checkIn.Sub(deadline).Hours() / 24)); days > 0 {
+		terms.FreeUntilDays = days
+	}
+	if amount, err := strconv.ParseFloat(cancellation.Amount, 64); err == nil && total > 0 {
+		terms.PenaltyPercent = math.Min(math.Round(amount/total*10000)/100, 100)
+	}
+	return terms
+}
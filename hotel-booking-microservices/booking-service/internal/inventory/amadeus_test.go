@@ -0,0 +1,137 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"booking-service/internal/models"
+	"booking-service/pkg/amadeus"
+)
+
+func TestAmadeusCancellationTerms(t *testing.T) {
+	// El servidor corre en Buenos Aires y el hotel está en Tokio
+	server := time.FixedZone("ART", -3*60*60)
+	req := &models.AvailabilityRequest{CheckInDate: time.Date(2026, 3, 10, 0, 0, 0, 0, server)}
+
+	tests := []struct {
+		name     string
+		policies models.AmadeusPolicies
+		want     *models.CancellationPolicy
+	}{
+		{
+			name:     "límite con desplazamiento del hotel",
+			policies: models.AmadeusPolicies{Cancellations: []models.AmadeusCancellation{{Deadline: "2026-03-09T00:00:00+09:00", Amount: "50.00"}}},
+			want:     &models.CancellationPolicy{FreeUntilDays: 1, PenaltyPercent: 25},
+		},
+		{
+			name:     "límite con fracción de segundo",
+			policies: models.AmadeusPolicies{Cancellations: []models.AmadeusCancellation{{Deadline: "2026-03-08T23:59:59.500+09:00"}}},
+			want:     &models.CancellationPolicy{FreeUntilDays: 2, PenaltyPercent: 100},
+		},
+		{
+			name:     "límite sin desplazamiento",
+			policies: models.AmadeusPolicies{Cancellations: []models.AmadeusCancellation{{Deadline: "2026-03-07T00:00:00"}}},
+			want:     &models.CancellationPolicy{FreeUntilDays: 3, PenaltyPercent: 100},
+		},
+		{
+			name:     "límite posterior al check-in",
+			policies: models.AmadeusPolicies{Cancellations: []models.AmadeusCancellation{{Deadline: "2026-03-10T12:00:00+09:00"}}},
+			want:     &models.CancellationPolicy{PenaltyPercent: 100},
+		},
+		{
+			name:     "no reembolsable",
+			policies: models.AmadeusPolicies{Refundable: &models.AmadeusRefundable{CancellationRefund: "NON_REFUNDABLE"}},
+			want:     &models.CancellationPolicy{NonRefundable: true, PenaltyPercent: 100},
+		},
+		{
+			name:     "límite inválido",
+			policies: models.AmadeusPolicies{Cancellations: []models.AmadeusCancellation{{Deadline: "mañana"}}},
+		},
+		{
+			name: "sin política",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := amadeusCancellationTerms(tt.policies, req, 200)
+			if (got == nil) != (tt.want == nil) {
+				t.Fatalf("política = %+v, se esperaba %+v", got, tt.want)
+			}
+			if got != nil && (got.FreeUntilDays != tt.want.FreeUntilDays || got.PenaltyPercent != tt.want.PenaltyPercent || got.NonRefundable != tt.want.NonRefundable) {
+				t.Fatalf("política = %+v, se esperaba %+v", *got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestAmadeusAvailability(t *testing.T) {
+	// Amadeus de prueba con tres ofertas; la de precio inválido no se puede reservar
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			fmt.Fprint(w, `{"access_token":"test-token","expires_in":1799}`)
+		case "/v3/shopping/hotel-offers":
+			if r.URL.Query().Get("hotelIds") != "MCLONGHM" || r.URL.Query().Get("checkInDate") != "2026-03-10" || r.URL.Query().Get("adults") != "2" {
+				t.Errorf("búsqueda inesperada: %s", r.URL.RawQuery)
+			}
+			fmt.Fprint(w, `{"data":[{"hotel":{"hotelId":"MCLONGHM"},"available":true,"offers":[
+				{"id":"SUITE","roomQuantity":1,"room":{"typeCode":"S1K","typeEstimated":{"category":"EXECUTIVE_SUITE"}},"price":{"currency":"EUR","total":"450.00"}},
+				{"id":"ROTA","roomQuantity":1,"room":{"typeCode":"A1K"},"price":{"currency":"EUR","total":"N/A"}},
+				{"id":"STD","roomQuantity":2,"room":{"typeCode":"A2D"},"price":{"currency":"EUR","total":"200.00"},
+				 "policies":{"cancellations":[{"deadline":"2026-03-08T00:00:00","amount":"100.00"}]}}
+			]}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewAmadeusProvider(amadeus.NewClient(server.URL, "id", "secret", amadeus.Options{}), models.AmadeusPayment{})
+	checkIn := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	response, err := provider.Availability(context.Background(), "MCLONGHM", &models.AvailabilityRequest{
+		HotelID:      "hotel-1",
+		CheckInDate:  checkIn,
+		CheckOutDate: checkIn.AddDate(0, 0, 2),
+		Guests:       2,
+	})
+	if err != nil {
+		t.Fatalf("error consultando disponibilidad: %v", err)
+	}
+
+	if len(response.Offers) != 2 || response.OfferID != "STD" || *response.Price != 200 || response.Currency != "EUR" {
+		t.Fatalf("respuesta = %+v, se esperaban las dos ofertas válidas con STD primero", response)
+	}
+	std, suite := response.Offers[0], response.Offers[1]
+	if std.RoomType != "A2D" || suite.RoomType != "Executive Suite" {
+		t.Fatalf("tipos = %s y %s", std.RoomType, suite.RoomType)
+	}
+	if std.NightlyPrice != 100 || std.RoomsAvailable == nil || *std.RoomsAvailable != 2 {
+		t.Fatalf("oferta estándar = %+v", std)
+	}
+	if std.CancellationTerms == nil || std.CancellationTerms.FreeUntilDays != 2 || std.CancellationTerms.PenaltyPercent != 50 {
+		t.Fatalf("política de la oferta estándar = %+v", std.CancellationTerms)
+	}
+}
+
+func TestAmadeusGuests(t *testing.T) {
+	guests := amadeusGuests([]models.BookingGuest{
+		{Title: "MS", FirstName: "Ana", LastName: "Pérez", Email: "ana@test.com", Phone: "+541100000000"},
+		{FirstName: "Juan", LastName: "Gómez", Email: "juan@test.com"},
+	})
+
+	if len(guests) != 2 {
+		t.Fatalf("huéspedes = %d, se esperaban 2", len(guests))
+	}
+	if guests[0].Name.FirstName != "ANA" || guests[0].Name.LastName != "PÉREZ" || guests[0].Name.Title != "MS" {
+		t.Fatalf("titular = %+v, el nombre va en mayúsculas", guests[0].Name)
+	}
+	// El segundo huésped conserva su email y toma el teléfono del titular
+	if guests[1].Contact.Email != "juan@test.com" || guests[1].Contact.Phone != "+541100000000" {
+		t.Fatalf("contacto del segundo huésped = %+v", guests[1].Contact)
+	}
+}
@@ -0,0 +1,40 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+
+	"booking-service/internal/models"
+)
+
+// LocalProvider usa solo las tablas propias de inventario y tarifas. Es el
+// proveedor de los hoteles que no están en Amadeus.
+type LocalProvider struct{}
+
+// NewLocalProvider crea el proveedor de inventario local
+func NewLocalProvider() *LocalProvider {
+	return &LocalProvider{}
+}
+
+func (p *LocalProvider) Name() string { return ProviderLocal }
+
+func (p *LocalProvider) ExternalSync() bool { return false }
+
+// Availability no informa precio ni cupo: ambos salen de las tablas propias
+func (p *LocalProvider) Availability(ctx context.Context, externalHotelID string, req *models.AvailabilityRequest) (*models.AvailabilityResponse, error) {
+	response := newAvailability(req, ProviderLocal)
+	response.Available = true
+	return response, nil
+}
+
+func (p *LocalProvider) GetOffer(ctx context.Context, offerID string) (*Offer, error) {
+	return nil, fmt.Errorf("oferta no disponible: el inventario local no emite ofertas")
+}
+
+func (p *LocalProvider) Book(ctx context.Context, offerID string, guests []models.BookingGuest) (string, error) {
+	return "", fmt.Errorf("el inventario local no requiere reservar en un proveedor")
+}
+
+func (p *LocalProvider) Cancel(ctx context.Context, providerBookingID string) error {
+	return nil
+}
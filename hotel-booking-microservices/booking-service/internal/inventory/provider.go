@@ -0,0 +1,89 @@
+package inventory
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"booking-service/internal/models"
+)
+
+// Nombres de los proveedores, guardados en hotel_mappings.provider y bookings.provider
+const (
+	ProviderAmadeus   = "amadeus"
+	ProviderLocal     = "local"
+	ProviderSimulated = "simulated"
+)
+
+// InventoryProvider origen de disponibilidad y reservas de un hotel. Cada hotel
+// usa el proveedor indicado en hotel_mappings o, si no está mapeado, el default.
+type InventoryProvider interface {
+	// Name identifica al proveedor en la base de datos
+	Name() string
+
+	// ExternalSync indica si las reservas deben crearse también en el proveedor
+	// (mediante la cola de sincronización)
+	ExternalSync() bool
+
+	// Availability cotiza una estadía en el hotel externalHotelID. Si la
+	// respuesta no trae precio, se cotiza con las tarifas propias.
+	Availability(ctx context.Context, externalHotelID string, req *models.AvailabilityRequest) (*models.AvailabilityResponse, error)
+
+	// GetOffer vuelve a cotizar una oferta devuelta por Availability. Si ya no
+	// existe, el error contiene "oferta no disponible".
+	GetOffer(ctx context.Context, offerID string) (*Offer, error)
+
+	// Book reserva la oferta en el proveedor y devuelve su ID de reserva
+	Book(ctx context.Context, offerID string, guests []models.BookingGuest) (string, error)
+
+	// Cancel cancela una reserva creada con Book
+	Cancel(ctx context.Context, providerBookingID string) error
+}
+
+// Offer oferta vigente de un proveedor
+type Offer struct {
+	ID       string
+	Total    float64
+	Currency string
+}
+
+// newAvailability arma la respuesta base de disponibilidad
+func newAvailability(req *models.AvailabilityRequest, provider string) *models.AvailabilityResponse {
+	return &models.AvailabilityResponse{
+		HotelID:      req.HotelID,
+		Provider:     provider,
+		CheckInDate:  req.CheckInDate.Format("2006-01-02"),
+		CheckOutDate: req.CheckOutDate.Format("2006-01-02"),
+		Guests:       req.Guests,
+	}
+}
+
+// setOffers guarda las ofertas ordenadas por precio y completa los campos
+// principales de la respuesta con la más barata
+func setOffers(response *models.AvailabilityResponse, offers []models.RoomOffer) {
+	sort.SliceStable(offers, func(i, j int) bool { return offers[i].TotalPrice < offers[j].TotalPrice })
+	response.Offers = offers
+	response.Available = len(offers) > 0
+	if len(offers) == 0 {
+		return
+	}
+
+	cheapest := offers[0]
+	total := cheapest.TotalPrice
+	response.OfferID = cheapest.OfferID
+	response.Price = &total
+	response.Currency = cheapest.Currency
+	if cheapest.RoomsAvailable != nil {
+		rooms := *cheapest.RoomsAvailable
+		response.RoomsAvailable = &rooms
+	}
+}
+
+// nightlyAverage precio promedio por noche de una estadía
+func nightlyAverage(req *models.AvailabilityRequest, total float64) float64 {
+	nights := int(math.Round(req.CheckOutDate.Sub(req.CheckInDate).Hours() / 24))
+	if nights < 1 {
+		nights = 1
+	}
+	return math.Round(total/float64(nights)*100) / 100
+}
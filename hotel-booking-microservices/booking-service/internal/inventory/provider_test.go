@@ -0,0 +1,205 @@
+package inventory
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"booking-service/internal/models"
+)
+
+// stayRequest búsqueda de nights noches desde el 10 de marzo de 2026
+func stayRequest(nights, guests int) *models.AvailabilityRequest {
+	checkIn := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	return &models.AvailabilityRequest{
+		HotelID:      "hotel-1",
+		CheckInDate:  checkIn,
+		CheckOutDate: checkIn.AddDate(0, 0, nights),
+		Guests:       guests,
+	}
+}
+
+func TestSetOffers(t *testing.T) {
+	rooms := 3
+	response := newAvailability(stayRequest(2, 2), ProviderAmadeus)
+	setOffers(response, []models.RoomOffer{
+		{OfferID: "SUITE", TotalPrice: 500, Currency: "EUR"},
+		{OfferID: "STD", TotalPrice: 200, Currency: "EUR", RoomsAvailable: &rooms},
+		{OfferID: "DBL", TotalPrice: 300, Currency: "EUR"},
+	})
+
+	if !response.Available || response.OfferID != "STD" || response.Price == nil || *response.Price != 200 || response.Currency != "EUR" {
+		t.Fatalf("respuesta = %+v, se esperaba la oferta más barata", response)
+	}
+	if response.RoomsAvailable == nil || *response.RoomsAvailable != 3 {
+		t.Fatalf("habitaciones disponibles = %v, se esperaban las de la oferta más barata", response.RoomsAvailable)
+	}
+	for i, want := range []string{"STD", "DBL", "SUITE"} {
+		if response.Offers[i].OfferID != want {
+			t.Fatalf("oferta %d = %s, se esperaba %s", i, response.Offers[i].OfferID, want)
+		}
+	}
+
+	// Modificar la respuesta no altera el cupo de la oferta
+	*response.RoomsAvailable = 0
+	if rooms != 3 {
+		t.Fatal("el cupo de la respuesta comparte memoria con el de la oferta")
+	}
+
+	empty := newAvailability(stayRequest(2, 2), ProviderAmadeus)
+	setOffers(empty, nil)
+	if empty.Available || empty.Price != nil || empty.OfferID != "" {
+		t.Fatalf("respuesta sin ofertas = %+v, se esperaba no disponible", empty)
+	}
+}
+
+func TestNightlyAverage(t *testing.T) {
+	tests := []struct {
+		nights int
+		total  float64
+		want   float64
+	}{
+		{nights: 1, total: 150, want: 150},
+		{nights: 3, total: 100, want: 33.33},
+		{nights: 0, total: 80, want: 80}, // estadía inválida: se toma una noche
+	}
+
+	for _, tt := range tests {
+		if got := nightlyAverage(stayRequest(tt.nights, 1), tt.total); got != tt.want {
+			t.Errorf("nightlyAverage(%d noches, %v) = %v, se esperaba %v", tt.nights, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestLocalProvider(t *testing.T) {
+	provider := NewLocalProvider()
+	ctx := context.Background()
+
+	if provider.Name() != ProviderLocal || provider.ExternalSync() {
+		t.Fatal("el proveedor local no se sincroniza con nadie")
+	}
+
+	// Precio y cupo salen de las tablas propias
+	response, err := provider.Availability(ctx, "hotel-1", stayRequest(2, 2))
+	if err != nil {
+		t.Fatalf("error consultando disponibilidad: %v", err)
+	}
+	if !response.Available || response.Price != nil || response.OfferID != "" || response.Provider != ProviderLocal {
+		t.Fatalf("respuesta = %+v, se esperaba disponible sin precio", response)
+	}
+	if response.CheckInDate != "2026-03-10" || response.CheckOutDate != "2026-03-12" {
+		t.Fatalf("fechas = %s/%s", response.CheckInDate, response.CheckOutDate)
+	}
+
+	if _, err := provider.GetOffer(ctx, "OFFER"); err == nil || !strings.Contains(err.Error(), "oferta no disponible") {
+		t.Fatalf("error = %v, el inventario local no emite ofertas", err)
+	}
+	if _, err := provider.Book(ctx, "OFFER", nil); err == nil {
+		t.Fatal("se esperaba error al reservar en el inventario local")
+	}
+	if err := provider.Cancel(ctx, "X"); err != nil {
+		t.Fatalf("cancelar en el inventario local no debe fallar: %v", err)
+	}
+}
+
+func TestSimulatedProviderAvailability(t *testing.T) {
+	provider := NewSimulatedProvider()
+	ctx := context.Background()
+
+	if provider.Name() != ProviderSimulated || !provider.ExternalSync() {
+		t.Fatal("el simulador se sincroniza como un proveedor externo")
+	}
+
+	first, err := provider.Availability(ctx, "SIMHOTEL", stayRequest(3, 2))
+	if err != nil {
+		t.Fatalf("error consultando disponibilidad: %v", err)
+	}
+	second, err := provider.Availability(ctx, "SIMHOTEL", stayRequest(3, 2))
+	if err != nil {
+		t.Fatalf("error consultando disponibilidad: %v", err)
+	}
+
+	if len(first.Offers) != len(simulatedRooms) {
+		t.Fatalf("ofertas = %d, se esperaba una por habitación", len(first.Offers))
+	}
+	if *first.Price != *second.Price || first.OfferID != second.OfferID {
+		t.Fatal("la misma búsqueda debe devolver los mismos precios")
+	}
+	if first.Offers[0].RoomType != "Standard Room" || first.Offers[0].TotalPrice >= first.Offers[1].TotalPrice {
+		t.Fatalf("ofertas = %+v, se esperaba la estándar primero y más barata", first.Offers)
+	}
+
+	// Cada oferta se re-cotiza al mismo precio a partir de su ID
+	for _, offer := range first.Offers {
+		repriced, err := provider.GetOffer(ctx, offer.OfferID)
+		if err != nil {
+			t.Fatalf("error re-cotizando %s: %v", offer.OfferID, err)
+		}
+		if repriced.Total != offer.TotalPrice || repriced.Currency != "USD" {
+			t.Fatalf("oferta %s = %+v, se esperaba %v USD", offer.OfferID, repriced, offer.TotalPrice)
+		}
+	}
+
+	// Más huéspedes encarecen la estadía
+	single, err := provider.Availability(ctx, "SIMHOTEL", stayRequest(3, 1))
+	if err != nil {
+		t.Fatalf("error consultando disponibilidad: %v", err)
+	}
+	if *single.Price >= *first.Price {
+		t.Fatalf("precio con 1 huésped = %v, con 2 = %v", *single.Price, *first.Price)
+	}
+}
+
+func TestSimulatedProviderOffers(t *testing.T) {
+	provider := NewSimulatedProvider()
+	ctx := context.Background()
+
+	for _, offerID := range []string{
+		"OTRO:SIMHOTEL:20260310:20260312:2",
+		"SIM:SIMHOTEL:20260312:20260310:2",
+		"SIM:SIMHOTEL:20260310:20260312:0",
+		"SIM:SIMHOTEL:20260310:20260312:2:PENT",
+		"SIM:SIMHOTEL:20260310",
+	} {
+		if _, err := provider.GetOffer(ctx, offerID); err == nil || !strings.Contains(err.Error(), "oferta no disponible") {
+			t.Errorf("%s: error = %v, se esperaba oferta no disponible", offerID, err)
+		}
+	}
+
+	// Las ofertas sin código de habitación son de la estándar
+	legacy, err := provider.GetOffer(ctx, "SIM:SIMHOTEL:20260310:20260312:2")
+	if err != nil {
+		t.Fatalf("error cotizando oferta sin habitación: %v", err)
+	}
+	standard, err := provider.GetOffer(ctx, "SIM:SIMHOTEL:20260310:20260312:2:STD")
+	if err != nil {
+		t.Fatalf("error cotizando oferta estándar: %v", err)
+	}
+	if legacy.Total != standard.Total {
+		t.Fatalf("oferta sin habitación = %v, estándar = %v", legacy.Total, standard.Total)
+	}
+
+	guests := []models.BookingGuest{{FirstName: "Ana", LastName: "Pérez"}}
+	if _, err := provider.Book(ctx, standard.ID, nil); err == nil {
+		t.Fatal("se esperaba error al reservar sin huéspedes")
+	}
+	first, err := provider.Book(ctx, standard.ID, guests)
+	if err != nil {
+		t.Fatalf("error reservando: %v", err)
+	}
+	second, err := provider.Book(ctx, standard.ID, guests)
+	if err != nil {
+		t.Fatalf("error reservando: %v", err)
+	}
+	if !strings.HasPrefix(first, "SIM-") || first == second {
+		t.Fatalf("reservas = %s y %s, se esperaban IDs distintos del simulador", first, second)
+	}
+
+	if err := provider.Cancel(ctx, first); err != nil {
+		t.Fatalf("error cancelando: %v", err)
+	}
+	if err := provider.Cancel(ctx, "AMA-123"); err == nil {
+		t.Fatal("se esperaba error al cancelar una reserva ajena al simulador")
+	}
+}
@@ -0,0 +1,141 @@
+package inventory
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"booking-service/internal/models"
+)
+
+// simulatedOfferPrefix identifica las ofertas emitidas por el simulador. El ID
+// codifica la búsqueda y la habitación para poder re-cotizarla sin guardar estado.
+const simulatedOfferPrefix = "SIM:"
+
+// simulatedRoom habitación del simulador; el precio es el de la estándar por el factor
+type simulatedRoom struct {
+	code        string
+	name        string
+	description string
+	factor      float64
+}
+
+// simulatedRooms habitaciones ofrecidas por todos los hoteles simulados. Las
+// ofertas sin código de habitación (formato anterior) son de la estándar.
+var simulatedRooms = []simulatedRoom{
+	{code: "STD", name: "Standard Room", description: "Habitación estándar con cama doble", factor: 1},
+	{code: "STE", name: "Suite", description: "Suite con living y cama king", factor: 1.6},
+}
+
+// SimulatedProvider proveedor externo ficticio y determinista: la misma búsqueda
+// siempre devuelve los mismos precios. Sirve para probar el flujo completo de
+// sincronización sin red.
+type SimulatedProvider struct {
+	currency string
+	bookings atomic.Int64
+}
+
+// NewSimulatedProvider crea el simulador de proveedor
+func NewSimulatedProvider() *SimulatedProvider {
+	return &SimulatedProvider{currency: "USD"}
+}
+
+func (p *SimulatedProvider) Name() string { return ProviderSimulated }
+
+func (p *SimulatedProvider) ExternalSync() bool { return true }
+
+func (p *SimulatedProvider) Availability(ctx context.Context, externalHotelID string, req *models.AvailabilityRequest) (*models.AvailabilityResponse, error) {
+	response := newAvailability(req, ProviderSimulated)
+
+	var offers []models.RoomOffer
+	for _, room := range simulatedRooms {
+		offerID := simulatedOfferPrefix + strings.Join([]string{
+			externalHotelID, req.CheckInDate.Format("20060102"), req.CheckOutDate.Format("20060102"), strconv.Itoa(req.Guests), room.code,
+		}, ":")
+
+		offer, err := p.GetOffer(ctx, offerID)
+		if err != nil {
+			return nil, err
+		}
+
+		offers = append(offers, models.RoomOffer{
+			OfferID:            offer.ID,
+			RoomType:           room.name,
+			Description:        room.description,
+			BoardType:          "ROOM_ONLY",
+			CancellationPolicy: "Cancelación gratuita hasta el día anterior al check-in",
+			CancellationTerms:  &models.CancellationPolicy{FreeUntilDays: 1, PenaltyPercent: 100},
+			NightlyPrice:       nightlyAverage(req, offer.Total),
+			TotalPrice:         offer.Total,
+			Currency:           offer.Currency,
+		})
+	}
+
+	setOffers(response, offers)
+	return response, nil
+}
+
+// GetOffer recalcula el precio a partir de los datos codificados en el ID
+func (p *SimulatedProvider) GetOffer(ctx context.Context, offerID string) (*Offer, error) {
+	parts := strings.Split(strings.TrimPrefix(offerID, simulatedOfferPrefix), ":")
+	if !strings.HasPrefix(offerID, simulatedOfferPrefix) || len(parts) < 4 || len(parts) > 5 {
+		return nil, fmt.Errorf("oferta no disponible: %s", offerID)
+	}
+
+	checkIn, errIn := time.Parse("20060102", parts[1])
+	checkOut, errOut := time.Parse("20060102", parts[2])
+	guests, errGuests := strconv.Atoi(parts[3])
+	if errIn != nil || errOut != nil || errGuests != nil || !checkOut.After(checkIn) || guests < 1 {
+		return nil, fmt.Errorf("oferta no disponible: %s", offerID)
+	}
+
+	room := simulatedRooms[0]
+	if len(parts) == 5 {
+		found := false
+		for _, candidate := range simulatedRooms {
+			if candidate.code == parts[4] {
+				room, found = candidate, true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("oferta no disponible: %s", offerID)
+		}
+	}
+
+	// Tarifa por noche entre 60 y 199 según el hotel y la fecha
+	total := 0.0
+	for night := checkIn; night.Before(checkOut); night = night.AddDate(0, 0, 1) {
+		sum := sha1.Sum([]byte(parts[0] + night.Format("20060102")))
+		total += 60 + float64(binary.BigEndian.Uint16(sum[:2])%140)
+	}
+	total *= (1 + 0.25*float64(guests-1)) * room.factor
+
+	return &Offer{ID: offerID, Total: math.Round(total*100) / 100, Currency: p.currency}, nil
+}
+
+func (p *SimulatedProvider) Book(ctx context.Context, offerID string, guests []models.BookingGuest) (string, error) {
+	if _, err := p.GetOffer(ctx, offerID); err != nil {
+		return "", err
+	}
+	if len(guests) == 0 {
+		return "", fmt.Errorf("se requiere al menos un huésped")
+	}
+
+	seq := p.bookings.Add(1)
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s|%s|%d|%d", offerID, guests[0].LastName, seq, time.Now().UnixNano())))
+	return "SIM-" + strings.ToUpper(hex.EncodeToString(sum[:5])), nil
+}
+
+func (p *SimulatedProvider) Cancel(ctx context.Context, providerBookingID string) error {
+	if !strings.HasPrefix(providerBookingID, "SIM-") {
+		return fmt.Errorf("reserva %s no pertenece al simulador", providerBookingID)
+	}
+	return nil
+}
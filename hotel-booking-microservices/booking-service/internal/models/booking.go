@@ -16,6 +16,7 @@ const (
 type User struct {
 	ID          int       `json:"id" db:"id"`
 	Email       string    `json:"email" db:"email" validate:"required,email"`
+	EmailVerified bool   `json:"email_verified" db:"email_verified"`
 	PasswordHash string   `json:"-" db:"password_hash"`
 	FirstName   string    `json:"first_name" db:"first_name" validate:"required,min=2,max=50"`
 	LastName    string    `json:"last_name" db:"last_name" validate:"required,min=2,max=50"`
@@ -25,6 +26,16 @@ type User struct {
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 	IsActive    bool      `json:"is_active" db:"is_active"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	TokenVersion int      `json:"-" db:"token_version"`
+}
+
+// TokenClaims datos del usuario incluidos en el access token
+type TokenClaims struct {
+	UserID       int
+	Email        string
+	Role         string
+	TokenVersion int
 }
 
 // IsAdmin verifica si el usuario es administrador
@@ -37,13 +48,32 @@ func (u *User) IsUser() bool {
 	return u.Role == RoleUser
 }
 
+// Estados posibles de una reserva
+const (
+	StatusPending   = "pending"
+	StatusConfirmed = "confirmed"
+	StatusCancelled = "cancelled"
+	StatusCompleted = "completed"
+)
+
+// bookingTransitions define las transiciones de estado permitidas
+var bookingTransitions = map[string][]string{
+	StatusPending:   {StatusConfirmed, StatusCancelled},
+	StatusConfirmed: {StatusCancelled, StatusCompleted},
+	StatusCancelled: {},
+	StatusCompleted: {},
+}
+
 // Booking representa una reserva
 type Booking struct {
 	ID               int       `json:"id" db:"id"`
 	UserID           int       `json:"user_id" db:"user_id"`
 	InternalHotelID  string    `json:"internal_hotel_id" db:"internal_hotel_id"`
+	Provider         string    `json:"provider" db:"provider"`
 	AmadeusHotelID   *string   `json:"amadeus_hotel_id" db:"amadeus_hotel_id"`
 	AmadeusBookingID *string   `json:"amadeus_booking_id" db:"amadeus_booking_id"`
+	AmadeusOfferID   *string   `json:"amadeus_offer_id,omitempty" db:"amadeus_offer_id"`
+	SyncStatus       string    `json:"sync_status" db:"sync_status"`
 	CheckInDate      time.Time `json:"check_in_date" db:"check_in_date"`
 	CheckOutDate     time.Time `json:"check_out_date" db:"check_out_date"`
 	Guests           int       `json:"guests" db:"guests"`
@@ -53,21 +83,260 @@ type Booking struct {
 	Status           string    `json:"status" db:"status"`
 	BookingReference string    `json:"booking_reference" db:"booking_reference"`
 	SpecialRequests  string    `json:"special_requests" db:"special_requests"`
+	CancelledAt      *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	CancelReason     *string   `json:"cancellation_reason,omitempty" db:"cancellation_reason"`
+	PriceLines       []BookingPriceLine `json:"price_lines,omitempty"`
+	GuestDetails     []BookingGuest `json:"guest_details,omitempty"`
+	Rooms            []BookingRoom `json:"rooms,omitempty"`
+	Payment          *Payment  `json:"payment,omitempty"`
 	CreatedAt        time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
 }
 
-// HotelMapping mapea IDs internos con IDs de Amadeus
+// CanTransitionTo verifica si la reserva puede pasar al estado indicado
+func (b *Booking) CanTransitionTo(status string) bool {
+	for _, next := range bookingTransitions[b.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsModifiable verifica si la reserva todavía admite cambios de fechas o huéspedes
+func (b *Booking) IsModifiable() bool {
+	return b.Status == StatusPending || b.Status == StatusConfirmed
+}
+
+// Estados de una habitación de la reserva
+const (
+	RoomStatusActive    = "active"
+	RoomStatusCancelled = "cancelled"
+)
+
+// BookingRoom habitación de una reserva. La reserva suma los huéspedes y el
+// total de sus habitaciones activas.
+type BookingRoom struct {
+	ID                int        `json:"id" db:"id"`
+	BookingID         int        `json:"booking_id" db:"booking_id"`
+	Position          int        `json:"position" db:"position"`
+	RoomType          string     `json:"room_type" db:"room_type"`
+	Guests            int        `json:"guests" db:"guests"`
+	TotalPrice        float64    `json:"total_price" db:"total_price"`
+	Currency          string     `json:"currency" db:"currency"`
+	ProviderOfferID   *string    `json:"provider_offer_id,omitempty" db:"provider_offer_id"`
+	ProviderBookingID *string    `json:"provider_booking_id,omitempty" db:"provider_booking_id"`
+	Status            string     `json:"status" db:"status"`
+	CancelledAt       *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	CancelReason      *string    `json:"cancellation_reason,omitempty" db:"cancellation_reason"`
+	CancellationFee   *float64   `json:"cancellation_fee,omitempty" db:"cancellation_fee"`
+	// Política vigente al reservar; nil en las reservas anteriores a las políticas
+	CancellationPolicy *CancellationPolicy `json:"cancellation_policy,omitempty" db:"cancellation_policy"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// ActiveRooms devuelve las habitaciones no canceladas
+func (b *Booking) ActiveRooms() []BookingRoom {
+	var rooms []BookingRoom
+	for _, room := range b.Rooms {
+		if room.Status == RoomStatusActive {
+			rooms = append(rooms, room)
+		}
+	}
+	return rooms
+}
+
+// DefaultRoomType tipo de habitación usado cuando la reserva no especifica uno
+const DefaultRoomType = "standard"
+
+// RoomInventory representa el inventario de un tipo de habitación para una noche
+type RoomInventory struct {
+	HotelID        string    `json:"hotel_id" db:"hotel_id"`
+	RoomType       string    `json:"room_type" db:"room_type"`
+	StayDate       time.Time `json:"stay_date" db:"stay_date"`
+	TotalRooms     int       `json:"total_rooms" db:"total_rooms"`
+	BookedRooms    int       `json:"booked_rooms" db:"booked_rooms"`
+	AvailableRooms int       `json:"available_rooms"`
+}
+
+// RoomType tipo de habitación publicado por hotel-service
+type RoomType struct {
+	ID               string  `json:"id"`
+	Name             string  `json:"name"`
+	Capacity         int     `json:"capacity"`
+	Description      string  `json:"description"`
+	BedConfiguration string  `json:"bed_configuration"`
+	BaseRate         float64 `json:"base_rate"`
+	Currency         string  `json:"currency"`
+	Count            int     `json:"count"`
+	CancellationPolicy *CancellationPolicy `json:"cancellation_policy,omitempty"` // nil: rige la del hotel
+}
+
+// CancellationPolicy política de cancelación de un hotel, una tarifa o una
+// oferta del proveedor. Cancelar hasta FreeUntilDays días antes del check-in no
+// tiene cargo; después se cobra PenaltyPercent del precio.
+type CancellationPolicy struct {
+	NonRefundable  bool    `json:"non_refundable"`
+	FreeUntilDays  int     `json:"free_until_days"`
+	PenaltyPercent float64 `json:"penalty_percent"`
+}
+
+// FreeUntil devuelve el límite de la cancelación sin cargo
+func (p *CancellationPolicy) FreeUntil(checkIn time.Time) time.Time {
+	return checkIn.AddDate(0, 0, -p.FreeUntilDays)
+}
+
+// PenaltyPercentAt devuelve el porcentaje del precio que se cobra al cancelar en at
+func (p *CancellationPolicy) PenaltyPercentAt(checkIn, at time.Time) float64 {
+	if p.NonRefundable {
+		return 100
+	}
+	if !at.After(p.FreeUntil(checkIn)) {
+		return 0
+	}
+	return p.PenaltyPercent
+}
+
+// Tipos de reglas de precio soportadas por el motor de tarifas
+const (
+	RuleSeason       = "season"
+	RuleWeekend      = "weekend"
+	RuleLengthOfStay = "length_of_stay"
+	RuleOccupancy    = "occupancy"
+)
+
+// RoomRate tarifa base de un tipo de habitación para una noche
+type RoomRate struct {
+	HotelID  string    `json:"hotel_id" db:"hotel_id"`
+	RoomType string    `json:"room_type" db:"room_type"`
+	StayDate time.Time `json:"stay_date" db:"stay_date"`
+	BaseRate float64   `json:"base_rate" db:"base_rate"`
+	Currency string    `json:"currency" db:"currency"`
+}
+
+// PricingRule regla de ajuste de precio (temporada, fin de semana, estadía u ocupación)
+type PricingRule struct {
+	ID            int        `json:"id" db:"id"`
+	HotelID       string     `json:"hotel_id" db:"hotel_id"`
+	RoomType      *string    `json:"room_type" db:"room_type"`
+	RuleType      string     `json:"rule_type" db:"rule_type"`
+	StartDate     *time.Time `json:"start_date,omitempty" db:"start_date"`
+	EndDate       *time.Time `json:"end_date,omitempty" db:"end_date"`
+	MinNights     *int       `json:"min_nights,omitempty" db:"min_nights"`
+	MinOccupancy  *float64   `json:"min_occupancy,omitempty" db:"min_occupancy"`
+	AdjustmentPct float64    `json:"adjustment_pct" db:"adjustment_pct"`
+	FixedRate     *float64   `json:"fixed_rate,omitempty" db:"fixed_rate"`
+	Description   string     `json:"description" db:"description"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// PriceAdjustment ajuste aplicado sobre la tarifa de una noche
+type PriceAdjustment struct {
+	RuleType    string  `json:"rule_type"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+}
+
+// NightlyPrice desglose del precio de una noche
+type NightlyPrice struct {
+	Date        string            `json:"date"`
+	BaseRate    float64           `json:"base_rate"`
+	Adjustments []PriceAdjustment `json:"adjustments"`
+	Price       float64           `json:"price"`
+}
+
+// BookingPriceLine línea de precio persistida para cada noche de una reserva
+type BookingPriceLine struct {
+	ID          int               `json:"id" db:"id"`
+	BookingID   int               `json:"booking_id" db:"booking_id"`
+	BookingRoomID *int            `json:"booking_room_id,omitempty" db:"booking_room_id"`
+	StayDate    time.Time         `json:"stay_date" db:"stay_date"`
+	BaseRate    float64           `json:"base_rate" db:"base_rate"`
+	Adjustments []PriceAdjustment `json:"adjustments" db:"adjustments"`
+	Price       float64           `json:"price" db:"price"`
+	Currency    string            `json:"currency" db:"currency"`
+}
+
+// HotelMapping mapea IDs internos con el proveedor de inventario del hotel.
+// amadeus_hotel_id guarda el ID en el proveedor externo, sea o no Amadeus.
 type HotelMapping struct {
 	ID               int       `json:"id" db:"id"`
 	InternalHotelID  string    `json:"internal_hotel_id" db:"internal_hotel_id"`
-	AmadeusHotelID   string    `json:"amadeus_hotel_id" db:"amadeus_hotel_id"`
+	Provider         string    `json:"provider" db:"provider"`
+	AmadeusHotelID   *string   `json:"amadeus_hotel_id" db:"amadeus_hotel_id"`
 	HotelName        string    `json:"hotel_name" db:"hotel_name"`
 	City             string    `json:"city" db:"city"`
 	CreatedAt        time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// HotelMappingRequest alta o modificación de un mapeo. Nombre y ciudad se
+// toman de hotel-service si no se informan.
+type HotelMappingRequest struct {
+	InternalHotelID string `json:"internal_hotel_id" validate:"required,max=100"`
+	Provider        string `json:"provider" validate:"required,max=20"`
+	AmadeusHotelID  string `json:"amadeus_hotel_id" validate:"omitempty,max=100"`
+	HotelName       string `json:"hotel_name" validate:"omitempty,max=255"`
+	City            string `json:"city" validate:"omitempty,max=100"`
+}
+
+// HotelMappingFilter filtros del listado de mapeos
+type HotelMappingFilter struct {
+	Provider string
+	City     string
+	Page     int
+	Limit    int
+}
+
+// UnmappedHotel hotel activo de hotel-service sin mapeo. Provider es el
+// proveedor por defecto que se le aplica.
+type UnmappedHotel struct {
+	InternalHotelID string `json:"internal_hotel_id"`
+	Name            string `json:"name"`
+	City            string `json:"city"`
+	Provider        string `json:"provider"`
+}
+
+// CatalogHotel hotel del catálogo de hotel-service. price_range y contact se
+// reenvían tal cual porque hotel-service los reemplaza completos al actualizar.
+type CatalogHotel struct {
+	ID          string                 `json:"id,omitempty"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	City        string                 `json:"city"`
+	Address     string                 `json:"address"`
+	Rating      float64                `json:"rating"`
+	PriceRange  map[string]interface{} `json:"price_range,omitempty"`
+	Contact     map[string]interface{} `json:"contact,omitempty"`
+	CancellationPolicy *CancellationPolicy `json:"cancellation_policy,omitempty"`
+}
+
+// HotelImportRequest importación del catálogo de Amadeus de una ciudad
+type HotelImportRequest struct {
+	CityCode string `json:"city_code" validate:"required,len=3,alpha"`
+	City     string `json:"city" validate:"omitempty,max=100"` // nombre a guardar; por defecto el de Amadeus
+	DryRun   bool   `json:"dry_run"`
+}
+
+// HotelImportItem resultado de importar un hotel de Amadeus
+type HotelImportItem struct {
+	AmadeusHotelID  string `json:"amadeus_hotel_id"`
+	InternalHotelID string `json:"internal_hotel_id,omitempty"`
+	Name            string `json:"name"`
+	Reason          string `json:"reason,omitempty"`
+}
+
+// HotelImportReport resumen de una importación
+type HotelImportReport struct {
+	CityCode string            `json:"city_code"`
+	DryRun   bool              `json:"dry_run"`
+	Total    int               `json:"total"`
+	Created  []HotelImportItem `json:"created"`
+	Updated  []HotelImportItem `json:"updated"`
+	Skipped  []HotelImportItem `json:"skipped"`
+}
+
 // AvailabilityCache representa datos de disponibilidad en caché
 type AvailabilityCache struct {
 	ID             int       `json:"id" db:"id"`
@@ -89,11 +358,47 @@ type RegisterRequest struct {
 	Password    string     `json:"password" validate:"required,min=6"`
 	FirstName   string     `json:"first_name" validate:"required,min=2,max=50"`
 	LastName    string     `json:"last_name" validate:"required,min=2,max=50"`
-	Role        UserRole   `json:"role"` // ← LÍNEA AGREGADA (opcional para registro)
 	Phone       string     `json:"phone"`
 	DateOfBirth *time.Time `json:"date_of_birth"`
 }
 
+// UpdateUserRequest datos de un usuario editables por un admin
+type UpdateUserRequest struct {
+	FirstName   *string    `json:"first_name" validate:"omitempty,min=2,max=50"`
+	LastName    *string    `json:"last_name" validate:"omitempty,min=2,max=50"`
+	Phone       *string    `json:"phone" validate:"omitempty,max=20"`
+	DateOfBirth *time.Time `json:"date_of_birth"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=6"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+// AccountExport datos personales del usuario para descarga
+type AccountExport struct {
+	User       *User      `json:"user"`
+	Bookings   []*Booking `json:"bookings"`
+	ExportedAt time.Time  `json:"exported_at"`
+}
+
+type UpdateUserRoleRequest struct {
+	Role UserRole `json:"role" validate:"required,oneof=user admin"`
+}
+
+// UserFilter filtros del listado de usuarios para admins
+type UserFilter struct {
+	Search   string
+	Role     string
+	IsActive *bool
+	Page     int
+	Limit    int
+}
+
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
@@ -104,19 +409,108 @@ type AvailabilityRequest struct {
 	CheckInDate  time.Time `json:"check_in_date" validate:"required"`
 	CheckOutDate time.Time `json:"check_out_date" validate:"required"`
 	Guests       int       `json:"guests" validate:"required,min=1,max=10"`
+	RoomType     string    `json:"room_type"`
 }
 
 type CreateBookingRequest struct {
 	HotelID         string    `json:"hotel_id" validate:"required"`
 	CheckInDate     time.Time `json:"check_in_date" validate:"required"`
 	CheckOutDate    time.Time `json:"check_out_date" validate:"required"`
-	Guests          int       `json:"guests" validate:"required,min=1,max=10"`
+	Guests          int       `json:"guests" validate:"required_without=Rooms,omitempty,min=1,max=10"`
 	RoomType        string    `json:"room_type"`
 	SpecialRequests string    `json:"special_requests"`
+	OfferID         string    `json:"offer_id"`                                       // oferta elegida en disponibilidad (offers[].offer_id)
+	GuestDetails    []BookingGuest `json:"guest_details" validate:"omitempty,max=90,dive"` // el primero es el titular
+	Rooms           []BookingRoomRequest `json:"rooms" validate:"omitempty,min=1,max=9,dive"` // varias habitaciones; reemplaza guests/room_type/offer_id
+	PaymentMethod   string    `json:"payment_method" validate:"omitempty,max=100"` // token del medio de pago; sin él la reserva queda pendiente de pago
+	IdempotencyKey  string    `json:"-"` // header Idempotency-Key; se vincula a la reserva creada
+}
+
+// Estados de una clave de idempotencia
+const (
+	IdempotencyInProgress = "in_progress"
+	IdempotencyCompleted  = "completed"
+)
+
+// IdempotencyKey petición de creación identificada por el header Idempotency-Key.
+// Guarda el hash del cuerpo y la respuesta para repetirla en los reintentos.
+type IdempotencyKey struct {
+	ID             int       `json:"id" db:"id"`
+	UserID         int       `json:"user_id" db:"user_id"`
+	Key            string    `json:"idempotency_key" db:"idempotency_key"`
+	RequestHash    string    `json:"request_hash" db:"request_hash"`
+	Status         string    `json:"status" db:"status"`
+	ResponseStatus *int      `json:"response_status,omitempty" db:"response_status"`
+	ResponseBody   *string   `json:"response_body,omitempty" db:"response_body"`
+	BookingID      *int      `json:"booking_id,omitempty" db:"booking_id"`
+	ExpiresAt      time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// PayBookingRequest pago de una reserva pendiente
+type PayBookingRequest struct {
+	PaymentMethod string `json:"payment_method" validate:"required,max=100"`
+}
+
+// BookingRoomRequest habitación pedida en una reserva de varias habitaciones
+type BookingRoomRequest struct {
+	RoomType string `json:"room_type"`
+	Guests   int    `json:"guests" validate:"required,min=1,max=10"`
+	OfferID  string `json:"offer_id"`
+}
+
+// BookingGuest huésped de una reserva
+type BookingGuest struct {
+	Title     string `json:"title,omitempty" validate:"omitempty,oneof=MR MRS MS"`
+	FirstName string `json:"first_name" validate:"required,min=1,max=50"`
+	LastName  string `json:"last_name" validate:"required,min=1,max=50"`
+	Email     string `json:"email,omitempty" validate:"omitempty,email"`
+	Phone     string `json:"phone,omitempty" validate:"omitempty,max=20"`
+}
+
+type UpdateBookingRequest struct {
+	CheckInDate     *time.Time `json:"check_in_date"`
+	CheckOutDate    *time.Time `json:"check_out_date"`
+	Guests          *int       `json:"guests" validate:"omitempty,min=1,max=10"`
+	RoomType        *string    `json:"room_type"`
+	SpecialRequests *string    `json:"special_requests"`
+}
+
+type CancelBookingRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+type SetInventoryRequest struct {
+	RoomType   string    `json:"room_type"`
+	FromDate   time.Time `json:"from_date" validate:"required"`
+	ToDate     time.Time `json:"to_date" validate:"required"`
+	TotalRooms int       `json:"total_rooms" validate:"min=0,max=10000"`
+}
+
+type SetRatesRequest struct {
+	RoomType string    `json:"room_type"`
+	FromDate time.Time `json:"from_date" validate:"required"`
+	ToDate   time.Time `json:"to_date" validate:"required"`
+	BaseRate float64   `json:"base_rate" validate:"required,gt=0"`
+	Currency string    `json:"currency" validate:"omitempty,len=3"`
+}
+
+type CreatePricingRuleRequest struct {
+	RoomType      string     `json:"room_type"`
+	RuleType      string     `json:"rule_type" validate:"required,oneof=season weekend length_of_stay occupancy"`
+	StartDate     *time.Time `json:"start_date"`
+	EndDate       *time.Time `json:"end_date"`
+	MinNights     *int       `json:"min_nights" validate:"omitempty,min=1"`
+	MinOccupancy  *float64   `json:"min_occupancy" validate:"omitempty,min=0,max=100"`
+	AdjustmentPct float64    `json:"adjustment_pct" validate:"min=-100,max=500"`
+	FixedRate     *float64   `json:"fixed_rate" validate:"omitempty,gt=0"`
+	Description   string     `json:"description" validate:"max=255"`
 }
 
 type AvailabilityResponse struct {
 	HotelID        string   `json:"hotel_id"`
+	Provider       string   `json:"provider,omitempty"`
 	Available      bool     `json:"available"`
 	Price          *float64 `json:"price"`
 	Currency       string   `json:"currency"`
@@ -124,11 +518,50 @@ type AvailabilityResponse struct {
 	CheckInDate    string   `json:"check_in_date"`
 	CheckOutDate   string   `json:"check_out_date"`
 	Guests         int      `json:"guests"`
+	RoomType       string   `json:"room_type"`
+	OfferID        string   `json:"offer_id,omitempty"` // oferta del proveedor a enviar al reservar
+	Nights         []NightlyPrice `json:"nights"`
+	Offers         []RoomOffer    `json:"offers"` // todas las opciones; los campos de arriba son los de la más barata
+}
+
+// RoomOffer opción de habitación y tarifa de una consulta de disponibilidad.
+// Se reserva enviando su offer_id en la creación de la reserva.
+type RoomOffer struct {
+	OfferID            string         `json:"offer_id"`
+	RoomType           string         `json:"room_type"`
+	Description        string         `json:"description,omitempty"`
+	BoardType          string         `json:"board_type,omitempty"`
+	CancellationPolicy string         `json:"cancellation_policy,omitempty"` // descripción legible
+	CancellationTerms  *CancellationPolicy `json:"cancellation_terms,omitempty"`
+	NightlyPrice       float64        `json:"nightly_price"` // promedio por noche
+	TotalPrice         float64        `json:"total_price"`
+	Currency           string         `json:"currency"`
+	RoomsAvailable     *int           `json:"rooms_available,omitempty"`
+	Nights             []NightlyPrice `json:"nights,omitempty"`
 }
 
 type AuthResponse struct {
-	User  *User  `json:"user"`
-	Token string `json:"token"`
+	User         *User  `json:"user"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // segundos de validez del access token
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=6"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
 // AmadeusTokenResponse para respuesta de autenticación de Amadeus
@@ -163,6 +596,15 @@ type AmadeusHotel struct {
 	CityCode    string `json:"cityCode"`
 	Latitude    float64 `json:"latitude"`
 	Longitude   float64 `json:"longitude"`
+	Address     AmadeusHotelAddress `json:"address"`
+}
+
+// AmadeusHotelAddress dirección informada en la búsqueda de hoteles por ciudad
+type AmadeusHotelAddress struct {
+	CountryCode string   `json:"countryCode"`
+	CityName    string   `json:"cityName,omitempty"`
+	PostalCode  string   `json:"postalCode,omitempty"`
+	Lines       []string `json:"lines,omitempty"`
 }
 
 type AmadeusOffer struct {
@@ -171,23 +613,177 @@ type AmadeusOffer struct {
 	CheckOutDate  string        `json:"checkOutDate"`
 	RoomQuantity  int           `json:"roomQuantity"`
 	RateCode      string        `json:"rateCode"`
+	BoardType     string        `json:"boardType,omitempty"`
 	Room          AmadeusRoom   `json:"room"`
 	Guests        AmadeusGuests `json:"guests"`
 	Price         AmadeusPrice  `json:"price"`
+	Policies      AmadeusPolicies `json:"policies"`
 }
 
 type AmadeusRoom struct {
-	Type        string `json:"type"`
-	TypeCode    string `json:"typeCode"`
-	Description string `json:"description"`
+	Type          string               `json:"type"`
+	TypeCode      string               `json:"typeCode"`
+	TypeEstimated AmadeusRoomEstimated `json:"typeEstimated"`
+	Description   AmadeusText          `json:"description"`
+}
+
+// AmadeusRoomEstimated categoría y camas deducidas por Amadeus del código de habitación
+type AmadeusRoomEstimated struct {
+	Category string `json:"category,omitempty"` // ej. STANDARD_ROOM, SUITE
+	Beds     int    `json:"beds,omitempty"`
+	BedType  string `json:"bedType,omitempty"`
+}
+
+type AmadeusText struct {
+	Text string `json:"text,omitempty"`
+	Lang string `json:"lang,omitempty"`
+}
+
+// AmadeusPolicies políticas de la oferta; solo se usan las de cancelación
+type AmadeusPolicies struct {
+	Cancellations []AmadeusCancellation `json:"cancellations,omitempty"`
+	Refundable    *AmadeusRefundable    `json:"refundable,omitempty"`
+}
+
+type AmadeusCancellation struct {
+	Deadline    string      `json:"deadline,omitempty"` // fecha y hora límite sin penalidad
+	Amount      string      `json:"amount,omitempty"`   // penalidad pasado el límite
+	Description AmadeusText `json:"description"`
+}
+
+type AmadeusRefundable struct {
+	CancellationRefund string `json:"cancellationRefund"` // REFUNDABLE_UP_TO_DEADLINE o NON_REFUNDABLE
 }
 
 type AmadeusGuests struct {
 	Adults int `json:"adults"`
 }
 
+// AmadeusBookingGuest huésped en el formato de /v1/booking/hotel-bookings
+type AmadeusBookingGuest struct {
+	Name    AmadeusGuestName    `json:"name"`
+	Contact AmadeusGuestContact `json:"contact"`
+}
+
+type AmadeusGuestName struct {
+	Title     string `json:"title,omitempty"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+}
+
+type AmadeusGuestContact struct {
+	Phone string `json:"phone"`
+	Email string `json:"email"`
+}
+
+// AmadeusPayment pago con la tarjeta de la agencia
+type AmadeusPayment struct {
+	Method string             `json:"method"`
+	Card   AmadeusPaymentCard `json:"card"`
+}
+
+type AmadeusPaymentCard struct {
+	VendorCode string `json:"vendorCode"`
+	CardNumber string `json:"cardNumber"`
+	ExpiryDate string `json:"expiryDate"` // YYYY-MM
+}
+
 type AmadeusPrice struct {
 	Currency string `json:"currency"`
 	Base     string `json:"base"`
 	Total    string `json:"total"`
-}
\ No newline at end of file
+}
+
+// Estados de sincronización de una reserva con el proveedor externo
+const (
+	SyncNotRequired = "not_required"
+	SyncPending     = "pending"
+	SyncSynced      = "synced"
+	SyncFailed      = "failed"
+)
+
+// Operaciones y estados de los trabajos de sincronización
+const (
+	SyncOperationCreate = "create"
+	SyncOperationCancel = "cancel"
+
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// ProviderSyncJob trabajo de sincronización de una reserva con el proveedor
+type ProviderSyncJob struct {
+	ID          int64      `json:"id"`
+	BookingID   int        `json:"booking_id"`
+	BookingRoomID *int     `json:"booking_room_id,omitempty"` // cancelaciones de una sola habitación
+	Operation   string     `json:"operation"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"max_attempts"`
+	NextRunAt   time.Time  `json:"next_run_at"`
+	LastError   *string    `json:"last_error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// SyncJobFilter filtros del listado de trabajos de sincronización
+type SyncJobFilter struct {
+	Status    string
+	BookingID int
+	Limit     int
+}
+
+// Tipos de eventos de reserva publicados en el exchange booking_events
+const (
+	EventBookingCreated   = "booking.created"
+	EventBookingCancelled = "booking.cancelled"
+	EventBookingModified  = "booking.modified"
+	EventBookingConfirmed = "booking.confirmed"
+)
+
+// BookingEvent mensaje publicado cuando cambia una reserva. Los consumidores
+// deben deduplicar por EventID: la entrega es al menos una vez.
+type BookingEvent struct {
+	EventID    string    `json:"event_id"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Booking    *Booking  `json:"booking"`
+	Previous   *Booking  `json:"previous,omitempty"` // estado anterior en booking.modified
+}
+
+// Estados de un pago
+const (
+	PaymentPending    = "pending"
+	PaymentAuthorized = "authorized"
+	PaymentCaptured   = "captured"
+	PaymentRefunded   = "refunded"
+	PaymentVoided     = "voided"
+	PaymentDeclined   = "declined"
+	PaymentFailed     = "failed"
+)
+
+// Payment pago de una reserva. AmountDue es lo que el cliente debe pagar
+// después de las cancelaciones; el worker de pagos captura, libera o reintegra
+// hasta que lo cobrado coincida.
+type Payment struct {
+	ID             int        `json:"id"`
+	BookingID      int        `json:"booking_id"`
+	Gateway        string     `json:"gateway"`
+	TransactionID  *string    `json:"transaction_id,omitempty"`
+	Status         string     `json:"status"`
+	Amount         float64    `json:"amount"`
+	AmountDue      float64    `json:"amount_due"`
+	CapturedAmount float64    `json:"captured_amount"`
+	RefundedAmount float64    `json:"refunded_amount"`
+	Currency       string     `json:"currency"`
+	FailureReason  *string    `json:"failure_reason,omitempty"`
+	Attempts       int        `json:"-"`
+	LastError      *string    `json:"-"`
+	AuthorizedAt   *time.Time `json:"authorized_at,omitempty"`
+	CapturedAt     *time.Time `json:"captured_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "testing"
+
+func TestCanTransitionTo(t *testing.T) {
+	statuses := []string{StatusPending, StatusConfirmed, StatusCancelled, StatusCompleted}
+	allowed := map[string]map[string]bool{
+		StatusPending:   {StatusConfirmed: true, StatusCancelled: true},
+		StatusConfirmed: {StatusCancelled: true, StatusCompleted: true},
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			t.Run(from+"->"+to, func(t *testing.T) {
+				booking := &Booking{Status: from}
+				if got := booking.CanTransitionTo(to); got != allowed[from][to] {
+					t.Fatalf("CanTransitionTo = %v, se esperaba %v", got, allowed[from][to])
+				}
+			})
+		}
+	}
+
+	// Un estado desconocido no admite ninguna transición
+	unknown := &Booking{Status: "archived"}
+	for _, to := range statuses {
+		if unknown.CanTransitionTo(to) {
+			t.Fatalf("archived -> %s permitido", to)
+		}
+	}
+}
+
+func TestIsModifiable(t *testing.T) {
+	tests := map[string]bool{
+		StatusPending:   true,
+		StatusConfirmed: true,
+		StatusCancelled: false,
+		StatusCompleted: false,
+	}
+	for status, want := range tests {
+		if got := (&Booking{Status: status}).IsModifiable(); got != want {
+			t.Fatalf("IsModifiable(%s) = %v, se esperaba %v", status, got, want)
+		}
+	}
+}
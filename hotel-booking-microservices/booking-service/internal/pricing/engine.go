@@ -0,0 +1,214 @@
+package pricing
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"booking-service/internal/models"
+)
+
+// Night datos de entrada para cotizar una noche
+type Night struct {
+	Date      time.Time
+	BaseRate  float64
+	Occupancy float64 // porcentaje de ocupación de la noche (0-100)
+}
+
+// Quote resultado de cotizar una estadía
+type Quote struct {
+	Nights []models.NightlyPrice
+	Total  float64
+}
+
+// Calculate aplica las reglas de precio a cada noche de la estadía.
+// El orden es: temporada, fin de semana, ocupación y por último el descuento
+// por duración de la estadía, que depende de la cantidad total de noches.
+func Calculate(nights []Night, rules []models.PricingRule) *Quote {
+	quote := &Quote{Nights: make([]models.NightlyPrice, 0, len(nights))}
+	losRule := bestLengthOfStayRule(rules, len(nights))
+
+	for _, night := range nights {
+		line := models.NightlyPrice{
+			Date:        night.Date.Format("2006-01-02"),
+			BaseRate:    round(night.BaseRate),
+			Adjustments: []models.PriceAdjustment{},
+		}
+		price := night.BaseRate
+
+		// Temporadas: una tarifa fija reemplaza la base, un porcentaje la ajusta
+		for _, rule := range rules {
+			if rule.RuleType != models.RuleSeason || !inSeason(rule, night.Date) {
+				continue
+			}
+			if rule.FixedRate != nil {
+				line.Adjustments = append(line.Adjustments, adjustment(rule, *rule.FixedRate-price))
+				price = *rule.FixedRate
+			}
+			if rule.AdjustmentPct != 0 {
+				amount := price * rule.AdjustmentPct / 100
+				line.Adjustments = append(line.Adjustments, adjustment(rule, amount))
+				price += amount
+			}
+		}
+
+		// Recargo de fin de semana (noches de viernes y sábado)
+		if isWeekendNight(night.Date) {
+			for _, rule := range rules {
+				if rule.RuleType != models.RuleWeekend {
+					continue
+				}
+				amount := price * rule.AdjustmentPct / 100
+				line.Adjustments = append(line.Adjustments, adjustment(rule, amount))
+				price += amount
+			}
+		}
+
+		// Ajuste por ocupación: se aplica el umbral más alto alcanzado
+		if rule := bestOccupancyRule(rules, night.Occupancy); rule != nil {
+			amount := price * rule.AdjustmentPct / 100
+			line.Adjustments = append(line.Adjustments, adjustment(*rule, amount))
+			price += amount
+		}
+
+		if losRule != nil {
+			amount := price * losRule.AdjustmentPct / 100
+			line.Adjustments = append(line.Adjustments, adjustment(*losRule, amount))
+			price += amount
+		}
+
+		if price < 0 {
+			price = 0
+		}
+
+		line.Price = round(price)
+		quote.Total += line.Price
+		quote.Nights = append(quote.Nights, line)
+	}
+
+	quote.Total = round(quote.Total)
+	return quote
+}
+
+// SplitTotal reparte un total en partes iguales por noche, usado cuando el
+// proveedor solo informa el precio de la estadía completa
+func SplitTotal(nights []time.Time, total float64) *Quote {
+	quote := &Quote{Nights: make([]models.NightlyPrice, 0, len(nights)), Total: round(total)}
+	if len(nights) == 0 {
+		return quote
+	}
+
+	// Se reparte en centavos: los que sobran de la división se suman de a uno
+	// a las primeras noches
+	cents := int64(math.Round(total * 100))
+	perNight := cents / int64(len(nights))
+	remainder := cents % int64(len(nights))
+	for i, night := range nights {
+		nightCents := perNight
+		if int64(i) < remainder {
+			nightCents++
+		}
+		price := float64(nightCents) / 100
+		quote.Nights = append(quote.Nights, models.NightlyPrice{
+			Date:        night.Format("2006-01-02"),
+			BaseRate:    price,
+			Adjustments: []models.PriceAdjustment{},
+			Price:       price,
+		})
+	}
+
+	return quote
+}
+
+// ValidateRule verifica que una regla tenga los campos que su tipo requiere
+func ValidateRule(rule *models.PricingRule) error {
+	switch rule.RuleType {
+	case models.RuleSeason:
+		if rule.StartDate == nil || rule.EndDate == nil {
+			return fmt.Errorf("regla inválida: una temporada requiere start_date y end_date")
+		}
+		if rule.EndDate.Before(*rule.StartDate) {
+			return fmt.Errorf("regla inválida: end_date debe ser igual o posterior a start_date")
+		}
+		if rule.FixedRate == nil && rule.AdjustmentPct == 0 {
+			return fmt.Errorf("regla inválida: una temporada requiere fixed_rate o adjustment_pct")
+		}
+	case models.RuleWeekend:
+		if rule.AdjustmentPct == 0 {
+			return fmt.Errorf("regla inválida: el recargo de fin de semana requiere adjustment_pct")
+		}
+	case models.RuleLengthOfStay:
+		if rule.MinNights == nil {
+			return fmt.Errorf("regla inválida: el descuento por estadía requiere min_nights")
+		}
+	case models.RuleOccupancy:
+		if rule.MinOccupancy == nil {
+			return fmt.Errorf("regla inválida: el ajuste por ocupación requiere min_occupancy")
+		}
+	default:
+		return fmt.Errorf("regla inválida: tipo %s desconocido", rule.RuleType)
+	}
+	return nil
+}
+
+// inSeason verifica si la noche cae dentro de la temporada (extremos incluidos)
+func inSeason(rule models.PricingRule, date time.Time) bool {
+	if rule.StartDate == nil || rule.EndDate == nil {
+		return false
+	}
+	day := date.Format("2006-01-02")
+	return day >= rule.StartDate.Format("2006-01-02") && day <= rule.EndDate.Format("2006-01-02")
+}
+
+// isWeekendNight considera fin de semana las noches de viernes y sábado
+func isWeekendNight(date time.Time) bool {
+	return date.Weekday() == time.Friday || date.Weekday() == time.Saturday
+}
+
+// bestLengthOfStayRule devuelve la regla de estadía con mayor mínimo de noches alcanzado
+func bestLengthOfStayRule(rules []models.PricingRule, nights int) *models.PricingRule {
+	var best *models.PricingRule
+	for i := range rules {
+		rule := &rules[i]
+		if rule.RuleType != models.RuleLengthOfStay || rule.MinNights == nil || nights < *rule.MinNights {
+			continue
+		}
+		if best == nil || *rule.MinNights > *best.MinNights {
+			best = rule
+		}
+	}
+	return best
+}
+
+// bestOccupancyRule devuelve la regla de ocupación con mayor umbral alcanzado
+func bestOccupancyRule(rules []models.PricingRule, occupancy float64) *models.PricingRule {
+	var best *models.PricingRule
+	for i := range rules {
+		rule := &rules[i]
+		if rule.RuleType != models.RuleOccupancy || rule.MinOccupancy == nil || occupancy < *rule.MinOccupancy {
+			continue
+		}
+		if best == nil || *rule.MinOccupancy > *best.MinOccupancy {
+			best = rule
+		}
+	}
+	return best
+}
+
+// adjustment construye el detalle de un ajuste aplicado
+func adjustment(rule models.PricingRule, amount float64) models.PriceAdjustment {
+	description := rule.Description
+	if description == "" {
+		description = rule.RuleType
+	}
+	return models.PriceAdjustment{
+		RuleType:    rule.RuleType,
+		Description: description,
+		Amount:      round(amount),
+	}
+}
+
+// round redondea a dos decimales
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
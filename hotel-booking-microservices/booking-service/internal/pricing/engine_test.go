@@ -0,0 +1,227 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"booking-service/internal/models"
+)
+
+// day fecha de marzo de 2026: el 2 es lunes y el 6 viernes
+func day(d int) time.Time {
+	return time.Date(2026, time.March, d, 0, 0, 0, 0, time.Local)
+}
+
+// stay noches consecutivas desde el día from con la misma tarifa y ocupación
+func stay(from, nights int, rate, occupancy float64) []Night {
+	result := make([]Night, 0, nights)
+	for i := 0; i < nights; i++ {
+		result = append(result, Night{Date: day(from + i), BaseRate: rate, Occupancy: occupancy})
+	}
+	return result
+}
+
+func floatPtr(v float64) *float64 { return &v }
+
+func intPtr(v int) *int { return &v }
+
+func timePtr(v time.Time) *time.Time { return &v }
+
+func season(from, to int, pct float64, fixed *float64) models.PricingRule {
+	return models.PricingRule{RuleType: models.RuleSeason, StartDate: timePtr(day(from)), EndDate: timePtr(day(to)), AdjustmentPct: pct, FixedRate: fixed}
+}
+
+func TestCalculate(t *testing.T) {
+	tests := []struct {
+		name       string
+		nights     []Night
+		rules      []models.PricingRule
+		wantPrices []float64
+		wantTotal  float64
+	}{
+		{
+			name:       "sin reglas",
+			nights:     stay(2, 2, 100, 0),
+			wantPrices: []float64{100, 100},
+			wantTotal:  200,
+		},
+		{
+			name:       "temporada con tarifa fija",
+			nights:     stay(2, 3, 100, 0),
+			rules:      []models.PricingRule{season(3, 3, 0, floatPtr(150))},
+			wantPrices: []float64{100, 150, 100},
+			wantTotal:  350,
+		},
+		{
+			name:       "temporada con porcentaje, extremos incluidos",
+			nights:     stay(2, 4, 100, 0),
+			rules:      []models.PricingRule{season(3, 4, 20, nil)},
+			wantPrices: []float64{100, 120, 120, 100},
+			wantTotal:  440,
+		},
+		{
+			name:       "temporada con tarifa fija y porcentaje",
+			nights:     stay(3, 1, 100, 0),
+			rules:      []models.PricingRule{season(3, 3, 10, floatPtr(150))},
+			wantPrices: []float64{165},
+			wantTotal:  165,
+		},
+		{
+			name:       "fin de semana solo viernes y sábado",
+			nights:     stay(5, 4, 100, 0),
+			rules:      []models.PricingRule{{RuleType: models.RuleWeekend, AdjustmentPct: 25}},
+			wantPrices: []float64{100, 125, 125, 100},
+			wantTotal:  450,
+		},
+		{
+			name: "ocupación aplica el umbral más alto alcanzado",
+			nights: []Night{
+				{Date: day(2), BaseRate: 100, Occupancy: 49.9},
+				{Date: day(3), BaseRate: 100, Occupancy: 50},
+				{Date: day(4), BaseRate: 100, Occupancy: 95},
+			},
+			rules: []models.PricingRule{
+				{RuleType: models.RuleOccupancy, MinOccupancy: floatPtr(50), AdjustmentPct: 10},
+				{RuleType: models.RuleOccupancy, MinOccupancy: floatPtr(80), AdjustmentPct: 30},
+			},
+			wantPrices: []float64{100, 110, 130},
+			wantTotal:  340,
+		},
+		{
+			name:   "duración por debajo del mínimo",
+			nights: stay(2, 2, 100, 0),
+			rules: []models.PricingRule{
+				{RuleType: models.RuleLengthOfStay, MinNights: intPtr(3), AdjustmentPct: -10},
+			},
+			wantPrices: []float64{100, 100},
+			wantTotal:  200,
+		},
+		{
+			name:   "duración aplica el mayor mínimo alcanzado",
+			nights: stay(9, 5, 100, 0),
+			rules: []models.PricingRule{
+				{RuleType: models.RuleLengthOfStay, MinNights: intPtr(3), AdjustmentPct: -10},
+				{RuleType: models.RuleLengthOfStay, MinNights: intPtr(5), AdjustmentPct: -20},
+			},
+			wantPrices: []float64{80, 80, 80, 80, 80},
+			wantTotal:  400,
+		},
+		{
+			name:   "temporada, fin de semana y duración en orden",
+			nights: stay(5, 3, 100, 0),
+			rules: []models.PricingRule{
+				{RuleType: models.RuleLengthOfStay, MinNights: intPtr(3), AdjustmentPct: -10},
+				{RuleType: models.RuleWeekend, AdjustmentPct: 25},
+				season(6, 6, 20, nil),
+			},
+			wantPrices: []float64{90, 135, 112.5},
+			wantTotal:  337.5,
+		},
+		{
+			name:       "redondeo a centavos por noche",
+			nights:     stay(2, 3, 33.333, 0),
+			rules:      []models.PricingRule{{RuleType: models.RuleLengthOfStay, MinNights: intPtr(1), AdjustmentPct: 15}},
+			wantPrices: []float64{38.33, 38.33, 38.33},
+			wantTotal:  114.99,
+		},
+		{
+			name:       "el precio no baja de cero",
+			nights:     stay(2, 1, 100, 0),
+			rules:      []models.PricingRule{season(2, 2, -150, nil)},
+			wantPrices: []float64{0},
+			wantTotal:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote := Calculate(tt.nights, tt.rules)
+			if len(quote.Nights) != len(tt.wantPrices) {
+				t.Fatalf("noches = %d, se esperaban %d", len(quote.Nights), len(tt.wantPrices))
+			}
+			for i, night := range quote.Nights {
+				if night.Price != tt.wantPrices[i] {
+					t.Fatalf("noche %s: precio = %v, se esperaba %v (%+v)", night.Date, night.Price, tt.wantPrices[i], night.Adjustments)
+				}
+				if night.Date != tt.nights[i].Date.Format("2006-01-02") {
+					t.Fatalf("noche %d: fecha = %s, se esperaba %s", i, night.Date, tt.nights[i].Date.Format("2006-01-02"))
+				}
+			}
+			if quote.Total != tt.wantTotal {
+				t.Fatalf("total = %v, se esperaba %v", quote.Total, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestCalculateAdjustments(t *testing.T) {
+	rules := []models.PricingRule{
+		season(6, 6, 0, floatPtr(150)),
+		{RuleType: models.RuleWeekend, AdjustmentPct: 10, Description: "Fin de semana"},
+	}
+
+	quote := Calculate(stay(6, 1, 100, 0), rules)
+	got := quote.Nights[0]
+	if got.BaseRate != 100 || got.Price != 165 {
+		t.Fatalf("noche = base %v precio %v, se esperaba base 100 precio 165", got.BaseRate, got.Price)
+	}
+
+	want := []models.PriceAdjustment{
+		{RuleType: models.RuleSeason, Description: models.RuleSeason, Amount: 50},
+		{RuleType: models.RuleWeekend, Description: "Fin de semana", Amount: 15},
+	}
+	if len(got.Adjustments) != len(want) {
+		t.Fatalf("ajustes = %+v, se esperaba %+v", got.Adjustments, want)
+	}
+	for i := range want {
+		if got.Adjustments[i] != want[i] {
+			t.Fatalf("ajuste %d = %+v, se esperaba %+v", i, got.Adjustments[i], want[i])
+		}
+	}
+}
+
+func TestSplitTotal(t *testing.T) {
+	tests := []struct {
+		name       string
+		nights     int
+		total      float64
+		wantPrices []float64
+	}{
+		{name: "sin noches", nights: 0, total: 100, wantPrices: []float64{}},
+		{name: "división exacta", nights: 4, total: 400, wantPrices: []float64{100, 100, 100, 100}},
+		{name: "un centavo de resto", nights: 3, total: 100, wantPrices: []float64{33.34, 33.33, 33.33}},
+		{name: "resto repartido de a un centavo", nights: 8, total: 1.06, wantPrices: []float64{0.14, 0.14, 0.13, 0.13, 0.13, 0.13, 0.13, 0.13}},
+		{name: "total con más de dos decimales", nights: 2, total: 99.999, wantPrices: []float64{50, 50}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nights := make([]time.Time, tt.nights)
+			for i := range nights {
+				nights[i] = day(2 + i)
+			}
+
+			quote := SplitTotal(nights, tt.total)
+			if quote.Total != round(tt.total) {
+				t.Fatalf("total = %v, se esperaba %v", quote.Total, round(tt.total))
+			}
+			if len(quote.Nights) != len(tt.wantPrices) {
+				t.Fatalf("noches = %d, se esperaban %d", len(quote.Nights), len(tt.wantPrices))
+			}
+
+			sum := 0.0
+			for i, night := range quote.Nights {
+				if night.Price != tt.wantPrices[i] || night.BaseRate != night.Price {
+					t.Fatalf("noche %d: precio = %v base = %v, se esperaba %v", i, night.Price, night.BaseRate, tt.wantPrices[i])
+				}
+				if night.Date != nights[i].Format("2006-01-02") {
+					t.Fatalf("noche %d: fecha = %s", i, night.Date)
+				}
+				sum += night.Price
+			}
+			if len(nights) > 0 && round(sum) != quote.Total {
+				t.Fatalf("suma de noches = %v, distinta del total %v", round(sum), quote.Total)
+			}
+		})
+	}
+}
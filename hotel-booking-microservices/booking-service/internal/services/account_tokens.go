@@ -0,0 +1,195 @@
+package services
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"booking-service/internal/models"
+	"booking-service/pkg/mailer"
+)
+
+// Propósitos de los tokens enviados por email
+const (
+	tokenPasswordReset     = "password_reset"
+	tokenEmailVerification = "email_verification"
+)
+
+// Vigencia de los tokens enviados por email
+const (
+	passwordResetTTL     = time.Hour
+	emailVerificationTTL = 48 * time.Hour
+)
+
+// RequestPasswordReset envía un enlace de recuperación de contraseña.
+// Si el email no existe no devuelve error, para no revelar qué cuentas existen.
+// El token y el envío se resuelven en segundo plano: si no, una cuenta
+// existente respondería más lento y el tiempo de respuesta la delataría.
+func (s *BookingService) RequestPasswordReset(email string) error {
+	user, err := s.GetUserByEmail(email)
+	if err != nil {
+		return nil
+	}
+
+	go func() {
+		token, err := s.createUserToken(user.ID, tokenPasswordReset, passwordResetTTL)
+		if err != nil {
+			fmt.Printf("⚠️ Warning: no se pudo generar el token de recuperación del usuario %d: %v\n", user.ID, err)
+			return
+		}
+
+		err = s.mailer.Send(mailer.Message{
+			To:      user.Email,
+			Subject: "Recuperá tu contraseña",
+			Body: fmt.Sprintf("Hola %s,\n\nPara elegir una nueva contraseña ingresá a:\n%s/reset-password?token=%s\n\n"+
+				"El enlace vence en %d minutos y solo puede usarse una vez. Si no lo pediste, ignorá este email.\n",
+				user.FirstName, s.frontendURL, token, int(passwordResetTTL.Minutes())),
+		})
+		if err != nil {
+			fmt.Printf("⚠️ Warning: no se pudo enviar el email de recuperación al usuario %d: %v\n", user.ID, err)
+		}
+	}()
+
+	return nil
+}
+
+// ResetPassword cambia la contraseña con un token de recuperación y cierra las sesiones abiertas
+func (s *BookingService) ResetPassword(token, newPassword string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("error hasheando password: %v", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error iniciando transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	userID, err := s.consumeUserToken(tx, token, tokenPasswordReset)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("UPDATE users SET password_hash = ? WHERE id = ?", string(hashedPassword), userID); err != nil {
+		return fmt.Errorf("error actualizando password: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error confirmando cambio de password: %v", err)
+	}
+
+	return s.BumpTokenVersion(userID)
+}
+
+// SendEmailVerification envía el enlace de verificación de email
+func (s *BookingService) SendEmailVerification(user *models.User) error {
+	if user.EmailVerified {
+		return fmt.Errorf("no se puede reenviar: el email ya está verificado")
+	}
+
+	token, err := s.createUserToken(user.ID, tokenEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verificá tu email",
+		Body: fmt.Sprintf("Hola %s,\n\nConfirmá tu email ingresando a:\n%s/verify-email?token=%s\n\nEl enlace vence en %d horas.\n",
+			user.FirstName, s.frontendURL, token, int(emailVerificationTTL.Hours())),
+	})
+}
+
+// VerifyEmail marca el email del usuario como verificado
+func (s *BookingService) VerifyEmail(token string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error iniciando transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	userID, err := s.consumeUserToken(tx, token, tokenEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("UPDATE users SET email_verified = TRUE WHERE id = ?", userID); err != nil {
+		return fmt.Errorf("error verificando email: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error confirmando verificación: %v", err)
+	}
+
+	return nil
+}
+
+// createUserToken genera un token de un solo uso e invalida los anteriores del mismo propósito
+func (s *BookingService) createUserToken(userID int, purpose string, ttl time.Duration) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("error generando token: %v", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return "", fmt.Errorf("error iniciando transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("UPDATE user_tokens SET used_at = NOW() WHERE user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose)
+	if err != nil {
+		return "", fmt.Errorf("error invalidando tokens anteriores: %v", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at)
+		VALUES (?, ?, ?, ?)
+	`, userID, purpose, hashToken(token), time.Now().Add(ttl))
+	if err != nil {
+		return "", fmt.Errorf("error guardando token: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("error confirmando token: %v", err)
+	}
+
+	return token, nil
+}
+
+// consumeUserToken valida un token y lo marca como usado dentro de la transacción
+func (s *BookingService) consumeUserToken(tx *sql.Tx, token, purpose string) (int, error) {
+	var tokenID, userID int
+	var expiresAt time.Time
+	var usedAt *time.Time
+	err := tx.QueryRow(`
+		SELECT id, user_id, expires_at, used_at FROM user_tokens
+		WHERE token_hash = ? AND purpose = ?
+		FOR UPDATE
+	`, hashToken(token), purpose).Scan(&tokenID, &userID, &expiresAt, &usedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("token inválido")
+		}
+		return 0, fmt.Errorf("error obteniendo token: %v", err)
+	}
+
+	if usedAt != nil {
+		return 0, fmt.Errorf("token inválido: ya fue utilizado")
+	}
+	if time.Now().After(expiresAt) {
+		return 0, fmt.Errorf("token inválido: expirado")
+	}
+
+	if _, err := tx.Exec("UPDATE user_tokens SET used_at = NOW() WHERE id = ?", tokenID); err != nil {
+		return 0, fmt.Errorf("error marcando token como usado: %v", err)
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"booking-service/pkg/mailer"
+)
+
+// blockingMailer retiene cada envío hasta que el test lo libera
+type blockingMailer struct {
+	release chan struct{}
+	sent    chan mailer.Message
+}
+
+func (m *blockingMailer) Send(msg mailer.Message) error {
+	<-m.release
+	m.sent <- msg
+	return nil
+}
+
+func TestRequestPasswordResetDoesNotWaitForMail(t *testing.T) {
+	db := newTestDB(t)
+	mail := &blockingMailer{release: make(chan struct{}), sent: make(chan mailer.Message, 1)}
+	service := NewBookingService(db, nil, nil, Options{Mailer: mail, FrontendURL: "http://frontend.test"})
+
+	mustExec(t, db, "INSERT INTO users (email, password_hash, first_name, last_name, phone) VALUES ('ana@test.com', 'x', 'Ana', 'Pérez', '')")
+
+	// Con el envío bloqueado, la respuesta para una cuenta existente no lo espera
+	done := make(chan error, 1)
+	go func() { done <- service.RequestPasswordReset("ana@test.com") }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("error pidiendo recuperación: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("RequestPasswordReset esperó el envío del email")
+	}
+
+	close(mail.release)
+	select {
+	case msg := <-mail.sent:
+		if msg.To != "ana@test.com" || !strings.Contains(msg.Body, "http://frontend.test/reset-password?token=") {
+			t.Fatalf("email inesperado: %+v", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no se envió el email de recuperación")
+	}
+
+	// Un email desconocido tampoco devuelve error ni envía nada
+	if err := service.RequestPasswordReset("nadie@test.com"); err != nil {
+		t.Fatalf("error con email desconocido: %v", err)
+	}
+	select {
+	case msg := <-mail.sent:
+		t.Fatalf("se envió un email a una cuenta inexistente: %+v", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,300 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"booking-service/internal/models"
+	"booking-service/pkg/memcached"
+)
+
+// tokenVersionCacheTTL tiempo que se cachea la versión de tokens de un usuario.
+// Se invalida explícitamente al cambiar, así que puede ser largo.
+const tokenVersionCacheTTL = 10 * time.Minute
+
+// Motivos de revocación de un refresh token
+const (
+	refreshRevokedRotated = "rotated" // reemplazado por uno nuevo al refrescar
+	refreshRevokedLogout  = "logout"
+	refreshRevokedAll     = "revoked" // sesiones del usuario revocadas
+)
+
+// issueTokens emite un access token de vida corta y un refresh token nuevo
+func (s *BookingService) issueTokens(user *models.User) (*models.AuthResponse, error) {
+	accessToken, err := s.generateJWTToken(user)
+	if err != nil {
+		return nil, fmt.Errorf("error generando token: %v", err)
+	}
+
+	refreshToken, err := s.createRefreshToken(s.db, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AuthResponse{
+		User:         user,
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(s.accessTokenTTL.Seconds()),
+	}, nil
+}
+
+// RefreshTokens rota un refresh token: el usado queda revocado y se emite un par nuevo
+func (s *BookingService) RefreshTokens(refreshToken string) (*models.AuthResponse, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error iniciando transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	var tokenID, userID int
+	var expiresAt time.Time
+	var revokedAt *time.Time
+	var revokedReason sql.NullString
+	err = tx.QueryRow(`
+		SELECT id, user_id, expires_at, revoked_at, revoked_reason FROM refresh_tokens
+		WHERE token_hash = ?
+		FOR UPDATE
+	`, hashToken(refreshToken)).Scan(&tokenID, &userID, &expiresAt, &revokedAt, &revokedReason)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("refresh token inválido")
+		}
+		return nil, fmt.Errorf("error obteniendo refresh token: %v", err)
+	}
+
+	if revokedAt != nil {
+		// Reutilizar un token ya rotado indica que fue robado: se cierra toda sesión del usuario
+		if revokedReason.String == refreshRevokedRotated {
+			tx.Rollback()
+			if err := s.BumpTokenVersion(userID); err != nil {
+				fmt.Printf("⚠️ Warning: No se pudieron revocar las sesiones del usuario %d: %v\n", userID, err)
+			}
+			return nil, fmt.Errorf("refresh token inválido: ya fue utilizado")
+		}
+		return nil, fmt.Errorf("refresh token inválido: revocado")
+	}
+
+	if time.Now().After(expiresAt) {
+		return nil, fmt.Errorf("refresh token inválido: expirado")
+	}
+
+	if _, err := tx.Exec("UPDATE refresh_tokens SET revoked_at = NOW(), revoked_reason = ? WHERE id = ?", refreshRevokedRotated, tokenID); err != nil {
+		return nil, fmt.Errorf("error revocando refresh token: %v", err)
+	}
+
+	// GetUserByID solo devuelve usuarios activos
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("refresh token inválido: %v", err)
+	}
+
+	newRefreshToken, err := s.createRefreshToken(tx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := s.generateJWTToken(user)
+	if err != nil {
+		return nil, fmt.Errorf("error generando token: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error confirmando refresh token: %v", err)
+	}
+
+	return &models.AuthResponse{
+		User:         user,
+		Token:        accessToken,
+		RefreshToken: newRefreshToken,
+		ExpiresIn:    int(s.accessTokenTTL.Seconds()),
+	}, nil
+}
+
+// RevokeRefreshToken revoca un refresh token (logout)
+func (s *BookingService) RevokeRefreshToken(refreshToken string) error {
+	_, err := s.db.Exec("UPDATE refresh_tokens SET revoked_at = NOW(), revoked_reason = ? WHERE token_hash = ? AND revoked_at IS NULL", refreshRevokedLogout, hashToken(refreshToken))
+	if err != nil {
+		return fmt.Errorf("error revocando refresh token: %v", err)
+	}
+	return nil
+}
+
+// BumpTokenVersion invalida todos los tokens emitidos para un usuario.
+// Se usa al cambiar su rol o desactivarlo.
+func (s *BookingService) BumpTokenVersion(userID int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error iniciando transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE users SET token_version = token_version + 1 WHERE id = ?", userID); err != nil {
+		return fmt.Errorf("error actualizando versión de token: %v", err)
+	}
+
+	if _, err := tx.Exec("UPDATE refresh_tokens SET revoked_at = NOW(), revoked_reason = ? WHERE user_id = ? AND revoked_at IS NULL", refreshRevokedAll, userID); err != nil {
+		return fmt.Errorf("error revocando refresh tokens: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error confirmando revocación: %v", err)
+	}
+
+	s.cache.Delete(memcached.GenerateTokenVersionKey(userID))
+	return nil
+}
+
+// execer abstrae *sql.DB y *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// createRefreshToken genera un refresh token aleatorio y guarda su hash
+func (s *BookingService) createRefreshToken(db execer, userID int) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("error generando refresh token: %v", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	_, err := db.Exec(`
+		INSERT INTO refresh_tokens (user_id, token_hash, expires_at)
+		VALUES (?, ?, ?)
+	`, userID, hashToken(token), time.Now().Add(s.refreshTokenTTL))
+	if err != nil {
+		return "", fmt.Errorf("error guardando refresh token: %v", err)
+	}
+
+	return token, nil
+}
+
+// hashToken calcula el SHA-256 de un token para no guardarlo en claro
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// generateJWTToken genera un access token JWT para un usuario.
+// El rol y el email permiten que otros servicios (hotel-service) autoricen
+// y auditen sin consultar la base de usuarios; tv es la versión de tokens.
+func (s *BookingService) generateJWTToken(user *models.User) (string, error) {
+	// Crear claims
+	claims := jwt.MapClaims{
+		"user_id": user.ID,
+		"role":    string(user.Role),
+		"email":   user.Email,
+		"tv":      user.TokenVersion,
+		"exp":     time.Now().Add(s.accessTokenTTL).Unix(),
+		"iat":     time.Now().Unix(),
+	}
+
+	// Crear token
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	// Firmar token
+	tokenString, err := token.SignedString([]byte(s.jwtSecret))
+	if err != nil {
+		return "", fmt.Errorf("error firmando token: %v", err)
+	}
+
+	return tokenString, nil
+}
+
+// ParseAccessToken valida firma, expiración y versión de un access token
+func (s *BookingService) ParseAccessToken(tokenString string) (*models.TokenClaims, error) {
+	// Parsear token
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// Verificar método de firma
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("método de firma inesperado: %v", token.Header["alg"])
+		}
+		return []byte(s.jwtSecret), nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("error parseando token: %v", err)
+	}
+
+	// Verificar que el token sea válido
+	if !token.Valid {
+		return nil, fmt.Errorf("token inválido")
+	}
+
+	// Extraer claims
+	mapClaims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("claims inválidos")
+	}
+
+	userID, ok := mapClaims["user_id"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("user_id inválido en token")
+	}
+
+	version, ok := mapClaims["tv"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("versión de token ausente")
+	}
+
+	claims := &models.TokenClaims{UserID: int(userID), TokenVersion: int(version)}
+	claims.Role, _ = mapClaims["role"].(string)
+	claims.Email, _ = mapClaims["email"].(string)
+
+	// La versión actual sale de Memcached, así que no hay consulta a MySQL por request
+	current, err := s.getTokenVersion(claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenVersion != current {
+		return nil, fmt.Errorf("token revocado")
+	}
+
+	return claims, nil
+}
+
+// ValidateJWTToken valida un token JWT
+func (s *BookingService) ValidateJWTToken(tokenString string) (int, error) {
+	claims, err := s.ParseAccessToken(tokenString)
+	if err != nil {
+		return 0, err
+	}
+	return claims.UserID, nil
+}
+
+// ValidateJWTTokenWithRole valida un token JWT y devuelve userID y role
+func (s *BookingService) ValidateJWTTokenWithRole(tokenString string) (int, string, error) {
+	claims, err := s.ParseAccessToken(tokenString)
+	if err != nil {
+		return 0, "", err
+	}
+	return claims.UserID, claims.Role, nil
+}
+
+// getTokenVersion obtiene la versión de tokens vigente de un usuario activo
+func (s *BookingService) getTokenVersion(userID int) (int, error) {
+	cacheKey := memcached.GenerateTokenVersionKey(userID)
+
+	var version int
+	if err := s.cache.Get(cacheKey, &version); err == nil {
+		return version, nil
+	}
+
+	err := s.db.QueryRow("SELECT token_version FROM users WHERE id = ? AND is_active = TRUE", userID).Scan(&version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("usuario no encontrado o inactivo")
+		}
+		return 0, fmt.Errorf("error obteniendo versión de token: %v", err)
+	}
+
+	s.cache.Set(cacheKey, version, tokenVersionCacheTTL)
+	return version, nil
+}
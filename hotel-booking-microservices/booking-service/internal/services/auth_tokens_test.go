@@ -0,0 +1,194 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"booking-service/internal/models"
+	"booking-service/pkg/mysql"
+)
+
+// loginTestUser carga un usuario y le emite tokens como lo hace el login
+func loginTestUser(t *testing.T, service *BookingService, db *mysql.DB, email string) (*models.User, *models.AuthResponse) {
+	t.Helper()
+
+	user, err := service.GetUserByID(insertUser(t, db, email))
+	if err != nil {
+		t.Fatalf("error obteniendo usuario: %v", err)
+	}
+	auth, err := service.issueTokens(user)
+	if err != nil {
+		t.Fatalf("error emitiendo tokens: %v", err)
+	}
+	return user, auth
+}
+
+// refreshTokenRevocation estado de revocación guardado de un refresh token
+func refreshTokenRevocation(t *testing.T, db *mysql.DB, token string) (revoked bool, reason string) {
+	t.Helper()
+
+	var revokedAt *time.Time
+	var revokedReason *string
+	if err := db.QueryRow("SELECT revoked_at, revoked_reason FROM refresh_tokens WHERE token_hash = ?", hashToken(token)).Scan(&revokedAt, &revokedReason); err != nil {
+		t.Fatalf("error leyendo refresh token: %v", err)
+	}
+	if revokedReason != nil {
+		reason = *revokedReason
+	}
+	return revokedAt != nil, reason
+}
+
+func TestRefreshTokensRotates(t *testing.T) {
+	service, db := newBookingTestService(t)
+	user, auth := loginTestUser(t, service, db, "rotate@test.com")
+
+	rotated, err := service.RefreshTokens(auth.RefreshToken)
+	if err != nil {
+		t.Fatalf("error refrescando tokens: %v", err)
+	}
+	if rotated.User.ID != user.ID || rotated.RefreshToken == "" || rotated.RefreshToken == auth.RefreshToken {
+		t.Fatalf("par rotado inválido: %+v", rotated)
+	}
+	if revoked, reason := refreshTokenRevocation(t, db, auth.RefreshToken); !revoked || reason != refreshRevokedRotated {
+		t.Fatalf("token usado: revocado = %v motivo = %q, se esperaba rotado", revoked, reason)
+	}
+
+	// El nuevo refresh token sigue la cadena y el access token es válido
+	if _, err := service.RefreshTokens(rotated.RefreshToken); err != nil {
+		t.Fatalf("error refrescando con el token rotado: %v", err)
+	}
+	if _, err := service.ParseAccessToken(rotated.Token); err != nil {
+		t.Fatalf("access token rotado rechazado: %v", err)
+	}
+}
+
+func TestRefreshTokensDetectsReuse(t *testing.T) {
+	service, db := newBookingTestService(t)
+	user, auth := loginTestUser(t, service, db, "reuse@test.com")
+
+	rotated, err := service.RefreshTokens(auth.RefreshToken)
+	if err != nil {
+		t.Fatalf("error refrescando tokens: %v", err)
+	}
+
+	// Presentar otra vez el token rotado cierra todas las sesiones del usuario
+	if _, err := service.RefreshTokens(auth.RefreshToken); err == nil || !strings.Contains(err.Error(), "ya fue utilizado") {
+		t.Fatalf("error = %v, se esperaba token ya utilizado", err)
+	}
+
+	var version int
+	if err := db.QueryRow("SELECT token_version FROM users WHERE id = ?", user.ID).Scan(&version); err != nil {
+		t.Fatalf("error leyendo versión de token: %v", err)
+	}
+	if version != user.TokenVersion+1 {
+		t.Fatalf("token_version = %d, se esperaba %d", version, user.TokenVersion+1)
+	}
+	if revoked, reason := refreshTokenRevocation(t, db, rotated.RefreshToken); !revoked || reason != refreshRevokedAll {
+		t.Fatalf("token vigente: revocado = %v motivo = %q, se esperaba revocado", revoked, reason)
+	}
+	if _, err := service.RefreshTokens(rotated.RefreshToken); err == nil || !strings.Contains(err.Error(), "revocado") {
+		t.Fatalf("error = %v, se esperaba token revocado", err)
+	}
+	if _, err := service.ParseAccessToken(rotated.Token); err == nil || !strings.Contains(err.Error(), "token revocado") {
+		t.Fatalf("error = %v, el access token debía quedar revocado", err)
+	}
+}
+
+func TestRefreshTokensAfterRevocationIsNotReuse(t *testing.T) {
+	service, db := newBookingTestService(t)
+	user, auth := loginTestUser(t, service, db, "logout@test.com")
+
+	// Otra sesión del mismo usuario
+	other, err := service.issueTokens(user)
+	if err != nil {
+		t.Fatalf("error emitiendo tokens: %v", err)
+	}
+
+	if err := service.RevokeRefreshToken(auth.RefreshToken); err != nil {
+		t.Fatalf("error en logout: %v", err)
+	}
+	if revoked, reason := refreshTokenRevocation(t, db, auth.RefreshToken); !revoked || reason != refreshRevokedLogout {
+		t.Fatalf("token: revocado = %v motivo = %q, se esperaba logout", revoked, reason)
+	}
+
+	// Usar el token cerrado por logout se rechaza sin cerrar las otras sesiones
+	if _, err := service.RefreshTokens(auth.RefreshToken); err == nil || !strings.Contains(err.Error(), "revocado") {
+		t.Fatalf("error = %v, se esperaba token revocado", err)
+	}
+	if _, err := service.ParseAccessToken(other.Token); err != nil {
+		t.Fatalf("access token de otra sesión rechazado: %v", err)
+	}
+	if _, err := service.RefreshTokens(other.RefreshToken); err != nil {
+		t.Fatalf("error refrescando otra sesión: %v", err)
+	}
+
+	// Tras revocar las sesiones, reintentar tampoco se toma como robo
+	if err := service.BumpTokenVersion(user.ID); err != nil {
+		t.Fatalf("error revocando sesiones: %v", err)
+	}
+	var version int
+	if err := db.QueryRow("SELECT token_version FROM users WHERE id = ?", user.ID).Scan(&version); err != nil {
+		t.Fatalf("error leyendo versión de token: %v", err)
+	}
+	if _, err := service.RefreshTokens(auth.RefreshToken); err == nil || strings.Contains(err.Error(), "ya fue utilizado") {
+		t.Fatalf("error = %v, se esperaba rechazo sin detección de robo", err)
+	}
+	var after int
+	if err := db.QueryRow("SELECT token_version FROM users WHERE id = ?", user.ID).Scan(&after); err != nil {
+		t.Fatalf("error leyendo versión de token: %v", err)
+	}
+	if after != version {
+		t.Fatalf("token_version = %d, no debía cambiar de %d", after, version)
+	}
+}
+
+func TestParseAccessTokenChecksVersion(t *testing.T) {
+	service, db := newBookingTestService(t)
+	user, auth := loginTestUser(t, service, db, "version@test.com")
+
+	claims, err := service.ParseAccessToken(auth.Token)
+	if err != nil {
+		t.Fatalf("error validando access token: %v", err)
+	}
+	if claims.UserID != user.ID || claims.Role != string(models.RoleUser) || claims.Email != user.Email || claims.TokenVersion != user.TokenVersion {
+		t.Fatalf("claims = %+v", claims)
+	}
+
+	sign := func(secret string, claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		if err != nil {
+			t.Fatalf("error firmando token: %v", err)
+		}
+		return token
+	}
+	exp := time.Now().Add(time.Minute).Unix()
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{name: "sin tv", token: sign("test-secret", jwt.MapClaims{"user_id": user.ID, "exp": exp}), wantErr: "versión de token ausente"},
+		{name: "tv distinto", token: sign("test-secret", jwt.MapClaims{"user_id": user.ID, "tv": user.TokenVersion + 1, "exp": exp}), wantErr: "token revocado"},
+		{name: "otra clave", token: sign("otra-clave", jwt.MapClaims{"user_id": user.ID, "tv": user.TokenVersion, "exp": exp}), wantErr: "error parseando token"},
+		{name: "expirado", token: sign("test-secret", jwt.MapClaims{"user_id": user.ID, "tv": user.TokenVersion, "exp": time.Now().Add(-time.Minute).Unix()}), wantErr: "error parseando token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.ParseAccessToken(tt.token); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, se esperaba %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Al subir la versión el token emitido antes queda revocado
+	if err := service.BumpTokenVersion(user.ID); err != nil {
+		t.Fatalf("error revocando sesiones: %v", err)
+	}
+	if _, err := service.ParseAccessToken(auth.Token); err == nil || !strings.Contains(err.Error(), "token revocado") {
+		t.Fatalf("error = %v, se esperaba token revocado", err)
+	}
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+
+	"booking-service/internal/models"
+)
+
+// resolveGuests devuelve los huéspedes de la reserva. Si no se informaron, el
+// titular es el propio usuario.
+func (s *BookingService) resolveGuests(userID, totalGuests int, guests []models.BookingGuest) ([]models.BookingGuest, error) {
+	if len(guests) > totalGuests {
+		return nil, fmt.Errorf("se informaron %d huéspedes para una reserva de %d", len(guests), totalGuests)
+	}
+	if len(guests) > 0 {
+		return guests, nil
+	}
+
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return []models.BookingGuest{{
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Email:     user.Email,
+		Phone:     user.Phone,
+	}}, nil
+}
+
+// insertBookingGuests guarda los huéspedes en la transacción de la reserva
+func (s *BookingService) insertBookingGuests(tx *sql.Tx, bookingID int64, guests []models.BookingGuest) error {
+	for i, guest := range guests {
+		_, err := tx.Exec(`
+			INSERT INTO booking_guests (booking_id, position, title, first_name, last_name, email, phone)
+			VALUES (?, ?, NULLIF(?, ''), ?, ?, NULLIF(?, ''), NULLIF(?, ''))
+		`, bookingID, i+1, guest.Title, guest.FirstName, guest.LastName, guest.Email, guest.Phone)
+		if err != nil {
+			return fmt.Errorf("error guardando huésped: %v", err)
+		}
+	}
+	return nil
+}
+
+// getBookingGuests obtiene los huéspedes de una reserva, titular primero
+func (s *BookingService) getBookingGuests(bookingID int) ([]models.BookingGuest, error) {
+	rows, err := s.db.Query(`
+		SELECT COALESCE(title, ''), first_name, last_name, COALESCE(email, ''), COALESCE(phone, '')
+		FROM booking_guests WHERE booking_id = ? ORDER BY position
+	`, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo huéspedes: %v", err)
+	}
+	defer rows.Close()
+
+	var guests []models.BookingGuest
+	for rows.Next() {
+		var guest models.BookingGuest
+		if err := rows.Scan(&guest.Title, &guest.FirstName, &guest.LastName, &guest.Email, &guest.Phone); err != nil {
+			return nil, fmt.Errorf("error escaneando huésped: %v", err)
+		}
+		guests = append(guests, guest)
+	}
+
+	return guests, rows.Err()
+}
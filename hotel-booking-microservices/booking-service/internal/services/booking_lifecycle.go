@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"booking-service/internal/models"
+)
+
+// CancelBooking cancela una reserva aplicando la política de cancelación de sus habitaciones
+func (s *BookingService) CancelBooking(bookingID int, reason string) (*models.Booking, error) {
+	booking, err := s.GetBookingByID(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !booking.CanTransitionTo(models.StatusCancelled) {
+		return nil, fmt.Errorf("no se puede cancelar una reserva en estado %s", booking.Status)
+	}
+
+	var cancelReason interface{}
+	if reason != "" {
+		cancelReason = reason
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error iniciando transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	// El filtro por estado evita pisar una transición concurrente
+	result, err := tx.Exec(`
+		UPDATE bookings SET status = ?, cancelled_at = NOW(), cancellation_reason = ?
+		WHERE id = ? AND status = ?
+	`, models.StatusCancelled, cancelReason, bookingID, booking.Status)
+	if err != nil {
+		return nil, fmt.Errorf("error cancelando reserva: %v", err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, fmt.Errorf("no se puede cancelar: la reserva cambió de estado")
+	}
+
+	// Devolver las noches de cada habitación al inventario. Las habitaciones se
+	// leen bloqueadas para no repetir una cancelación parcial concurrente.
+	rooms, err := s.lockActiveRooms(tx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cargo de cada habitación según la política de cancelación copiada al reservar
+	refund, err := s.applyCancellationFees(booking, rooms, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cancelRooms(tx, booking, rooms, cancelReason); err != nil {
+		return nil, err
+	}
+
+	// Devolver lo pagado por las habitaciones menos los cargos
+	if err := s.releasePaymentTx(tx, booking, refund); err != nil {
+		return nil, err
+	}
+
+	if err := enqueueBookingEvent(tx, models.EventBookingCancelled, int64(bookingID), nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error confirmando cancelación: %v", err)
+	}
+
+	fmt.Printf("🚫 Reserva %s cancelada\n", booking.BookingReference)
+	return s.GetBookingByID(bookingID)
+}
+
+// ModifyBooking cambia fechas, huéspedes o tipo de habitación de una reserva
+func (s *BookingService) ModifyBooking(ctx context.Context, bookingID int, req *models.UpdateBookingRequest) (*models.Booking, error) {
+	booking, err := s.GetBookingByID(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !booking.IsModifiable() {
+		return nil, fmt.Errorf("no se puede modificar una reserva en estado %s", booking.Status)
+	}
+
+	// Las reservas de varias habitaciones se ajustan cancelando habitaciones
+	rooms := booking.ActiveRooms()
+	if len(rooms) != 1 {
+		return nil, fmt.Errorf("no se puede modificar una reserva de %d habitaciones: cancele las habitaciones que no necesite", len(rooms))
+	}
+
+	// Amadeus no permite modificar reservas ya emitidas
+	if booking.AmadeusBookingID != nil && *booking.AmadeusBookingID != "" {
+		return nil, fmt.Errorf("no se puede modificar una reserva sincronizada con Amadeus: cancele y cree una nueva")
+	}
+	if err := s.checkSyncAllowsModification(booking.Provider, booking.SyncStatus); err != nil {
+		return nil, err
+	}
+
+	today := dateOnly(time.Now())
+	if booking.CheckInDate.Before(today) {
+		return nil, fmt.Errorf("no se puede modificar una reserva cuyo check-in ya pasó")
+	}
+
+	// Combinar los valores actuales con los cambios solicitados
+	checkIn := booking.CheckInDate
+	checkOut := booking.CheckOutDate
+	guests := booking.Guests
+	roomType := rooms[0].RoomType
+	specialRequests := booking.SpecialRequests
+
+	if req.CheckInDate != nil {
+		checkIn = *req.CheckInDate
+	}
+	if req.CheckOutDate != nil {
+		checkOut = *req.CheckOutDate
+	}
+	if req.Guests != nil {
+		guests = *req.Guests
+	}
+	if req.RoomType != nil {
+		roomType = normalizeRoomType(*req.RoomType)
+	}
+	if req.SpecialRequests != nil {
+		specialRequests = *req.SpecialRequests
+	}
+
+	if checkIn.Before(today) {
+		return nil, fmt.Errorf("fecha inválida: el check-in no puede ser en el pasado")
+	}
+	if !checkOut.After(checkIn) {
+		return nil, fmt.Errorf("fecha inválida: el check-out debe ser posterior al check-in")
+	}
+
+	// Validar el tipo de habitación contra los que ofrece el hotel
+	room, err := s.resolveRoomType(booking.InternalHotelID, roomType, guests)
+	if err != nil {
+		return nil, err
+	}
+	if room != nil {
+		roomType = room.Name
+	}
+
+	// Volver a verificar disponibilidad con los nuevos datos
+	availability, err := s.CheckAvailability(ctx, &models.AvailabilityRequest{
+		HotelID:      booking.InternalHotelID,
+		CheckInDate:  checkIn,
+		CheckOutDate: checkOut,
+		Guests:       guests,
+		RoomType:     roomType,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error verificando disponibilidad: %v", err)
+	}
+
+	// Si solo falta inventario propio, la decisión queda en reserveInventory,
+	// que descuenta primero las noches que ocupa esta misma reserva
+	inventoryOnly := availability.RoomsAvailable != nil && *availability.RoomsAvailable <= 0
+	if !availability.Available && !inventoryOnly {
+		return nil, fmt.Errorf("hotel no disponible para las fechas seleccionadas")
+	}
+
+	totalPrice := booking.TotalPrice
+	currency := booking.Currency
+	if availability.Price != nil {
+		totalPrice = *availability.Price
+	}
+	if availability.Currency != "" {
+		currency = availability.Currency
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error iniciando transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Bloquear la reserva para que no cambie de estado durante la modificación
+	var currentStatus, currentSync string
+	err = tx.QueryRow("SELECT status, sync_status FROM bookings WHERE id = ? FOR UPDATE", bookingID).Scan(&currentStatus, &currentSync)
+	if err != nil {
+		return nil, fmt.Errorf("error bloqueando reserva: %v", err)
+	}
+	if currentStatus != booking.Status {
+		return nil, fmt.Errorf("no se puede modificar: la reserva cambió de estado")
+	}
+	if err := s.checkSyncAllowsModification(booking.Provider, currentSync); err != nil {
+		return nil, err
+	}
+
+	// Liberar las noches anteriores y tomar las nuevas
+	if err := s.releaseInventory(tx, booking.InternalHotelID, rooms[0].RoomType, booking.CheckInDate, booking.CheckOutDate); err != nil {
+		return nil, err
+	}
+	if err := s.reserveInventory(tx, booking.InternalHotelID, roomType, checkIn, checkOut, s.roomInventoryTotal(room)); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE bookings
+		SET check_in_date = ?, check_out_date = ?, guests = ?, room_type = ?, special_requests = ?, total_price = ?, currency = ?
+		WHERE id = ?
+	`, dateOnly(checkIn), dateOnly(checkOut), guests, roomType, specialRequests, totalPrice, currency, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("error modificando reserva: %v", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE booking_rooms SET room_type = ?, guests = ?, total_price = ?, currency = ?
+		WHERE id = ?
+	`, roomType, guests, totalPrice, currency, rooms[0].ID)
+	if err != nil {
+		return nil, fmt.Errorf("error modificando habitación: %v", err)
+	}
+
+	if err := s.adjustPaymentTx(tx, bookingID, totalPrice); err != nil {
+		return nil, err
+	}
+
+	// Reemplazar el desglose por noche con la nueva cotización
+	if _, err := tx.Exec("DELETE FROM booking_price_lines WHERE booking_room_id = ?", rooms[0].ID); err != nil {
+		return nil, fmt.Errorf("error eliminando líneas de precio: %v", err)
+	}
+	if err := s.insertPriceLines(tx, int64(bookingID), int64(rooms[0].ID), availability.Nights, currency); err != nil {
+		return nil, err
+	}
+
+	if err := enqueueBookingEvent(tx, models.EventBookingModified, int64(bookingID), booking); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error confirmando modificación: %v", err)
+	}
+
+	updated, err := s.GetBookingByID(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("✏️ Reserva %s modificada\n", booking.BookingReference)
+	return updated, nil
+}
+
+// checkSyncAllowsModification rechaza modificar una reserva de un proveedor
+// externo que todavía no se creó en él. El trabajo de sincronización reserva
+// la oferta cotizada al crearla: con otras fechas o habitación crearía en el
+// proveedor una estadía distinta de la guardada.
+func (s *BookingService) checkSyncAllowsModification(providerName, syncStatus string) error {
+	provider, ok := s.providers[providerName]
+	if !ok || !provider.ExternalSync() {
+		return nil
+	}
+
+	switch syncStatus {
+	case models.SyncPending:
+		return fmt.Errorf("no se puede modificar una reserva pendiente de sincronizar con el proveedor: espere la confirmación o cancele y cree una nueva")
+	case models.SyncFailed:
+		return fmt.Errorf("no se puede modificar una reserva que no se pudo crear en el proveedor: cancele y cree una nueva")
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"booking-service/internal/models"
+)
+
+func TestModifyBookingRejectsUnsyncedProviderBooking(t *testing.T) {
+	service, db := newBookingTestService(t)
+	guests := 1
+	req := &models.UpdateBookingRequest{Guests: &guests}
+
+	tests := []struct {
+		name       string
+		reference  string
+		syncStatus string
+		wantErr    string
+	}{
+		{name: "pendiente", reference: "BK-SYNC-PENDING", syncStatus: models.SyncPending, wantErr: "pendiente de sincronizar"},
+		{name: "fallida", reference: "BK-SYNC-FAILED", syncStatus: models.SyncFailed, wantErr: "no se pudo crear en el proveedor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bookingID := insertBooking(t, db, testBooking{Reference: tt.reference, Provider: "simulated", SyncStatus: tt.syncStatus})
+
+			_, err := service.ModifyBooking(context.Background(), bookingID, req)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), "no se puede") {
+				t.Fatalf("error = %v, se esperaba %q", err, tt.wantErr)
+			}
+
+			var booked int
+			if err := db.QueryRow("SELECT guests FROM bookings WHERE id = ?", bookingID).Scan(&booked); err != nil {
+				t.Fatalf("error leyendo reserva: %v", err)
+			}
+			if booked != 2 {
+				t.Fatalf("huéspedes = %d, la reserva no debía cambiar", booked)
+			}
+		})
+	}
+
+	t.Run("sincronizada", func(t *testing.T) {
+		bookingID := insertBooking(t, db, testBooking{Reference: "BK-SYNC-DONE", Provider: "simulated", SyncStatus: models.SyncSynced})
+		mustExec(t, db, "UPDATE bookings SET amadeus_booking_id = 'SIM-1' WHERE id = ?", bookingID)
+
+		_, err := service.ModifyBooking(context.Background(), bookingID, req)
+		if err == nil || !strings.Contains(err.Error(), "sincronizada") {
+			t.Fatalf("error = %v, se esperaba rechazo de reserva sincronizada", err)
+		}
+	})
+
+	t.Run("proveedor local", func(t *testing.T) {
+		bookingID := insertBooking(t, db, testBooking{Reference: "BK-LOCAL"})
+
+		booking, err := service.ModifyBooking(context.Background(), bookingID, req)
+		if err != nil {
+			t.Fatalf("error modificando reserva local: %v", err)
+		}
+		if booking.Guests != 1 {
+			t.Fatalf("huéspedes = %d, se esperaba 1", booking.Guests)
+		}
+	})
+}
+
+func TestCancellationDeadlineBoundary(t *testing.T) {
+	service := NewBookingService(nil, nil, nil, Options{CancellationDeadline: 48 * time.Hour})
+	checkIn := time.Date(2026, time.March, 10, 0, 0, 0, 0, time.Local)
+	deadline := checkIn.Add(-48 * time.Hour)
+
+	tests := []struct {
+		name    string
+		at      time.Time
+		wantErr string
+	}{
+		{name: "antes del plazo", at: deadline.Add(-time.Hour)},
+		{name: "justo en el plazo", at: deadline},
+		{name: "un segundo después del plazo", at: deadline.Add(time.Second), wantErr: "plazo de cancelación vencido: se permitía cancelar hasta 2026-03-08 00:00"},
+		{name: "en el check-in", at: checkIn, wantErr: "la estadía ya comenzó"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			booking := &models.Booking{Status: models.StatusConfirmed, CheckInDate: checkIn}
+			rooms := []models.BookingRoom{{TotalPrice: 100}}
+
+			refund, err := service.applyCancellationFees(booking, rooms, tt.at)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, se esperaba %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error cancelando: %v", err)
+			}
+			if refund != 100 || rooms[0].CancellationFee == nil || *rooms[0].CancellationFee != 0 {
+				t.Fatalf("reintegro = %v cargo = %v, se esperaba cancelación gratuita", refund, rooms[0].CancellationFee)
+			}
+		})
+	}
+}
+
+func TestCancelBookingDeadline(t *testing.T) {
+	service, db := newBookingTestService(t)
+	service.cancellationDeadline = 48 * time.Hour
+	today := dateOnly(time.Now())
+
+	// Check-in mañana: vencido el plazo de 48 horas, la reserva no cambia
+	lateID := insertBooking(t, db, testBooking{Reference: "BK-LATE", CheckIn: today.AddDate(0, 0, 1)})
+	if _, err := service.CancelBooking(lateID, "cambio de planes"); err == nil || !strings.Contains(err.Error(), "plazo") {
+		t.Fatalf("error = %v, se esperaba plazo vencido", err)
+	}
+	late, err := service.GetBookingByID(lateID)
+	if err != nil {
+		t.Fatalf("error obteniendo reserva: %v", err)
+	}
+	if late.Status != models.StatusConfirmed || late.Rooms[0].Status != models.RoomStatusActive {
+		t.Fatalf("reserva = %s habitación = %s, la cancelación debía deshacerse", late.Status, late.Rooms[0].Status)
+	}
+
+	// Check-in en tres días: dentro del plazo
+	onTimeID := insertBooking(t, db, testBooking{Reference: "BK-ONTIME", CheckIn: today.AddDate(0, 0, 3)})
+	cancelled, err := service.CancelBooking(onTimeID, "cambio de planes")
+	if err != nil {
+		t.Fatalf("error cancelando: %v", err)
+	}
+	if cancelled.Status != models.StatusCancelled {
+		t.Fatalf("estado = %s, se esperaba cancelada", cancelled.Status)
+	}
+	if _, err := service.CancelBooking(onTimeID, ""); err == nil || !strings.Contains(err.Error(), "no se puede cancelar") {
+		t.Fatalf("error = %v, una reserva cancelada no se cancela de nuevo", err)
+	}
+}
+
+func TestModifyBookingCheckInBoundary(t *testing.T) {
+	service, db := newBookingTestService(t)
+	today := dateOnly(time.Now())
+	notes := "llegada tarde"
+	req := &models.UpdateBookingRequest{SpecialRequests: &notes}
+
+	// El día del check-in todavía se puede modificar
+	todayID := insertBooking(t, db, testBooking{Reference: "BK-TODAY", CheckIn: today})
+	if _, err := service.ModifyBooking(context.Background(), todayID, req); err != nil {
+		t.Fatalf("error modificando reserva con check-in hoy: %v", err)
+	}
+
+	yesterdayID := insertBooking(t, db, testBooking{Reference: "BK-YESTERDAY", CheckIn: today.AddDate(0, 0, -1), Nights: 3})
+	if _, err := service.ModifyBooking(context.Background(), yesterdayID, req); err == nil || !strings.Contains(err.Error(), "check-in ya pasó") {
+		t.Fatalf("error = %v, se esperaba check-in pasado", err)
+	}
+
+	// Mover el check-in al pasado tampoco se acepta
+	yesterday := today.AddDate(0, 0, -1)
+	tomorrowID := insertBooking(t, db, testBooking{Reference: "BK-TOMORROW", CheckIn: today.AddDate(0, 0, 1)})
+	_, err := service.ModifyBooking(context.Background(), tomorrowID, &models.UpdateBookingRequest{CheckInDate: &yesterday})
+	if err == nil || !strings.Contains(err.Error(), "fecha inválida") {
+		t.Fatalf("error = %v, se esperaba fecha inválida", err)
+	}
+}
@@ -0,0 +1,292 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"booking-service/internal/models"
+)
+
+// maxBookingRooms máximo de habitaciones en una misma reserva
+const maxBookingRooms = 9
+
+// roomColumns columnas leídas de booking_rooms
+const roomColumns = `id, booking_id, position, room_type, guests, total_price, currency, provider_offer_id, provider_booking_id,
+		       cancellation_policy, status, cancelled_at, cancellation_reason, cancellation_fee, created_at, updated_at`
+
+// scanBookingRoom escanea una fila con las columnas de roomColumns
+func scanBookingRoom(row rowScanner) (*models.BookingRoom, error) {
+	var room models.BookingRoom
+	var policy []byte
+	err := row.Scan(
+		&room.ID, &room.BookingID, &room.Position, &room.RoomType, &room.Guests, &room.TotalPrice, &room.Currency,
+		&room.ProviderOfferID, &room.ProviderBookingID, &policy, &room.Status, &room.CancelledAt, &room.CancelReason,
+		&room.CancellationFee, &room.CreatedAt, &room.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(policy) > 0 {
+		room.CancellationPolicy = &models.CancellationPolicy{}
+		if err := json.Unmarshal(policy, room.CancellationPolicy); err != nil {
+			return nil, fmt.Errorf("política de cancelación inválida: %v", err)
+		}
+	}
+	return &room, nil
+}
+
+// roomLine habitación cotizada durante la creación de una reserva
+type roomLine struct {
+	roomType   string
+	room       *models.RoomType
+	guests     int
+	totalPrice float64
+	currency   string
+	nights     []models.NightlyPrice
+	offerID    *string
+	policy     *models.CancellationPolicy
+}
+
+// bookingRoomRequests devuelve las habitaciones pedidas. Una reserva sin rooms
+// es una reserva de una habitación con guests, room_type y offer_id.
+func bookingRoomRequests(req *models.CreateBookingRequest) ([]models.BookingRoomRequest, error) {
+	if len(req.Rooms) == 0 {
+		if req.Guests < 1 {
+			return nil, fmt.Errorf("habitaciones inválidas: indique guests o rooms")
+		}
+		return []models.BookingRoomRequest{{RoomType: req.RoomType, Guests: req.Guests, OfferID: req.OfferID}}, nil
+	}
+
+	if req.Guests != 0 || strings.TrimSpace(req.RoomType) != "" || req.OfferID != "" {
+		return nil, fmt.Errorf("habitaciones inválidas: con rooms no se usan guests, room_type ni offer_id")
+	}
+	if len(req.Rooms) > maxBookingRooms {
+		return nil, fmt.Errorf("habitaciones inválidas: máximo %d habitaciones por reserva", maxBookingRooms)
+	}
+	return req.Rooms, nil
+}
+
+// insertBookingRoom guarda una habitación en la transacción de la reserva
+func (s *BookingService) insertBookingRoom(tx *sql.Tx, bookingID int64, position int, line *roomLine) (int64, error) {
+	// La política se copia para que los cambios posteriores no afecten a la reserva
+	policy, err := json.Marshal(line.policy)
+	if err != nil {
+		return 0, fmt.Errorf("error serializando política de cancelación: %v", err)
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO booking_rooms (booking_id, position, room_type, guests, total_price, currency, provider_offer_id, cancellation_policy)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, bookingID, position, line.roomType, line.guests, line.totalPrice, line.currency, line.offerID, string(policy))
+	if err != nil {
+		return 0, fmt.Errorf("error guardando habitación: %v", err)
+	}
+
+	roomID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("error obteniendo ID de habitación: %v", err)
+	}
+	return roomID, nil
+}
+
+// getBookingRooms obtiene las habitaciones de una reserva en el orden en que se pidieron
+func (s *BookingService) getBookingRooms(bookingID int) ([]models.BookingRoom, error) {
+	rows, err := s.db.Query("SELECT "+roomColumns+" FROM booking_rooms WHERE booking_id = ? ORDER BY position", bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo habitaciones: %v", err)
+	}
+	defer rows.Close()
+
+	var rooms []models.BookingRoom
+	for rows.Next() {
+		room, err := scanBookingRoom(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error escaneando habitación: %v", err)
+		}
+		rooms = append(rooms, *room)
+	}
+
+	return rooms, rows.Err()
+}
+
+// lockActiveRooms bloquea las habitaciones activas de la reserva dentro de la transacción
+func (s *BookingService) lockActiveRooms(tx *sql.Tx, bookingID int) ([]models.BookingRoom, error) {
+	rows, err := tx.Query("SELECT "+roomColumns+" FROM booking_rooms WHERE booking_id = ? AND status = ? ORDER BY position FOR UPDATE", bookingID, models.RoomStatusActive)
+	if err != nil {
+		return nil, fmt.Errorf("error bloqueando habitaciones: %v", err)
+	}
+	defer rows.Close()
+
+	var rooms []models.BookingRoom
+	for rows.Next() {
+		room, err := scanBookingRoom(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error escaneando habitación: %v", err)
+		}
+		rooms = append(rooms, *room)
+	}
+
+	return rooms, rows.Err()
+}
+
+// cancelRooms cancela las habitaciones, devuelve sus noches al inventario y
+// encola la cancelación en el proveedor de las que ya estaban sincronizadas.
+// Las que todavía no llegaron al proveedor las omite el trabajo de creación.
+// Se guarda el cargo calculado por applyCancellationFees, si lo hay.
+func (s *BookingService) cancelRooms(tx *sql.Tx, booking *models.Booking, rooms []models.BookingRoom, reason interface{}) error {
+	for _, room := range rooms {
+		_, err := tx.Exec(`
+			UPDATE booking_rooms SET status = ?, cancelled_at = NOW(), cancellation_reason = ?, cancellation_fee = ?
+			WHERE id = ?
+		`, models.RoomStatusCancelled, reason, room.CancellationFee, room.ID)
+		if err != nil {
+			return fmt.Errorf("error cancelando habitación: %v", err)
+		}
+
+		if err := s.releaseInventory(tx, booking.InternalHotelID, room.RoomType, booking.CheckInDate, booking.CheckOutDate); err != nil {
+			return err
+		}
+
+		if room.ProviderBookingID != nil && *room.ProviderBookingID != "" {
+			roomID := room.ID
+			if err := s.enqueueSyncJob(tx, int64(booking.ID), &roomID, models.SyncOperationCancel); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// refreshBookingTotals recalcula huéspedes y total de la reserva con sus
+// habitaciones activas. El tipo de habitación, la moneda y la oferta de la
+// reserva pasan a ser los de la primera habitación activa, como al crearla.
+func (s *BookingService) refreshBookingTotals(tx *sql.Tx, bookingID int) error {
+	_, err := tx.Exec(`
+		UPDATE bookings b
+		JOIN (
+			SELECT booking_id, SUM(guests) AS guests, SUM(total_price) AS total_price, MIN(position) AS first_position
+			FROM booking_rooms WHERE booking_id = ? AND status = ?
+			GROUP BY booking_id
+		) r ON r.booking_id = b.id
+		JOIN booking_rooms f ON f.booking_id = r.booking_id AND f.position = r.first_position
+		SET b.guests = r.guests, b.total_price = r.total_price,
+			b.room_type = f.room_type, b.currency = f.currency, b.amadeus_offer_id = f.provider_offer_id
+	`, bookingID, models.RoomStatusActive)
+	if err != nil {
+		return fmt.Errorf("error actualizando totales de la reserva: %v", err)
+	}
+	return nil
+}
+
+// CancelBookingRoom cancela una habitación de la reserva. La reserva sigue
+// vigente con las demás; si era la última, se cancela la reserva completa.
+func (s *BookingService) CancelBookingRoom(bookingID, roomID int, reason string) (*models.Booking, error) {
+	booking, err := s.GetBookingByID(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	var room *models.BookingRoom
+	for i := range booking.Rooms {
+		if booking.Rooms[i].ID == roomID {
+			room = &booking.Rooms[i]
+		}
+	}
+	if room == nil {
+		return nil, fmt.Errorf("habitación no encontrada")
+	}
+	if room.Status != models.RoomStatusActive {
+		return nil, fmt.Errorf("no se puede cancelar: la habitación ya está cancelada")
+	}
+
+	if !booking.CanTransitionTo(models.StatusCancelled) {
+		return nil, fmt.Errorf("no se puede cancelar una reserva en estado %s", booking.Status)
+	}
+
+	var cancelReason interface{}
+	if reason != "" {
+		cancelReason = reason
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error iniciando transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Bloquear la reserva: dos cancelaciones parciales simultáneas no pueden
+	// dejarla sin habitaciones activas y confirmada
+	var currentStatus string
+	err = tx.QueryRow("SELECT status FROM bookings WHERE id = ? FOR UPDATE", bookingID).Scan(&currentStatus)
+	if err != nil {
+		return nil, fmt.Errorf("error bloqueando reserva: %v", err)
+	}
+	if currentStatus != booking.Status {
+		return nil, fmt.Errorf("no se puede cancelar: la reserva cambió de estado")
+	}
+
+	active, err := s.lockActiveRooms(tx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	var target *models.BookingRoom
+	for i := range active {
+		if active[i].ID == roomID {
+			target = &active[i]
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("no se puede cancelar: la habitación ya está cancelada")
+	}
+
+	// Cargo según la política de cancelación de la habitación
+	cancelled := []models.BookingRoom{*target}
+	refund, err := s.applyCancellationFees(booking, cancelled, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	event := models.EventBookingModified
+	if len(active) == 1 {
+		// Era la última habitación: se cancela la reserva completa
+		_, err = tx.Exec(`
+			UPDATE bookings SET status = ?, cancelled_at = NOW(), cancellation_reason = ?
+			WHERE id = ?
+		`, models.StatusCancelled, cancelReason, bookingID)
+		if err != nil {
+			return nil, fmt.Errorf("error cancelando reserva: %v", err)
+		}
+		event = models.EventBookingCancelled
+	}
+
+	if err := s.cancelRooms(tx, booking, cancelled, cancelReason); err != nil {
+		return nil, err
+	}
+
+	if err := s.releasePaymentTx(tx, booking, refund); err != nil {
+		return nil, err
+	}
+
+	var previous *models.Booking
+	if event == models.EventBookingModified {
+		if err := s.refreshBookingTotals(tx, bookingID); err != nil {
+			return nil, err
+		}
+		previous = booking
+	}
+	if err := enqueueBookingEvent(tx, event, int64(bookingID), previous); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error confirmando cancelación: %v", err)
+	}
+
+	fmt.Printf("🚫 Habitación %d de la reserva %s cancelada\n", target.Position, booking.BookingReference)
+	return s.GetBookingByID(bookingID)
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"booking-service/internal/models"
+	"booking-service/pkg/mysql"
+)
+
+// bookedRooms devuelve las habitaciones ocupadas de un tipo en una noche
+func bookedRooms(t *testing.T, db *mysql.DB, roomType string, night interface{}) int {
+	t.Helper()
+
+	var booked int
+	err := db.QueryRow(`
+		SELECT booked_rooms FROM room_inventory WHERE hotel_id = ? AND room_type = ? AND stay_date = ?
+	`, testHotelID, roomType, night).Scan(&booked)
+	if err != nil {
+		t.Fatalf("error leyendo inventario de %s: %v", roomType, err)
+	}
+	return booked
+}
+
+func TestCancelBookingRoom(t *testing.T) {
+	service, db := newBookingTestService(t)
+
+	bookingID := insertBooking(t, db, testBooking{Reference: "BK-ROOMS", Rooms: []string{"standard", "suite"}})
+	// Moneda distinta en la segunda habitación para ver que la reserva la toma
+	mustExec(t, db, "UPDATE booking_rooms SET currency = 'USD' WHERE booking_id = ? AND position = 2", bookingID)
+
+	booking, err := service.GetBookingByID(bookingID)
+	if err != nil {
+		t.Fatalf("error obteniendo reserva: %v", err)
+	}
+	first, second := booking.Rooms[0], booking.Rooms[1]
+
+	updated, err := service.CancelBookingRoom(bookingID, first.ID, "sobra una habitación")
+	if err != nil {
+		t.Fatalf("error cancelando habitación: %v", err)
+	}
+
+	if updated.Status != models.StatusConfirmed {
+		t.Fatalf("estado = %s, la reserva sigue vigente con la otra habitación", updated.Status)
+	}
+	if updated.Guests != second.Guests || updated.TotalPrice != second.TotalPrice {
+		t.Fatalf("huéspedes = %d total = %v, se esperaban los de la habitación restante", updated.Guests, updated.TotalPrice)
+	}
+	if updated.RoomType != "suite" || updated.Currency != "USD" {
+		t.Fatalf("tipo = %s moneda = %s, se esperaban los de la habitación restante", updated.RoomType, updated.Currency)
+	}
+	if updated.AmadeusOfferID == nil || *updated.AmadeusOfferID != "BK-ROOMS-suite" {
+		t.Fatalf("oferta = %v, se esperaba la de la habitación restante", updated.AmadeusOfferID)
+	}
+	if updated.Rooms[0].Status != models.RoomStatusCancelled || updated.Rooms[1].Status != models.RoomStatusActive {
+		t.Fatalf("habitaciones = %s/%s, solo se cancela la primera", updated.Rooms[0].Status, updated.Rooms[1].Status)
+	}
+
+	// Solo se devuelve al inventario la noche de la habitación cancelada
+	night := dateOnly(booking.CheckInDate)
+	if booked := bookedRooms(t, db, "standard", night); booked != 0 {
+		t.Fatalf("standard ocupadas = %d, se esperaba liberar la noche", booked)
+	}
+	if booked := bookedRooms(t, db, "suite", night); booked != 1 {
+		t.Fatalf("suite ocupadas = %d, la habitación activa sigue ocupando su noche", booked)
+	}
+
+	if _, err := service.CancelBookingRoom(bookingID, first.ID, ""); err == nil || !strings.Contains(err.Error(), "ya está cancelada") {
+		t.Fatalf("error = %v, una habitación cancelada no se cancela de nuevo", err)
+	}
+	if _, err := service.CancelBookingRoom(bookingID, 999999, ""); err == nil || !strings.Contains(err.Error(), "no encontrada") {
+		t.Fatalf("error = %v, se esperaba habitación no encontrada", err)
+	}
+}
+
+func TestCancelBookingRoomLastActive(t *testing.T) {
+	service, db := newBookingTestService(t)
+
+	bookingID := insertBooking(t, db, testBooking{Reference: "BK-LAST", Rooms: []string{"standard", "suite"}})
+	booking, err := service.GetBookingByID(bookingID)
+	if err != nil {
+		t.Fatalf("error obteniendo reserva: %v", err)
+	}
+
+	if _, err := service.CancelBookingRoom(bookingID, booking.Rooms[1].ID, ""); err != nil {
+		t.Fatalf("error cancelando la segunda habitación: %v", err)
+	}
+	cancelled, err := service.CancelBookingRoom(bookingID, booking.Rooms[0].ID, "cambio de planes")
+	if err != nil {
+		t.Fatalf("error cancelando la última habitación: %v", err)
+	}
+
+	if cancelled.Status != models.StatusCancelled || cancelled.CancelledAt == nil {
+		t.Fatalf("estado = %s, cancelar la última habitación cancela la reserva", cancelled.Status)
+	}
+	if len(cancelled.ActiveRooms()) != 0 {
+		t.Fatalf("habitaciones activas = %d, se esperaba ninguna", len(cancelled.ActiveRooms()))
+	}
+
+	var events int
+	err = db.QueryRow("SELECT COUNT(*) FROM outbox_events WHERE aggregate_id = ? AND event_type = ?", bookingID, models.EventBookingCancelled).Scan(&events)
+	if err != nil {
+		t.Fatalf("error leyendo eventos: %v", err)
+	}
+	if events != 1 {
+		t.Fatalf("eventos de cancelación = %d, se esperaba uno", events)
+	}
+
+	if _, err := service.CancelBookingRoom(bookingID, booking.Rooms[0].ID, ""); err == nil {
+		t.Fatal("se esperaba error al cancelar una habitación de una reserva cancelada")
+	}
+}
@@ -1,17 +1,25 @@
 package services
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"strconv"
+	"math"
+	"sort"
 	"strings"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
-	"github.com/golang-jwt/jwt/v5"
 
+	"booking-service/internal/inventory"
 	"booking-service/internal/models"
+	"booking-service/internal/pricing"
 	"booking-service/pkg/amadeus"
+	"booking-service/pkg/bookingref"
+	"booking-service/pkg/hotelservice"
+	"booking-service/pkg/mailer"
+	"booking-service/pkg/oidc"
+	"booking-service/pkg/payments"
 	"booking-service/pkg/memcached"
 	"booking-service/pkg/mysql"
 )
@@ -20,17 +28,87 @@ import (
 type BookingService struct {
 	db            *mysql.DB
 	cache         *memcached.Client
-	amadeusClient *amadeus.Client
+	hotelClient   *hotelservice.Client
 	jwtSecret     string
+	cancellationDeadline time.Duration
+	defaultRoomInventory int
+	accessTokenTTL       time.Duration
+	refreshTokenTTL      time.Duration
+	mailer               mailer.Mailer
+	frontendURL          string
+	loginMaxAttempts     int
+	loginIPMaxAttempts   int
+	loginLockout         time.Duration
+	oidcProvider         *oidc.Provider
+	syncMaxAttempts      int
+	syncBaseBackoff      time.Duration
+	providers            map[string]inventory.InventoryProvider
+	defaultProvider      string
+	fallbackProvider     string
+	amadeusClient        *amadeus.Client
+	paymentGateway       payments.PaymentGateway
+	paymentWebhookSecret string
+	paymentPendingTimeout time.Duration
+	idempotencyKeyTTL     time.Duration
+}
+
+// Options agrupa los parámetros configurables del servicio
+type Options struct {
+	JWTSecret            string
+	CancellationDeadline time.Duration
+	DefaultRoomInventory int
+	AccessTokenTTL       time.Duration
+	RefreshTokenTTL      time.Duration
+	Mailer               mailer.Mailer
+	FrontendURL          string
+	LoginMaxAttempts     int
+	LoginIPMaxAttempts   int
+	LoginLockout         time.Duration
+	OIDCProvider         *oidc.Provider // nil deshabilita el login externo
+	SyncMaxAttempts      int
+	SyncBaseBackoff      time.Duration
+	Providers            []inventory.InventoryProvider
+	DefaultProvider      string // proveedor de los hoteles sin mapeo
+	FallbackProvider     string // proveedor usado cuando el del hotel falla
+	AmadeusClient        *amadeus.Client // catálogo de hoteles para importar
+	PaymentGateway       payments.PaymentGateway // nil confirma las reservas sin cobrar
+	PaymentWebhookSecret string
+	PaymentPendingTimeout time.Duration // plazo para autorizar el pago antes de cancelar la reserva
+	IdempotencyKeyTTL     time.Duration // tiempo durante el que se repite la respuesta de una clave
 }
 
 // NewBookingService crea una nueva instancia del servicio
-func NewBookingService(db *mysql.DB, cache *memcached.Client, amadeusClient *amadeus.Client, jwtSecret string) *BookingService {
+func NewBookingService(db *mysql.DB, cache *memcached.Client, hotelClient *hotelservice.Client, opts Options) *BookingService {
+	providers := make(map[string]inventory.InventoryProvider)
+	for _, provider := range opts.Providers {
+		providers[provider.Name()] = provider
+	}
+
 	return &BookingService{
 		db:            db,
 		cache:         cache,
-		amadeusClient: amadeusClient,
-		jwtSecret:     jwtSecret,
+		hotelClient:   hotelClient,
+		jwtSecret:     opts.JWTSecret,
+		cancellationDeadline: opts.CancellationDeadline,
+		defaultRoomInventory: opts.DefaultRoomInventory,
+		accessTokenTTL:       opts.AccessTokenTTL,
+		refreshTokenTTL:      opts.RefreshTokenTTL,
+		mailer:               opts.Mailer,
+		frontendURL:          strings.TrimRight(opts.FrontendURL, "/"),
+		loginMaxAttempts:     opts.LoginMaxAttempts,
+		loginIPMaxAttempts:   opts.LoginIPMaxAttempts,
+		loginLockout:         opts.LoginLockout,
+		oidcProvider:         opts.OIDCProvider,
+		syncMaxAttempts:      opts.SyncMaxAttempts,
+		syncBaseBackoff:      opts.SyncBaseBackoff,
+		providers:            providers,
+		defaultProvider:      opts.DefaultProvider,
+		fallbackProvider:     opts.FallbackProvider,
+		amadeusClient:        opts.AmadeusClient,
+		paymentGateway:       opts.PaymentGateway,
+		paymentWebhookSecret: opts.PaymentWebhookSecret,
+		paymentPendingTimeout: opts.PaymentPendingTimeout,
+		idempotencyKeyTTL:     opts.IdempotencyKeyTTL,
 	}
 }
 
@@ -48,19 +126,14 @@ func (s *BookingService) RegisterUser(req *models.RegisterRequest) (*models.User
 		return nil, fmt.Errorf("error hasheando password: %v", err)
 	}
 
-	// Insertar usuario - MODIFICADO: agregado role
+	// Insertar usuario: el registro público siempre crea usuarios normales,
+	// los admins se promueven desde /api/admin/users o con create-admin
 	query := `
 		INSERT INTO users (email, password_hash, first_name, last_name, phone, date_of_birth, role)
 		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`
-	
-	// Establecer role por defecto si no se proporciona
-	role := req.Role
-	if role == "" {
-		role = "user" // Valor por defecto
-	}
-	
-	result, err := s.db.Exec(query, req.Email, string(hashedPassword), req.FirstName, req.LastName, req.Phone, req.DateOfBirth, role)
+
+	result, err := s.db.Exec(query, req.Email, string(hashedPassword), req.FirstName, req.LastName, req.Phone, req.DateOfBirth, models.RoleUser)
 	if err != nil {
 		// Manejo específico de errores MySQL
 		if strings.Contains(err.Error(), "Duplicate entry") || strings.Contains(err.Error(), "duplicate key") {
@@ -75,45 +148,55 @@ func (s *BookingService) RegisterUser(req *models.RegisterRequest) (*models.User
 	}
 
 	// Obtener usuario creado
-	return s.GetUserByID(int(userID))
+	user, err := s.GetUserByID(int(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	// Un fallo al enviar el email no impide el registro: se puede reenviar
+	if err := s.SendEmailVerification(user); err != nil {
+		fmt.Printf("⚠️ Warning: No se pudo enviar la verificación a %s: %v\n", user.Email, err)
+	}
+
+	return user, nil
 }
 
-// LoginUser autentica un usuario
-func (s *BookingService) LoginUser(req *models.LoginRequest) (*models.User, string, error) {
+// LoginUser autentica un usuario y emite access y refresh token. Los intentos
+// fallidos se limitan por cuenta y por IP, y un email inexistente devuelve el
+// mismo error que una contraseña incorrecta.
+func (s *BookingService) LoginUser(req *models.LoginRequest, clientIP string) (*models.AuthResponse, error) {
+	subjects := s.loginSubjects(req.Email, clientIP)
+	if err := s.checkLoginLock(subjects); err != nil {
+		return nil, err
+	}
+
 	// Buscar usuario por email
 	user, err := s.GetUserByEmail(req.Email)
 	if err != nil {
-		return nil, "", fmt.Errorf("usuario no encontrado")
+		if !strings.Contains(err.Error(), "usuario no encontrado") {
+			return nil, err
+		}
+		bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(req.Password))
+		s.recordLoginFailure(subjects)
+		return nil, fmt.Errorf("credenciales inválidas")
 	}
 
 	// Verificar password
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
 	if err != nil {
-		return nil, "", fmt.Errorf("credenciales inválidas")
+		s.recordLoginFailure(subjects)
+		return nil, fmt.Errorf("credenciales inválidas")
 	}
 
-	// Generar JWT token
-	token, err := s.generateJWTToken(user.ID)
-	if err != nil {
-		return nil, "", fmt.Errorf("error generando token: %v", err)
-	}
-
-	return user, token, nil
+	s.resetLoginFailures(subjects)
+	return s.issueTokens(user)
 }
 
 // GetUserByID obtiene un usuario por ID - MODIFICADO: agregado role
 func (s *BookingService) GetUserByID(userID int) (*models.User, error) {
-	query := `
-		SELECT id, email, password_hash, first_name, last_name, phone, date_of_birth, role, created_at, updated_at, is_active
-		FROM users WHERE id = ? AND is_active = TRUE
-	`
-
-	var user models.User
-	err := s.db.QueryRow(query, userID).Scan(
-		&user.ID, &user.Email, &user.PasswordHash, &user.FirstName, &user.LastName,
-		&user.Phone, &user.DateOfBirth, &user.Role, &user.CreatedAt, &user.UpdatedAt, &user.IsActive,
-	)
+	query := "SELECT " + userColumns + " FROM users WHERE id = ? AND is_active = TRUE"
 
+	user, err := scanUser(s.db.QueryRow(query, userID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("usuario no encontrado")
@@ -121,22 +204,14 @@ func (s *BookingService) GetUserByID(userID int) (*models.User, error) {
 		return nil, fmt.Errorf("error obteniendo usuario: %v", err)
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 // GetUserByEmail obtiene un usuario por email - MODIFICADO: agregado role
 func (s *BookingService) GetUserByEmail(email string) (*models.User, error) {
-	query := `
-		SELECT id, email, password_hash, first_name, last_name, phone, date_of_birth, role, created_at, updated_at, is_active
-		FROM users WHERE email = ? AND is_active = TRUE
-	`
-
-	var user models.User
-	err := s.db.QueryRow(query, email).Scan(
-		&user.ID, &user.Email, &user.PasswordHash, &user.FirstName, &user.LastName,
-		&user.Phone, &user.DateOfBirth, &user.Role, &user.CreatedAt, &user.UpdatedAt, &user.IsActive,
-	)
+	query := "SELECT " + userColumns + " FROM users WHERE email = ? AND is_active = TRUE"
 
+	user, err := scanUser(s.db.QueryRow(query, email))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("usuario no encontrado")
@@ -144,13 +219,13 @@ func (s *BookingService) GetUserByEmail(email string) (*models.User, error) {
 		return nil, fmt.Errorf("error obteniendo usuario: %v", err)
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 // CheckAvailability verifica disponibilidad de un hotel
-func (s *BookingService) CheckAvailability(req *models.AvailabilityRequest) (*models.AvailabilityResponse, error) {
+func (s *BookingService) CheckAvailability(ctx context.Context, req *models.AvailabilityRequest) (*models.AvailabilityResponse, error) {
 	// Generar clave de caché
-	cacheKey := memcached.GenerateAvailabilityKey(req.HotelID, req.CheckInDate, req.CheckOutDate, req.Guests)
+	cacheKey := memcached.GenerateAvailabilityKey(req.HotelID, req.CheckInDate, req.CheckOutDate, req.Guests, normalizeRoomType(req.RoomType))
 
 	// Intentar obtener del caché
 	var cachedResponse models.AvailabilityResponse
@@ -161,56 +236,22 @@ func (s *BookingService) CheckAvailability(req *models.AvailabilityRequest) (*mo
 		return &cachedResponse, nil
 	}
 
-	// Cache miss - consultar Amadeus
-	// Primero obtener mapeo de hotel
-	amadeusHotelID, err := s.getAmadeusHotelID(req.HotelID)
+	// Cache miss - consultar al proveedor del hotel
+	provider, externalHotelID, err := s.providerForHotel(req.HotelID)
 	if err != nil {
-		// Si no hay mapeo, simular respuesta sin error 500
-		fmt.Printf("⚠️ Warning: No se encontró mapeo para hotel %s, simulando disponibilidad\n", req.HotelID)
-		response := s.createSimulatedAvailability(req)
-		s.cache.Set(cacheKey, response, 10*time.Second)
-		fmt.Printf("💾 Valor almacenado en caché: %s\n", cacheKey)
-		return response, nil
+		return nil, err
 	}
 
-	// Consultar ofertas en Amadeus
-	checkInStr := req.CheckInDate.Format("2006-01-02")
-	checkOutStr := req.CheckOutDate.Format("2006-01-02")
-	
-	offers, err := s.amadeusClient.GetHotelOffers(amadeusHotelID, checkInStr, checkOutStr, req.Guests)
+	response, err := provider.Availability(ctx, externalHotelID, req)
 	if err != nil {
-		// Log del error pero no fallar
-		fmt.Printf("⚠️ Warning: Error conectando con Amadeus: %v\n", err)
-		fmt.Printf("📝 Simulando disponibilidad para hotel %s\n", req.HotelID)
-		
-		// Devolver disponibilidad simulada en lugar de error 500
-		response := s.createSimulatedAvailability(req)
-		s.cache.Set(cacheKey, response, 10*time.Second)
-		fmt.Printf("💾 Valor almacenado en caché: %s\n", cacheKey)
-		return response, nil
-	}
-
-	// Procesar ofertas de Amadeus
-	response := &models.AvailabilityResponse{
-		HotelID:      req.HotelID,
-		Available:    len(offers) > 0,
-		CheckInDate:  checkInStr,
-		CheckOutDate: checkOutStr,
-		Guests:       req.Guests,
+		// Log del error pero no fallar: se responde con el proveedor de respaldo
+		fmt.Printf("⚠️ Warning: Error consultando proveedor %s: %v\n", provider.Name(), err)
+		response = s.fallbackAvailability(ctx, provider, req)
 	}
 
-	if len(offers) > 0 && len(offers[0].Offers) > 0 {
-		firstOffer := offers[0].Offers[0]
-		response.Currency = firstOffer.Price.Currency
-		
-		// Convertir precio
-		if priceFloat, err := strconv.ParseFloat(firstOffer.Price.Total, 64); err == nil {
-			response.Price = &priceFloat
-		}
-
-		rooms := firstOffer.RoomQuantity
-		response.RoomsAvailable = &rooms
-	}
+	// Desglosar el precio y limitar por el inventario propio. Si el proveedor
+	// no informó precio se cotiza con las tarifas propias.
+	s.applyRoomAvailability(response, req, response.Available && response.Price == nil)
 
 	// Guardar en caché por 10 segundos
 	s.cache.Set(cacheKey, response, 10*time.Second)
@@ -219,8 +260,99 @@ func (s *BookingService) CheckAvailability(req *models.AvailabilityRequest) (*mo
 	return response, nil
 }
 
-// createSimulatedAvailability crea una respuesta simulada cuando Amadeus falla
-func (s *BookingService) createSimulatedAvailability(req *models.AvailabilityRequest) *models.AvailabilityResponse {
+// applyRoomAvailability ajusta la respuesta de disponibilidad según el tipo de
+// habitación, las tarifas y el inventario propio. Con localPricing se arma una
+// oferta por tipo de habitación con el motor de tarifas; si no, se desglosan los
+// totales informados por el proveedor.
+func (s *BookingService) applyRoomAvailability(response *models.AvailabilityResponse, req *models.AvailabilityRequest, localPricing bool) {
+	response.RoomType = normalizeRoomType(req.RoomType)
+	response.Nights = []models.NightlyPrice{}
+	if response.Offers == nil {
+		response.Offers = []models.RoomOffer{}
+	}
+	totalRooms := s.defaultRoomInventory
+
+	room, err := s.resolveRoomType(req.HotelID, req.RoomType, req.Guests)
+	if err != nil {
+		if isRoomTypeError(err) {
+			noRooms := 0
+			response.Available = false
+			response.RoomsAvailable = &noRooms
+			response.Offers = []models.RoomOffer{}
+			return
+		}
+		fmt.Printf("⚠️ Warning: No se pudieron validar tipos de habitación para hotel %s: %v\n", req.HotelID, err)
+	} else if room != nil {
+		response.RoomType = room.Name
+		totalRooms = s.roomInventoryTotal(room)
+	}
+
+	if localPricing {
+		s.applyLocalOffers(response, req, room)
+		return
+	}
+
+	if response.Price != nil {
+		response.Nights = pricing.SplitTotal(stayNights(req.CheckInDate, req.CheckOutDate), *response.Price).Nights
+	}
+	for i := range response.Offers {
+		response.Offers[i].Nights = pricing.SplitTotal(stayNights(req.CheckInDate, req.CheckOutDate), response.Offers[i].TotalPrice).Nights
+	}
+
+	rooms, err := s.GetRoomsAvailable(req.HotelID, response.RoomType, req.CheckInDate, req.CheckOutDate, totalRooms)
+	if err != nil {
+		fmt.Printf("⚠️ Warning: No se pudo consultar inventario para hotel %s: %v\n", req.HotelID, err)
+		return
+	}
+
+	if response.RoomsAvailable == nil || rooms < *response.RoomsAvailable {
+		response.RoomsAvailable = &rooms
+	}
+	if rooms <= 0 {
+		response.Available = false
+	}
+}
+
+// applyLocalOffers cotiza las ofertas propias y completa los campos principales
+// con la más barata que tenga habitaciones. Si todas están agotadas se informa
+// igual el precio (lo usa la modificación de reservas) con available en false.
+func (s *BookingService) applyLocalOffers(response *models.AvailabilityResponse, req *models.AvailabilityRequest, room *models.RoomType) {
+	offers := s.localOffers(req, room)
+	sort.SliceStable(offers, func(i, j int) bool { return offers[i].TotalPrice < offers[j].TotalPrice })
+	response.Offers = offers
+
+	if len(offers) == 0 {
+		return
+	}
+
+	selected := &offers[0]
+	for i := range offers {
+		if offers[i].RoomsAvailable == nil || *offers[i].RoomsAvailable > 0 {
+			selected = &offers[i]
+			break
+		}
+	}
+
+	total := selected.TotalPrice
+	response.OfferID = selected.OfferID
+	response.RoomType = selected.RoomType
+	response.Price = &total
+	response.Currency = selected.Currency
+	response.Nights = selected.Nights
+	if selected.RoomsAvailable != nil {
+		rooms := *selected.RoomsAvailable
+		if response.RoomsAvailable == nil || rooms < *response.RoomsAvailable {
+			response.RoomsAvailable = &rooms
+		}
+		if rooms <= 0 {
+			response.Available = false
+		}
+	}
+}
+
+// createLocalAvailability crea una respuesta con tarifas propias cuando Amadeus
+// no tiene el hotel o falla. El precio lo completa applyRoomAvailability.
+func (s *BookingService) createLocalAvailability(req *models.AvailabilityRequest) *models.AvailabilityResponse {
 	checkInStr := req.CheckInDate.Format("2006-01-02")
 	checkOutStr := req.CheckOutDate.Format("2006-01-02")
 	
@@ -233,55 +365,101 @@ func (s *BookingService) createSimulatedAvailability(req *models.AvailabilityReq
 		Currency:       "ARS",
 	}
 	
-	// Simular precio basado en cantidad de huéspedes
-	price := float64(15000 + (req.Guests-1)*5000) // Precio base + extra por huésped
-	response.Price = &price
-	rooms := 5
+	rooms := s.defaultRoomInventory
 	response.RoomsAvailable = &rooms
 
 	return response
 }
 
+// maxReferenceAttempts intentos de generar una referencia de reserva libre
+const maxReferenceAttempts = 3
+
 // CreateBooking crea una nueva reserva - VERSIÓN CORREGIDA
-func (s *BookingService) CreateBooking(userID int, req *models.CreateBookingRequest) (*models.Booking, error) {
-	// Verificar disponibilidad primero
-	availReq := &models.AvailabilityRequest{
-		HotelID:      req.HotelID,
-		CheckInDate:  req.CheckInDate,
-		CheckOutDate: req.CheckOutDate,
-		Guests:       req.Guests,
+func (s *BookingService) CreateBooking(ctx context.Context, userID int, req *models.CreateBookingRequest) (*models.Booking, error) {
+	roomRequests, err := bookingRoomRequests(req)
+	if err != nil {
+		return nil, err
 	}
 
-	availability, err := s.CheckAvailability(availReq)
+	// Solo los proveedores externos requieren sincronizar la reserva
+	provider, _, err := s.providerForHotel(req.HotelID)
 	if err != nil {
-		return nil, fmt.Errorf("error verificando disponibilidad: %v", err)
+		return nil, err
 	}
+	syncWithProvider := provider.ExternalSync()
 
-	if !availability.Available {
-		return nil, fmt.Errorf("hotel no disponible para las fechas seleccionadas")
+	// Cotizar cada habitación; la reserva suma huéspedes y total
+	lines := make([]*roomLine, 0, len(roomRequests))
+	totalGuests := 0
+	totalPrice := 0.0
+	for _, roomReq := range roomRequests {
+		roomBooking := *req
+		roomBooking.RoomType = roomReq.RoomType
+		roomBooking.Guests = roomReq.Guests
+		roomBooking.OfferID = roomReq.OfferID
+		roomBooking.Rooms = nil
+
+		line, err := s.quoteRoomLine(ctx, &roomBooking, provider)
+		if err != nil {
+			return nil, err
+		}
+		if len(lines) > 0 && line.currency != lines[0].currency {
+			return nil, fmt.Errorf("habitaciones inválidas: las habitaciones cotizan en monedas distintas")
+		}
+
+		lines = append(lines, line)
+		totalGuests += line.guests
+		totalPrice += line.totalPrice
 	}
+	totalPrice = math.Round(totalPrice*100) / 100
 
-	// Crear reserva en base de datos - CORREGIDO: agregado status = 'confirmed' desde el inicio
-	query := `
-		INSERT INTO bookings (user_id, internal_hotel_id, check_in_date, check_out_date, guests, room_type, total_price, currency, status, special_requests, booking_reference)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, 'confirmed', ?, ?)
-	`
+	guests, err := s.resolveGuests(userID, totalGuests, req.GuestDetails)
+	if err != nil {
+		return nil, err
+	}
 
-	// Generar referencia de reserva
-	bookingRef := fmt.Sprintf("BK%d%d", userID, time.Now().Unix())
-	
-	totalPrice := 0.0
-	currency := "ARS"
-	if availability.Price != nil {
-		totalPrice = *availability.Price
+	// El inventario, la reserva y sus habitaciones se escriben en la misma
+	// transacción: o se reservan todas las habitaciones o ninguna
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error iniciando transacción: %v", err)
 	}
-	if availability.Currency != "" {
-		currency = availability.Currency
+	defer tx.Rollback()
+
+	for _, line := range lines {
+		if err := s.reserveInventory(tx, req.HotelID, line.roomType, req.CheckInDate, req.CheckOutDate, s.roomInventoryTotal(line.room)); err != nil {
+			return nil, err
+		}
 	}
 
-	result, err := s.db.Exec(query, userID, req.HotelID, req.CheckInDate, req.CheckOutDate, req.Guests, req.RoomType, totalPrice, currency, req.SpecialRequests, bookingRef)
-	if err != nil {
-		return nil, fmt.Errorf("error creando reserva: %v", err)
+	// Sin pasarela de pagos la reserva se confirma al crearse; con pasarela queda
+	// pendiente hasta que se autorice el cobro
+	status := models.StatusConfirmed
+	if s.paymentGateway != nil {
+		status = models.StatusPending
+	}
+
+	query := `
+		INSERT INTO bookings (user_id, internal_hotel_id, provider, amadeus_offer_id, check_in_date, check_out_date, guests, room_type, total_price, currency, status, special_requests, booking_reference)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	// La referencia es aleatoria: ante la improbable colisión con otra se
+	// genera una nueva (el error de clave duplicada no aborta la transacción)
+	var result sql.Result
+	for attempt := 1; ; attempt++ {
+		bookingRef, err := bookingref.New()
+		if err != nil {
+			return nil, err
+		}
+
+		result, err = tx.Exec(query, userID, req.HotelID, provider.Name(), lines[0].offerID, dateOnly(req.CheckInDate), dateOnly(req.CheckOutDate), totalGuests, lines[0].roomType, totalPrice, lines[0].currency, status, req.SpecialRequests, bookingRef)
+		if err == nil {
+			break
+		}
+		if attempt == maxReferenceAttempts || !strings.Contains(err.Error(), "Duplicate entry") {
+			return nil, fmt.Errorf("error creando reserva: %v", err)
+		}
 	}
 
 	bookingID, err := result.LastInsertId()
@@ -289,193 +467,260 @@ func (s *BookingService) CreateBooking(userID int, req *models.CreateBookingRequ
 		return nil, fmt.Errorf("error obteniendo ID de reserva: %v", err)
 	}
 
-	// Intentar crear reserva en Amadeus en background (opcional, no afecta el status)
-	go func() {
-		amadeusHotelID, err := s.getAmadeusHotelID(req.HotelID)
-		if err == nil {
-			guestInfo := map[string]interface{}{
-				"adults": req.Guests,
-			}
-			
-			amadeusBookingID, err := s.amadeusClient.CreateBooking(amadeusHotelID, guestInfo)
-			if err == nil {
-				// Solo actualizar el amadeus_booking_id, el status ya es 'confirmed'
-				s.db.Exec("UPDATE bookings SET amadeus_booking_id = ? WHERE id = ?", amadeusBookingID, bookingID)
-			}
+	if err := linkIdempotencyKey(tx, userID, req.IdempotencyKey, bookingID); err != nil {
+		return nil, err
+	}
+
+	for i, line := range lines {
+		roomID, err := s.insertBookingRoom(tx, bookingID, i+1, line)
+		if err != nil {
+			return nil, err
+		}
+
+		// Guardar el desglose por noche con el que se cotizó la habitación
+		if err := s.insertPriceLines(tx, bookingID, roomID, line.nights, line.currency); err != nil {
+			return nil, err
 		}
-	}()
+	}
+
+	if err := s.insertBookingGuests(tx, bookingID, guests); err != nil {
+		return nil, err
+	}
+
+	// Una reserva pendiente de pago llega al proveedor recién al autorizarse
+	if s.paymentGateway != nil {
+		if err := s.createPayment(tx, bookingID, totalPrice, lines[0].currency); err != nil {
+			return nil, err
+		}
+	} else if syncWithProvider {
+		if err := s.enqueueSyncJob(tx, bookingID, nil, models.SyncOperationCreate); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := enqueueBookingEvent(tx, models.EventBookingCreated, bookingID, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error confirmando reserva: %v", err)
+	}
+
+	// Con el medio de pago informado se autoriza enseguida; si no, la reserva
+	// espera el pago en /bookings/:id/payment
+	if s.paymentGateway != nil && req.PaymentMethod != "" {
+		return s.AuthorizeBookingPayment(ctx, int(bookingID), req.PaymentMethod)
+	}
 
 	// Obtener reserva creada
 	return s.GetBookingByID(int(bookingID))
 }
 
-// GetBookingByID obtiene una reserva por ID
-func (s *BookingService) GetBookingByID(bookingID int) (*models.Booking, error) {
-	query := `
-		SELECT id, user_id, internal_hotel_id, amadeus_hotel_id, amadeus_booking_id, check_in_date, check_out_date, 
-		       guests, room_type, total_price, currency, status, booking_reference, special_requests, created_at, updated_at
-		FROM bookings WHERE id = ?
-	`
-
-	var booking models.Booking
-	err := s.db.QueryRow(query, bookingID).Scan(
-		&booking.ID, &booking.UserID, &booking.InternalHotelID, &booking.AmadeusHotelID, &booking.AmadeusBookingID,
-		&booking.CheckInDate, &booking.CheckOutDate, &booking.Guests, &booking.RoomType, &booking.TotalPrice,
-		&booking.Currency, &booking.Status, &booking.BookingReference, &booking.SpecialRequests,
-		&booking.CreatedAt, &booking.UpdatedAt,
-	)
+// quoteRoomLine valida y cotiza una habitación de la reserva con la oferta elegida
+func (s *BookingService) quoteRoomLine(ctx context.Context, req *models.CreateBookingRequest, provider inventory.InventoryProvider) (*roomLine, error) {
+	// Si se eligió una oferta sin indicar habitación, la habitación es la de la oferta
+	requestedRoomType := req.RoomType
+	if req.OfferID != "" && strings.TrimSpace(requestedRoomType) == "" {
+		roomType, err := s.offerRoomType(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		requestedRoomType = roomType
+	}
 
+	// Validar el tipo de habitación contra los que ofrece el hotel
+	room, err := s.resolveRoomType(req.HotelID, requestedRoomType, req.Guests)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("reserva no encontrada")
-		}
-		return nil, fmt.Errorf("error obteniendo reserva: %v", err)
+		return nil, err
 	}
 
-	return &booking, nil
-}
+	roomType := normalizeRoomType(requestedRoomType)
+	if room != nil {
+		roomType = room.Name
+	}
 
-// GetUserBookings obtiene todas las reservas de un usuario
-func (s *BookingService) GetUserBookings(userID int) ([]*models.Booking, error) {
-	query := `
-		SELECT id, user_id, internal_hotel_id, amadeus_hotel_id, amadeus_booking_id, check_in_date, check_out_date, 
-		       guests, room_type, total_price, currency, status, booking_reference, special_requests, created_at, updated_at
-		FROM bookings WHERE user_id = ? ORDER BY created_at DESC
-	`
+	// Verificar disponibilidad primero
+	availReq := &models.AvailabilityRequest{
+		HotelID:      req.HotelID,
+		CheckInDate:  req.CheckInDate,
+		CheckOutDate: req.CheckOutDate,
+		Guests:       req.Guests,
+		RoomType:     roomType,
+	}
 
-	rows, err := s.db.Query(query, userID)
+	availability, err := s.CheckAvailability(ctx, availReq)
 	if err != nil {
-		return nil, fmt.Errorf("error obteniendo reservas: %v", err)
+		return nil, fmt.Errorf("error verificando disponibilidad: %v", err)
 	}
-	defer rows.Close()
 
-	var bookings []*models.Booking
-	for rows.Next() {
-		var booking models.Booking
-		err := rows.Scan(
-			&booking.ID, &booking.UserID, &booking.InternalHotelID, &booking.AmadeusHotelID, &booking.AmadeusBookingID,
-			&booking.CheckInDate, &booking.CheckOutDate, &booking.Guests, &booking.RoomType, &booking.TotalPrice,
-			&booking.Currency, &booking.Status, &booking.BookingReference, &booking.SpecialRequests,
-			&booking.CreatedAt, &booking.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("error escaneando reserva: %v", err)
+	if !availability.Available {
+		return nil, fmt.Errorf("hotel no disponible para las fechas seleccionadas")
+	}
+
+	line := &roomLine{
+		roomType: roomType,
+		room:     room,
+		guests:   req.Guests,
+		currency: "ARS",
+		nights:   availability.Nights,
+	}
+	if availability.Price != nil {
+		line.totalPrice = *availability.Price
+	}
+	if availability.Currency != "" {
+		line.currency = availability.Currency
+	}
+
+	// Oferta propia elegida: se reserva con su precio recién cotizado
+	if isLocalOffer(req.OfferID) {
+		offer := findOffer(availability.Offers, req.OfferID)
+		if offer == nil {
+			return nil, fmt.Errorf("la oferta ya no está disponible")
 		}
-		bookings = append(bookings, &booking)
+		line.totalPrice = offer.TotalPrice
+		line.currency = offer.Currency
+		line.nights = offer.Nights
 	}
 
-	return bookings, nil
-}
+	// La política de cancelación es la de la oferta reservada; si el proveedor
+	// no la informa, rige la de la tarifa propia
+	selectedOffer := req.OfferID
+	if selectedOffer == "" {
+		selectedOffer = availability.OfferID
+	}
+	if offer := findOffer(availability.Offers, selectedOffer); offer != nil && offer.CancellationTerms != nil {
+		line.policy = offer.CancellationTerms
+	} else {
+		line.policy = s.roomCancellationPolicy(req.HotelID, room)
+	}
 
-// getAmadeusHotelID obtiene el ID de Amadeus para un hotel interno
-func (s *BookingService) getAmadeusHotelID(internalHotelID string) (string, error) {
-	query := "SELECT amadeus_hotel_id FROM hotel_mappings WHERE internal_hotel_id = ?"
-	
-	var amadeusHotelID string
-	err := s.db.QueryRow(query, internalHotelID).Scan(&amadeusHotelID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return "", fmt.Errorf("mapeo de hotel no encontrado")
+	// Volver a cotizar la oferta elegida: el proveedor solo garantiza el precio
+	// de una oferta vigente y la reserva se hace con ese ID
+	if provider.ExternalSync() {
+		selected := req.OfferID
+		if selected == "" && availability.Provider == provider.Name() {
+			selected = availability.OfferID
+		}
+		if selected != "" && !isLocalOffer(selected) {
+			var quoted *float64
+			if shown := findOffer(availability.Offers, selected); shown != nil {
+				quoted = &shown.TotalPrice
+			}
+			offer, err := s.repriceOffer(ctx, provider, selected, quoted)
+			switch {
+			case err == nil:
+				line.totalPrice = offer.Total
+				if offer.Currency != "" {
+					line.currency = offer.Currency
+				}
+				line.nights = pricing.SplitTotal(stayNights(req.CheckInDate, req.CheckOutDate), offer.Total).Nights
+				line.offerID = &selected
+			case strings.Contains(err.Error(), "error verificando oferta"):
+				// El proveedor no responde: se reserva con la cotización mostrada y
+				// la cola de sincronización buscará una oferta vigente
+				fmt.Printf("⚠️ Warning: %v\n", err)
+			default:
+				return nil, err
+			}
 		}
-		return "", fmt.Errorf("error obteniendo mapeo: %v", err)
 	}
 
-	return amadeusHotelID, nil
+	return line, nil
 }
 
-// generateJWTToken genera un token JWT para un usuario
-func (s *BookingService) generateJWTToken(userID int) (string, error) {
-	// Crear claims
-	claims := jwt.MapClaims{
-		"user_id": userID,
-		"exp":     time.Now().Add(time.Hour * 24 * 7).Unix(), // 7 días
-		"iat":     time.Now().Unix(),
-	}
+// bookingColumns columnas leídas en todas las consultas de reservas
+const bookingColumns = `id, user_id, internal_hotel_id, provider, amadeus_hotel_id, amadeus_booking_id, amadeus_offer_id, sync_status, check_in_date, check_out_date,
+		       guests, room_type, total_price, currency, status, booking_reference, special_requests,
+		       cancelled_at, cancellation_reason, created_at, updated_at`
 
-	// Crear token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+// rowScanner abstrae *sql.Row y *sql.Rows para reutilizar el escaneo
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
-	// Firmar token
-	tokenString, err := token.SignedString([]byte(s.jwtSecret))
+// scanBooking escanea una fila con las columnas de bookingColumns
+func scanBooking(row rowScanner) (*models.Booking, error) {
+	var booking models.Booking
+	err := row.Scan(
+		&booking.ID, &booking.UserID, &booking.InternalHotelID, &booking.Provider, &booking.AmadeusHotelID, &booking.AmadeusBookingID, &booking.AmadeusOfferID, &booking.SyncStatus,
+		&booking.CheckInDate, &booking.CheckOutDate, &booking.Guests, &booking.RoomType, &booking.TotalPrice,
+		&booking.Currency, &booking.Status, &booking.BookingReference, &booking.SpecialRequests,
+		&booking.CancelledAt, &booking.CancelReason, &booking.CreatedAt, &booking.UpdatedAt,
+	)
 	if err != nil {
-		return "", fmt.Errorf("error firmando token: %v", err)
+		return nil, err
 	}
-
-	return tokenString, nil
+	return &booking, nil
 }
 
-// ValidateJWTToken valida un token JWT
-func (s *BookingService) ValidateJWTToken(tokenString string) (int, error) {
-	// Parsear token
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Verificar método de firma
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("método de firma inesperado: %v", token.Header["alg"])
+// GetBookingByID obtiene una reserva por ID
+func (s *BookingService) GetBookingByID(bookingID int) (*models.Booking, error) {
+	query := "SELECT " + bookingColumns + " FROM bookings WHERE id = ?"
+
+	booking, err := scanBooking(s.db.QueryRow(query, bookingID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("reserva no encontrada")
 		}
-		return []byte(s.jwtSecret), nil
-	})
+		return nil, fmt.Errorf("error obteniendo reserva: %v", err)
+	}
 
+	booking.PriceLines, err = s.getPriceLines(booking.ID)
 	if err != nil {
-		return 0, fmt.Errorf("error parseando token: %v", err)
+		return nil, err
 	}
 
-	// Verificar que el token sea válido
-	if !token.Valid {
-		return 0, fmt.Errorf("token inválido")
+	booking.GuestDetails, err = s.getBookingGuests(booking.ID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Extraer claims
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return 0, fmt.Errorf("claims inválidos")
+	booking.Rooms, err = s.getBookingRooms(booking.ID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Obtener user_id
-	userID, ok := claims["user_id"].(float64)
-	if !ok {
-		return 0, fmt.Errorf("user_id inválido en token")
+	booking.Payment, err = s.getBookingPayment(booking.ID)
+	if err != nil {
+		return nil, err
 	}
 
-	return int(userID), nil
+	return booking, nil
 }
 
-// ValidateJWTTokenWithRole valida un token JWT y devuelve userID y role
-func (s *BookingService) ValidateJWTTokenWithRole(tokenString string) (int, string, error) {
-	// Parsear token
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Verificar método de firma
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("método de firma inesperado: %v", token.Header["alg"])
-		}
-		return []byte(s.jwtSecret), nil
-	})
-
+// GetBookingByReference obtiene una reserva por su referencia normalizada
+func (s *BookingService) GetBookingByReference(reference string) (*models.Booking, error) {
+	var bookingID int
+	err := s.db.QueryRow("SELECT id FROM bookings WHERE booking_reference = ?", reference).Scan(&bookingID)
 	if err != nil {
-		return 0, "", fmt.Errorf("error parseando token: %v", err)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("reserva no encontrada")
+		}
+		return nil, fmt.Errorf("error obteniendo reserva: %v", err)
 	}
 
-	// Verificar que el token sea válido
-	if !token.Valid {
-		return 0, "", fmt.Errorf("token inválido")
-	}
+	return s.GetBookingByID(bookingID)
+}
 
-	// Extraer claims
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return 0, "", fmt.Errorf("claims inválidos")
-	}
+// GetUserBookings obtiene todas las reservas de un usuario
+func (s *BookingService) GetUserBookings(userID int) ([]*models.Booking, error) {
+	query := "SELECT " + bookingColumns + " FROM bookings WHERE user_id = ? ORDER BY created_at DESC"
 
-	// Obtener user_id
-	userID, ok := claims["user_id"].(float64)
-	if !ok {
-		return 0, "", fmt.Errorf("user_id inválido en token")
+	rows, err := s.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo reservas: %v", err)
 	}
+	defer rows.Close()
 
-	// Obtener el usuario para verificar su rol actual
-	user, err := s.GetUserByID(int(userID))
-	if err != nil {
-		return 0, "", fmt.Errorf("usuario no encontrado: %v", err)
+	var bookings []*models.Booking
+	for rows.Next() {
+		booking, err := scanBooking(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error escaneando reserva: %v", err)
+		}
+		bookings = append(bookings, booking)
 	}
 
-	return int(userID), string(user.Role), nil
-}
\ No newline at end of file
+	return bookings, nil
+}
+
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"booking-service/pkg/bookingref"
+)
+
+func TestGetBookingByReference(t *testing.T) {
+	service, db := newPaymentsTestService(t)
+
+	reference, err := bookingref.New()
+	if err != nil {
+		t.Fatalf("error generando referencia: %v", err)
+	}
+	bookingID, _ := insertPendingBooking(t, db, reference, 0)
+
+	booking, err := service.GetBookingByReference(reference)
+	if err != nil {
+		t.Fatalf("error obteniendo reserva: %v", err)
+	}
+	if booking.ID != bookingID || booking.BookingReference != reference {
+		t.Fatalf("reserva = %d %s, se esperaba %d %s", booking.ID, booking.BookingReference, bookingID, reference)
+	}
+	if len(booking.Rooms) != 1 || booking.Payment == nil {
+		t.Fatalf("la reserva no trae habitaciones y pago: %+v", booking)
+	}
+
+	other, err := bookingref.New()
+	if err != nil {
+		t.Fatalf("error generando referencia: %v", err)
+	}
+	if _, err := service.GetBookingByReference(other); err == nil || !strings.Contains(err.Error(), "no encontrada") {
+		t.Fatalf("error = %v, se esperaba reserva no encontrada", err)
+	}
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"booking-service/internal/models"
+	"booking-service/pkg/memcached"
+)
+
+// getCatalogHotel obtiene el hotel de hotel-service, con caché de un minuto
+func (s *BookingService) getCatalogHotel(hotelID string) (*models.CatalogHotel, error) {
+	cacheKey := memcached.GenerateHotelKey(hotelID)
+
+	var hotel models.CatalogHotel
+	if err := s.cache.Get(cacheKey, &hotel); err == nil {
+		return &hotel, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	fetched, err := s.hotelClient.GetHotel(ctx, hotelID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.Set(cacheKey, fetched, time.Minute)
+	return fetched, nil
+}
+
+// defaultCancellationPolicy política de los hoteles que no definen una:
+// cancelación gratuita hasta el plazo configurado y sin devolución después
+func (s *BookingService) defaultCancellationPolicy() *models.CancellationPolicy {
+	return &models.CancellationPolicy{
+		FreeUntilDays:  int(math.Ceil(s.cancellationDeadline.Hours() / 24)),
+		PenaltyPercent: 100,
+	}
+}
+
+// roomCancellationPolicy devuelve la política de la tarifa propia de una
+// habitación: la del tipo de habitación, si no la del hotel y si no la default
+func (s *BookingService) roomCancellationPolicy(hotelID string, room *models.RoomType) *models.CancellationPolicy {
+	if room != nil && room.CancellationPolicy != nil {
+		return room.CancellationPolicy
+	}
+
+	hotel, err := s.getCatalogHotel(hotelID)
+	if err != nil {
+		fmt.Printf("⚠️ Warning: No se pudo obtener la política de cancelación del hotel %s: %v\n", hotelID, err)
+	} else if hotel.CancellationPolicy != nil {
+		return hotel.CancellationPolicy
+	}
+
+	return s.defaultCancellationPolicy()
+}
+
+// cancellationPolicyText describe la política para mostrarla en las ofertas
+func cancellationPolicyText(policy *models.CancellationPolicy) string {
+	if policy.NonRefundable {
+		return "Tarifa no reembolsable"
+	}
+
+	var text string
+	switch policy.FreeUntilDays {
+	case 0:
+		text = "Cancelación gratuita hasta el check-in"
+	case 1:
+		text = "Cancelación gratuita hasta el día anterior al check-in"
+	default:
+		text = fmt.Sprintf("Cancelación gratuita hasta %d días antes del check-in", policy.FreeUntilDays)
+	}
+
+	if policy.FreeUntilDays > 0 && policy.PenaltyPercent > 0 {
+		text += fmt.Sprintf("; luego se cobra el %s%% del total", strconv.FormatFloat(policy.PenaltyPercent, 'f', -1, 64))
+	}
+	return text
+}
+
+// applyCancellationFees calcula el cargo de cancelar las habitaciones en at
+// según la política copiada al reservar, lo guarda en cada habitación y
+// devuelve el monto a reintegrar. Las reservas sin política conservan la regla
+// anterior: cancelación gratuita y solo hasta el plazo configurado.
+func (s *BookingService) applyCancellationFees(booking *models.Booking, rooms []models.BookingRoom, at time.Time) (float64, error) {
+	if !at.Before(booking.CheckInDate) {
+		return 0, fmt.Errorf("plazo de cancelación vencido: la estadía ya comenzó")
+	}
+
+	refund := 0.0
+	for i := range rooms {
+		room := &rooms[i]
+		fee := 0.0
+
+		switch {
+		case room.CancellationPolicy == nil:
+			deadline := booking.CheckInDate.Add(-s.cancellationDeadline)
+			if at.After(deadline) {
+				return 0, fmt.Errorf("plazo de cancelación vencido: se permitía cancelar hasta %s", deadline.Format("2006-01-02 15:04"))
+			}
+		case booking.Status == models.StatusPending:
+			// Una reserva que todavía no se pagó se cancela sin cargo
+		default:
+			fee = roundMoney(room.TotalPrice * room.CancellationPolicy.PenaltyPercentAt(booking.CheckInDate, at) / 100)
+		}
+
+		room.CancellationFee = &fee
+		refund += room.TotalPrice - fee
+	}
+
+	return roundMoney(refund), nil
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"booking-service/internal/inventory"
+	"booking-service/internal/models"
+	"booking-service/pkg/hotelservice"
+	"booking-service/pkg/memcached/memcachedtest"
+	"booking-service/pkg/mysql"
+)
+
+// testHotelID hotel que ofrece el hotel-service de prueba
+const testHotelID = "hotel-1"
+
+// testRoomTypes tipos de habitación de testHotelID
+var testRoomTypes = []models.RoomType{
+	{ID: "rt-standard", Name: "standard", Capacity: 2, BaseRate: 100, Currency: "ARS", Count: 5},
+	{ID: "rt-suite", Name: "suite", Capacity: 4, BaseRate: 250, Currency: "ARS", Count: 2},
+}
+
+// newHotelServiceStub hotel-service de prueba que solo conoce testHotelID y
+// sus tipos de habitación
+func newHotelServiceStub(t *testing.T) *hotelservice.Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var data interface{}
+		switch {
+		case r.Method != http.MethodGet:
+		case r.URL.Path == "/api/v1/hotels/"+testHotelID:
+			data = models.CatalogHotel{ID: testHotelID, Name: "Hotel de prueba", City: "Córdoba"}
+		case r.URL.Path == "/api/v1/hotels/"+testHotelID+"/rooms":
+			data = testRoomTypes
+		}
+		if data == nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+	t.Cleanup(server.Close)
+
+	return hotelservice.NewClient(server.URL)
+}
+
+// newBookingTestService servicio con la base, el caché y el hotel-service de
+// prueba, sin pasarela de pago. Los hoteles sin mapeo usan el proveedor local.
+func newBookingTestService(t *testing.T) (*BookingService, *mysql.DB) {
+	t.Helper()
+
+	db := newTestDB(t)
+	cache, _ := memcachedtest.New(t)
+	service := NewBookingService(db, cache, newHotelServiceStub(t), Options{
+		JWTSecret:            "test-secret",
+		AccessTokenTTL:       15 * time.Minute,
+		RefreshTokenTTL:      time.Hour,
+		CancellationDeadline: 24 * time.Hour,
+		DefaultRoomInventory: 5,
+		SyncMaxAttempts:      3,
+		SyncBaseBackoff:      time.Second,
+		Providers: []inventory.InventoryProvider{
+			inventory.NewLocalProvider(),
+			inventory.NewSimulatedProvider(),
+		},
+		DefaultProvider: "local",
+	})
+	return service, db
+}
+
+// insertUser carga un usuario normal y devuelve su id
+func insertUser(t *testing.T, db *mysql.DB, email string) int {
+	t.Helper()
+	return int(mustExec(t, db, "INSERT INTO users (email, password_hash, first_name, last_name, phone) VALUES (?, 'x', 'Ana', 'Pérez', '')", email))
+}
+
+// testBooking datos de una reserva cargada con insertBooking. Los campos vacíos
+// toman valores por defecto: proveedor local, confirmada, una habitación
+// standard de 100 ARS por noche y check-in dentro de un mes.
+type testBooking struct {
+	Reference  string
+	Provider   string
+	Status     string
+	SyncStatus string
+	CheckIn    time.Time
+	Nights     int
+	Rooms      []string // tipo de habitación de cada una, en orden
+}
+
+// insertBooking carga una reserva con sus habitaciones y el inventario que
+// ocupan, y devuelve su id
+func insertBooking(t *testing.T, db *mysql.DB, b testBooking) int {
+	t.Helper()
+
+	if b.Provider == "" {
+		b.Provider = "local"
+	}
+	if b.Status == "" {
+		b.Status = models.StatusConfirmed
+	}
+	if b.SyncStatus == "" {
+		b.SyncStatus = models.SyncNotRequired
+	}
+	if b.CheckIn.IsZero() {
+		b.CheckIn = dateOnly(time.Now().AddDate(0, 1, 0))
+	}
+	if b.Nights == 0 {
+		b.Nights = 1
+	}
+	if len(b.Rooms) == 0 {
+		b.Rooms = []string{"standard"}
+	}
+	checkOut := b.CheckIn.AddDate(0, 0, b.Nights)
+	roomPrice := float64(100 * b.Nights)
+
+	userID := insertUser(t, db, strings.ToLower(b.Reference)+"@test.com")
+	bookingID := mustExec(t, db, `
+		INSERT INTO bookings (user_id, internal_hotel_id, provider, check_in_date, check_out_date, guests, room_type, total_price, currency, status, sync_status, booking_reference, special_requests)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, 'ARS', ?, ?, ?, '')
+	`, userID, testHotelID, b.Provider, b.CheckIn, checkOut, 2*len(b.Rooms), b.Rooms[0], roomPrice*float64(len(b.Rooms)), b.Status, b.SyncStatus, b.Reference)
+
+	for i, roomType := range b.Rooms {
+		mustExec(t, db, `
+			INSERT INTO booking_rooms (booking_id, position, room_type, guests, total_price, currency, provider_offer_id)
+			VALUES (?, ?, ?, 2, ?, 'ARS', ?)
+		`, bookingID, i+1, roomType, roomPrice, b.Reference+"-"+roomType)
+		for night := b.CheckIn; night.Before(checkOut); night = night.AddDate(0, 0, 1) {
+			mustExec(t, db, `
+				INSERT INTO room_inventory (hotel_id, room_type, stay_date, total_rooms, booked_rooms)
+				VALUES (?, ?, ?, 5, 1)
+				ON DUPLICATE KEY UPDATE booked_rooms = booked_rooms + 1
+			`, testHotelID, roomType, night)
+		}
+	}
+
+	return int(bookingID)
+}
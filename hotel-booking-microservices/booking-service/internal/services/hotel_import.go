@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"booking-service/internal/inventory"
+	"booking-service/internal/models"
+)
+
+// Resultado de importar un hotel, usado para armar el reporte
+const (
+	importCreated = "created"
+	importUpdated = "updated"
+	importSkipped = "skipped"
+)
+
+// ImportHotels trae el catálogo de Amadeus de una ciudad y lo refleja en
+// hotel-service y en hotel_mappings. Un hotel de Amadeus ya mapeado se
+// actualiza en lugar de duplicarse. Con DryRun solo se informa qué se haría.
+func (s *BookingService) ImportHotels(ctx context.Context, authorization string, req *models.HotelImportRequest) (*models.HotelImportReport, error) {
+	cityCode := strings.ToUpper(req.CityCode)
+
+	hotels, err := s.amadeusClient.SearchHotelsByCity(ctx, cityCode)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando Amadeus: %v", err)
+	}
+
+	report := &models.HotelImportReport{
+		CityCode: cityCode,
+		DryRun:   req.DryRun,
+		Total:    len(hotels),
+		Created:  []models.HotelImportItem{},
+		Updated:  []models.HotelImportItem{},
+		Skipped:  []models.HotelImportItem{},
+	}
+
+	seen := make(map[string]bool)
+	for _, hotel := range hotels {
+		var action string
+		var item models.HotelImportItem
+
+		switch {
+		case hotel.HotelID == "" || strings.TrimSpace(hotel.Name) == "":
+			action = importSkipped
+			item = models.HotelImportItem{AmadeusHotelID: hotel.HotelID, Name: hotel.Name, Reason: "sin ID o nombre"}
+		case seen[hotel.HotelID]:
+			action = importSkipped
+			item = models.HotelImportItem{AmadeusHotelID: hotel.HotelID, Name: hotel.Name, Reason: "duplicado en la respuesta de Amadeus"}
+		default:
+			action, item = s.importHotel(ctx, authorization, hotel, req.City, req.DryRun)
+		}
+		seen[hotel.HotelID] = true
+
+		switch action {
+		case importCreated:
+			report.Created = append(report.Created, item)
+		case importUpdated:
+			report.Updated = append(report.Updated, item)
+		default:
+			report.Skipped = append(report.Skipped, item)
+		}
+	}
+
+	fmt.Printf("📥 Importación de Amadeus %s (dry_run=%t): %d creados, %d actualizados, %d omitidos\n",
+		cityCode, req.DryRun, len(report.Created), len(report.Updated), len(report.Skipped))
+
+	return report, nil
+}
+
+// importHotel crea o actualiza un hotel de Amadeus. Los errores no cortan la
+// importación: el hotel se informa como omitido con el motivo.
+func (s *BookingService) importHotel(ctx context.Context, authorization string, hotel models.AmadeusHotel, city string, dryRun bool) (string, models.HotelImportItem) {
+	imported := catalogHotel(hotel, city)
+	item := models.HotelImportItem{AmadeusHotelID: hotel.HotelID, Name: imported.Name}
+
+	var mappingID int
+	var internalHotelID string
+	err := s.db.QueryRow("SELECT id, internal_hotel_id FROM hotel_mappings WHERE amadeus_hotel_id = ? ORDER BY id LIMIT 1", hotel.HotelID).Scan(&mappingID, &internalHotelID)
+	if err != nil && err != sql.ErrNoRows {
+		item.Reason = fmt.Sprintf("error obteniendo mapeo: %v", err)
+		return importSkipped, item
+	}
+
+	// Hotel nuevo
+	if err == sql.ErrNoRows {
+		if dryRun {
+			return importCreated, item
+		}
+
+		internalHotelID, err := s.hotelClient.CreateHotel(ctx, authorization, imported)
+		if err != nil {
+			item.Reason = fmt.Sprintf("error creando hotel: %v", err)
+			return importSkipped, item
+		}
+		item.InternalHotelID = internalHotelID
+
+		_, err = s.db.Exec(`
+			INSERT INTO hotel_mappings (internal_hotel_id, provider, amadeus_hotel_id, hotel_name, city)
+			VALUES (?, ?, ?, ?, ?)
+		`, internalHotelID, inventory.ProviderAmadeus, hotel.HotelID, imported.Name, imported.City)
+		if err != nil {
+			fmt.Printf("⚠️ Warning: Hotel %s creado en hotel-service sin mapeo a %s: %v\n", internalHotelID, hotel.HotelID, err)
+			item.Reason = fmt.Sprintf("hotel creado pero no se pudo guardar el mapeo: %v", err)
+			return importSkipped, item
+		}
+
+		return importCreated, item
+	}
+
+	// Hotel ya mapeado: se actualiza el existente
+	item.InternalHotelID = internalHotelID
+
+	current, err := s.hotelClient.GetHotel(ctx, internalHotelID)
+	if err != nil {
+		if strings.Contains(err.Error(), "no encontrado") {
+			item.Reason = "el hotel mapeado no existe en hotel-service"
+		} else {
+			item.Reason = fmt.Sprintf("error obteniendo hotel: %v", err)
+		}
+		return importSkipped, item
+	}
+
+	merged, changed := mergeCatalogHotel(current, imported)
+	if !changed {
+		item.Reason = "sin cambios"
+		return importSkipped, item
+	}
+
+	if dryRun {
+		return importUpdated, item
+	}
+
+	if err := s.hotelClient.UpdateHotel(ctx, authorization, internalHotelID, merged); err != nil {
+		item.Reason = fmt.Sprintf("error actualizando hotel: %v", err)
+		return importSkipped, item
+	}
+
+	_, err = s.db.Exec("UPDATE hotel_mappings SET hotel_name = ?, city = ? WHERE id = ?", merged.Name, merged.City, mappingID)
+	if err != nil {
+		fmt.Printf("⚠️ Warning: No se pudo actualizar el mapeo de %s: %v\n", hotel.HotelID, err)
+	}
+
+	return importUpdated, item
+}
+
+// catalogHotel arma el hotel de hotel-service a partir del de Amadeus. Amadeus
+// informa nombres en mayúsculas; se guardan capitalizados.
+func catalogHotel(hotel models.AmadeusHotel, city string) *models.CatalogHotel {
+	if city == "" {
+		city = titleCase(hotel.Address.CityName)
+	}
+	if city == "" {
+		city = hotel.CityCode
+	}
+
+	var parts []string
+	for _, line := range hotel.Address.Lines {
+		if line = strings.TrimSpace(line); line != "" {
+			parts = append(parts, titleCase(line))
+		}
+	}
+	if hotel.Address.PostalCode != "" {
+		parts = append(parts, hotel.Address.PostalCode)
+	}
+	parts = append(parts, city)
+	if hotel.Address.CountryCode != "" {
+		parts = append(parts, hotel.Address.CountryCode)
+	}
+
+	rating, err := strconv.Atoi(hotel.Rating)
+	if err != nil || rating < 1 || rating > 5 {
+		rating = 0
+	}
+
+	return &models.CatalogHotel{
+		Name:        titleCase(hotel.Name),
+		Description: fmt.Sprintf("Hotel importado desde Amadeus (%s).", hotel.HotelID),
+		City:        city,
+		Address:     strings.Join(parts, ", "),
+		Rating:      float64(rating),
+	}
+}
+
+// mergeCatalogHotel aplica los datos importados sobre el hotel actual. La
+// importación solo pisa nombre, ciudad, dirección y categoría; la descripción,
+// precios y contacto cargados a mano se conservan.
+func mergeCatalogHotel(current, imported *models.CatalogHotel) (*models.CatalogHotel, bool) {
+	merged := *current
+	merged.ID = ""
+	merged.Name = imported.Name
+	merged.City = imported.City
+	merged.Address = imported.Address
+	if imported.Rating > 0 {
+		merged.Rating = imported.Rating
+	}
+	if merged.Description == "" {
+		merged.Description = imported.Description
+	}
+
+	changed := merged.Name != current.Name || merged.City != current.City ||
+		merged.Address != current.Address || merged.Rating != current.Rating ||
+		merged.Description != current.Description
+	return &merged, changed
+}
+
+// titleCase capitaliza cada palabra ("HOTEL PLAZA" -> "Hotel Plaza")
+func titleCase(value string) string {
+	runes := []rune(strings.ToLower(strings.TrimSpace(value)))
+	for i, r := range runes {
+		if i == 0 || !unicode.IsLetter(runes[i-1]) && !unicode.IsDigit(runes[i-1]) {
+			runes[i] = unicode.ToUpper(r)
+		}
+	}
+	return string(runes)
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"booking-service/internal/models"
+	"booking-service/pkg/amadeus"
+	"booking-service/pkg/hotelservice"
+	"booking-service/pkg/memcached/memcachedtest"
+	"booking-service/pkg/mysql"
+)
+
+// catalogStub hotel-service en memoria que registra las altas y modificaciones
+type catalogStub struct {
+	mu      sync.Mutex
+	hotels  map[string]models.CatalogHotel
+	created int
+	updated int
+}
+
+func newCatalogStub(t *testing.T) (*catalogStub, *hotelservice.Client) {
+	t.Helper()
+
+	stub := &catalogStub{hotels: map[string]models.CatalogHotel{}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stub.mu.Lock()
+		defer stub.mu.Unlock()
+
+		if r.Method != http.MethodGet && r.Header.Get("Authorization") != "Bearer admin" {
+			t.Errorf("%s %s sin el header del administrador", r.Method, r.URL.Path)
+		}
+
+		id := strings.TrimPrefix(r.URL.Path, "/api/v1/hotels/")
+		var hotel models.CatalogHotel
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/hotels":
+			json.NewDecoder(r.Body).Decode(&hotel)
+			stub.created++
+			hotel.ID = fmt.Sprintf("catalog-%d", stub.created)
+			stub.hotels[hotel.ID] = hotel
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPut:
+			if _, ok := stub.hotels[id]; !ok {
+				http.NotFound(w, r)
+				return
+			}
+			json.NewDecoder(r.Body).Decode(&hotel)
+			hotel.ID = id
+			stub.hotels[id] = hotel
+			stub.updated++
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/hotels":
+			hotels := []models.CatalogHotel{}
+			for _, hotel := range stub.hotels {
+				hotels = append(hotels, hotel)
+			}
+			sort.Slice(hotels, func(i, j int) bool { return hotels[i].ID < hotels[j].ID })
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": hotels})
+			return
+		case r.Method == http.MethodGet:
+			var ok bool
+			if hotel, ok = stub.hotels[id]; !ok {
+				http.NotFound(w, r)
+				return
+			}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": hotel})
+	}))
+	t.Cleanup(server.Close)
+
+	return stub, hotelservice.NewClient(server.URL)
+}
+
+// newAmadeusCityStub Amadeus de prueba que devuelve hotels en la búsqueda por ciudad
+func newAmadeusCityStub(t *testing.T, hotels string) *amadeus.Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			fmt.Fprint(w, `{"access_token":"test-token","expires_in":1799}`)
+		case "/v1/reference-data/locations/hotels/by-city":
+			fmt.Fprintf(w, `{"data":%s}`, hotels)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return amadeus.NewClient(server.URL, "id", "secret", amadeus.Options{})
+}
+
+// importNames nombres de los hoteles de un resultado de importación
+func importNames(items []models.HotelImportItem) string {
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		names = append(names, item.AmadeusHotelID)
+	}
+	return strings.Join(names, ",")
+}
+
+// mappingName nombre guardado en el mapeo de un hotel de Amadeus
+func mappingName(t *testing.T, db *mysql.DB, amadeusHotelID string) (string, bool) {
+	t.Helper()
+
+	var name string
+	err := db.QueryRow("SELECT hotel_name FROM hotel_mappings WHERE amadeus_hotel_id = ?", amadeusHotelID).Scan(&name)
+	if err != nil {
+		return "", false
+	}
+	return name, true
+}
+
+func TestImportHotels(t *testing.T) {
+	db := newTestDB(t)
+	cache, _ := memcachedtest.New(t)
+	catalog, hotelClient := newCatalogStub(t)
+
+	// MAPPED ya está importado con una descripción cargada a mano; SAME no cambió
+	catalog.hotels["catalog-mapped"] = models.CatalogHotel{ID: "catalog-mapped", Name: "Viejo Nombre", City: "Buenos Aires", Description: "Descripción propia"}
+	catalog.hotels["catalog-same"] = models.CatalogHotel{ID: "catalog-same", Name: "Hotel Igual", City: "Buenos Aires", Address: "Buenos Aires, AR", Description: "Hotel importado desde Amadeus (SAME)."}
+	mustExec(t, db, "INSERT INTO hotel_mappings (internal_hotel_id, provider, amadeus_hotel_id, hotel_name, city) VALUES ('catalog-mapped', 'amadeus', 'MAPPED', 'Viejo Nombre', 'Buenos Aires')")
+	mustExec(t, db, "INSERT INTO hotel_mappings (internal_hotel_id, provider, amadeus_hotel_id, hotel_name, city) VALUES ('catalog-same', 'amadeus', 'SAME', 'Hotel Igual', 'Buenos Aires')")
+
+	service := NewBookingService(db, cache, hotelClient, Options{
+		AmadeusClient: newAmadeusCityStub(t, `[
+			{"hotelId":"NEW","name":"HOTEL NUEVO","rating":"4","cityCode":"BUE","address":{"countryCode":"AR","lines":["AV CORRIENTES 123"]}},
+			{"hotelId":"MAPPED","name":"HOTEL RENOVADO","rating":"9","cityCode":"BUE","address":{"countryCode":"AR"}},
+			{"hotelId":"SAME","name":"HOTEL IGUAL","cityCode":"BUE","address":{"countryCode":"AR"}},
+			{"hotelId":"NEW","name":"HOTEL NUEVO","cityCode":"BUE"},
+			{"hotelId":"NONAME","name":" ","cityCode":"BUE"}
+		]`),
+	})
+	req := &models.HotelImportRequest{CityCode: "bue", City: "Buenos Aires", DryRun: true}
+
+	// La simulación informa lo mismo que la importación sin tocar nada
+	preview, err := service.ImportHotels(context.Background(), "Bearer admin", req)
+	if err != nil {
+		t.Fatalf("error simulando importación: %v", err)
+	}
+	if preview.CityCode != "BUE" || preview.Total != 5 || importNames(preview.Created) != "NEW" || importNames(preview.Updated) != "MAPPED" {
+		t.Fatalf("simulación = %+v", preview)
+	}
+	if catalog.created != 0 || catalog.updated != 0 {
+		t.Fatalf("la simulación modificó hotel-service: %d altas, %d cambios", catalog.created, catalog.updated)
+	}
+	if _, ok := mappingName(t, db, "NEW"); ok {
+		t.Fatal("la simulación guardó un mapeo")
+	}
+
+	req.DryRun = false
+	report, err := service.ImportHotels(context.Background(), "Bearer admin", req)
+	if err != nil {
+		t.Fatalf("error importando: %v", err)
+	}
+	if importNames(report.Created) != "NEW" || importNames(report.Updated) != "MAPPED" || importNames(report.Skipped) != "SAME,NEW,NONAME" {
+		t.Fatalf("importación = %+v", report)
+	}
+	reasons := []string{"sin cambios", "duplicado", "sin ID o nombre"}
+	for i, item := range report.Skipped {
+		if !strings.Contains(item.Reason, reasons[i]) {
+			t.Fatalf("%s omitido por %q, se esperaba %q", item.AmadeusHotelID, item.Reason, reasons[i])
+		}
+	}
+
+	created := catalog.hotels[report.Created[0].InternalHotelID]
+	if created.Name != "Hotel Nuevo" || created.Address != "Av Corrientes 123, Buenos Aires, AR" || created.Rating != 4 {
+		t.Fatalf("hotel creado = %+v", created)
+	}
+	if name, ok := mappingName(t, db, "NEW"); !ok || name != "Hotel Nuevo" {
+		t.Fatalf("mapeo del hotel nuevo = %q", name)
+	}
+
+	// El existente toma los datos de Amadeus y conserva lo cargado a mano
+	updated := catalog.hotels["catalog-mapped"]
+	if updated.Name != "Hotel Renovado" || updated.Description != "Descripción propia" || updated.Rating != 0 {
+		t.Fatalf("hotel actualizado = %+v", updated)
+	}
+	if name, _ := mappingName(t, db, "MAPPED"); name != "Hotel Renovado" {
+		t.Fatalf("mapeo del hotel actualizado = %q", name)
+	}
+
+	// Una segunda importación no duplica nada
+	again, err := service.ImportHotels(context.Background(), "Bearer admin", req)
+	if err != nil {
+		t.Fatalf("error reimportando: %v", err)
+	}
+	if len(again.Created) != 0 || len(again.Updated) != 0 || catalog.created != 1 {
+		t.Fatalf("reimportación = %+v, altas en hotel-service = %d", again, catalog.created)
+	}
+}
+
+func TestImportHotelsMissingCatalogHotel(t *testing.T) {
+	db := newTestDB(t)
+	cache, _ := memcachedtest.New(t)
+	_, hotelClient := newCatalogStub(t)
+	mustExec(t, db, "INSERT INTO hotel_mappings (internal_hotel_id, provider, amadeus_hotel_id, hotel_name, city) VALUES ('catalog-gone', 'amadeus', 'GONE', 'Borrado', 'Buenos Aires')")
+
+	service := NewBookingService(db, cache, hotelClient, Options{
+		AmadeusClient: newAmadeusCityStub(t, `[{"hotelId":"GONE","name":"HOTEL BORRADO","cityCode":"BUE"}]`),
+	})
+
+	report, err := service.ImportHotels(context.Background(), "Bearer admin", &models.HotelImportRequest{CityCode: "BUE"})
+	if err != nil {
+		t.Fatalf("error importando: %v", err)
+	}
+	if len(report.Skipped) != 1 || !strings.Contains(report.Skipped[0].Reason, "no existe en hotel-service") {
+		t.Fatalf("importación = %+v, el mapeo a un hotel borrado se informa y no se recrea", report)
+	}
+}
+
+func TestCatalogHotel(t *testing.T) {
+	hotel := models.AmadeusHotel{
+		HotelID:  "PARPLAZA",
+		Name:     "HOTEL DE LA PLAZA",
+		Rating:   "7",
+		CityCode: "PAR",
+		Address:  models.AmadeusHotelAddress{CountryCode: "FR", CityName: "PARIS", PostalCode: "75001", Lines: []string{"1 RUE DE RIVOLI", " "}},
+	}
+
+	imported := catalogHotel(hotel, "")
+	if imported.Name != "Hotel De La Plaza" || imported.City != "Paris" || imported.Rating != 0 {
+		t.Fatalf("hotel = %+v, la categoría fuera de 1-5 se descarta", imported)
+	}
+	if imported.Address != "1 Rue De Rivoli, 75001, Paris, FR" {
+		t.Fatalf("dirección = %q", imported.Address)
+	}
+	if !strings.Contains(imported.Description, "PARPLAZA") {
+		t.Fatalf("descripción = %q", imported.Description)
+	}
+
+	// Sin ciudad pedida ni informada se usa el código de Amadeus
+	hotel.Address.CityName = ""
+	if city := catalogHotel(hotel, "").City; city != "PAR" {
+		t.Fatalf("ciudad = %q, se esperaba el código", city)
+	}
+	if city := catalogHotel(hotel, "París").City; city != "París" {
+		t.Fatalf("ciudad = %q, se esperaba la pedida", city)
+	}
+}
+
+func TestTitleCase(t *testing.T) {
+	tests := map[string]string{
+		"HOTEL PLAZA":      "Hotel Plaza",
+		"  hôtel d'orsay ": "Hôtel D'Orsay",
+		"AV 9 DE JULIO":    "Av 9 De Julio",
+		"":                 "",
+	}
+	for input, want := range tests {
+		if got := titleCase(input); got != want {
+			t.Errorf("titleCase(%q) = %q, se esperaba %q", input, got, want)
+		}
+	}
+}
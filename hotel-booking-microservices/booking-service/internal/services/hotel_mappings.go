@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"booking-service/internal/inventory"
+	"booking-service/internal/models"
+)
+
+// mappingColumns columnas leídas de hotel_mappings
+const mappingColumns = `id, internal_hotel_id, provider, amadeus_hotel_id, hotel_name, city, created_at, updated_at`
+
+// scanHotelMapping escanea una fila con las columnas de mappingColumns
+func scanHotelMapping(row rowScanner) (*models.HotelMapping, error) {
+	var mapping models.HotelMapping
+	err := row.Scan(
+		&mapping.ID, &mapping.InternalHotelID, &mapping.Provider, &mapping.AmadeusHotelID,
+		&mapping.HotelName, &mapping.City, &mapping.CreatedAt, &mapping.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &mapping, nil
+}
+
+// ListHotelMappings lista los mapeos con filtros y paginación
+func (s *BookingService) ListHotelMappings(filter models.HotelMappingFilter) ([]*models.HotelMapping, int, error) {
+	where := []string{"1 = 1"}
+	var args []interface{}
+
+	if filter.Provider != "" {
+		where = append(where, "provider = ?")
+		args = append(args, filter.Provider)
+	}
+	if filter.City != "" {
+		where = append(where, "city LIKE ?")
+		args = append(args, "%"+filter.City+"%")
+	}
+	whereClause := strings.Join(where, " AND ")
+
+	var total int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM hotel_mappings WHERE "+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error contando mapeos: %v", err)
+	}
+
+	query := "SELECT " + mappingColumns + " FROM hotel_mappings WHERE " + whereClause + " ORDER BY id LIMIT ? OFFSET ?"
+	rows, err := s.db.Query(query, append(args, filter.Limit, (filter.Page-1)*filter.Limit)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error obteniendo mapeos: %v", err)
+	}
+	defer rows.Close()
+
+	mappings := []*models.HotelMapping{}
+	for rows.Next() {
+		mapping, err := scanHotelMapping(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error escaneando mapeo: %v", err)
+		}
+		mappings = append(mappings, mapping)
+	}
+
+	return mappings, total, rows.Err()
+}
+
+// GetHotelMapping obtiene un mapeo por ID
+func (s *BookingService) GetHotelMapping(mappingID int) (*models.HotelMapping, error) {
+	mapping, err := scanHotelMapping(s.db.QueryRow("SELECT "+mappingColumns+" FROM hotel_mappings WHERE id = ?", mappingID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("mapeo no encontrado")
+		}
+		return nil, fmt.Errorf("error obteniendo mapeo: %v", err)
+	}
+	return mapping, nil
+}
+
+// CreateHotelMapping crea un mapeo validando el hotel en hotel-service y en el proveedor
+func (s *BookingService) CreateHotelMapping(ctx context.Context, req *models.HotelMappingRequest) (*models.HotelMapping, error) {
+	externalID, err := s.validateHotelMapping(ctx, 0, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.db.Exec(`
+		INSERT INTO hotel_mappings (internal_hotel_id, provider, amadeus_hotel_id, hotel_name, city)
+		VALUES (?, ?, ?, ?, ?)
+	`, req.InternalHotelID, req.Provider, externalID, req.HotelName, req.City)
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			return nil, fmt.Errorf("el hotel %s ya tiene un mapeo", req.InternalHotelID)
+		}
+		return nil, fmt.Errorf("error creando mapeo: %v", err)
+	}
+
+	mappingID, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo ID del mapeo: %v", err)
+	}
+
+	fmt.Printf("🗺️ Hotel %s mapeado a %s\n", req.InternalHotelID, req.Provider)
+	return s.GetHotelMapping(int(mappingID))
+}
+
+// UpdateHotelMapping reemplaza un mapeo. Las reservas existentes conservan el
+// proveedor con el que se crearon.
+func (s *BookingService) UpdateHotelMapping(ctx context.Context, mappingID int, req *models.HotelMappingRequest) (*models.HotelMapping, error) {
+	if _, err := s.GetHotelMapping(mappingID); err != nil {
+		return nil, err
+	}
+
+	externalID, err := s.validateHotelMapping(ctx, mappingID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.Exec(`
+		UPDATE hotel_mappings
+		SET internal_hotel_id = ?, provider = ?, amadeus_hotel_id = ?, hotel_name = ?, city = ?
+		WHERE id = ?
+	`, req.InternalHotelID, req.Provider, externalID, req.HotelName, req.City, mappingID)
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			return nil, fmt.Errorf("el hotel %s ya tiene un mapeo", req.InternalHotelID)
+		}
+		return nil, fmt.Errorf("error actualizando mapeo: %v", err)
+	}
+
+	return s.GetHotelMapping(mappingID)
+}
+
+// DeleteHotelMapping elimina un mapeo; el hotel pasa al proveedor por defecto
+func (s *BookingService) DeleteHotelMapping(mappingID int) error {
+	result, err := s.db.Exec("DELETE FROM hotel_mappings WHERE id = ?", mappingID)
+	if err != nil {
+		return fmt.Errorf("error eliminando mapeo: %v", err)
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("mapeo no encontrado")
+	}
+
+	return nil
+}
+
+// ListUnmappedHotels lista los hoteles activos de hotel-service sin mapeo, que
+// hoy se cotizan con el proveedor por defecto
+func (s *BookingService) ListUnmappedHotels(ctx context.Context) ([]models.UnmappedHotel, error) {
+	hotels, err := s.hotelClient.ListHotels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando hotel-service: %v", err)
+	}
+
+	rows, err := s.db.Query("SELECT internal_hotel_id FROM hotel_mappings")
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo mapeos: %v", err)
+	}
+	defer rows.Close()
+
+	mapped := make(map[string]bool)
+	for rows.Next() {
+		var hotelID string
+		if err := rows.Scan(&hotelID); err != nil {
+			return nil, fmt.Errorf("error escaneando mapeo: %v", err)
+		}
+		mapped[hotelID] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error obteniendo mapeos: %v", err)
+	}
+
+	unmapped := []models.UnmappedHotel{}
+	for _, hotel := range hotels {
+		if mapped[hotel.ID] {
+			continue
+		}
+		unmapped = append(unmapped, models.UnmappedHotel{
+			InternalHotelID: hotel.ID,
+			Name:            hotel.Name,
+			City:            hotel.City,
+			Provider:        s.defaultProvider,
+		})
+	}
+
+	return unmapped, nil
+}
+
+// validateHotelMapping verifica que el hotel exista en hotel-service y que el
+// ID externo sea válido para el proveedor. Completa nombre y ciudad desde
+// hotel-service y devuelve el ID externo a guardar (nil si no aplica).
+func (s *BookingService) validateHotelMapping(ctx context.Context, mappingID int, req *models.HotelMappingRequest) (*string, error) {
+	if _, err := s.providerByName(req.Provider); err != nil {
+		return nil, err
+	}
+
+	hotel, err := s.hotelClient.GetHotel(ctx, req.InternalHotelID)
+	if err != nil {
+		if strings.Contains(err.Error(), "no encontrado") {
+			return nil, fmt.Errorf("hotel %s no encontrado en hotel-service", req.InternalHotelID)
+		}
+		return nil, fmt.Errorf("error consultando hotel-service: %v", err)
+	}
+	if req.HotelName == "" {
+		req.HotelName = hotel.Name
+	}
+	if req.City == "" {
+		req.City = hotel.City
+	}
+
+	var existing int
+	err = s.db.QueryRow("SELECT id FROM hotel_mappings WHERE internal_hotel_id = ? AND id <> ?", req.InternalHotelID, mappingID).Scan(&existing)
+	if err == nil {
+		return nil, fmt.Errorf("el hotel %s ya tiene un mapeo", req.InternalHotelID)
+	} else if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("error verificando mapeo: %v", err)
+	}
+
+	externalID := strings.TrimSpace(req.AmadeusHotelID)
+	switch req.Provider {
+	case inventory.ProviderLocal:
+		if externalID != "" {
+			return nil, fmt.Errorf("ID externo inválido: el proveedor local no usa ID externo")
+		}
+		return nil, nil
+	case inventory.ProviderAmadeus:
+		if externalID == "" {
+			return nil, fmt.Errorf("ID externo inválido: el proveedor amadeus requiere amadeus_hotel_id")
+		}
+		externalID = strings.ToUpper(externalID)
+		if _, err := s.amadeusClient.GetHotel(ctx, externalID); err != nil {
+			if strings.Contains(err.Error(), "no encontrado") {
+				return nil, fmt.Errorf("ID externo inválido: %v", err)
+			}
+			return nil, fmt.Errorf("error consultando Amadeus: %v", err)
+		}
+	}
+
+	if externalID == "" {
+		return nil, nil
+	}
+
+	// Dos hoteles propios no pueden apuntar al mismo hotel del proveedor
+	var otherHotelID string
+	err = s.db.QueryRow(
+		"SELECT internal_hotel_id FROM hotel_mappings WHERE provider = ? AND amadeus_hotel_id = ? AND id <> ? LIMIT 1",
+		req.Provider, externalID, mappingID,
+	).Scan(&otherHotelID)
+	if err == nil {
+		return nil, fmt.Errorf("el hotel %s de %s ya está mapeado al hotel %s", externalID, req.Provider, otherHotelID)
+	} else if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("error verificando mapeo: %v", err)
+	}
+
+	return &externalID, nil
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"booking-service/internal/inventory"
+	"booking-service/internal/models"
+	"booking-service/pkg/amadeus"
+	"booking-service/pkg/memcached/memcachedtest"
+)
+
+// newMappingTestService servicio con hotel-service en memoria y un Amadeus que
+// solo conoce los hoteles MCLONGHM y PARPLAZA
+func newMappingTestService(t *testing.T) *BookingService {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			fmt.Fprint(w, `{"access_token":"test-token","expires_in":1799}`)
+		case "/v1/reference-data/locations/hotels/by-hotels":
+			hotelID := r.URL.Query().Get("hotelIds")
+			if hotelID != "MCLONGHM" && hotelID != "PARPLAZA" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"errors":[{"title":"NOTHING FOUND FOR REQUESTED CITY"}]}`)
+				return
+			}
+			fmt.Fprintf(w, `{"data":[{"hotelId":%q,"name":"HOTEL"}]}`, hotelID)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	client := amadeus.NewClient(server.URL, "id", "secret", amadeus.Options{})
+
+	db := newTestDB(t)
+	// Los mapeos de ejemplo de la migración inicial no interesan aquí
+	mustExec(t, db, "DELETE FROM hotel_mappings")
+	cache, _ := memcachedtest.New(t)
+	catalog, hotelClient := newCatalogStub(t)
+	catalog.hotels["hotel-a"] = models.CatalogHotel{ID: "hotel-a", Name: "Hotel A", City: "Córdoba"}
+	catalog.hotels["hotel-b"] = models.CatalogHotel{ID: "hotel-b", Name: "Hotel B", City: "Mendoza"}
+	catalog.hotels["hotel-c"] = models.CatalogHotel{ID: "hotel-c", Name: "Hotel C", City: "Córdoba"}
+
+	service := NewBookingService(db, cache, hotelClient, Options{
+		AmadeusClient: client,
+		Providers: []inventory.InventoryProvider{
+			inventory.NewAmadeusProvider(client, models.AmadeusPayment{}),
+			inventory.NewLocalProvider(),
+			inventory.NewSimulatedProvider(),
+		},
+		DefaultProvider: inventory.ProviderLocal,
+	})
+	return service
+}
+
+func TestCreateHotelMapping(t *testing.T) {
+	service := newMappingTestService(t)
+	ctx := context.Background()
+
+	mapping, err := service.CreateHotelMapping(ctx, &models.HotelMappingRequest{InternalHotelID: "hotel-a", Provider: "amadeus", AmadeusHotelID: " mclonghm "})
+	if err != nil {
+		t.Fatalf("error creando mapeo: %v", err)
+	}
+	if mapping.AmadeusHotelID == nil || *mapping.AmadeusHotelID != "MCLONGHM" {
+		t.Fatalf("ID externo = %v, se esperaba normalizado", mapping.AmadeusHotelID)
+	}
+	if mapping.HotelName != "Hotel A" || mapping.City != "Córdoba" {
+		t.Fatalf("mapeo = %+v, nombre y ciudad se toman de hotel-service", mapping)
+	}
+
+	local, err := service.CreateHotelMapping(ctx, &models.HotelMappingRequest{InternalHotelID: "hotel-b", Provider: "local", HotelName: "Nombre propio"})
+	if err != nil {
+		t.Fatalf("error creando mapeo local: %v", err)
+	}
+	if local.AmadeusHotelID != nil || local.HotelName != "Nombre propio" {
+		t.Fatalf("mapeo local = %+v", local)
+	}
+
+	tests := []struct {
+		name    string
+		req     models.HotelMappingRequest
+		wantErr string
+	}{
+		{name: "proveedor desconocido", req: models.HotelMappingRequest{InternalHotelID: "hotel-c", Provider: "booking"}, wantErr: "proveedor de inventario desconocido"},
+		{name: "hotel inexistente", req: models.HotelMappingRequest{InternalHotelID: "hotel-x", Provider: "local"}, wantErr: "no encontrado en hotel-service"},
+		{name: "hotel ya mapeado", req: models.HotelMappingRequest{InternalHotelID: "hotel-a", Provider: "local"}, wantErr: "ya tiene un mapeo"},
+		{name: "local con ID externo", req: models.HotelMappingRequest{InternalHotelID: "hotel-c", Provider: "local", AmadeusHotelID: "X"}, wantErr: "no usa ID externo"},
+		{name: "amadeus sin ID externo", req: models.HotelMappingRequest{InternalHotelID: "hotel-c", Provider: "amadeus"}, wantErr: "requiere amadeus_hotel_id"},
+		{name: "amadeus inexistente", req: models.HotelMappingRequest{InternalHotelID: "hotel-c", Provider: "amadeus", AmadeusHotelID: "NOPE"}, wantErr: "ID externo inválido"},
+		{name: "hotel de amadeus ya mapeado", req: models.HotelMappingRequest{InternalHotelID: "hotel-c", Provider: "amadeus", AmadeusHotelID: "MCLONGHM"}, wantErr: "ya está mapeado al hotel hotel-a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			if _, err := service.CreateHotelMapping(ctx, &req); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, se esperaba que contenga %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// El mismo ID externo puede usarse con otro proveedor
+	if _, err := service.CreateHotelMapping(ctx, &models.HotelMappingRequest{InternalHotelID: "hotel-c", Provider: "simulated", AmadeusHotelID: "MCLONGHM"}); err != nil {
+		t.Fatalf("error creando mapeo simulado: %v", err)
+	}
+}
+
+func TestUpdateAndDeleteHotelMapping(t *testing.T) {
+	service := newMappingTestService(t)
+	ctx := context.Background()
+
+	a, err := service.CreateHotelMapping(ctx, &models.HotelMappingRequest{InternalHotelID: "hotel-a", Provider: "amadeus", AmadeusHotelID: "MCLONGHM"})
+	if err != nil {
+		t.Fatalf("error creando mapeo: %v", err)
+	}
+	b, err := service.CreateHotelMapping(ctx, &models.HotelMappingRequest{InternalHotelID: "hotel-b", Provider: "amadeus", AmadeusHotelID: "PARPLAZA"})
+	if err != nil {
+		t.Fatalf("error creando mapeo: %v", err)
+	}
+
+	// Reemplazar el mapeo por el mismo hotel externo no choca consigo mismo
+	updated, err := service.UpdateHotelMapping(ctx, a.ID, &models.HotelMappingRequest{InternalHotelID: "hotel-a", Provider: "amadeus", AmadeusHotelID: "MCLONGHM", HotelName: "Renombrado"})
+	if err != nil {
+		t.Fatalf("error actualizando mapeo: %v", err)
+	}
+	if updated.HotelName != "Renombrado" {
+		t.Fatalf("nombre = %s", updated.HotelName)
+	}
+
+	if _, err := service.UpdateHotelMapping(ctx, a.ID, &models.HotelMappingRequest{InternalHotelID: "hotel-a", Provider: "amadeus", AmadeusHotelID: "PARPLAZA"}); err == nil || !strings.Contains(err.Error(), "ya está mapeado") {
+		t.Fatalf("error = %v, PARPLAZA ya es de hotel-b", err)
+	}
+	if _, err := service.UpdateHotelMapping(ctx, a.ID, &models.HotelMappingRequest{InternalHotelID: "hotel-b", Provider: "local"}); err == nil || !strings.Contains(err.Error(), "ya tiene un mapeo") {
+		t.Fatalf("error = %v, hotel-b ya tiene mapeo", err)
+	}
+	if _, err := service.UpdateHotelMapping(ctx, 999999, &models.HotelMappingRequest{InternalHotelID: "hotel-c", Provider: "local"}); err == nil || !strings.Contains(err.Error(), "mapeo no encontrado") {
+		t.Fatalf("error = %v, se esperaba mapeo no encontrado", err)
+	}
+
+	// Pasar a local borra el ID externo
+	local, err := service.UpdateHotelMapping(ctx, b.ID, &models.HotelMappingRequest{InternalHotelID: "hotel-b", Provider: "local"})
+	if err != nil {
+		t.Fatalf("error pasando a local: %v", err)
+	}
+	if local.Provider != "local" || local.AmadeusHotelID != nil {
+		t.Fatalf("mapeo local = %+v", local)
+	}
+
+	if err := service.DeleteHotelMapping(a.ID); err != nil {
+		t.Fatalf("error eliminando mapeo: %v", err)
+	}
+	if err := service.DeleteHotelMapping(a.ID); err == nil || !strings.Contains(err.Error(), "mapeo no encontrado") {
+		t.Fatalf("error = %v, el mapeo ya no existe", err)
+	}
+	if _, err := service.GetHotelMapping(a.ID); err == nil || !strings.Contains(err.Error(), "mapeo no encontrado") {
+		t.Fatalf("error = %v, el mapeo ya no existe", err)
+	}
+}
+
+func TestListHotelMappings(t *testing.T) {
+	service := newMappingTestService(t)
+	ctx := context.Background()
+
+	for _, req := range []models.HotelMappingRequest{
+		{InternalHotelID: "hotel-a", Provider: "amadeus", AmadeusHotelID: "MCLONGHM"},
+		{InternalHotelID: "hotel-b", Provider: "local"},
+	} {
+		req := req
+		if _, err := service.CreateHotelMapping(ctx, &req); err != nil {
+			t.Fatalf("error creando mapeo de %s: %v", req.InternalHotelID, err)
+		}
+	}
+
+	mappings, total, err := service.ListHotelMappings(models.HotelMappingFilter{Provider: "amadeus", Page: 1, Limit: 10})
+	if err != nil {
+		t.Fatalf("error listando mapeos: %v", err)
+	}
+	if total != 1 || len(mappings) != 1 || mappings[0].InternalHotelID != "hotel-a" {
+		t.Fatalf("mapeos de amadeus = %d de %d", len(mappings), total)
+	}
+
+	mappings, total, err = service.ListHotelMappings(models.HotelMappingFilter{City: "Mendo", Page: 1, Limit: 10})
+	if err != nil {
+		t.Fatalf("error listando mapeos: %v", err)
+	}
+	if total != 1 || mappings[0].InternalHotelID != "hotel-b" {
+		t.Fatalf("mapeos de Mendoza = %d", total)
+	}
+
+	// La segunda página trae el resto y el total no depende de la paginación
+	mappings, total, err = service.ListHotelMappings(models.HotelMappingFilter{Page: 2, Limit: 1})
+	if err != nil {
+		t.Fatalf("error listando mapeos: %v", err)
+	}
+	if total != 2 || len(mappings) != 1 || mappings[0].InternalHotelID != "hotel-b" {
+		t.Fatalf("segunda página = %d mapeos de %d", len(mappings), total)
+	}
+
+	// hotel-c no tiene mapeo: se cotiza con el proveedor por defecto
+	unmapped, err := service.ListUnmappedHotels(ctx)
+	if err != nil {
+		t.Fatalf("error listando hoteles sin mapeo: %v", err)
+	}
+	if len(unmapped) != 1 || unmapped[0].InternalHotelID != "hotel-c" || unmapped[0].Provider != "local" {
+		t.Fatalf("hoteles sin mapeo = %+v", unmapped)
+	}
+}
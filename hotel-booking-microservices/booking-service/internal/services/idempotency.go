@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"booking-service/internal/models"
+)
+
+// idempotencyLockTimeout tiempo que una petición en curso retiene su clave.
+// Pasado ese plazo se asume que el proceso murió y un reintento puede retomarla.
+const idempotencyLockTimeout = time.Minute
+
+// idempotencyColumns columnas leídas de idempotency_keys
+const idempotencyColumns = `id, user_id, idempotency_key, request_hash, status, response_status, response_body, booking_id,
+		       expires_at, created_at, updated_at`
+
+// scanIdempotencyKey escanea una fila con las columnas de idempotencyColumns
+func scanIdempotencyKey(row rowScanner, extra ...interface{}) (*models.IdempotencyKey, error) {
+	var record models.IdempotencyKey
+	dest := []interface{}{
+		&record.ID, &record.UserID, &record.Key, &record.RequestHash, &record.Status, &record.ResponseStatus,
+		&record.ResponseBody, &record.BookingID, &record.ExpiresAt, &record.CreatedAt, &record.UpdatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// BeginIdempotentRequest reserva la clave para una petición del usuario. Devuelve
+// nil si la petición debe ejecutarse, o la clave ya usada si hay que repetir su
+// resultado: la respuesta guardada o, si el proceso murió tras crear la
+// reserva, la reserva vinculada.
+func (s *BookingService) BeginIdempotentRequest(userID int, key, requestHash string) (*models.IdempotencyKey, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error iniciando transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	lock := int(idempotencyLockTimeout.Seconds())
+	ttl := int(s.idempotencyKeyTTL.Seconds())
+
+	_, err = tx.Exec(`
+		INSERT INTO idempotency_keys (user_id, idempotency_key, request_hash, locked_until, expires_at)
+		VALUES (?, ?, ?, NOW() + INTERVAL ? SECOND, NOW() + INTERVAL ? SECOND)
+	`, userID, key, requestHash, lock, ttl)
+	if err == nil {
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("error guardando clave de idempotencia: %v", err)
+		}
+		return nil, nil
+	}
+	if !strings.Contains(err.Error(), "Duplicate entry") {
+		return nil, fmt.Errorf("error guardando clave de idempotencia: %v", err)
+	}
+
+	// La clave ya existe: se bloquea para decidir junto con los reintentos concurrentes
+	var expired, locked bool
+	record, err := scanIdempotencyKey(tx.QueryRow(`
+		SELECT `+idempotencyColumns+`, expires_at <= NOW(), locked_until IS NOT NULL AND locked_until > NOW()
+		FROM idempotency_keys WHERE user_id = ? AND idempotency_key = ? FOR UPDATE
+	`, userID, key), &expired, &locked)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo clave de idempotencia: %v", err)
+	}
+
+	switch {
+	case expired:
+		// Una clave vencida se reutiliza como nueva
+		_, err = tx.Exec(`
+			UPDATE idempotency_keys
+			SET request_hash = ?, status = ?, response_status = NULL, response_body = NULL, booking_id = NULL,
+			    locked_until = NOW() + INTERVAL ? SECOND, expires_at = NOW() + INTERVAL ? SECOND, created_at = NOW()
+			WHERE id = ?
+		`, requestHash, models.IdempotencyInProgress, lock, ttl, record.ID)
+	case record.RequestHash != requestHash:
+		return nil, fmt.Errorf("clave de idempotencia reutilizada con otra petición")
+	case record.Status == models.IdempotencyCompleted:
+		return record, nil
+	case locked:
+		return nil, fmt.Errorf("petición en curso con la misma clave de idempotencia")
+	case record.BookingID != nil:
+		return record, nil
+	default:
+		// El intento anterior murió antes de crear la reserva: se retoma
+		_, err = tx.Exec("UPDATE idempotency_keys SET locked_until = NOW() + INTERVAL ? SECOND WHERE id = ?", lock, record.ID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error guardando clave de idempotencia: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error guardando clave de idempotencia: %v", err)
+	}
+	return nil, nil
+}
+
+// FinishIdempotentRequest guarda la respuesta para repetirla en los reintentos.
+// Un error del servidor que no llegó a crear la reserva libera la clave para
+// que el reintento vuelva a ejecutarse.
+func (s *BookingService) FinishIdempotentRequest(userID int, key string, status int, body []byte) error {
+	if status >= 500 {
+		result, err := s.db.Exec("DELETE FROM idempotency_keys WHERE user_id = ? AND idempotency_key = ? AND booking_id IS NULL", userID, key)
+		if err != nil {
+			return fmt.Errorf("error liberando clave de idempotencia: %v", err)
+		}
+		if affected, _ := result.RowsAffected(); affected > 0 {
+			return nil
+		}
+	}
+
+	_, err := s.db.Exec(`
+		UPDATE idempotency_keys SET status = ?, response_status = ?, response_body = ?, locked_until = NULL
+		WHERE user_id = ? AND idempotency_key = ?
+	`, models.IdempotencyCompleted, status, string(body), userID, key)
+	if err != nil {
+		return fmt.Errorf("error guardando respuesta idempotente: %v", err)
+	}
+	return nil
+}
+
+// linkIdempotencyKey vincula la clave a la reserva en la transacción que la
+// crea, así un reintento encuentra la reserva aunque la respuesta no se guarde
+func linkIdempotencyKey(tx *sql.Tx, userID int, key string, bookingID int64) error {
+	if key == "" {
+		return nil
+	}
+	_, err := tx.Exec("UPDATE idempotency_keys SET booking_id = ? WHERE user_id = ? AND idempotency_key = ?", bookingID, userID, key)
+	if err != nil {
+		return fmt.Errorf("error vinculando clave de idempotencia: %v", err)
+	}
+	return nil
+}
+
+// RunIdempotencyPurge borra periódicamente las claves vencidas
+func (s *BookingService) RunIdempotencyPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := s.db.Exec("DELETE FROM idempotency_keys WHERE expires_at <= NOW()")
+		if err != nil {
+			log.Printf("⚠️ Error borrando claves de idempotencia vencidas: %v", err)
+		} else if purged, _ := result.RowsAffected(); purged > 0 {
+			log.Printf("🧹 %d claves de idempotencia vencidas borradas", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"booking-service/internal/models"
+)
+
+// stayNights devuelve las noches (fechas) ocupadas entre check-in y check-out
+func stayNights(checkIn, checkOut time.Time) []time.Time {
+	var nights []time.Time
+	for night := dateOnly(checkIn); night.Before(dateOnly(checkOut)); night = night.AddDate(0, 0, 1) {
+		nights = append(nights, night)
+	}
+	return nights
+}
+
+// dateOnly descarta la hora de una fecha. Se usa la zona local porque es la
+// que el driver de MySQL utiliza al serializar fechas (loc=Local)
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// normalizeRoomType aplica el tipo de habitación por defecto cuando no se especifica
+func normalizeRoomType(roomType string) string {
+	roomType = strings.TrimSpace(roomType)
+	if roomType == "" {
+		return models.DefaultRoomType
+	}
+	return roomType
+}
+
+// reserveInventory descuenta una habitación por noche dentro de la transacción.
+// Las filas se bloquean en orden de fecha para que dos reservas concurrentes
+// por la última habitación se serialicen y solo una tenga éxito.
+func (s *BookingService) reserveInventory(tx *sql.Tx, hotelID, roomType string, checkIn, checkOut time.Time, totalRooms int) error {
+	nights := stayNights(checkIn, checkOut)
+	if len(nights) == 0 {
+		return fmt.Errorf("fecha inválida: la estadía debe tener al menos una noche")
+	}
+
+	// Crear las noches que todavía no tienen inventario cargado. Con las que ya
+	// existen, ON DUPLICATE KEY toma el lock exclusivo (INSERT IGNORE toma uno
+	// compartido y dos reservas concurrentes se trabarían en el FOR UPDATE)
+	placeholders := make([]string, 0, len(nights))
+	args := make([]interface{}, 0, len(nights)*4)
+	for _, night := range nights {
+		placeholders = append(placeholders, "(?, ?, ?, ?)")
+		args = append(args, hotelID, roomType, night, totalRooms)
+	}
+	_, err := tx.Exec(`
+		INSERT INTO room_inventory (hotel_id, room_type, stay_date, total_rooms)
+		VALUES `+strings.Join(placeholders, ", ")+`
+		ON DUPLICATE KEY UPDATE total_rooms = total_rooms`, args...)
+	if err != nil {
+		return fmt.Errorf("error inicializando inventario: %v", err)
+	}
+
+	// Bloquear las noches de la estadía
+	rows, err := tx.Query(`
+		SELECT stay_date, total_rooms, booked_rooms FROM room_inventory
+		WHERE hotel_id = ? AND room_type = ? AND stay_date >= ? AND stay_date < ?
+		ORDER BY stay_date
+		FOR UPDATE
+	`, hotelID, roomType, nights[0], dateOnly(checkOut))
+	if err != nil {
+		return fmt.Errorf("error bloqueando inventario: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var stayDate time.Time
+		var total, booked int
+		if err := rows.Scan(&stayDate, &total, &booked); err != nil {
+			return fmt.Errorf("error escaneando inventario: %v", err)
+		}
+		if booked >= total {
+			return fmt.Errorf("hotel no disponible: sin habitaciones %s para la noche del %s", roomType, stayDate.Format("2006-01-02"))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error leyendo inventario: %v", err)
+	}
+	rows.Close()
+
+	_, err = tx.Exec(`
+		UPDATE room_inventory SET booked_rooms = booked_rooms + 1
+		WHERE hotel_id = ? AND room_type = ? AND stay_date >= ? AND stay_date < ?
+	`, hotelID, roomType, nights[0], dateOnly(checkOut))
+	if err != nil {
+		return fmt.Errorf("error actualizando inventario: %v", err)
+	}
+
+	return nil
+}
+
+// releaseInventory devuelve al inventario las noches de una reserva
+func (s *BookingService) releaseInventory(tx *sql.Tx, hotelID, roomType string, checkIn, checkOut time.Time) error {
+	_, err := tx.Exec(`
+		UPDATE room_inventory SET booked_rooms = GREATEST(booked_rooms - 1, 0)
+		WHERE hotel_id = ? AND room_type = ? AND stay_date >= ? AND stay_date < ?
+	`, hotelID, normalizeRoomType(roomType), dateOnly(checkIn), dateOnly(checkOut))
+	if err != nil {
+		return fmt.Errorf("error liberando inventario: %v", err)
+	}
+	return nil
+}
+
+// GetRoomsAvailable devuelve la cantidad mínima de habitaciones libres en la estadía.
+// totalRooms es el inventario que se asume para las noches sin fila cargada.
+func (s *BookingService) GetRoomsAvailable(hotelID, roomType string, checkIn, checkOut time.Time, totalRooms int) (int, error) {
+	nights := stayNights(checkIn, checkOut)
+	if len(nights) == 0 {
+		return 0, nil
+	}
+
+	rows, err := s.db.Query(`
+		SELECT total_rooms - booked_rooms FROM room_inventory
+		WHERE hotel_id = ? AND room_type = ? AND stay_date >= ? AND stay_date < ?
+	`, hotelID, normalizeRoomType(roomType), nights[0], dateOnly(checkOut))
+	if err != nil {
+		return 0, fmt.Errorf("error consultando inventario: %v", err)
+	}
+	defer rows.Close()
+
+	minimum := -1
+	found := 0
+	for rows.Next() {
+		var free int
+		if err := rows.Scan(&free); err != nil {
+			return 0, fmt.Errorf("error escaneando inventario: %v", err)
+		}
+		found++
+		if minimum < 0 || free < minimum {
+			minimum = free
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error leyendo inventario: %v", err)
+	}
+
+	// Las noches sin fila todavía tienen el inventario completo
+	if found < len(nights) && (minimum < 0 || totalRooms < minimum) {
+		minimum = totalRooms
+	}
+
+	available := minimum
+	if available < 0 {
+		available = 0
+	}
+
+	return available, nil
+}
+
+// GetInventory lista el inventario de un hotel en un rango de fechas
+func (s *BookingService) GetInventory(hotelID, roomType string, from, to time.Time) ([]*models.RoomInventory, error) {
+	query := `
+		SELECT hotel_id, room_type, stay_date, total_rooms, booked_rooms FROM room_inventory
+		WHERE hotel_id = ? AND stay_date >= ? AND stay_date <= ?
+	`
+	args := []interface{}{hotelID, dateOnly(from), dateOnly(to)}
+	if roomType != "" {
+		query += " AND room_type = ?"
+		args = append(args, roomType)
+	}
+	query += " ORDER BY room_type, stay_date"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo inventario: %v", err)
+	}
+	defer rows.Close()
+
+	var inventory []*models.RoomInventory
+	for rows.Next() {
+		var item models.RoomInventory
+		if err := rows.Scan(&item.HotelID, &item.RoomType, &item.StayDate, &item.TotalRooms, &item.BookedRooms); err != nil {
+			return nil, fmt.Errorf("error escaneando inventario: %v", err)
+		}
+		item.AvailableRooms = item.TotalRooms - item.BookedRooms
+		inventory = append(inventory, &item)
+	}
+
+	return inventory, rows.Err()
+}
+
+// SetInventory carga la cantidad total de habitaciones para un rango de noches
+func (s *BookingService) SetInventory(hotelID string, req *models.SetInventoryRequest) error {
+	if req.ToDate.Before(req.FromDate) {
+		return fmt.Errorf("fecha inválida: to_date debe ser igual o posterior a from_date")
+	}
+
+	roomType := normalizeRoomType(req.RoomType)
+	nights := stayNights(req.FromDate, req.ToDate.AddDate(0, 0, 1))
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error iniciando transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, night := range nights {
+		var booked int
+		err := tx.QueryRow(`
+			SELECT booked_rooms FROM room_inventory
+			WHERE hotel_id = ? AND room_type = ? AND stay_date = ?
+			FOR UPDATE
+		`, hotelID, roomType, night).Scan(&booked)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("error obteniendo inventario: %v", err)
+		}
+
+		if req.TotalRooms < booked {
+			return fmt.Errorf("no se puede reducir el inventario del %s a %d: hay %d habitaciones reservadas",
+				night.Format("2006-01-02"), req.TotalRooms, booked)
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO room_inventory (hotel_id, room_type, stay_date, total_rooms)
+			VALUES (?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE total_rooms = VALUES(total_rooms)
+		`, hotelID, roomType, night, req.TotalRooms)
+		if err != nil {
+			return fmt.Errorf("error guardando inventario: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error confirmando inventario: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"booking-service/internal/inventory"
+	"booking-service/internal/models"
+)
+
+// providerForHotel devuelve el proveedor de inventario del hotel y su ID en ese
+// proveedor. Los hoteles sin mapeo usan el proveedor por defecto.
+func (s *BookingService) providerForHotel(hotelID string) (inventory.InventoryProvider, string, error) {
+	var name string
+	var externalHotelID sql.NullString
+	err := s.db.QueryRow("SELECT provider, amadeus_hotel_id FROM hotel_mappings WHERE internal_hotel_id = ?", hotelID).Scan(&name, &externalHotelID)
+	if err == sql.ErrNoRows {
+		name = s.defaultProvider
+	} else if err != nil {
+		return nil, "", fmt.Errorf("error obteniendo mapeo: %v", err)
+	}
+
+	provider, err := s.providerByName(name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if externalHotelID.Valid && externalHotelID.String != "" {
+		return provider, externalHotelID.String, nil
+	}
+	return provider, hotelID, nil
+}
+
+// providerByName busca un proveedor registrado
+func (s *BookingService) providerByName(name string) (inventory.InventoryProvider, error) {
+	provider, ok := s.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("proveedor de inventario desconocido: %s", name)
+	}
+	return provider, nil
+}
+
+// fallbackAvailability responde con el proveedor de respaldo cuando el del hotel
+// falla (por ejemplo, con el circuito de Amadeus abierto)
+func (s *BookingService) fallbackAvailability(ctx context.Context, failed inventory.InventoryProvider, req *models.AvailabilityRequest) *models.AvailabilityResponse {
+	if fallback, ok := s.providers[s.fallbackProvider]; ok && fallback != failed {
+		fmt.Printf("📝 Usando proveedor de respaldo %s para hotel %s\n", fallback.Name(), req.HotelID)
+		response, err := fallback.Availability(ctx, req.HotelID, req)
+		if err == nil {
+			return response
+		}
+		fmt.Printf("⚠️ Warning: Error consultando proveedor de respaldo %s: %v\n", fallback.Name(), err)
+	}
+
+	fmt.Printf("📝 Simulando disponibilidad para hotel %s\n", req.HotelID)
+	response := s.createLocalAvailability(req)
+	response.Provider = failed.Name()
+	return response
+}
+
+// repriceOffer vuelve a cotizar la oferta en el proveedor antes de reservar.
+// Falla si la oferta ya no existe o si el precio subió respecto del mostrado.
+func (s *BookingService) repriceOffer(ctx context.Context, provider inventory.InventoryProvider, offerID string, quoted *float64) (*inventory.Offer, error) {
+	offer, err := provider.GetOffer(ctx, offerID)
+	if err != nil {
+		if strings.Contains(err.Error(), "no disponible") {
+			return nil, fmt.Errorf("la oferta ya no está disponible")
+		}
+		return nil, fmt.Errorf("error verificando oferta: %v", err)
+	}
+
+	if quoted != nil && offer.Total > *quoted+0.005 {
+		return nil, fmt.Errorf("el precio de la oferta cambió de %.2f a %.2f", *quoted, offer.Total)
+	}
+
+	return offer, nil
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"booking-service/internal/inventory"
+	"booking-service/internal/models"
+	"booking-service/pkg/amadeus"
+)
+
+func TestRepriceOffer(t *testing.T) {
+	// Amadeus de prueba: la oferta OFFER-1 cuesta 120, GONE ya venció
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/security/oauth2/token":
+			fmt.Fprint(w, `{"access_token":"test-token","expires_in":1799}`)
+		case "/v3/shopping/hotel-offers/OFFER-1":
+			fmt.Fprint(w, `{"data":{"available":true,"offers":[{"id":"OFFER-1","price":{"currency":"EUR","total":"120.00"}}]}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	provider := inventory.NewAmadeusProvider(amadeus.NewClient(server.URL, "id", "secret", amadeus.Options{}), models.AmadeusPayment{})
+	service := NewBookingService(nil, nil, nil, Options{})
+
+	price := func(value float64) *float64 { return &value }
+	tests := []struct {
+		name    string
+		offerID string
+		quoted  *float64
+		wantErr string
+	}{
+		{name: "mismo precio", offerID: "OFFER-1", quoted: price(120)},
+		{name: "bajó el precio", offerID: "OFFER-1", quoted: price(150)},
+		{name: "sin precio mostrado", offerID: "OFFER-1"},
+		{name: "subió el precio", offerID: "OFFER-1", quoted: price(100), wantErr: "el precio de la oferta cambió de 100.00 a 120.00"},
+		{name: "oferta vencida", offerID: "GONE", quoted: price(100), wantErr: "la oferta ya no está disponible"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offer, err := service.repriceOffer(context.Background(), provider, tt.offerID, tt.quoted)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, se esperaba que contenga %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if offer.ID != tt.offerID || offer.Total != 120 || offer.Currency != "EUR" {
+				t.Fatalf("oferta = %+v", offer)
+			}
+		})
+	}
+}
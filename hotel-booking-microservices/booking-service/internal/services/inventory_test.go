@@ -0,0 +1,89 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReserveInventoryLastRoomConcurrently(t *testing.T) {
+	db := newTestDB(t)
+	service := NewBookingService(db, nil, nil, Options{})
+
+	checkIn := dateOnly(time.Now().AddDate(0, 1, 0))
+	checkOut := checkIn.AddDate(0, 0, 2)
+
+	// Varias rondas para que las dos transacciones se crucen en distintos puntos:
+	// con la fila de inventario todavía sin crear y con la fila ya cargada
+	for round := 0; round < 10; round++ {
+		hotelID := fmt.Sprintf("hotel-%d", round)
+		if round%2 == 1 {
+			mustExec(t, db, `
+				INSERT INTO room_inventory (hotel_id, room_type, stay_date, total_rooms)
+				VALUES (?, 'standard', ?, 1), (?, 'standard', ?, 1)
+			`, hotelID, checkIn, hotelID, checkIn.AddDate(0, 0, 1))
+		}
+
+		start := make(chan struct{})
+		errs := make([]error, 2)
+		var wg sync.WaitGroup
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				<-start
+
+				tx, err := db.Begin()
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				defer tx.Rollback()
+
+				if errs[i] = service.reserveInventory(tx, hotelID, "standard", checkIn, checkOut, 1); errs[i] == nil {
+					errs[i] = tx.Commit()
+				}
+			}(i)
+		}
+		close(start)
+		wg.Wait()
+
+		succeeded, rejected := 0, 0
+		for _, err := range errs {
+			switch {
+			case err == nil:
+				succeeded++
+			// El handler responde 409 a los errores "no disponible"
+			case strings.Contains(err.Error(), "no disponible"):
+				rejected++
+			default:
+				t.Fatalf("ronda %d: error inesperado: %v", round, err)
+			}
+		}
+		if succeeded != 1 || rejected != 1 {
+			t.Fatalf("ronda %d: %d reservas exitosas y %d rechazadas, se esperaba una de cada una", round, succeeded, rejected)
+		}
+
+		rows, err := db.Query("SELECT booked_rooms FROM room_inventory WHERE hotel_id = ?", hotelID)
+		if err != nil {
+			t.Fatalf("error leyendo inventario: %v", err)
+		}
+		nights := 0
+		for rows.Next() {
+			var booked int
+			if err := rows.Scan(&booked); err != nil {
+				t.Fatalf("error escaneando inventario: %v", err)
+			}
+			if booked != 1 {
+				t.Fatalf("ronda %d: habitaciones reservadas = %d, se esperaba 1", round, booked)
+			}
+			nights++
+		}
+		rows.Close()
+		if nights != 2 {
+			t.Fatalf("ronda %d: %d noches en el inventario, se esperaban 2", round, nights)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"booking-service/pkg/memcached"
+)
+
+// Cantidad de fallos a partir de la cual cada intento fallido agrega una espera creciente
+const (
+	accountBackoffStart = 3
+	ipBackoffStart      = 10
+)
+
+// LoginLockedError indica que el login está bloqueado temporalmente
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("demasiados intentos fallidos, reintentar en %d segundos", e.RetryAfterSeconds())
+}
+
+// RetryAfterSeconds devuelve la espera redondeada hacia arriba, como espera el header Retry-After
+func (e *LoginLockedError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
+
+// loginSubject identifica a quién se le cuentan los intentos fallidos
+type loginSubject struct {
+	scope        string
+	id           string
+	backoffStart int
+	maxAttempts  int
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// dummyPasswordHash devuelve un hash bcrypt con el mismo costo que los reales. Se
+// compara contra él cuando el email no existe para que el tiempo de respuesta
+// no permita distinguir cuentas existentes.
+func dummyPasswordHash() []byte {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	})
+	return dummyHash
+}
+
+// loginSubjects arma los contadores de la cuenta y de la IP de origen. El email
+// se hashea porque puede contener caracteres no válidos en claves de Memcached.
+func (s *BookingService) loginSubjects(email, clientIP string) []loginSubject {
+	subjects := []loginSubject{{
+		scope:        "account",
+		id:           hashToken(strings.ToLower(strings.TrimSpace(email))),
+		backoffStart: accountBackoffStart,
+		maxAttempts:  s.loginMaxAttempts,
+	}}
+	if clientIP != "" {
+		subjects = append(subjects, loginSubject{
+			scope:        "ip",
+			id:           clientIP,
+			backoffStart: ipBackoffStart,
+			maxAttempts:  s.loginIPMaxAttempts,
+		})
+	}
+	return subjects
+}
+
+// checkLoginLock devuelve LoginLockedError si la cuenta o la IP están bloqueadas
+func (s *BookingService) checkLoginLock(subjects []loginSubject) error {
+	var longest time.Duration
+	for _, subject := range subjects {
+		var until time.Time
+		if err := s.cache.Get(memcached.GenerateLoginLockKey(subject.scope, subject.id), &until); err != nil {
+			continue
+		}
+		if wait := time.Until(until); wait > longest {
+			longest = wait
+		}
+	}
+
+	if longest > 0 {
+		return &LoginLockedError{RetryAfter: longest}
+	}
+	return nil
+}
+
+// recordLoginFailure cuenta un intento fallido y aplica la espera que corresponda.
+// Si Memcached falla el login sigue funcionando, sin limitación.
+func (s *BookingService) recordLoginFailure(subjects []loginSubject) {
+	for _, subject := range subjects {
+		failures, err := s.cache.Increment(memcached.GenerateLoginFailuresKey(subject.scope, subject.id), 1, s.loginLockout)
+		if err != nil {
+			log.Printf("⚠️ Error registrando intento de login fallido: %v", err)
+			continue
+		}
+
+		wait := loginBackoff(int(failures), subject.backoffStart, subject.maxAttempts, s.loginLockout)
+		if wait <= 0 {
+			continue
+		}
+
+		if err := s.cache.Set(memcached.GenerateLoginLockKey(subject.scope, subject.id), time.Now().Add(wait), wait); err != nil {
+			log.Printf("⚠️ Error bloqueando login: %v", err)
+			continue
+		}
+
+		if int(failures) >= subject.maxAttempts {
+			log.Printf("🔒 Login bloqueado por %v (%s, %d intentos fallidos)", wait, subject.scope, failures)
+		}
+	}
+}
+
+// resetLoginFailures limpia los intentos fallidos de la cuenta tras un login exitoso.
+// El contador de la IP se mantiene para que no sirva para rotar cuentas.
+func (s *BookingService) resetLoginFailures(subjects []loginSubject) {
+	for _, subject := range subjects {
+		if subject.scope != "account" {
+			continue
+		}
+		s.cache.Delete(memcached.GenerateLoginFailuresKey(subject.scope, subject.id))
+		s.cache.Delete(memcached.GenerateLoginLockKey(subject.scope, subject.id))
+	}
+}
+
+// loginBackoff calcula la espera tras `failures` intentos fallidos: nada hasta
+// backoffStart, luego 1s, 2s, 4s... y el bloqueo completo al llegar a maxAttempts
+func loginBackoff(failures, backoffStart, maxAttempts int, lockout time.Duration) time.Duration {
+	if failures >= maxAttempts {
+		return lockout
+	}
+	if failures < backoffStart {
+		return 0
+	}
+
+	wait := time.Second << uint(failures-backoffStart)
+	if wait > lockout {
+		return lockout
+	}
+	return wait
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"booking-service/internal/models"
+	"booking-service/pkg/memcached"
+	"booking-service/pkg/memcached/memcachedtest"
+)
+
+// newThrottleTestService servicio con el caché de prueba: 5 intentos por
+// cuenta, 20 por IP y 15 minutos de bloqueo
+func newThrottleTestService(t *testing.T) *BookingService {
+	t.Helper()
+	cache, _ := memcachedtest.New(t)
+	return NewBookingService(nil, cache, nil, Options{
+		LoginMaxAttempts:   5,
+		LoginIPMaxAttempts: 20,
+		LoginLockout:       15 * time.Minute,
+	})
+}
+
+// expireLoginLock simula que pasó la espera: el caché de prueba no vence claves
+func expireLoginLock(service *BookingService, subjects []loginSubject) {
+	for _, subject := range subjects {
+		service.cache.Delete(memcached.GenerateLoginLockKey(subject.scope, subject.id))
+	}
+}
+
+// lockWait espera informada por checkLoginLock, 0 si no hay bloqueo
+func lockWait(t *testing.T, service *BookingService, subjects []loginSubject) time.Duration {
+	t.Helper()
+	err := service.checkLoginLock(subjects)
+	if err == nil {
+		return 0
+	}
+	var locked *LoginLockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("error = %v, se esperaba LoginLockedError", err)
+	}
+	return locked.RetryAfter
+}
+
+// assertWait verifica una espera con margen para el tiempo transcurrido
+func assertWait(t *testing.T, got, want time.Duration) {
+	t.Helper()
+	if got > want || got < want-time.Second/2 {
+		t.Fatalf("espera = %v, se esperaba %v", got, want)
+	}
+}
+
+func TestLoginBackoff(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int
+		backoffStart int
+		maxAttempts  int
+		lockout      time.Duration
+		want         time.Duration
+	}{
+		{name: "primer fallo", failures: 1, backoffStart: 3, maxAttempts: 5, lockout: 15 * time.Minute, want: 0},
+		{name: "antes del inicio", failures: 2, backoffStart: 3, maxAttempts: 5, lockout: 15 * time.Minute, want: 0},
+		{name: "inicio", failures: 3, backoffStart: 3, maxAttempts: 5, lockout: 15 * time.Minute, want: time.Second},
+		{name: "se duplica", failures: 4, backoffStart: 3, maxAttempts: 5, lockout: 15 * time.Minute, want: 2 * time.Second},
+		{name: "máximo de intentos", failures: 5, backoffStart: 3, maxAttempts: 5, lockout: 15 * time.Minute, want: 15 * time.Minute},
+		{name: "pasado el máximo", failures: 9, backoffStart: 3, maxAttempts: 5, lockout: 15 * time.Minute, want: 15 * time.Minute},
+		{name: "IP", failures: 13, backoffStart: 10, maxAttempts: 20, lockout: 15 * time.Minute, want: 8 * time.Second},
+		{name: "tope en el bloqueo", failures: 10, backoffStart: 3, maxAttempts: 50, lockout: 30 * time.Second, want: 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := loginBackoff(tt.failures, tt.backoffStart, tt.maxAttempts, tt.lockout); got != tt.want {
+				t.Fatalf("loginBackoff(%d) = %v, se esperaba %v", tt.failures, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoginThrottleLocksAccount(t *testing.T) {
+	service := newThrottleTestService(t)
+	subjects := service.loginSubjects("ana@test.com", "10.0.0.1")
+
+	wants := []time.Duration{0, 0, time.Second, 2 * time.Second, 15 * time.Minute}
+	for _, want := range wants {
+		service.recordLoginFailure(subjects)
+		assertWait(t, lockWait(t, service, subjects), want)
+		expireLoginLock(service, subjects)
+	}
+
+	// El bloqueo es de la cuenta, sin importar mayúsculas ni espacios ni la IP
+	service.recordLoginFailure(subjects)
+	assertWait(t, lockWait(t, service, service.loginSubjects(" ANA@Test.com ", "10.0.0.2")), 15*time.Minute)
+	if wait := lockWait(t, service, service.loginSubjects("otra@test.com", "10.0.0.2")); wait != 0 {
+		t.Fatalf("otra cuenta bloqueada por %v", wait)
+	}
+}
+
+func TestLoginThrottleLimitsIP(t *testing.T) {
+	service := newThrottleTestService(t)
+
+	// Una cuenta distinta por intento: solo cuenta el límite de la IP
+	for i := 1; i <= 20; i++ {
+		subjects := service.loginSubjects(strings.Repeat("x", i)+"@test.com", "10.0.0.1")
+		service.recordLoginFailure(subjects)
+
+		want := loginBackoff(i, ipBackoffStart, 20, 15*time.Minute)
+		assertWait(t, lockWait(t, service, service.loginSubjects("nueva@test.com", "10.0.0.1")), want)
+		if i < 20 {
+			expireLoginLock(service, subjects)
+		}
+	}
+
+	// Otra IP no queda bloqueada
+	if wait := lockWait(t, service, service.loginSubjects("nueva@test.com", "10.0.0.2")); wait != 0 {
+		t.Fatalf("otra IP bloqueada por %v", wait)
+	}
+}
+
+func TestLoginUserThrottle(t *testing.T) {
+	service, db := newBookingTestService(t)
+	service.loginMaxAttempts = 5
+	service.loginIPMaxAttempts = 20
+	service.loginLockout = 15 * time.Minute
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("correcta"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("error hasheando password: %v", err)
+	}
+	mustExec(t, db, "INSERT INTO users (email, password_hash, first_name, last_name, phone) VALUES ('ana@test.com', ?, 'Ana', 'Pérez', '')", string(hash))
+
+	login := func(email, password, ip string) error {
+		_, err := service.LoginUser(&models.LoginRequest{Email: email, Password: password}, ip)
+		return err
+	}
+	account := service.loginSubjects("ana@test.com", "10.0.0.1")
+
+	t.Run("éxito reinicia la cuenta", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			if err := login("ana@test.com", "incorrecta", "10.0.0.1"); err == nil || err.Error() != "credenciales inválidas" {
+				t.Fatalf("error = %v, se esperaba credenciales inválidas", err)
+			}
+		}
+		if err := login("ana@test.com", "correcta", "10.0.0.1"); err != nil {
+			t.Fatalf("error en login: %v", err)
+		}
+
+		var failures int
+		if err := service.cache.Get(memcached.GenerateLoginFailuresKey("account", account[0].id), &failures); err == nil {
+			t.Fatalf("fallos de la cuenta = %d, debían reiniciarse", failures)
+		}
+		// El contador de la IP se mantiene
+		if err := service.cache.Get(memcached.GenerateLoginFailuresKey("ip", "10.0.0.1"), &failures); err != nil || failures != 2 {
+			t.Fatalf("fallos de la IP = %d (%v), se esperaban 2", failures, err)
+		}
+	})
+
+	t.Run("bloqueo rechaza la contraseña correcta", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			login("ana@test.com", "incorrecta", "10.0.0.3")
+		}
+		var locked *LoginLockedError
+		if err := login("ana@test.com", "correcta", "10.0.0.3"); !errors.As(err, &locked) {
+			t.Fatalf("error = %v, se esperaba login bloqueado", err)
+		}
+		assertWait(t, locked.RetryAfter, time.Second)
+		expireLoginLock(service, account)
+	})
+
+	t.Run("email desconocido", func(t *testing.T) {
+		// Mismo error que una contraseña incorrecta y misma cuenta de intentos
+		for i := 0; i < 3; i++ {
+			if err := login("nadie@test.com", "x", "10.0.0.4"); err == nil || err.Error() != "credenciales inválidas" {
+				t.Fatalf("error = %v, se esperaba credenciales inválidas", err)
+			}
+		}
+		var locked *LoginLockedError
+		if err := login("nadie@test.com", "x", "10.0.0.4"); !errors.As(err, &locked) {
+			t.Fatalf("error = %v, se esperaba login bloqueado", err)
+		}
+	})
+}
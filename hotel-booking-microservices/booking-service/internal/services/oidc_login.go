@@ -0,0 +1,233 @@
+package services
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"booking-service/internal/models"
+	"booking-service/pkg/memcached"
+	"booking-service/pkg/oidc"
+)
+
+// OIDCStateTTL tiempo máximo para completar el login en el proveedor
+const OIDCStateTTL = 10 * time.Minute
+
+// oidcLoginState datos guardados entre el inicio del login y el callback
+type oidcLoginState struct {
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	RedirectPath string `json:"redirect_path"`
+}
+
+// OIDCEnabled indica si hay un proveedor OpenID Connect configurado
+func (s *BookingService) OIDCEnabled() bool {
+	return s.oidcProvider != nil
+}
+
+// StartOIDCLogin genera state, nonce y PKCE y devuelve la URL del proveedor
+// junto con el state, que el handler guarda en una cookie del navegador
+func (s *BookingService) StartOIDCLogin(redirectPath string) (string, string, error) {
+	if s.oidcProvider == nil {
+		return "", "", fmt.Errorf("login externo no configurado")
+	}
+
+	state, err := oidc.RandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := oidc.RandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := oidc.RandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	loginState := oidcLoginState{
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		RedirectPath: redirectPath,
+	}
+	if err := s.cache.Set(memcached.GenerateOIDCStateKey(state), loginState, OIDCStateTTL); err != nil {
+		return "", "", fmt.Errorf("error guardando estado de login: %v", err)
+	}
+
+	authURL, err := s.oidcProvider.AuthCodeURL(state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		return "", "", err
+	}
+	return authURL, state, nil
+}
+
+// CompleteOIDCLogin valida el callback del proveedor, vincula la identidad y
+// emite los mismos tokens que LoginUser. Devuelve también la ruta a la que
+// volver en el frontend. browserState es el state guardado en la cookie del
+// navegador: si no coincide, el callback lo abrió otro navegador (login CSRF,
+// por ejemplo un enlace con el código de la cuenta del atacante).
+func (s *BookingService) CompleteOIDCLogin(state, browserState, code string) (*models.AuthResponse, string, error) {
+	if s.oidcProvider == nil {
+		return nil, "", fmt.Errorf("login externo no configurado")
+	}
+
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return nil, "", fmt.Errorf("login externo inválido: el state no corresponde a este navegador")
+	}
+
+	// El state es de un solo uso
+	var loginState oidcLoginState
+	stateKey := memcached.GenerateOIDCStateKey(state)
+	if s.cache.Get(stateKey, &loginState) != nil {
+		return nil, "", fmt.Errorf("login externo inválido: state desconocido o expirado")
+	}
+	s.cache.Delete(stateKey)
+
+	token, err := s.oidcProvider.Exchange(code, loginState.CodeVerifier)
+	if err != nil {
+		return nil, "", fmt.Errorf("login externo inválido: %v", err)
+	}
+
+	claims, err := s.oidcProvider.VerifyIDToken(token.IDToken, loginState.Nonce)
+	if err != nil {
+		return nil, "", fmt.Errorf("login externo inválido: %v", err)
+	}
+
+	user, err := s.resolveOIDCUser(claims)
+	if err != nil {
+		return nil, "", err
+	}
+
+	auth, err := s.issueTokens(user)
+	if err != nil {
+		return nil, "", err
+	}
+	return auth, loginState.RedirectPath, nil
+}
+
+// resolveOIDCUser busca el usuario vinculado a la identidad externa. Si no hay
+// vínculo, lo crea contra el usuario con el mismo email (verificado por el
+// proveedor) o registra un usuario nuevo.
+func (s *BookingService) resolveOIDCUser(claims *oidc.IDTokenClaims) (*models.User, error) {
+	provider := s.oidcProvider.Name()
+
+	var userID int
+	err := s.db.QueryRow(
+		"SELECT user_id FROM user_identities WHERE provider = ? AND subject = ?",
+		provider, claims.Subject,
+	).Scan(&userID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("error buscando identidad: %v", err)
+	}
+
+	if err == nil {
+		if _, err := s.db.Exec("UPDATE user_identities SET last_login_at = NOW() WHERE provider = ? AND subject = ?", provider, claims.Subject); err != nil {
+			return nil, fmt.Errorf("error actualizando identidad: %v", err)
+		}
+		user, err := s.GetUserByID(userID)
+		if err != nil {
+			return nil, fmt.Errorf("login externo inválido: la cuenta vinculada está desactivada")
+		}
+		return user, nil
+	}
+
+	// Sin un email verificado no se puede vincular ni crear la cuenta
+	email := strings.ToLower(strings.TrimSpace(claims.Email))
+	if email == "" || !bool(claims.EmailVerified) {
+		return nil, fmt.Errorf("login externo inválido: el proveedor no informó un email verificado")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error iniciando transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	var isActive, emailVerified, revokeSessions bool
+	err = tx.QueryRow("SELECT id, is_active, email_verified FROM users WHERE email = ? FOR UPDATE", email).Scan(&userID, &isActive, &emailVerified)
+	switch {
+	case err == sql.ErrNoRows:
+		userID, err = createOIDCUser(tx, email, claims)
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, fmt.Errorf("error buscando usuario: %v", err)
+	case !isActive:
+		return nil, fmt.Errorf("login externo inválido: la cuenta está desactivada")
+	case !emailVerified:
+		// Alguien pudo registrar este email sin ser su dueño: se invalida la
+		// contraseña local para que solo el titular verificado pueda entrar
+		if _, err := tx.Exec("UPDATE users SET email_verified = TRUE, password_hash = '!' WHERE id = ?", userID); err != nil {
+			return nil, fmt.Errorf("error actualizando usuario: %v", err)
+		}
+		revokeSessions = true
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO user_identities (user_id, provider, subject, email, last_login_at)
+		VALUES (?, ?, ?, ?, NOW())
+	`, userID, provider, claims.Subject, email)
+	if err != nil {
+		return nil, fmt.Errorf("error vinculando identidad: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error confirmando vinculación: %v", err)
+	}
+
+	if revokeSessions {
+		if err := s.BumpTokenVersion(userID); err != nil {
+			return nil, err
+		}
+	}
+
+	fmt.Printf("🔗 Identidad %s vinculada al usuario %d\n", provider, userID)
+	return s.GetUserByID(userID)
+}
+
+// createOIDCUser registra un usuario sin contraseña local. Puede definir una
+// después con el flujo de recuperación de contraseña.
+func createOIDCUser(tx *sql.Tx, email string, claims *oidc.IDTokenClaims) (int, error) {
+	firstName, lastName := claims.GivenName, claims.FamilyName
+	if firstName == "" && lastName == "" {
+		parts := strings.SplitN(strings.TrimSpace(claims.Name), " ", 2)
+		firstName = parts[0]
+		if len(parts) > 1 {
+			lastName = parts[1]
+		}
+	}
+	if firstName == "" {
+		firstName = strings.SplitN(email, "@", 2)[0]
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO users (email, email_verified, password_hash, first_name, last_name, phone, role)
+		VALUES (?, TRUE, '!', ?, ?, '', ?)
+	`, email, truncate(firstName, 50), truncate(lastName, 50), models.RoleUser)
+	if err != nil {
+		return 0, fmt.Errorf("error creando usuario: %v", err)
+	}
+
+	userID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("error obteniendo ID de usuario: %v", err)
+	}
+	return int(userID), nil
+}
+
+// truncate corta un texto a n runas para respetar el largo de las columnas
+func truncate(value string, n int) string {
+	runes := []rune(value)
+	if len(runes) > n {
+		return string(runes[:n])
+	}
+	return value
+}
+
+// FrontendURL devuelve la URL base del frontend (sin barra final)
+func (s *BookingService) FrontendURL() string {
+	return s.frontendURL
+}
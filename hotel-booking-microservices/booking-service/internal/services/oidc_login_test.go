@@ -0,0 +1,221 @@
+package services
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"booking-service/pkg/memcached"
+	"booking-service/pkg/memcached/memcachedtest"
+	"booking-service/pkg/mysql"
+	"booking-service/pkg/oidc"
+	"booking-service/pkg/oidc/oidctest"
+)
+
+// oidcTestLogin login iniciado contra el proveedor de prueba
+type oidcTestLogin struct {
+	state         string
+	nonce         string
+	codeChallenge string
+}
+
+// newOIDCTestService servicio con el proveedor y el caché de prueba. db puede
+// ser nil en los tests que fallan antes de vincular la identidad.
+func newOIDCTestService(t *testing.T, db *mysql.DB) (*BookingService, *oidctest.IdP, *memcachedtest.Server) {
+	t.Helper()
+
+	idp := oidctest.New(t)
+	cache, cacheServer := memcachedtest.New(t)
+	service := NewBookingService(db, cache, nil, Options{
+		JWTSecret:       "test-secret",
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: time.Hour,
+		OIDCProvider: oidc.NewProvider(oidc.Config{
+			Name:        "test",
+			IssuerURL:   idp.URL,
+			ClientID:    "booking-service",
+			RedirectURL: "http://localhost:8003/api/auth/oidc/callback",
+		}),
+	})
+	return service, idp, cacheServer
+}
+
+// startTestOIDCLogin inicia el login y lee de la URL del proveedor lo que este
+// recibiría: state, nonce y code_challenge
+func startTestOIDCLogin(t *testing.T, service *BookingService, redirectPath string) oidcTestLogin {
+	t.Helper()
+
+	authURL, state, err := service.StartOIDCLogin(redirectPath)
+	if err != nil {
+		t.Fatalf("error iniciando login: %v", err)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("URL de autorización inválida: %v", err)
+	}
+
+	query := parsed.Query()
+	if query.Get("state") != state {
+		t.Fatalf("state de la URL = %q, se esperaba %q", query.Get("state"), state)
+	}
+	return oidcTestLogin{state: state, nonce: query.Get("nonce"), codeChallenge: query.Get("code_challenge")}
+}
+
+// authorize simula que el usuario se autenticó en el proveedor: emite el
+// código para el login con un id_token del subject indicado
+func (login oidcTestLogin) authorize(idp *oidctest.IdP, subject, email string) string {
+	return idp.IssueCode(login.codeChallenge, idp.Sign(jwt.MapClaims{
+		"iss":            idp.URL,
+		"aud":            "booking-service",
+		"sub":            subject,
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"nonce":          login.nonce,
+		"email":          email,
+		"email_verified": true,
+		"given_name":     "Ana",
+		"family_name":    "Pérez",
+	}))
+}
+
+func TestCompleteOIDCLoginRejects(t *testing.T) {
+	t.Run("cookie de otro navegador", func(t *testing.T) {
+		service, idp, cacheServer := newOIDCTestService(t, nil)
+		login := startTestOIDCLogin(t, service, "")
+		code := login.authorize(idp, "user-1", "ana@test.com")
+
+		// El atacante inició su propio login y hace abrir el callback a la víctima
+		victimLogin := startTestOIDCLogin(t, service, "")
+		_, _, err := service.CompleteOIDCLogin(login.state, victimLogin.state, code)
+		if err == nil || !strings.Contains(err.Error(), "no corresponde a este navegador") {
+			t.Fatalf("error = %v, se esperaba rechazo por cookie", err)
+		}
+
+		// El intento rechazado no consume el state del login legítimo
+		if !cacheServer.Has(memcached.GenerateOIDCStateKey(login.state)) {
+			t.Fatalf("el state se borró con un callback de otro navegador")
+		}
+	})
+
+	t.Run("sin cookie", func(t *testing.T) {
+		service, idp, _ := newOIDCTestService(t, nil)
+		login := startTestOIDCLogin(t, service, "")
+		code := login.authorize(idp, "user-1", "ana@test.com")
+
+		_, _, err := service.CompleteOIDCLogin(login.state, "", code)
+		if err == nil || !strings.Contains(err.Error(), "no corresponde a este navegador") {
+			t.Fatalf("error = %v, se esperaba rechazo por cookie", err)
+		}
+	})
+
+	t.Run("sin state", func(t *testing.T) {
+		service, _, _ := newOIDCTestService(t, nil)
+
+		_, _, err := service.CompleteOIDCLogin("", "", "code")
+		if err == nil || !strings.Contains(err.Error(), "login externo inválido") {
+			t.Fatalf("error = %v, se esperaba login inválido", err)
+		}
+	})
+
+	t.Run("state desconocido", func(t *testing.T) {
+		service, _, _ := newOIDCTestService(t, nil)
+
+		_, _, err := service.CompleteOIDCLogin("inventado", "inventado", "code")
+		if err == nil || !strings.Contains(err.Error(), "state desconocido") {
+			t.Fatalf("error = %v, se esperaba state desconocido", err)
+		}
+	})
+
+	t.Run("state reutilizado", func(t *testing.T) {
+		service, idp, _ := newOIDCTestService(t, nil)
+		login := startTestOIDCLogin(t, service, "")
+		login.authorize(idp, "user-1", "ana@test.com")
+
+		_, _, err := service.CompleteOIDCLogin(login.state, login.state, "codigo-invalido")
+		if err == nil || !strings.Contains(err.Error(), "rechazó el código") {
+			t.Fatalf("error = %v, se esperaba código rechazado", err)
+		}
+
+		code := login.authorize(idp, "user-1", "ana@test.com")
+		_, _, err = service.CompleteOIDCLogin(login.state, login.state, code)
+		if err == nil || !strings.Contains(err.Error(), "state desconocido") {
+			t.Fatalf("error = %v, se esperaba state de un solo uso", err)
+		}
+	})
+
+	t.Run("id_token de otro login", func(t *testing.T) {
+		service, idp, _ := newOIDCTestService(t, nil)
+		login := startTestOIDCLogin(t, service, "")
+		other := startTestOIDCLogin(t, service, "")
+
+		// El código es de este login pero el id_token trae el nonce de otro
+		code := idp.IssueCode(login.codeChallenge, idp.Sign(jwt.MapClaims{
+			"iss":   idp.URL,
+			"aud":   "booking-service",
+			"sub":   "user-1",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"nonce": other.nonce,
+		}))
+
+		_, _, err := service.CompleteOIDCLogin(login.state, login.state, code)
+		if err == nil || !strings.Contains(err.Error(), "nonce no coincide") {
+			t.Fatalf("error = %v, se esperaba nonce inválido", err)
+		}
+	})
+
+	t.Run("código de otro login", func(t *testing.T) {
+		service, idp, _ := newOIDCTestService(t, nil)
+		login := startTestOIDCLogin(t, service, "")
+		other := startTestOIDCLogin(t, service, "")
+
+		// Sin el code_verifier del otro login el proveedor no canjea su código
+		code := other.authorize(idp, "user-1", "ana@test.com")
+		_, _, err := service.CompleteOIDCLogin(login.state, login.state, code)
+		if err == nil || !strings.Contains(err.Error(), "rechazó el código") {
+			t.Fatalf("error = %v, se esperaba código rechazado", err)
+		}
+	})
+}
+
+func TestCompleteOIDCLoginLinksIdentity(t *testing.T) {
+	db := newTestDB(t)
+	service, idp, _ := newOIDCTestService(t, db)
+
+	login := startTestOIDCLogin(t, service, "/bookings")
+	code := login.authorize(idp, "user-1", "Ana@Test.com")
+
+	auth, redirectPath, err := service.CompleteOIDCLogin(login.state, login.state, code)
+	if err != nil {
+		t.Fatalf("error completando login: %v", err)
+	}
+	if redirectPath != "/bookings" {
+		t.Fatalf("redirect = %q, se esperaba /bookings", redirectPath)
+	}
+	if auth.Token == "" || auth.RefreshToken == "" {
+		t.Fatalf("faltan tokens en la respuesta: %+v", auth)
+	}
+	if auth.User.Email != "ana@test.com" || auth.User.FirstName != "Ana" {
+		t.Fatalf("usuario creado = %+v", auth.User)
+	}
+
+	// El segundo login del mismo subject entra a la misma cuenta aunque cambie el email
+	login = startTestOIDCLogin(t, service, "")
+	code = login.authorize(idp, "user-1", "ana.perez@test.com")
+	again, _, err := service.CompleteOIDCLogin(login.state, login.state, code)
+	if err != nil {
+		t.Fatalf("error en el segundo login: %v", err)
+	}
+	if again.User.ID != auth.User.ID {
+		t.Fatalf("segundo login en el usuario %d, se esperaba %d", again.User.ID, auth.User.ID)
+	}
+
+	var identities int
+	if err := db.QueryRow("SELECT COUNT(*) FROM user_identities WHERE provider = 'test' AND subject = 'user-1'").Scan(&identities); err != nil {
+		t.Fatalf("error leyendo identidades: %v", err)
+	}
+	if identities != 1 {
+		t.Fatalf("identidades vinculadas = %d, se esperaba 1", identities)
+	}
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"booking-service/internal/models"
+	"booking-service/pkg/mysql"
+)
+
+// EventPublisher publica un mensaje y solo devuelve nil cuando el broker lo confirmó
+type EventPublisher interface {
+	Publish(ctx context.Context, routingKey, messageID string, body []byte) error
+}
+
+// enqueueBookingEvent escribe el evento en outbox_events dentro de la transacción
+// de la reserva: si la transacción se revierte, el evento no existe.
+func enqueueBookingEvent(tx *sql.Tx, eventType string, bookingID int64, previous *models.Booking) error {
+	// Se relee la reserva dentro de la transacción para publicar el estado confirmado
+	booking, err := scanBooking(tx.QueryRow("SELECT "+bookingColumns+" FROM bookings WHERE id = ?", bookingID))
+	if err != nil {
+		return fmt.Errorf("error leyendo reserva para evento: %v", err)
+	}
+
+	event := models.BookingEvent{
+		EventID:    newEventID(),
+		Type:       eventType,
+		OccurredAt: time.Now(),
+		Booking:    booking,
+		Previous:   previous,
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error serializando evento: %v", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO outbox_events (event_id, aggregate_type, aggregate_id, event_type, payload)
+		VALUES (?, 'booking', ?, ?, ?)
+	`, event.EventID, strconv.FormatInt(bookingID, 10), eventType, payload)
+	if err != nil {
+		return fmt.Errorf("error guardando evento: %v", err)
+	}
+
+	return nil
+}
+
+// newEventID genera un UUID v4
+func newEventID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+const (
+	// outboxLease tiempo por el que un relay se queda con un lote
+	outboxLease = time.Minute
+	// outboxPublishTimeout espera máxima por la confirmación de cada evento
+	outboxPublishTimeout = 10 * time.Second
+)
+
+// OutboxRelay publica los eventos pendientes de outbox_events en orden.
+// Un evento se marca como enviado solo después de la confirmación del broker,
+// por lo que un corte entre ambos pasos lo vuelve a publicar (al menos una vez).
+type OutboxRelay struct {
+	db        *mysql.DB
+	publisher EventPublisher
+	interval  time.Duration
+	batchSize int
+}
+
+// NewOutboxRelay crea el relay
+func NewOutboxRelay(db *mysql.DB, publisher EventPublisher, interval time.Duration, batchSize int) *OutboxRelay {
+	return &OutboxRelay{
+		db:        db,
+		publisher: publisher,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+// Run procesa lotes hasta que se cancele el contexto
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	log.Printf("📮 Relay de outbox iniciado (cada %v)", r.interval)
+	for {
+		// Vaciar mientras haya lotes completos, sin esperar al siguiente tick
+		for {
+			published, err := r.relayBatch(ctx)
+			if err != nil {
+				log.Printf("⚠️ Error publicando eventos de outbox: %v", err)
+				break
+			}
+			if published < r.batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// outboxEvent fila pendiente de outbox_events
+type outboxEvent struct {
+	id        int64
+	eventID   string
+	eventType string
+	payload   []byte
+}
+
+// relayBatch publica un lote. Las filas se toman con un UPDATE atómico que
+// las asigna a este relay por outboxLease, así varias instancias del servicio
+// no publican la misma fila y la publicación ocurre fuera de toda transacción:
+// una espera del broker no mantiene locks en outbox_events. Ante el primer
+// error se corta el lote para no alterar el orden de los eventos.
+func (r *OutboxRelay) relayBatch(ctx context.Context) (int, error) {
+	relayID := newEventID()
+	lockedUntil := time.Now().Add(outboxLease)
+
+	_, err := r.db.Exec(`
+		UPDATE outbox_events
+		SET locked_by = ?, locked_until = ?
+		WHERE published_at IS NULL AND (locked_until IS NULL OR locked_until < NOW())
+		ORDER BY id
+		LIMIT ?
+	`, relayID, lockedUntil, r.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("error tomando eventos pendientes: %v", err)
+	}
+
+	// Devolver lo que quede sin publicar para que el próximo lote no espere
+	// a que venza el lease
+	defer func() {
+		_, err := r.db.Exec(`
+			UPDATE outbox_events SET locked_by = NULL, locked_until = NULL
+			WHERE locked_by = ? AND published_at IS NULL
+		`, relayID)
+		if err != nil {
+			log.Printf("⚠️ Error liberando eventos de outbox: %v", err)
+		}
+	}()
+
+	rows, err := r.db.Query(`
+		SELECT id, event_id, event_type, payload FROM outbox_events
+		WHERE locked_by = ? AND published_at IS NULL
+		ORDER BY id
+	`, relayID)
+	if err != nil {
+		return 0, fmt.Errorf("error obteniendo eventos pendientes: %v", err)
+	}
+
+	var events []outboxEvent
+	for rows.Next() {
+		var event outboxEvent
+		if err := rows.Scan(&event.id, &event.eventID, &event.eventType, &event.payload); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("error escaneando evento: %v", err)
+		}
+		events = append(events, event)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error leyendo eventos: %v", err)
+	}
+
+	published := 0
+	var publishErr error
+	for _, event := range events {
+		// Sin tiempo para publicar antes de que venza el lease, otro relay
+		// podría tomar el resto del lote: se deja para el próximo
+		if time.Now().Add(outboxPublishTimeout).After(lockedUntil) {
+			break
+		}
+
+		publishCtx, cancel := context.WithTimeout(ctx, outboxPublishTimeout)
+		publishErr = r.publisher.Publish(publishCtx, event.eventType, event.eventID, event.payload)
+		cancel()
+
+		if publishErr != nil {
+			_, err := r.db.Exec(`
+				UPDATE outbox_events SET attempts = attempts + 1, last_error = ?
+				WHERE id = ? AND locked_by = ?
+			`, publishErr.Error(), event.id, relayID)
+			if err != nil {
+				return published, fmt.Errorf("error registrando fallo de publicación: %v", err)
+			}
+			break
+		}
+
+		_, err := r.db.Exec(`
+			UPDATE outbox_events
+			SET published_at = NOW(), attempts = attempts + 1, last_error = NULL, locked_by = NULL, locked_until = NULL
+			WHERE id = ? AND locked_by = ?
+		`, event.id, relayID)
+		if err != nil {
+			return published, fmt.Errorf("error marcando evento como publicado: %v", err)
+		}
+		published++
+	}
+
+	if published > 0 {
+		log.Printf("📤 %d eventos de reserva publicados", published)
+	}
+	return published, publishErr
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"booking-service/pkg/mysql"
+)
+
+// fakePublisher registra los eventos publicados y falla a partir de failAt
+type fakePublisher struct {
+	published []string
+	failAt    int
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, routingKey, messageID string, body []byte) error {
+	if p.failAt > 0 && len(p.published)+1 >= p.failAt {
+		return errors.New("broker caído")
+	}
+	p.published = append(p.published, messageID)
+	return nil
+}
+
+// insertOutboxEvents carga n eventos pendientes y devuelve sus event_id en orden
+func insertOutboxEvents(t *testing.T, db *mysql.DB, n int) []string {
+	t.Helper()
+
+	ids := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("00000000-0000-4000-8000-%012d", i+1)
+		mustExec(t, db, `
+			INSERT INTO outbox_events (event_id, aggregate_type, aggregate_id, event_type, payload)
+			VALUES (?, 'booking', '1', 'booking.created', '{}')
+		`, id)
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// outboxRow estado de publicación y lease de un evento
+type outboxRow struct {
+	published bool
+	attempts  int
+	lastError *string
+	lockedBy  *string
+}
+
+func readOutboxRow(t *testing.T, db *mysql.DB, eventID string) outboxRow {
+	t.Helper()
+
+	var row outboxRow
+	var publishedAt *time.Time
+	err := db.QueryRow(`
+		SELECT published_at, attempts, last_error, locked_by FROM outbox_events WHERE event_id = ?
+	`, eventID).Scan(&publishedAt, &row.attempts, &row.lastError, &row.lockedBy)
+	if err != nil {
+		t.Fatalf("error leyendo evento %s: %v", eventID, err)
+	}
+	row.published = publishedAt != nil
+	return row
+}
+
+func TestOutboxRelayPublishesBatchInOrder(t *testing.T) {
+	db := newTestDB(t)
+	ids := insertOutboxEvents(t, db, 3)
+	publisher := &fakePublisher{}
+	relay := NewOutboxRelay(db, publisher, time.Second, 2)
+
+	published, err := relay.relayBatch(context.Background())
+	if err != nil || published != 2 {
+		t.Fatalf("publicados = %d error = %v, se esperaba un lote de 2", published, err)
+	}
+	if published, err := relay.relayBatch(context.Background()); err != nil || published != 1 {
+		t.Fatalf("publicados = %d error = %v, se esperaba el evento restante", published, err)
+	}
+
+	if fmt.Sprint(publisher.published) != fmt.Sprint(ids) {
+		t.Fatalf("orden = %v, se esperaba %v", publisher.published, ids)
+	}
+	for _, id := range ids {
+		row := readOutboxRow(t, db, id)
+		if !row.published || row.attempts != 1 || row.lockedBy != nil {
+			t.Fatalf("evento %s = %+v, se esperaba publicado y sin lease", id, row)
+		}
+	}
+
+	if published, err := relay.relayBatch(context.Background()); err != nil || published != 0 {
+		t.Fatalf("publicados = %d error = %v, no quedaban eventos", published, err)
+	}
+}
+
+func TestOutboxRelayStopsAtFirstFailure(t *testing.T) {
+	db := newTestDB(t)
+	ids := insertOutboxEvents(t, db, 3)
+	publisher := &fakePublisher{failAt: 2}
+	relay := NewOutboxRelay(db, publisher, time.Second, 10)
+
+	published, err := relay.relayBatch(context.Background())
+	if err == nil || published != 1 {
+		t.Fatalf("publicados = %d error = %v, se esperaba cortar en el segundo evento", published, err)
+	}
+
+	failed := readOutboxRow(t, db, ids[1])
+	if failed.published || failed.attempts != 1 || failed.lastError == nil {
+		t.Fatalf("evento fallido = %+v, se esperaba el intento registrado", failed)
+	}
+	// El resto del lote se libera para reintentarlo sin esperar el lease
+	for _, id := range ids[1:] {
+		if row := readOutboxRow(t, db, id); row.lockedBy != nil {
+			t.Fatalf("evento %s sigue tomado por %s", id, *row.lockedBy)
+		}
+	}
+	if row := readOutboxRow(t, db, ids[2]); row.attempts != 0 {
+		t.Fatalf("intentos = %d, el evento posterior al fallo no se publica", row.attempts)
+	}
+
+	publisher.failAt = 0
+	if published, err := relay.relayBatch(context.Background()); err != nil || published != 2 {
+		t.Fatalf("publicados = %d error = %v, se esperaba reintentar los pendientes", published, err)
+	}
+	if fmt.Sprint(publisher.published) != fmt.Sprint(ids) {
+		t.Fatalf("orden = %v, se esperaba %v", publisher.published, ids)
+	}
+}
+
+func TestOutboxRelayRespectsLease(t *testing.T) {
+	db := newTestDB(t)
+	ids := insertOutboxEvents(t, db, 2)
+	publisher := &fakePublisher{}
+	relay := NewOutboxRelay(db, publisher, time.Second, 10)
+
+	// Otro relay tiene el primer evento con un lease vigente y el segundo con
+	// uno vencido (el relay se cayó a mitad del lote)
+	mustExec(t, db, "UPDATE outbox_events SET locked_by = 'otro', locked_until = ? WHERE event_id = ?", time.Now().Add(time.Minute), ids[0])
+	mustExec(t, db, "UPDATE outbox_events SET locked_by = 'caido', locked_until = ? WHERE event_id = ?", time.Now().Add(-time.Minute), ids[1])
+
+	published, err := relay.relayBatch(context.Background())
+	if err != nil || published != 1 {
+		t.Fatalf("publicados = %d error = %v, se esperaba solo el de lease vencido", published, err)
+	}
+	if len(publisher.published) != 1 || publisher.published[0] != ids[1] {
+		t.Fatalf("publicados = %v, se esperaba %s", publisher.published, ids[1])
+	}
+	if row := readOutboxRow(t, db, ids[0]); row.published || row.lockedBy == nil || *row.lockedBy != "otro" {
+		t.Fatalf("evento tomado = %+v, no debía tocarse", row)
+	}
+}
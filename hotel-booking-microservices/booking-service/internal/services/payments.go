@@ -0,0 +1,618 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"booking-service/internal/models"
+	"booking-service/pkg/payments"
+)
+
+// Parámetros del worker de pagos
+const (
+	paymentLease     = time.Minute
+	paymentBatchSize = 10
+	webhookTolerance = 5 * time.Minute
+)
+
+// paymentColumns columnas leídas de payments
+const paymentColumns = `id, booking_id, gateway, transaction_id, status, amount, amount_due, captured_amount, refunded_amount, currency,
+		       failure_reason, attempts, last_error, authorized_at, captured_at, created_at, updated_at`
+
+// scanPayment escanea una fila con las columnas de paymentColumns
+func scanPayment(row rowScanner) (*models.Payment, error) {
+	var payment models.Payment
+	err := row.Scan(
+		&payment.ID, &payment.BookingID, &payment.Gateway, &payment.TransactionID, &payment.Status,
+		&payment.Amount, &payment.AmountDue, &payment.CapturedAmount, &payment.RefundedAmount, &payment.Currency,
+		&payment.FailureReason, &payment.Attempts, &payment.LastError, &payment.AuthorizedAt, &payment.CapturedAt,
+		&payment.CreatedAt, &payment.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// roundMoney redondea a centavos
+func roundMoney(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// createPayment registra el pago pendiente en la transacción de la reserva. Si
+// no se autoriza antes del plazo, el worker cancela la reserva.
+func (s *BookingService) createPayment(tx *sql.Tx, bookingID int64, amount float64, currency string) error {
+	_, err := tx.Exec(`
+		INSERT INTO payments (booking_id, gateway, status, amount, amount_due, currency, next_attempt_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, bookingID, s.paymentGateway.Name(), models.PaymentPending, amount, amount, currency, time.Now().Add(s.paymentPendingTimeout))
+	if err != nil {
+		return fmt.Errorf("error registrando pago: %v", err)
+	}
+	return nil
+}
+
+// getBookingPayment obtiene el último pago de una reserva (nil si no tiene)
+func (s *BookingService) getBookingPayment(bookingID int) (*models.Payment, error) {
+	payment, err := scanPayment(s.db.QueryRow("SELECT "+paymentColumns+" FROM payments WHERE booking_id = ? ORDER BY id DESC LIMIT 1", bookingID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo pago: %v", err)
+	}
+	return payment, nil
+}
+
+// AuthorizeBookingPayment autoriza el cobro de una reserva pendiente. Con la
+// autorización la reserva queda confirmada; un rechazo la cancela. Si la
+// pasarela no responde la reserva sigue pendiente y puede reintentarse.
+func (s *BookingService) AuthorizeBookingPayment(ctx context.Context, bookingID int, paymentMethod string) (*models.Booking, error) {
+	if s.paymentGateway == nil {
+		return nil, fmt.Errorf("pagos deshabilitados")
+	}
+
+	payment, err := s.getBookingPayment(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if payment == nil {
+		return nil, fmt.Errorf("pago no encontrado")
+	}
+	if payment.Status != models.PaymentPending {
+		return nil, fmt.Errorf("no se puede pagar: el pago está en estado %s", payment.Status)
+	}
+	if payment.TransactionID != nil {
+		return nil, fmt.Errorf("no se puede pagar: el pago ya está en proceso")
+	}
+
+	booking, err := s.GetBookingByID(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if booking.Status != models.StatusPending {
+		return nil, fmt.Errorf("no se puede pagar una reserva en estado %s", booking.Status)
+	}
+
+	// Tomar el pago: dos pedidos simultáneos no pueden autorizar dos veces
+	lockID := newEventID()
+	result, err := s.db.Exec(`
+		UPDATE payments SET locked_by = ?, locked_until = ?
+		WHERE id = ? AND status = ? AND transaction_id IS NULL AND (locked_until IS NULL OR locked_until < NOW())
+	`, lockID, time.Now().Add(paymentLease), payment.ID, models.PaymentPending)
+	if err != nil {
+		return nil, fmt.Errorf("error bloqueando pago: %v", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, fmt.Errorf("no se puede pagar: el pago ya está en proceso")
+	}
+
+	auth, err := s.paymentGateway.Authorize(ctx, payments.AuthorizeRequest{
+		Reference:     booking.BookingReference,
+		Amount:        payment.AmountDue,
+		Currency:      payment.Currency,
+		PaymentMethod: paymentMethod,
+	})
+	if err != nil {
+		s.unlockPayment(payment.ID, lockID)
+		return nil, fmt.Errorf("error procesando pago: %v", err)
+	}
+
+	switch auth.Status {
+	case payments.StatusAuthorized:
+		err = s.authorizePayment(payment.ID, auth.TransactionID, auth.Amount)
+	case payments.StatusPending:
+		// La pasarela confirma por webhook
+		_, err = s.db.Exec(`
+			UPDATE payments SET transaction_id = ?, locked_by = NULL, locked_until = NULL
+			WHERE id = ? AND locked_by = ?
+		`, auth.TransactionID, payment.ID, lockID)
+		if err != nil {
+			err = fmt.Errorf("error guardando pago: %v", err)
+		}
+	case payments.StatusDeclined:
+		transactionID := auth.TransactionID
+		if err := s.failPayment(payment.ID, &transactionID, models.PaymentDeclined, auth.Message); err != nil {
+			return nil, err
+		}
+		fmt.Printf("💳 Pago de la reserva %s rechazado: %s\n", booking.BookingReference, auth.Message)
+		return nil, fmt.Errorf("pago rechazado: %s", auth.Message)
+	default:
+		s.unlockPayment(payment.ID, lockID)
+		return nil, fmt.Errorf("error procesando pago: estado desconocido %s", auth.Status)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetBookingByID(bookingID)
+}
+
+// unlockPayment libera el pago tomado para autorizar
+func (s *BookingService) unlockPayment(paymentID int, lockID string) {
+	_, err := s.db.Exec("UPDATE payments SET locked_by = NULL, locked_until = NULL WHERE id = ? AND locked_by = ?", paymentID, lockID)
+	if err != nil {
+		log.Printf("⚠️ Error liberando pago %d: %v", paymentID, err)
+	}
+}
+
+// authorizePayment registra la autorización en su propia transacción
+func (s *BookingService) authorizePayment(paymentID int, transactionID string, amount float64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error iniciando transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.authorizePaymentTx(tx, paymentID, transactionID, amount); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error confirmando pago: %v", err)
+	}
+	return nil
+}
+
+// authorizePaymentTx marca el pago autorizado y confirma la reserva pendiente.
+// Recién confirmada la reserva se envía al proveedor externo. Si la reserva se
+// canceló mientras se autorizaba, el worker libera la autorización.
+func (s *BookingService) authorizePaymentTx(tx *sql.Tx, paymentID int, transactionID string, amount float64) error {
+	payment, err := scanPayment(tx.QueryRow("SELECT "+paymentColumns+" FROM payments WHERE id = ? FOR UPDATE", paymentID))
+	if err != nil {
+		return fmt.Errorf("error bloqueando pago: %v", err)
+	}
+	// Un pago vencido todavía puede autorizarse: queda para liberar
+	if payment.Status != models.PaymentPending && payment.Status != models.PaymentFailed {
+		return nil
+	}
+	if amount <= 0 {
+		amount = payment.AmountDue
+	}
+
+	_, err = tx.Exec(`
+		UPDATE payments
+		SET status = ?, transaction_id = ?, amount = ?, failure_reason = NULL, authorized_at = NOW(),
+		    next_attempt_at = NOW(), attempts = 0, locked_by = NULL, locked_until = NULL
+		WHERE id = ?
+	`, models.PaymentAuthorized, transactionID, amount, paymentID)
+	if err != nil {
+		return fmt.Errorf("error guardando autorización: %v", err)
+	}
+
+	booking, err := scanBooking(tx.QueryRow("SELECT "+bookingColumns+" FROM bookings WHERE id = ? FOR UPDATE", payment.BookingID))
+	if err != nil {
+		return fmt.Errorf("error bloqueando reserva: %v", err)
+	}
+	if booking.Status != models.StatusPending {
+		return nil
+	}
+
+	if _, err := tx.Exec("UPDATE bookings SET status = ? WHERE id = ?", models.StatusConfirmed, booking.ID); err != nil {
+		return fmt.Errorf("error confirmando reserva: %v", err)
+	}
+
+	provider, err := s.providerByName(booking.Provider)
+	if err != nil {
+		return err
+	}
+	if provider.ExternalSync() {
+		if err := s.enqueueSyncJob(tx, int64(booking.ID), nil, models.SyncOperationCreate); err != nil {
+			return err
+		}
+	}
+
+	if err := enqueueBookingEvent(tx, models.EventBookingConfirmed, int64(booking.ID), nil); err != nil {
+		return err
+	}
+
+	fmt.Printf("💳 Pago de la reserva %s autorizado\n", booking.BookingReference)
+	return nil
+}
+
+// failPayment registra el rechazo o vencimiento en su propia transacción
+func (s *BookingService) failPayment(paymentID int, transactionID *string, status, reason string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error iniciando transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.failPaymentTx(tx, paymentID, transactionID, status, reason); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error confirmando pago: %v", err)
+	}
+	return nil
+}
+
+// failPaymentTx marca el pago pendiente como rechazado o fallido y cancela la
+// reserva que lo esperaba, devolviendo sus noches al inventario
+func (s *BookingService) failPaymentTx(tx *sql.Tx, paymentID int, transactionID *string, status, reason string) error {
+	payment, err := scanPayment(tx.QueryRow("SELECT "+paymentColumns+" FROM payments WHERE id = ? FOR UPDATE", paymentID))
+	if err != nil {
+		return fmt.Errorf("error bloqueando pago: %v", err)
+	}
+	if payment.Status != models.PaymentPending {
+		return nil
+	}
+
+	_, err = tx.Exec(`
+		UPDATE payments
+		SET status = ?, transaction_id = COALESCE(?, transaction_id), failure_reason = ?, amount_due = 0,
+		    next_attempt_at = NULL, locked_by = NULL, locked_until = NULL
+		WHERE id = ?
+	`, status, transactionID, reason, paymentID)
+	if err != nil {
+		return fmt.Errorf("error guardando pago: %v", err)
+	}
+
+	booking, err := scanBooking(tx.QueryRow("SELECT "+bookingColumns+" FROM bookings WHERE id = ? FOR UPDATE", payment.BookingID))
+	if err != nil {
+		return fmt.Errorf("error bloqueando reserva: %v", err)
+	}
+	if booking.Status != models.StatusPending {
+		return nil
+	}
+
+	cancelReason := "pago no autorizado: " + reason
+	_, err = tx.Exec(`
+		UPDATE bookings SET status = ?, cancelled_at = NOW(), cancellation_reason = ?
+		WHERE id = ?
+	`, models.StatusCancelled, cancelReason, booking.ID)
+	if err != nil {
+		return fmt.Errorf("error cancelando reserva: %v", err)
+	}
+
+	rooms, err := s.lockActiveRooms(tx, booking.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.cancelRooms(tx, booking, rooms, cancelReason); err != nil {
+		return err
+	}
+
+	return enqueueBookingEvent(tx, models.EventBookingCancelled, int64(booking.ID), nil)
+}
+
+// releasePaymentTx descuenta del pago lo que corresponde devolver por una
+// cancelación (lo cancelado menos los cargos de la política). El worker libera
+// la autorización o reintegra la diferencia.
+func (s *BookingService) releasePaymentTx(tx *sql.Tx, booking *models.Booking, refund float64) error {
+	_, err := tx.Exec(`
+		UPDATE payments SET amount_due = GREATEST(amount_due - ?, 0), next_attempt_at = NOW()
+		WHERE booking_id = ? AND status IN (?, ?, ?)
+	`, roundMoney(refund), booking.ID, models.PaymentPending, models.PaymentAuthorized, models.PaymentCaptured)
+	if err != nil {
+		return fmt.Errorf("error actualizando pago: %v", err)
+	}
+	return nil
+}
+
+// adjustPaymentTx ajusta el pago al nuevo total de una reserva modificada. Un
+// pago sin autorizar toma el nuevo total; uno autorizado o cobrado solo puede
+// bajar, y el worker reintegra la diferencia.
+func (s *BookingService) adjustPaymentTx(tx *sql.Tx, bookingID int, total float64) error {
+	payment, err := scanPayment(tx.QueryRow(
+		"SELECT "+paymentColumns+" FROM payments WHERE booking_id = ? AND status IN (?, ?, ?) ORDER BY id DESC LIMIT 1 FOR UPDATE",
+		bookingID, models.PaymentPending, models.PaymentAuthorized, models.PaymentCaptured,
+	))
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error bloqueando pago: %v", err)
+	}
+
+	if payment.Status == models.PaymentPending {
+		if payment.TransactionID != nil {
+			return fmt.Errorf("no se puede modificar: el pago de la reserva está en proceso")
+		}
+		_, err = tx.Exec("UPDATE payments SET amount = ?, amount_due = ? WHERE id = ?", total, total, payment.ID)
+		if err != nil {
+			return fmt.Errorf("error actualizando pago: %v", err)
+		}
+		return nil
+	}
+
+	paid := payment.Amount
+	if payment.Status == models.PaymentCaptured {
+		paid = roundMoney(payment.CapturedAmount - payment.RefundedAmount)
+	}
+	if total > paid {
+		return fmt.Errorf("no se puede modificar: el nuevo total (%.2f) supera el monto pagado (%.2f); cancele y cree una nueva reserva", total, paid)
+	}
+
+	_, err = tx.Exec("UPDATE payments SET amount_due = ?, next_attempt_at = NOW() WHERE id = ?", total, payment.ID)
+	if err != nil {
+		return fmt.Errorf("error actualizando pago: %v", err)
+	}
+	return nil
+}
+
+// RunPaymentSettlement procesa los pagos con operaciones pendientes hasta que
+// se cancele el contexto
+func (s *BookingService) RunPaymentSettlement(ctx context.Context, interval time.Duration) {
+	if s.paymentGateway == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Printf("💳 Worker de pagos iniciado (cada %v)", interval)
+	for {
+		processed, err := s.processPayments(ctx)
+		if err != nil {
+			log.Printf("⚠️ Error procesando pagos: %v", err)
+		}
+
+		// Si el lote vino lleno hay más trabajo: seguir sin esperar
+		if processed == paymentBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processPayments toma un lote de pagos vencidos y los lleva a su estado final
+func (s *BookingService) processPayments(ctx context.Context) (int, error) {
+	workerID := newEventID()
+
+	// Tomar el lote con un UPDATE atómico: dos workers nunca reciben el mismo pago
+	_, err := s.db.Exec(`
+		UPDATE payments
+		SET locked_by = ?, locked_until = ?, attempts = attempts + 1
+		WHERE next_attempt_at <= NOW() AND (locked_until IS NULL OR locked_until < NOW())
+		ORDER BY next_attempt_at
+		LIMIT ?
+	`, workerID, time.Now().Add(paymentLease), paymentBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("error tomando pagos: %v", err)
+	}
+
+	rows, err := s.db.Query("SELECT "+paymentColumns+" FROM payments WHERE locked_by = ?", workerID)
+	if err != nil {
+		return 0, fmt.Errorf("error obteniendo pagos: %v", err)
+	}
+
+	var pending []*models.Payment
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("error escaneando pago: %v", err)
+		}
+		pending = append(pending, payment)
+	}
+	rows.Close()
+
+	for _, payment := range pending {
+		// Cortar la llamada a la pasarela antes de que venza el lease
+		paymentCtx, cancel := context.WithTimeout(ctx, paymentLease/2)
+		next, err := s.settlePayment(paymentCtx, payment)
+		cancel()
+		if err != nil {
+			s.failPaymentAttempt(payment, workerID, err)
+			continue
+		}
+		s.completePaymentAttempt(payment, workerID, next)
+	}
+
+	return len(pending), nil
+}
+
+// settlePayment ejecuta la operación que lleva lo cobrado a amount_due.
+// Devuelve cuándo volver a revisar el pago (cero si no hay nada pendiente).
+func (s *BookingService) settlePayment(ctx context.Context, payment *models.Payment) (time.Time, error) {
+	var bookingStatus string
+	if err := s.db.QueryRow("SELECT status FROM bookings WHERE id = ?", payment.BookingID).Scan(&bookingStatus); err != nil {
+		return time.Time{}, fmt.Errorf("error obteniendo reserva: %v", err)
+	}
+
+	switch payment.Status {
+	case models.PaymentPending:
+		expiresAt := payment.CreatedAt.Add(s.paymentPendingTimeout)
+		switch {
+		case bookingStatus != models.StatusPending:
+			return time.Time{}, s.failPayment(payment.ID, nil, models.PaymentFailed, "reserva cancelada")
+		case time.Now().Before(expiresAt):
+			return expiresAt, nil
+		default:
+			return time.Time{}, s.failPayment(payment.ID, nil, models.PaymentFailed, "autorización vencida")
+		}
+
+	case models.PaymentAuthorized:
+		if payment.TransactionID == nil {
+			return time.Time{}, fmt.Errorf("pago autorizado sin transacción")
+		}
+
+		if payment.AmountDue <= 0 {
+			if _, err := s.paymentGateway.Void(ctx, *payment.TransactionID); err != nil {
+				return time.Time{}, fmt.Errorf("error liberando autorización: %v", err)
+			}
+			_, err := s.db.Exec("UPDATE payments SET status = ? WHERE id = ? AND status = ?", models.PaymentVoided, payment.ID, models.PaymentAuthorized)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("error guardando liberación: %v", err)
+			}
+			log.Printf("💳 Autorización del pago %d liberada", payment.ID)
+			return time.Time{}, nil
+		}
+
+		amount := math.Min(payment.AmountDue, payment.Amount)
+		result, err := s.paymentGateway.Capture(ctx, *payment.TransactionID, amount)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("error capturando pago: %v", err)
+		}
+		if result.Status != payments.StatusCaptured {
+			return time.Time{}, fmt.Errorf("error capturando pago: la pasarela respondió %s", result.Status)
+		}
+		_, err = s.db.Exec(`
+			UPDATE payments SET status = ?, captured_amount = ?, captured_at = NOW()
+			WHERE id = ? AND status = ?
+		`, models.PaymentCaptured, result.Amount, payment.ID, models.PaymentAuthorized)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("error guardando captura: %v", err)
+		}
+		log.Printf("💳 Pago %d capturado: %.2f %s", payment.ID, result.Amount, payment.Currency)
+		return time.Time{}, nil
+
+	case models.PaymentCaptured:
+		charged := roundMoney(payment.CapturedAmount - payment.RefundedAmount)
+		refund := roundMoney(charged - payment.AmountDue)
+		if refund <= 0 || payment.TransactionID == nil {
+			return time.Time{}, nil
+		}
+
+		// La clave cambia con cada reintegro y se repite en los reintentos del mismo
+		key := fmt.Sprintf("refund-%d-%.2f", payment.ID, payment.RefundedAmount)
+		result, err := s.paymentGateway.Refund(ctx, *payment.TransactionID, refund, key)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("error reintegrando pago: %v", err)
+		}
+		if result.Status != payments.StatusRefunded {
+			return time.Time{}, fmt.Errorf("error reintegrando pago: la pasarela respondió %s", result.Status)
+		}
+		_, err = s.db.Exec(`
+			UPDATE payments
+			SET status = IF(refunded_amount + ? >= captured_amount, ?, status), refunded_amount = refunded_amount + ?
+			WHERE id = ? AND refunded_amount = ?
+		`, refund, models.PaymentRefunded, refund, payment.ID, payment.RefundedAmount)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("error guardando reintegro: %v", err)
+		}
+		log.Printf("💳 Pago %d: reintegro de %.2f %s", payment.ID, refund, payment.Currency)
+		return time.Time{}, nil
+	}
+
+	return time.Time{}, nil
+}
+
+// completePaymentAttempt libera el pago. Si amount_due cambió mientras se
+// procesaba (otra cancelación), queda para revisarlo de nuevo enseguida.
+func (s *BookingService) completePaymentAttempt(payment *models.Payment, workerID string, next time.Time) {
+	var nextAttempt interface{}
+	if !next.IsZero() {
+		nextAttempt = next
+	}
+
+	_, err := s.db.Exec(`
+		UPDATE payments
+		SET next_attempt_at = IF(amount_due = ?, ?, NOW()), attempts = 0, last_error = NULL, locked_by = NULL, locked_until = NULL
+		WHERE id = ? AND locked_by = ?
+	`, payment.AmountDue, nextAttempt, payment.ID, workerID)
+	if err != nil {
+		log.Printf("⚠️ Error completando pago %d: %v", payment.ID, err)
+	}
+}
+
+// failPaymentAttempt programa el reintento con backoff exponencial
+func (s *BookingService) failPaymentAttempt(payment *models.Payment, workerID string, attemptErr error) {
+	log.Printf("⚠️ Pago %d falló (intento %d): %v", payment.ID, payment.Attempts, attemptErr)
+
+	_, err := s.db.Exec(`
+		UPDATE payments SET next_attempt_at = ?, last_error = ?, locked_by = NULL, locked_until = NULL
+		WHERE id = ? AND locked_by = ?
+	`, time.Now().Add(syncBackoff(s.syncBaseBackoff, payment.Attempts)), attemptErr.Error(), payment.ID, workerID)
+	if err != nil {
+		log.Printf("⚠️ Error reprogramando pago %d: %v", payment.ID, err)
+	}
+}
+
+// HandlePaymentWebhook procesa una notificación firmada de la pasarela.
+// Devuelve true si el evento ya se había recibido.
+func (s *BookingService) HandlePaymentWebhook(payload []byte, signature string) (bool, error) {
+	if s.paymentGateway == nil {
+		return false, fmt.Errorf("pagos deshabilitados")
+	}
+
+	if err := payments.VerifySignature(s.paymentWebhookSecret, signature, payload, webhookTolerance, time.Now()); err != nil {
+		return false, err
+	}
+
+	var event payments.Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return false, fmt.Errorf("evento inválido: %v", err)
+	}
+	if event.ID == "" || event.TransactionID == "" {
+		return false, fmt.Errorf("evento inválido: faltan id o transaction_id")
+	}
+
+	// Si el pago todavía no tiene la transacción guardada la pasarela reintenta
+	var paymentID int
+	err := s.db.QueryRow("SELECT id FROM payments WHERE gateway = ? AND transaction_id = ?", s.paymentGateway.Name(), event.TransactionID).Scan(&paymentID)
+	if err == sql.ErrNoRows {
+		return false, fmt.Errorf("pago no encontrado")
+	}
+	if err != nil {
+		return false, fmt.Errorf("error obteniendo pago: %v", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("error iniciando transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	// El evento y su efecto se guardan juntos: una entrega repetida no hace nada
+	_, err = tx.Exec(`
+		INSERT INTO payment_events (event_id, payment_id, event_type, payload)
+		VALUES (?, ?, ?, ?)
+	`, event.ID, paymentID, event.Type, string(payload))
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			return true, nil
+		}
+		return false, fmt.Errorf("error registrando evento de pago: %v", err)
+	}
+
+	switch event.Type {
+	case payments.EventAuthorized:
+		err = s.authorizePaymentTx(tx, paymentID, event.TransactionID, event.Amount)
+	case payments.EventDeclined:
+		err = s.failPaymentTx(tx, paymentID, nil, models.PaymentDeclined, event.Message)
+	default:
+		// Captura, liberación y reintegro los inicia el worker: el evento solo se registra
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("error confirmando evento de pago: %v", err)
+	}
+	return false, nil
+}
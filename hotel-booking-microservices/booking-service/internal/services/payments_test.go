@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"booking-service/internal/inventory"
+	"booking-service/internal/models"
+	"booking-service/pkg/mysql"
+	"booking-service/pkg/payments"
+)
+
+// newPaymentsTestService servicio con la pasarela fake y la base de prueba
+func newPaymentsTestService(t *testing.T) (*BookingService, *mysql.DB) {
+	t.Helper()
+
+	db := newTestDB(t)
+	gateway, err := payments.New(payments.Config{Driver: "fake", WebhookSecret: "whsec-test"})
+	if err != nil {
+		t.Fatalf("error creando pasarela: %v", err)
+	}
+
+	service := NewBookingService(db, nil, nil, Options{
+		SyncBaseBackoff:       time.Second,
+		Providers:             []inventory.InventoryProvider{inventory.NewLocalProvider()},
+		DefaultProvider:       "local",
+		PaymentGateway:        gateway,
+		PaymentWebhookSecret:  "whsec-test",
+		PaymentPendingTimeout: 15 * time.Minute,
+	})
+	return service, db
+}
+
+// insertPendingBooking carga una reserva pendiente de pago con una habitación
+// y su noche descontada del inventario. age es la antigüedad del pago.
+func insertPendingBooking(t *testing.T, db *mysql.DB, reference string, age time.Duration) (bookingID, paymentID int) {
+	t.Helper()
+
+	userID := mustExec(t, db, "INSERT INTO users (email, password_hash, first_name, last_name) VALUES (?, 'x', 'Ana', 'Pérez')", reference+"@test.com")
+	checkIn := dateOnly(time.Now().AddDate(0, 1, 0))
+	checkOut := checkIn.AddDate(0, 0, 1)
+
+	booking := mustExec(t, db, `
+		INSERT INTO bookings (user_id, internal_hotel_id, provider, check_in_date, check_out_date, guests, room_type, total_price, currency, status, booking_reference, special_requests)
+		VALUES (?, 'hotel-1', 'local', ?, ?, 2, 'standard', 100, 'ARS', ?, ?, '')
+	`, userID, checkIn, checkOut, models.StatusPending, reference)
+	mustExec(t, db, `
+		INSERT INTO booking_rooms (booking_id, position, room_type, guests, total_price, currency)
+		VALUES (?, 1, 'standard', 2, 100, 'ARS')
+	`, booking)
+	mustExec(t, db, `
+		INSERT INTO room_inventory (hotel_id, room_type, stay_date, total_rooms, booked_rooms)
+		VALUES ('hotel-1', 'standard', ?, 5, 1)
+	`, checkIn)
+
+	createdAt := time.Now().Add(-age)
+	payment := mustExec(t, db, `
+		INSERT INTO payments (booking_id, gateway, status, amount, amount_due, currency, next_attempt_at, created_at)
+		VALUES (?, 'fake', ?, 100, 100, 'ARS', ?, ?)
+	`, booking, models.PaymentPending, createdAt.Add(15*time.Minute), createdAt)
+
+	return int(booking), int(payment)
+}
+
+func TestSettlementExpiresPendingPayment(t *testing.T) {
+	service, db := newPaymentsTestService(t)
+	bookingID, paymentID := insertPendingBooking(t, db, "BK-EXPIRED", time.Hour)
+
+	processed, err := service.processPayments(context.Background())
+	if err != nil {
+		t.Fatalf("error procesando pagos: %v", err)
+	}
+	if processed != 1 {
+		t.Fatalf("pagos procesados = %d, se esperaba 1", processed)
+	}
+
+	var status, reason string
+	var amountDue float64
+	if err := db.QueryRow("SELECT status, failure_reason, amount_due FROM payments WHERE id = ?", paymentID).Scan(&status, &reason, &amountDue); err != nil {
+		t.Fatalf("error leyendo pago: %v", err)
+	}
+	if status != models.PaymentFailed || reason != "autorización vencida" || amountDue != 0 {
+		t.Fatalf("pago = (%s, %s, %.2f), se esperaba (failed, autorización vencida, 0)", status, reason, amountDue)
+	}
+
+	booking, err := service.GetBookingByID(bookingID)
+	if err != nil {
+		t.Fatalf("error leyendo reserva: %v", err)
+	}
+	if booking.Status != models.StatusCancelled {
+		t.Fatalf("reserva en estado %s, se esperaba cancelled", booking.Status)
+	}
+	if booking.CancelReason == nil || !strings.Contains(*booking.CancelReason, "autorización vencida") {
+		t.Fatalf("motivo de cancelación inesperado: %v", booking.CancelReason)
+	}
+
+	var booked int
+	if err := db.QueryRow("SELECT booked_rooms FROM room_inventory WHERE hotel_id = 'hotel-1'").Scan(&booked); err != nil {
+		t.Fatalf("error leyendo inventario: %v", err)
+	}
+	if booked != 0 {
+		t.Fatalf("habitaciones reservadas = %d, la noche debía volver al inventario", booked)
+	}
+}
+
+func TestSettlementKeepsPaymentWithinTimeout(t *testing.T) {
+	service, db := newPaymentsTestService(t)
+	bookingID, paymentID := insertPendingBooking(t, db, "BK-WAITING", time.Minute)
+	// Adelantar el próximo intento para que el worker lo tome antes del plazo
+	mustExec(t, db, "UPDATE payments SET next_attempt_at = NOW() - INTERVAL 1 SECOND WHERE id = ?", paymentID)
+
+	if _, err := service.processPayments(context.Background()); err != nil {
+		t.Fatalf("error procesando pagos: %v", err)
+	}
+
+	var status string
+	var expires bool
+	err := db.QueryRow("SELECT status, next_attempt_at > NOW() FROM payments WHERE id = ?", paymentID).Scan(&status, &expires)
+	if err != nil {
+		t.Fatalf("error leyendo pago: %v", err)
+	}
+	if status != models.PaymentPending || !expires {
+		t.Fatalf("pago = (%s, próximo intento futuro %v), se esperaba pendiente hasta el vencimiento", status, expires)
+	}
+
+	booking, err := service.GetBookingByID(bookingID)
+	if err != nil {
+		t.Fatalf("error leyendo reserva: %v", err)
+	}
+	if booking.Status != models.StatusPending {
+		t.Fatalf("reserva en estado %s, se esperaba pending", booking.Status)
+	}
+}
+
+func TestAuthorizeBookingPaymentRejectsSecondAuthorization(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("pago ya autorizado", func(t *testing.T) {
+		service, db := newPaymentsTestService(t)
+		bookingID, _ := insertPendingBooking(t, db, "BK-TWICE", time.Minute)
+
+		booking, err := service.AuthorizeBookingPayment(ctx, bookingID, "tok_visa")
+		if err != nil {
+			t.Fatalf("error autorizando: %v", err)
+		}
+		if booking.Status != models.StatusConfirmed {
+			t.Fatalf("reserva en estado %s, se esperaba confirmed", booking.Status)
+		}
+
+		_, err = service.AuthorizeBookingPayment(ctx, bookingID, "tok_visa")
+		if err == nil || !strings.Contains(err.Error(), "no se puede pagar") {
+			t.Fatalf("error = %v, se esperaba rechazo del segundo pago", err)
+		}
+	})
+
+	t.Run("autorización en curso", func(t *testing.T) {
+		service, db := newPaymentsTestService(t)
+		bookingID, paymentID := insertPendingBooking(t, db, "BK-LOCKED", time.Minute)
+		// Otro pedido tomó el pago y todavía espera a la pasarela
+		mustExec(t, db, "UPDATE payments SET locked_by = 'otro', locked_until = NOW() + INTERVAL 1 MINUTE WHERE id = ?", paymentID)
+
+		_, err := service.AuthorizeBookingPayment(ctx, bookingID, "tok_visa")
+		if err == nil || !strings.Contains(err.Error(), "ya está en proceso") {
+			t.Fatalf("error = %v, se esperaba pago en proceso", err)
+		}
+
+		var transactionID *string
+		if err := db.QueryRow("SELECT transaction_id FROM payments WHERE id = ?", paymentID).Scan(&transactionID); err != nil {
+			t.Fatalf("error leyendo pago: %v", err)
+		}
+		if transactionID != nil {
+			t.Fatalf("la pasarela no debía recibir un segundo pedido (transacción %s)", *transactionID)
+		}
+	})
+
+	t.Run("esperando confirmación de la pasarela", func(t *testing.T) {
+		service, db := newPaymentsTestService(t)
+		bookingID, _ := insertPendingBooking(t, db, "BK-PENDING", time.Minute)
+
+		if _, err := service.AuthorizeBookingPayment(ctx, bookingID, "tok_pending"); err != nil {
+			t.Fatalf("error autorizando: %v", err)
+		}
+
+		_, err := service.AuthorizeBookingPayment(ctx, bookingID, "tok_visa")
+		if err == nil || !strings.Contains(err.Error(), "ya está en proceso") {
+			t.Fatalf("error = %v, se esperaba pago en proceso", err)
+		}
+	})
+}
@@ -0,0 +1,377 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"booking-service/internal/models"
+	"booking-service/internal/pricing"
+)
+
+// quoteStay cotiza una estadía con el calendario de tarifas y las reglas del hotel
+func (s *BookingService) quoteStay(hotelID, roomType string, room *models.RoomType, checkIn, checkOut time.Time, guests int) (*pricing.Quote, string, error) {
+	nights := stayNights(checkIn, checkOut)
+	if len(nights) == 0 {
+		return &pricing.Quote{}, "ARS", nil
+	}
+
+	// Tarifa por defecto: la del tipo de habitación o la fórmula histórica por huésped
+	fallbackRate := float64(15000 + (guests-1)*5000)
+	if room != nil && room.BaseRate > 0 {
+		fallbackRate = room.BaseRate
+	}
+	currency := roomCurrency(room)
+
+	rates, err := s.loadRoomRates(hotelID, roomType, nights[0], dateOnly(checkOut))
+	if err != nil {
+		return nil, "", err
+	}
+
+	occupancy, err := s.loadOccupancy(hotelID, roomType, nights[0], dateOnly(checkOut))
+	if err != nil {
+		return nil, "", err
+	}
+
+	rules, err := s.loadPricingRules(hotelID, roomType)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Todas las noches se suman en una sola moneda: las que no tienen tarifa
+	// cargada usan la del tipo de habitación
+	inputs := make([]pricing.Night, 0, len(nights))
+	stayCurrency := ""
+	for _, night := range nights {
+		key := night.Format("2006-01-02")
+		input := pricing.Night{Date: night, BaseRate: fallbackRate, Occupancy: occupancy[key]}
+		nightCurrency := currency
+		if rate, ok := rates[key]; ok {
+			input.BaseRate = rate.BaseRate
+			nightCurrency = rate.Currency
+		}
+		if stayCurrency == "" {
+			stayCurrency = nightCurrency
+		} else if nightCurrency != stayCurrency {
+			return nil, "", fmt.Errorf("moneda inválida: las noches de %s en hotel %s tienen tarifas en %s y %s", roomType, hotelID, stayCurrency, nightCurrency)
+		}
+		inputs = append(inputs, input)
+	}
+
+	return pricing.Calculate(inputs, rules), stayCurrency, nil
+}
+
+// roomCurrency moneda en la que se cotizan las noches sin tarifa cargada
+func roomCurrency(room *models.RoomType) string {
+	if room != nil && room.BaseRate > 0 && room.Currency != "" {
+		return strings.ToUpper(room.Currency)
+	}
+	return "ARS"
+}
+
+// loadRoomRates obtiene las tarifas base cargadas por noche
+func (s *BookingService) loadRoomRates(hotelID, roomType string, from, to time.Time) (map[string]models.RoomRate, error) {
+	rows, err := s.db.Query(`
+		SELECT hotel_id, room_type, stay_date, base_rate, currency FROM room_rates
+		WHERE hotel_id = ? AND room_type = ? AND stay_date >= ? AND stay_date < ?
+	`, hotelID, roomType, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo tarifas: %v", err)
+	}
+	defer rows.Close()
+
+	rates := make(map[string]models.RoomRate)
+	for rows.Next() {
+		var rate models.RoomRate
+		if err := rows.Scan(&rate.HotelID, &rate.RoomType, &rate.StayDate, &rate.BaseRate, &rate.Currency); err != nil {
+			return nil, fmt.Errorf("error escaneando tarifa: %v", err)
+		}
+		rates[rate.StayDate.Format("2006-01-02")] = rate
+	}
+
+	return rates, rows.Err()
+}
+
+// loadOccupancy calcula el porcentaje de ocupación por noche según el inventario
+func (s *BookingService) loadOccupancy(hotelID, roomType string, from, to time.Time) (map[string]float64, error) {
+	rows, err := s.db.Query(`
+		SELECT stay_date, total_rooms, booked_rooms FROM room_inventory
+		WHERE hotel_id = ? AND room_type = ? AND stay_date >= ? AND stay_date < ?
+	`, hotelID, roomType, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo ocupación: %v", err)
+	}
+	defer rows.Close()
+
+	occupancy := make(map[string]float64)
+	for rows.Next() {
+		var stayDate time.Time
+		var total, booked int
+		if err := rows.Scan(&stayDate, &total, &booked); err != nil {
+			return nil, fmt.Errorf("error escaneando ocupación: %v", err)
+		}
+		if total > 0 {
+			occupancy[stayDate.Format("2006-01-02")] = float64(booked) * 100 / float64(total)
+		}
+	}
+
+	return occupancy, rows.Err()
+}
+
+// loadPricingRules obtiene las reglas activas del hotel que aplican al tipo de habitación
+func (s *BookingService) loadPricingRules(hotelID, roomType string) ([]models.PricingRule, error) {
+	query := `
+		SELECT id, hotel_id, room_type, rule_type, start_date, end_date, min_nights, min_occupancy,
+		       adjustment_pct, fixed_rate, description, created_at
+		FROM pricing_rules
+		WHERE hotel_id = ? AND is_active = TRUE
+	`
+	args := []interface{}{hotelID}
+	if roomType != "" {
+		query += " AND (room_type IS NULL OR room_type = ?)"
+		args = append(args, roomType)
+	}
+	query += " ORDER BY id"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo reglas de precio: %v", err)
+	}
+	defer rows.Close()
+
+	var rules []models.PricingRule
+	for rows.Next() {
+		var rule models.PricingRule
+		err := rows.Scan(
+			&rule.ID, &rule.HotelID, &rule.RoomType, &rule.RuleType, &rule.StartDate, &rule.EndDate,
+			&rule.MinNights, &rule.MinOccupancy, &rule.AdjustmentPct, &rule.FixedRate, &rule.Description, &rule.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error escaneando regla de precio: %v", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// insertPriceLines guarda el desglose por noche de una habitación de la reserva
+func (s *BookingService) insertPriceLines(tx *sql.Tx, bookingID, roomID int64, nights []models.NightlyPrice, currency string) error {
+	for _, night := range nights {
+		adjustments, err := json.Marshal(night.Adjustments)
+		if err != nil {
+			return fmt.Errorf("error serializando ajustes: %v", err)
+		}
+
+		stayDate, err := time.ParseInLocation("2006-01-02", night.Date, time.Local)
+		if err != nil {
+			return fmt.Errorf("error parseando fecha de línea de precio: %v", err)
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO booking_price_lines (booking_id, booking_room_id, stay_date, base_rate, adjustments, price, currency)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, bookingID, roomID, stayDate, night.BaseRate, string(adjustments), night.Price, currency)
+		if err != nil {
+			return fmt.Errorf("error guardando línea de precio: %v", err)
+		}
+	}
+	return nil
+}
+
+// getPriceLines obtiene el desglose por noche de una reserva
+func (s *BookingService) getPriceLines(bookingID int) ([]models.BookingPriceLine, error) {
+	rows, err := s.db.Query(`
+		SELECT id, booking_id, booking_room_id, stay_date, base_rate, adjustments, price, currency
+		FROM booking_price_lines WHERE booking_id = ? ORDER BY booking_room_id, stay_date
+	`, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo líneas de precio: %v", err)
+	}
+	defer rows.Close()
+
+	var lines []models.BookingPriceLine
+	for rows.Next() {
+		var line models.BookingPriceLine
+		var adjustments string
+		err := rows.Scan(&line.ID, &line.BookingID, &line.BookingRoomID, &line.StayDate, &line.BaseRate, &adjustments, &line.Price, &line.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("error escaneando línea de precio: %v", err)
+		}
+		if err := json.Unmarshal([]byte(adjustments), &line.Adjustments); err != nil {
+			return nil, fmt.Errorf("error deserializando ajustes: %v", err)
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
+
+// GetRates lista el calendario de tarifas de un hotel
+func (s *BookingService) GetRates(hotelID, roomType string, from, to time.Time) ([]models.RoomRate, error) {
+	query := `
+		SELECT hotel_id, room_type, stay_date, base_rate, currency FROM room_rates
+		WHERE hotel_id = ? AND stay_date >= ? AND stay_date <= ?
+	`
+	args := []interface{}{hotelID, dateOnly(from), dateOnly(to)}
+	if roomType != "" {
+		query += " AND room_type = ?"
+		args = append(args, roomType)
+	}
+	query += " ORDER BY room_type, stay_date"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo tarifas: %v", err)
+	}
+	defer rows.Close()
+
+	var rates []models.RoomRate
+	for rows.Next() {
+		var rate models.RoomRate
+		if err := rows.Scan(&rate.HotelID, &rate.RoomType, &rate.StayDate, &rate.BaseRate, &rate.Currency); err != nil {
+			return nil, fmt.Errorf("error escaneando tarifa: %v", err)
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, rows.Err()
+}
+
+// SetRates carga la tarifa base de un tipo de habitación para un rango de noches
+func (s *BookingService) SetRates(hotelID string, req *models.SetRatesRequest) error {
+	if req.ToDate.Before(req.FromDate) {
+		return fmt.Errorf("fecha inválida: to_date debe ser igual o posterior a from_date")
+	}
+
+	roomType := normalizeRoomType(req.RoomType)
+
+	// Las tarifas van en la moneda del tipo de habitación: las noches sin
+	// tarifa se cotizan con su precio base y una estadía no mezcla monedas
+	expected := ""
+	if rooms, err := s.getRoomTypes(hotelID); err != nil {
+		fmt.Printf("⚠️ Warning: No se pudo validar la moneda del tipo de habitación para hotel %s: %v\n", hotelID, err)
+	} else {
+		for i := range rooms {
+			if strings.EqualFold(rooms[i].Name, roomType) {
+				expected = roomCurrency(&rooms[i])
+			}
+		}
+	}
+
+	currency := strings.ToUpper(req.Currency)
+	if currency == "" {
+		currency = expected
+	}
+	if currency == "" {
+		currency = "ARS"
+	}
+	if expected != "" && currency != expected {
+		return fmt.Errorf("moneda inválida: las tarifas de %s deben cargarse en %s", roomType, expected)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error iniciando transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Tampoco se mezcla con las tarifas ya cargadas fuera del rango
+	var otherCurrency string
+	err = tx.QueryRow(`
+		SELECT currency FROM room_rates
+		WHERE hotel_id = ? AND room_type = ? AND currency <> ? AND (stay_date < ? OR stay_date > ?)
+		LIMIT 1
+	`, hotelID, roomType, currency, dateOnly(req.FromDate), dateOnly(req.ToDate)).Scan(&otherCurrency)
+	if err == nil {
+		return fmt.Errorf("moneda inválida: %s ya tiene tarifas cargadas en %s", roomType, otherCurrency)
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("error verificando moneda de tarifas: %v", err)
+	}
+
+	for _, night := range stayNights(req.FromDate, req.ToDate.AddDate(0, 0, 1)) {
+		_, err := tx.Exec(`
+			INSERT INTO room_rates (hotel_id, room_type, stay_date, base_rate, currency)
+			VALUES (?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE base_rate = VALUES(base_rate), currency = VALUES(currency)
+		`, hotelID, roomType, night, req.BaseRate, currency)
+		if err != nil {
+			return fmt.Errorf("error guardando tarifa: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error confirmando tarifas: %v", err)
+	}
+
+	return nil
+}
+
+// GetPricingRules lista las reglas de precio activas de un hotel
+func (s *BookingService) GetPricingRules(hotelID string) ([]models.PricingRule, error) {
+	return s.loadPricingRules(hotelID, "")
+}
+
+// CreatePricingRule crea una regla de precio para un hotel
+func (s *BookingService) CreatePricingRule(hotelID string, req *models.CreatePricingRuleRequest) (*models.PricingRule, error) {
+	rule := &models.PricingRule{
+		HotelID:       hotelID,
+		RuleType:      req.RuleType,
+		StartDate:     req.StartDate,
+		EndDate:       req.EndDate,
+		MinNights:     req.MinNights,
+		MinOccupancy:  req.MinOccupancy,
+		AdjustmentPct: req.AdjustmentPct,
+		FixedRate:     req.FixedRate,
+		Description:   req.Description,
+	}
+	if req.RoomType != "" {
+		rule.RoomType = &req.RoomType
+	}
+
+	if err := pricing.ValidateRule(rule); err != nil {
+		return nil, err
+	}
+
+	var startDate, endDate interface{}
+	if rule.StartDate != nil {
+		startDate = dateOnly(*rule.StartDate)
+	}
+	if rule.EndDate != nil {
+		endDate = dateOnly(*rule.EndDate)
+	}
+
+	result, err := s.db.Exec(`
+		INSERT INTO pricing_rules (hotel_id, room_type, rule_type, start_date, end_date, min_nights, min_occupancy, adjustment_pct, fixed_rate, description)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, hotelID, rule.RoomType, rule.RuleType, startDate, endDate, rule.MinNights, rule.MinOccupancy, rule.AdjustmentPct, rule.FixedRate, rule.Description)
+	if err != nil {
+		return nil, fmt.Errorf("error creando regla de precio: %v", err)
+	}
+
+	ruleID, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo ID de regla: %v", err)
+	}
+
+	rule.ID = int(ruleID)
+	rule.CreatedAt = time.Now()
+	return rule, nil
+}
+
+// DeletePricingRule desactiva una regla de precio
+func (s *BookingService) DeletePricingRule(hotelID string, ruleID int) error {
+	result, err := s.db.Exec("UPDATE pricing_rules SET is_active = FALSE WHERE id = ? AND hotel_id = ? AND is_active = TRUE", ruleID, hotelID)
+	if err != nil {
+		return fmt.Errorf("error eliminando regla de precio: %v", err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("regla de precio no encontrada")
+	}
+
+	return nil
+}
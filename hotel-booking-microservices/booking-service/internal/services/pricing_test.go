@@ -0,0 +1,79 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"booking-service/internal/models"
+)
+
+func TestQuoteStayRejectsMixedCurrencies(t *testing.T) {
+	service, db := newBookingTestService(t)
+	standard := &testRoomTypes[0]
+	checkIn := dateOnly(time.Now().AddDate(0, 1, 0))
+	checkOut := checkIn.AddDate(0, 0, 2)
+
+	// Sin tarifas cargadas se cotiza con el precio base del tipo de habitación
+	quote, currency, err := service.quoteStay(testHotelID, "standard", standard, checkIn, checkOut, 2)
+	if err != nil {
+		t.Fatalf("error cotizando: %v", err)
+	}
+	if quote.Total != 200 || currency != "ARS" {
+		t.Fatalf("cotización = %v %s, se esperaba 200 ARS", quote.Total, currency)
+	}
+
+	// Una noche en USD y la otra con el precio base en ARS no se suman
+	mustExec(t, db, "INSERT INTO room_rates (hotel_id, room_type, stay_date, base_rate, currency) VALUES (?, 'standard', ?, 80, 'USD')", testHotelID, checkIn)
+	if _, _, err := service.quoteStay(testHotelID, "standard", standard, checkIn, checkOut, 2); err == nil || !strings.Contains(err.Error(), "moneda inválida") {
+		t.Fatalf("error = %v, se esperaba moneda inválida", err)
+	}
+
+	// Con todas las noches en USD la estadía se cotiza en USD
+	mustExec(t, db, "INSERT INTO room_rates (hotel_id, room_type, stay_date, base_rate, currency) VALUES (?, 'standard', ?, 90, 'USD')", testHotelID, checkIn.AddDate(0, 0, 1))
+	quote, currency, err = service.quoteStay(testHotelID, "standard", standard, checkIn, checkOut, 2)
+	if err != nil {
+		t.Fatalf("error cotizando: %v", err)
+	}
+	if quote.Total != 170 || currency != "USD" {
+		t.Fatalf("cotización = %v %s, se esperaba 170 USD", quote.Total, currency)
+	}
+}
+
+func TestSetRatesRequiresRoomCurrency(t *testing.T) {
+	service, db := newBookingTestService(t)
+	from := dateOnly(time.Now().AddDate(0, 1, 0))
+
+	err := service.SetRates(testHotelID, &models.SetRatesRequest{RoomType: "standard", FromDate: from, ToDate: from, BaseRate: 80, Currency: "USD"})
+	if err == nil || !strings.Contains(err.Error(), "moneda inválida") {
+		t.Fatalf("error = %v, se esperaba moneda inválida", err)
+	}
+
+	// Sin moneda se usa la del tipo de habitación
+	if err := service.SetRates(testHotelID, &models.SetRatesRequest{RoomType: "standard", FromDate: from, ToDate: from.AddDate(0, 0, 1), BaseRate: 120}); err != nil {
+		t.Fatalf("error cargando tarifas: %v", err)
+	}
+	rates, err := service.GetRates(testHotelID, "standard", from, from.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("error listando tarifas: %v", err)
+	}
+	if len(rates) != 2 || rates[0].Currency != "ARS" || rates[1].Currency != "ARS" {
+		t.Fatalf("tarifas = %+v, se esperaban dos noches en ARS", rates)
+	}
+
+	// Un tipo que el hotel no ofrece no puede mezclar monedas entre rangos
+	if err := service.SetRates(testHotelID, &models.SetRatesRequest{RoomType: "loft", FromDate: from, ToDate: from, BaseRate: 80, Currency: "USD"}); err != nil {
+		t.Fatalf("error cargando tarifas: %v", err)
+	}
+	err = service.SetRates(testHotelID, &models.SetRatesRequest{RoomType: "loft", FromDate: from.AddDate(0, 0, 1), ToDate: from.AddDate(0, 0, 1), BaseRate: 80, Currency: "EUR"})
+	if err == nil || !strings.Contains(err.Error(), "moneda inválida") {
+		t.Fatalf("error = %v, se esperaba moneda inválida", err)
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM room_rates WHERE room_type = 'loft'").Scan(&count); err != nil {
+		t.Fatalf("error contando tarifas: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("tarifas de loft = %d, se esperaba 1", count)
+	}
+}
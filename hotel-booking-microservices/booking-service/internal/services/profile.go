@@ -0,0 +1,142 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"booking-service/internal/models"
+)
+
+// ChangePassword cambia la contraseña verificando la actual. Cierra las demás
+// sesiones y devuelve tokens nuevos para la sesión que hizo el cambio.
+func (s *BookingService) ChangePassword(userID int, currentPassword, newPassword string) (*models.AuthResponse, error) {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(currentPassword)); err != nil {
+		return nil, fmt.Errorf("contraseña actual incorrecta")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("error hasheando password: %v", err)
+	}
+
+	if _, err := s.db.Exec("UPDATE users SET password_hash = ? WHERE id = ?", string(hashedPassword), userID); err != nil {
+		return nil, fmt.Errorf("error actualizando password: %v", err)
+	}
+
+	if err := s.BumpTokenVersion(userID); err != nil {
+		return nil, err
+	}
+
+	// Releer para emitir el token con la nueva versión
+	user, err = s.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user)
+}
+
+// ExportUserData reúne el perfil y todas las reservas del usuario
+func (s *BookingService) ExportUserData(userID int) (*models.AccountExport, error) {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	bookings, err := s.GetUserBookings(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, booking := range bookings {
+		lines, err := s.getPriceLines(booking.ID)
+		if err != nil {
+			return nil, err
+		}
+		booking.PriceLines = lines
+	}
+
+	return &models.AccountExport{
+		User:       user,
+		Bookings:   bookings,
+		ExportedAt: time.Now(),
+	}, nil
+}
+
+// DeleteAccount anonimiza los datos personales del usuario. La fila de users y
+// sus reservas se conservan porque bookings la referencia con ON DELETE RESTRICT.
+func (s *BookingService) DeleteAccount(userID int, password string) error {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return fmt.Errorf("contraseña actual incorrecta")
+	}
+
+	if user.Role == models.RoleAdmin {
+		if err := s.ensureOtherAdmin(userID); err != nil {
+			return err
+		}
+	}
+
+	// No se elimina una cuenta con estadías por delante: el hotel necesita los datos del huésped
+	var activeBookings int
+	err = s.db.QueryRow(`
+		SELECT COUNT(*) FROM bookings
+		WHERE user_id = ? AND status IN (?, ?) AND check_out_date >= ?
+	`, userID, models.StatusPending, models.StatusConfirmed, dateOnly(time.Now())).Scan(&activeBookings)
+	if err != nil {
+		return fmt.Errorf("error verificando reservas activas: %v", err)
+	}
+	if activeBookings > 0 {
+		return fmt.Errorf("no se puede eliminar la cuenta: tiene %d reservas activas, cancelalas primero", activeBookings)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error iniciando transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	// El hash "!" no corresponde a ninguna contraseña, por lo que la cuenta queda inaccesible
+	_, err = tx.Exec(`
+		UPDATE users SET
+			email = CONCAT('deleted-', id, '@deleted.invalid'),
+			email_verified = FALSE,
+			password_hash = '!',
+			first_name = 'Usuario',
+			last_name = 'Eliminado',
+			phone = '',
+			date_of_birth = NULL,
+			is_active = FALSE,
+			deleted_at = NOW()
+		WHERE id = ?
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("error anonimizando usuario: %v", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM user_tokens WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("error eliminando tokens: %v", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM user_identities WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("error eliminando identidades externas: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error confirmando eliminación: %v", err)
+	}
+
+	fmt.Printf("🗑️ Cuenta %d eliminada y anonimizada\n", userID)
+	return s.BumpTokenVersion(userID)
+}
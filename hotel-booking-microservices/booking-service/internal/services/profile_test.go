@@ -0,0 +1,182 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"booking-service/internal/models"
+	"booking-service/pkg/mysql"
+)
+
+// setPassword guarda la contraseña del usuario con el costo mínimo de bcrypt
+func setPassword(t *testing.T, db *mysql.DB, userID int, password string) {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("error hasheando contraseña: %v", err)
+	}
+	mustExec(t, db, "UPDATE users SET password_hash = ? WHERE id = ?", string(hash), userID)
+}
+
+// bookingUserID devuelve el usuario que cargó insertBooking para la reserva
+func bookingUserID(t *testing.T, db *mysql.DB, bookingID int) int {
+	t.Helper()
+
+	var userID int
+	if err := db.QueryRow("SELECT user_id FROM bookings WHERE id = ?", bookingID).Scan(&userID); err != nil {
+		t.Fatalf("error leyendo reserva: %v", err)
+	}
+	return userID
+}
+
+func TestChangePassword(t *testing.T) {
+	service, db := newBookingTestService(t)
+	user, auth := loginTestUser(t, service, db, "password@test.com")
+	setPassword(t, db, user.ID, "anterior-123")
+
+	if _, err := service.ChangePassword(user.ID, "otra-cosa", "nueva-12345"); err == nil || !strings.Contains(err.Error(), "contraseña actual incorrecta") {
+		t.Fatalf("error = %v, se esperaba contraseña actual incorrecta", err)
+	}
+	if _, err := service.ParseAccessToken(auth.Token); err != nil {
+		t.Fatalf("un intento fallido no debe cerrar la sesión: %v", err)
+	}
+
+	changed, err := service.ChangePassword(user.ID, "anterior-123", "nueva-12345")
+	if err != nil {
+		t.Fatalf("error cambiando contraseña: %v", err)
+	}
+
+	stored, err := service.GetUserByID(user.ID)
+	if err != nil {
+		t.Fatalf("error obteniendo usuario: %v", err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(stored.PasswordHash), []byte("nueva-12345")) != nil {
+		t.Fatal("la contraseña nueva no quedó guardada")
+	}
+
+	// Las demás sesiones se cierran; la que hizo el cambio recibe tokens nuevos
+	if _, err := service.ParseAccessToken(auth.Token); err == nil {
+		t.Fatal("el access token anterior al cambio sigue siendo válido")
+	}
+	if _, err := service.RefreshTokens(auth.RefreshToken); err == nil {
+		t.Fatal("el refresh token anterior al cambio sigue siendo válido")
+	}
+	if _, err := service.ParseAccessToken(changed.Token); err != nil {
+		t.Fatalf("el access token nuevo no es válido: %v", err)
+	}
+}
+
+func TestExportUserData(t *testing.T) {
+	service, db := newBookingTestService(t)
+	bookingID := insertBooking(t, db, testBooking{Reference: "BK-EXPORT", Nights: 2})
+	userID := bookingUserID(t, db, bookingID)
+
+	booking, err := service.GetBookingByID(bookingID)
+	if err != nil {
+		t.Fatalf("error obteniendo reserva: %v", err)
+	}
+	for night := booking.CheckInDate; night.Before(booking.CheckOutDate); night = night.AddDate(0, 0, 1) {
+		mustExec(t, db, `
+			INSERT INTO booking_price_lines (booking_id, booking_room_id, stay_date, base_rate, adjustments, price, currency)
+			VALUES (?, ?, ?, 100, '[]', 100, 'ARS')
+		`, bookingID, booking.Rooms[0].ID, night)
+	}
+
+	export, err := service.ExportUserData(userID)
+	if err != nil {
+		t.Fatalf("error exportando datos: %v", err)
+	}
+
+	if export.User == nil || export.User.Email != "bk-export@test.com" {
+		t.Fatalf("usuario exportado = %+v", export.User)
+	}
+	if len(export.Bookings) != 1 || export.Bookings[0].BookingReference != "BK-EXPORT" {
+		t.Fatalf("reservas exportadas = %d, se esperaba BK-EXPORT", len(export.Bookings))
+	}
+	if len(export.Bookings[0].PriceLines) != 2 {
+		t.Fatalf("líneas de precio = %d, se esperaba una por noche", len(export.Bookings[0].PriceLines))
+	}
+	if export.ExportedAt.IsZero() {
+		t.Fatal("falta la fecha de exportación")
+	}
+}
+
+func TestDeleteAccount(t *testing.T) {
+	service, db := newBookingTestService(t)
+	bookingID := insertBooking(t, db, testBooking{Reference: "BK-DELETE"})
+	userID := bookingUserID(t, db, bookingID)
+	setPassword(t, db, userID, "secreta-123")
+	mustExec(t, db, "INSERT INTO user_identities (user_id, provider, subject, email) VALUES (?, 'google', 'sub-1', 'bk-delete@test.com')", userID)
+
+	user, err := service.GetUserByID(userID)
+	if err != nil {
+		t.Fatalf("error obteniendo usuario: %v", err)
+	}
+	auth, err := service.issueTokens(user)
+	if err != nil {
+		t.Fatalf("error emitiendo tokens: %v", err)
+	}
+
+	if err := service.DeleteAccount(userID, "otra-cosa"); err == nil || !strings.Contains(err.Error(), "contraseña actual incorrecta") {
+		t.Fatalf("error = %v, se esperaba contraseña actual incorrecta", err)
+	}
+
+	// Una estadía por delante impide eliminar la cuenta
+	if err := service.DeleteAccount(userID, "secreta-123"); err == nil || !strings.Contains(err.Error(), "reservas activas") {
+		t.Fatalf("error = %v, se esperaba reservas activas", err)
+	}
+
+	mustExec(t, db, "UPDATE bookings SET status = ? WHERE id = ?", models.StatusCancelled, bookingID)
+	if err := service.DeleteAccount(userID, "secreta-123"); err != nil {
+		t.Fatalf("error eliminando cuenta: %v", err)
+	}
+
+	var email, firstName, passwordHash string
+	var isActive bool
+	var deletedAt *time.Time
+	err = db.QueryRow("SELECT email, first_name, password_hash, is_active, deleted_at FROM users WHERE id = ?", userID).
+		Scan(&email, &firstName, &passwordHash, &isActive, &deletedAt)
+	if err != nil {
+		t.Fatalf("error leyendo usuario: %v", err)
+	}
+	if !strings.HasSuffix(email, "@deleted.invalid") || firstName != "Usuario" || passwordHash != "!" || isActive || deletedAt == nil {
+		t.Fatalf("usuario = %s %s activo = %v, se esperaba anonimizado", email, firstName, isActive)
+	}
+
+	var identities int
+	if err := db.QueryRow("SELECT COUNT(*) FROM user_identities WHERE user_id = ?", userID).Scan(&identities); err != nil {
+		t.Fatalf("error contando identidades: %v", err)
+	}
+	if identities != 0 {
+		t.Fatalf("identidades = %d, se esperaba eliminarlas", identities)
+	}
+
+	// La reserva se conserva y la sesión queda cerrada
+	if _, err := service.GetBookingByID(bookingID); err != nil {
+		t.Fatalf("la reserva debía conservarse: %v", err)
+	}
+	if _, err := service.ParseAccessToken(auth.Token); err == nil {
+		t.Fatal("el access token sigue siendo válido tras eliminar la cuenta")
+	}
+}
+
+func TestDeleteAccountLastAdmin(t *testing.T) {
+	service, db := newBookingTestService(t)
+	adminID := insertUser(t, db, "admin@test.com")
+	setPassword(t, db, adminID, "secreta-123")
+	mustExec(t, db, "UPDATE users SET role = ? WHERE id = ?", models.RoleAdmin, adminID)
+
+	if err := service.DeleteAccount(adminID, "secreta-123"); err == nil || !strings.Contains(err.Error(), "sin administradores") {
+		t.Fatalf("error = %v, el único administrador no puede eliminar su cuenta", err)
+	}
+
+	otherID := insertUser(t, db, "otro-admin@test.com")
+	mustExec(t, db, "UPDATE users SET role = ? WHERE id = ?", models.RoleAdmin, otherID)
+	if err := service.DeleteAccount(adminID, "secreta-123"); err != nil {
+		t.Fatalf("error eliminando cuenta con otro administrador activo: %v", err)
+	}
+}
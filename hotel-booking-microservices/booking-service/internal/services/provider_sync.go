@@ -0,0 +1,424 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"booking-service/internal/inventory"
+	"booking-service/internal/models"
+)
+
+// Parámetros de la cola de sincronización con el proveedor
+const (
+	syncJobLease     = 2 * time.Minute
+	syncJobBatchSize = 10
+	syncMaxBackoff   = time.Hour
+)
+
+// syncJobColumns columnas leídas en las consultas de trabajos de sincronización
+const syncJobColumns = `id, booking_id, booking_room_id, operation, status, attempts, max_attempts, next_run_at, last_error, created_at, updated_at, completed_at`
+
+// scanSyncJob escanea una fila con las columnas de syncJobColumns
+func scanSyncJob(row rowScanner) (*models.ProviderSyncJob, error) {
+	var job models.ProviderSyncJob
+	err := row.Scan(
+		&job.ID, &job.BookingID, &job.BookingRoomID, &job.Operation, &job.Status, &job.Attempts, &job.MaxAttempts,
+		&job.NextRunAt, &job.LastError, &job.CreatedAt, &job.UpdatedAt, &job.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// enqueueSyncJob agrega un trabajo de sincronización dentro de la transacción de
+// la reserva y marca la reserva como pendiente de sincronizar. roomID limita el
+// trabajo a una habitación; nil abarca todas.
+func (s *BookingService) enqueueSyncJob(tx *sql.Tx, bookingID int64, roomID *int, operation string) error {
+	_, err := tx.Exec(`
+		INSERT INTO provider_sync_jobs (booking_id, booking_room_id, operation, max_attempts)
+		VALUES (?, ?, ?, ?)
+	`, bookingID, roomID, operation, s.syncMaxAttempts)
+	if err != nil {
+		return fmt.Errorf("error encolando sincronización: %v", err)
+	}
+
+	if _, err := tx.Exec("UPDATE bookings SET sync_status = ? WHERE id = ?", models.SyncPending, bookingID); err != nil {
+		return fmt.Errorf("error actualizando estado de sincronización: %v", err)
+	}
+
+	return nil
+}
+
+// RunProviderSync procesa la cola de sincronización hasta que se cancele el contexto
+func (s *BookingService) RunProviderSync(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Printf("🔄 Worker de sincronización con proveedor iniciado (cada %v)", interval)
+	for {
+		processed, err := s.processSyncJobs(ctx)
+		if err != nil {
+			log.Printf("⚠️ Error procesando sincronizaciones: %v", err)
+		}
+
+		// Si el lote vino lleno hay más trabajo: seguir sin esperar
+		if processed == syncJobBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processSyncJobs toma un lote de trabajos vencidos (o con lease expirado) y los ejecuta
+func (s *BookingService) processSyncJobs(ctx context.Context) (int, error) {
+	workerID := newEventID()
+
+	// Tomar el lote con un UPDATE atómico: dos workers nunca reciben el mismo trabajo
+	_, err := s.db.Exec(`
+		UPDATE provider_sync_jobs
+		SET status = ?, locked_by = ?, locked_until = ?, attempts = attempts + 1
+		WHERE (status = ? AND next_run_at <= NOW()) OR (status = ? AND locked_until < NOW())
+		ORDER BY next_run_at
+		LIMIT ?
+	`, models.JobRunning, workerID, time.Now().Add(syncJobLease), models.JobPending, models.JobRunning, syncJobBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("error tomando trabajos: %v", err)
+	}
+
+	rows, err := s.db.Query("SELECT "+syncJobColumns+" FROM provider_sync_jobs WHERE locked_by = ? AND status = ?", workerID, models.JobRunning)
+	if err != nil {
+		return 0, fmt.Errorf("error obteniendo trabajos: %v", err)
+	}
+
+	var jobs []*models.ProviderSyncJob
+	for rows.Next() {
+		job, err := scanSyncJob(rows)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("error escaneando trabajo: %v", err)
+		}
+		jobs = append(jobs, job)
+	}
+	rows.Close()
+
+	for _, job := range jobs {
+		// Cortar la llamada al proveedor antes de que venza el lease del trabajo
+		jobCtx, cancel := context.WithTimeout(ctx, syncJobLease/2)
+		err := s.runSyncJob(jobCtx, job)
+		cancel()
+		if err != nil {
+			s.failSyncJob(job, workerID, err)
+			continue
+		}
+		s.completeSyncJob(job, workerID)
+	}
+
+	return len(jobs), nil
+}
+
+// runSyncJob ejecuta la operación contra el proveedor
+func (s *BookingService) runSyncJob(ctx context.Context, job *models.ProviderSyncJob) error {
+	booking, err := s.GetBookingByID(job.BookingID)
+	if err != nil {
+		return err
+	}
+
+	provider, err := s.providerByName(booking.Provider)
+	if err != nil {
+		return err
+	}
+
+	switch job.Operation {
+	case models.SyncOperationCreate:
+		if booking.Status == models.StatusCancelled {
+			return nil // se canceló antes de llegar al proveedor: no hay nada que crear
+		}
+
+		_, externalHotelID, err := s.providerForHotel(booking.InternalHotelID)
+		if err != nil {
+			return err
+		}
+
+		guests := booking.GuestDetails
+		if len(guests) == 0 {
+			// Reservas anteriores a los datos de huéspedes: el titular es el usuario
+			if guests, err = s.resolveGuests(booking.UserID, booking.Guests, nil); err != nil {
+				return err
+			}
+		}
+
+		// Cada habitación es una reserva en el proveedor. Se guarda apenas se
+		// crea, así un reintento solo reserva las que faltan.
+		for _, room := range booking.ActiveRooms() {
+			if room.ProviderBookingID != nil && *room.ProviderBookingID != "" {
+				continue // ya sincronizada en un intento anterior
+			}
+
+			offerID, err := s.providerOfferID(ctx, provider, booking, &room, externalHotelID)
+			if err != nil {
+				return err
+			}
+
+			providerBookingID, err := provider.Book(ctx, offerID, guests)
+			if err != nil {
+				return err
+			}
+
+			if err := s.storeProviderBooking(booking.ID, room.ID, externalHotelID, providerBookingID); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case models.SyncOperationCancel:
+		if job.BookingRoomID != nil {
+			for _, room := range booking.Rooms {
+				if room.ID == *job.BookingRoomID && room.ProviderBookingID != nil && *room.ProviderBookingID != "" {
+					return provider.Cancel(ctx, *room.ProviderBookingID)
+				}
+			}
+			return nil
+		}
+
+		// Trabajos encolados antes de las reservas por habitación
+		if booking.AmadeusBookingID == nil || *booking.AmadeusBookingID == "" {
+			return nil
+		}
+		return provider.Cancel(ctx, *booking.AmadeusBookingID)
+
+	default:
+		return fmt.Errorf("operación de sincronización desconocida: %s", job.Operation)
+	}
+}
+
+// providerOfferID devuelve la oferta con la que se cotizó la habitación. Si no
+// se guardó (el proveedor no respondía al reservar), busca una oferta vigente.
+func (s *BookingService) providerOfferID(ctx context.Context, provider inventory.InventoryProvider, booking *models.Booking, room *models.BookingRoom, externalHotelID string) (string, error) {
+	if room.ProviderOfferID != nil && *room.ProviderOfferID != "" {
+		return *room.ProviderOfferID, nil
+	}
+
+	availability, err := provider.Availability(ctx, externalHotelID, &models.AvailabilityRequest{
+		HotelID:      booking.InternalHotelID,
+		CheckInDate:  booking.CheckInDate,
+		CheckOutDate: booking.CheckOutDate,
+		Guests:       room.Guests,
+		RoomType:     room.RoomType,
+	})
+	if err != nil {
+		return "", err
+	}
+	if availability.OfferID == "" {
+		return "", fmt.Errorf("sin ofertas disponibles en el proveedor")
+	}
+
+	return availability.OfferID, nil
+}
+
+// storeProviderBooking guarda el ID del proveedor de una habitación. La primera
+// habitación sincronizada queda también como reserva del proveedor de la
+// cabecera. Si la reserva o la habitación se cancelaron mientras se creaba,
+// encola la cancelación en el proveedor.
+func (s *BookingService) storeProviderBooking(bookingID, roomID int, externalHotelID, providerBookingID string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error iniciando transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	var status string
+	if err := tx.QueryRow("SELECT status FROM bookings WHERE id = ? FOR UPDATE", bookingID).Scan(&status); err != nil {
+		return fmt.Errorf("error bloqueando reserva: %v", err)
+	}
+
+	var roomStatus string
+	if err := tx.QueryRow("SELECT status FROM booking_rooms WHERE id = ? FOR UPDATE", roomID).Scan(&roomStatus); err != nil {
+		return fmt.Errorf("error bloqueando habitación: %v", err)
+	}
+
+	if _, err := tx.Exec("UPDATE booking_rooms SET provider_booking_id = ? WHERE id = ?", providerBookingID, roomID); err != nil {
+		return fmt.Errorf("error guardando reserva del proveedor: %v", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE bookings SET amadeus_hotel_id = ?, amadeus_booking_id = COALESCE(amadeus_booking_id, ?)
+		WHERE id = ?
+	`, externalHotelID, providerBookingID, bookingID)
+	if err != nil {
+		return fmt.Errorf("error guardando reserva del proveedor: %v", err)
+	}
+
+	if status == models.StatusCancelled || roomStatus == models.RoomStatusCancelled {
+		if err := s.enqueueSyncJob(tx, int64(bookingID), &roomID, models.SyncOperationCancel); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error confirmando reserva del proveedor: %v", err)
+	}
+	return nil
+}
+
+// completeSyncJob marca el trabajo como exitoso. Si no quedan trabajos abiertos
+// para la reserva, queda sincronizada.
+func (s *BookingService) completeSyncJob(job *models.ProviderSyncJob, workerID string) {
+	result, err := s.db.Exec(`
+		UPDATE provider_sync_jobs
+		SET status = ?, locked_by = NULL, locked_until = NULL, last_error = NULL, completed_at = NOW()
+		WHERE id = ? AND locked_by = ?
+	`, models.JobSucceeded, job.ID, workerID)
+	if err != nil {
+		log.Printf("⚠️ Error completando sincronización %d: %v", job.ID, err)
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return // el lease expiró y otro worker tomó el trabajo
+	}
+
+	_, err = s.db.Exec(`
+		UPDATE bookings SET sync_status = ?
+		WHERE id = ? AND NOT EXISTS (
+			SELECT 1 FROM provider_sync_jobs WHERE booking_id = ? AND status IN (?, ?)
+		)
+	`, models.SyncSynced, job.BookingID, job.BookingID, models.JobPending, models.JobRunning)
+	if err != nil {
+		log.Printf("⚠️ Error actualizando estado de sincronización de la reserva %d: %v", job.BookingID, err)
+		return
+	}
+
+	log.Printf("✅ Sincronización %s de la reserva %d completada", job.Operation, job.BookingID)
+}
+
+// failSyncJob reprograma el trabajo con backoff exponencial o lo marca como
+// fallido al agotar los intentos
+func (s *BookingService) failSyncJob(job *models.ProviderSyncJob, workerID string, jobErr error) {
+	errMsg := jobErr.Error()
+
+	if job.Attempts >= job.MaxAttempts {
+		result, err := s.db.Exec(`
+			UPDATE provider_sync_jobs
+			SET status = ?, locked_by = NULL, locked_until = NULL, last_error = ?, completed_at = NOW()
+			WHERE id = ? AND locked_by = ?
+		`, models.JobFailed, errMsg, job.ID, workerID)
+		if err != nil {
+			log.Printf("⚠️ Error registrando fallo de sincronización %d: %v", job.ID, err)
+			return
+		}
+		if affected, _ := result.RowsAffected(); affected > 0 {
+			s.db.Exec("UPDATE bookings SET sync_status = ? WHERE id = ?", models.SyncFailed, job.BookingID)
+			log.Printf("❌ Sincronización %s de la reserva %d falló tras %d intentos: %s", job.Operation, job.BookingID, job.Attempts, errMsg)
+		}
+		return
+	}
+
+	nextRun := time.Now().Add(syncBackoff(s.syncBaseBackoff, job.Attempts))
+	_, err := s.db.Exec(`
+		UPDATE provider_sync_jobs
+		SET status = ?, locked_by = NULL, locked_until = NULL, last_error = ?, next_run_at = ?
+		WHERE id = ? AND locked_by = ?
+	`, models.JobPending, errMsg, nextRun, job.ID, workerID)
+	if err != nil {
+		log.Printf("⚠️ Error reprogramando sincronización %d: %v", job.ID, err)
+		return
+	}
+
+	log.Printf("⚠️ Sincronización %s de la reserva %d falló (intento %d/%d), reintento a las %s: %s",
+		job.Operation, job.BookingID, job.Attempts, job.MaxAttempts, nextRun.Format("15:04:05"), errMsg)
+}
+
+// syncBackoff duplica la espera en cada intento: base, 2*base, 4*base... hasta syncMaxBackoff
+func syncBackoff(base time.Duration, attempts int) time.Duration {
+	wait := base
+	for i := 1; i < attempts && wait < syncMaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > syncMaxBackoff {
+		return syncMaxBackoff
+	}
+	return wait
+}
+
+// ListSyncJobs lista trabajos de sincronización, los más recientes primero
+func (s *BookingService) ListSyncJobs(filter models.SyncJobFilter) ([]*models.ProviderSyncJob, error) {
+	where := []string{"1 = 1"}
+	var args []interface{}
+
+	if filter.Status != "" {
+		where = append(where, "status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.BookingID > 0 {
+		where = append(where, "booking_id = ?")
+		args = append(args, filter.BookingID)
+	}
+
+	query := "SELECT " + syncJobColumns + " FROM provider_sync_jobs WHERE " + strings.Join(where, " AND ") + " ORDER BY id DESC LIMIT ?"
+	rows, err := s.db.Query(query, append(args, filter.Limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo sincronizaciones: %v", err)
+	}
+	defer rows.Close()
+
+	jobs := []*models.ProviderSyncJob{}
+	for rows.Next() {
+		job, err := scanSyncJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error escaneando sincronización: %v", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// RetrySyncJob vuelve a encolar un trabajo fallido con los intentos en cero
+func (s *BookingService) RetrySyncJob(jobID int64) (*models.ProviderSyncJob, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error iniciando transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	job, err := scanSyncJob(tx.QueryRow("SELECT "+syncJobColumns+" FROM provider_sync_jobs WHERE id = ? FOR UPDATE", jobID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("sincronización no encontrada")
+		}
+		return nil, fmt.Errorf("error obteniendo sincronización: %v", err)
+	}
+
+	if job.Status != models.JobFailed {
+		return nil, fmt.Errorf("no se puede reintentar una sincronización en estado %s", job.Status)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE provider_sync_jobs
+		SET status = ?, attempts = 0, next_run_at = NOW(), completed_at = NULL
+		WHERE id = ?
+	`, models.JobPending, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("error reencolando sincronización: %v", err)
+	}
+
+	if _, err := tx.Exec("UPDATE bookings SET sync_status = ? WHERE id = ?", models.SyncPending, job.BookingID); err != nil {
+		return nil, fmt.Errorf("error actualizando estado de sincronización: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error confirmando reintento: %v", err)
+	}
+
+	log.Printf("🔁 Sincronización %d reencolada", jobID)
+	return scanSyncJob(s.db.QueryRow("SELECT "+syncJobColumns+" FROM provider_sync_jobs WHERE id = ?", jobID))
+}
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"booking-service/internal/inventory"
+	"booking-service/internal/models"
+	"booking-service/pkg/mysql"
+)
+
+// fakeSyncProvider proveedor externo que registra las reservas y cancelaciones.
+// Book falla para las ofertas de failOffers.
+type fakeSyncProvider struct {
+	*inventory.SimulatedProvider
+	booked     []string
+	cancelled  []string
+	failOffers map[string]bool
+}
+
+func (p *fakeSyncProvider) Name() string { return "fake" }
+
+func (p *fakeSyncProvider) Book(ctx context.Context, offerID string, guests []models.BookingGuest) (string, error) {
+	if p.failOffers[offerID] {
+		return "", errors.New("proveedor no disponible")
+	}
+	p.booked = append(p.booked, offerID)
+	return "PRV-" + offerID, nil
+}
+
+func (p *fakeSyncProvider) Cancel(ctx context.Context, providerBookingID string) error {
+	p.cancelled = append(p.cancelled, providerBookingID)
+	return nil
+}
+
+// newSyncTestService servicio con fakeSyncProvider registrado como "fake". El
+// backoff de un minuto deja los trabajos reprogramados fuera del próximo lote.
+func newSyncTestService(t *testing.T) (*BookingService, *mysql.DB, *fakeSyncProvider) {
+	t.Helper()
+
+	service, db := newBookingTestService(t)
+	service.syncBaseBackoff = time.Minute
+	provider := &fakeSyncProvider{SimulatedProvider: inventory.NewSimulatedProvider(), failOffers: map[string]bool{}}
+	service.providers[provider.Name()] = provider
+	return service, db, provider
+}
+
+// insertSyncBooking carga una reserva del proveedor fake con su trabajo de creación
+func insertSyncBooking(t *testing.T, service *BookingService, db *mysql.DB, reference string, rooms ...string) int {
+	t.Helper()
+
+	bookingID := insertBooking(t, db, testBooking{Reference: reference, Provider: "fake", SyncStatus: models.SyncPending, Rooms: rooms})
+	enqueueTestSyncJob(t, service, db, bookingID, nil, models.SyncOperationCreate)
+	return bookingID
+}
+
+func enqueueTestSyncJob(t *testing.T, service *BookingService, db *mysql.DB, bookingID int, roomID *int, operation string) {
+	t.Helper()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("error iniciando transacción: %v", err)
+	}
+	defer tx.Rollback()
+	if err := service.enqueueSyncJob(tx, int64(bookingID), roomID, operation); err != nil {
+		t.Fatalf("error encolando sincronización: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("error confirmando sincronización: %v", err)
+	}
+}
+
+// bookingSyncJobs trabajos de la reserva en orden de creación
+func bookingSyncJobs(t *testing.T, service *BookingService, bookingID int) []*models.ProviderSyncJob {
+	t.Helper()
+
+	jobs, err := service.ListSyncJobs(models.SyncJobFilter{BookingID: bookingID, Limit: 10})
+	if err != nil {
+		t.Fatalf("error listando sincronizaciones: %v", err)
+	}
+	for i, j := 0, len(jobs)-1; i < j; i, j = i+1, j-1 {
+		jobs[i], jobs[j] = jobs[j], jobs[i]
+	}
+	return jobs
+}
+
+// processDueSyncJobs adelanta los trabajos reprogramados y procesa un lote
+func processDueSyncJobs(t *testing.T, service *BookingService, db *mysql.DB) int {
+	t.Helper()
+
+	mustExec(t, db, "UPDATE provider_sync_jobs SET next_run_at = ? WHERE status = ?", time.Now().Add(-time.Minute), models.JobPending)
+	processed, err := service.processSyncJobs(context.Background())
+	if err != nil {
+		t.Fatalf("error procesando sincronizaciones: %v", err)
+	}
+	return processed
+}
+
+func TestProcessSyncJobsCreatesEachRoom(t *testing.T) {
+	service, db, provider := newSyncTestService(t)
+	bookingID := insertSyncBooking(t, service, db, "BK-SYNC", "standard", "suite")
+
+	if processed := processDueSyncJobs(t, service, db); processed != 1 {
+		t.Fatalf("procesados = %d, se esperaba el trabajo de creación", processed)
+	}
+
+	if fmt.Sprint(provider.booked) != "[BK-SYNC-standard BK-SYNC-suite]" {
+		t.Fatalf("reservas en el proveedor = %v, se esperaba una por habitación con su oferta", provider.booked)
+	}
+
+	booking, err := service.GetBookingByID(bookingID)
+	if err != nil {
+		t.Fatalf("error obteniendo reserva: %v", err)
+	}
+	if booking.SyncStatus != models.SyncSynced {
+		t.Fatalf("sync_status = %s, se esperaba sincronizada", booking.SyncStatus)
+	}
+	if booking.AmadeusBookingID == nil || *booking.AmadeusBookingID != "PRV-BK-SYNC-standard" {
+		t.Fatalf("reserva del proveedor = %v, se esperaba la de la primera habitación", booking.AmadeusBookingID)
+	}
+	for _, room := range booking.Rooms {
+		if room.ProviderBookingID == nil || *room.ProviderBookingID != "PRV-BK-SYNC-"+room.RoomType {
+			t.Fatalf("habitación %s sin reserva del proveedor: %v", room.RoomType, room.ProviderBookingID)
+		}
+	}
+
+	jobs := bookingSyncJobs(t, service, bookingID)
+	if len(jobs) != 1 || jobs[0].Status != models.JobSucceeded || jobs[0].Attempts != 1 || jobs[0].CompletedAt == nil {
+		t.Fatalf("trabajo = %+v, se esperaba completado en el primer intento", jobs[0])
+	}
+}
+
+func TestProcessSyncJobsRetriesOnlyMissingRooms(t *testing.T) {
+	service, db, provider := newSyncTestService(t)
+	bookingID := insertSyncBooking(t, service, db, "BK-RETRY", "standard", "suite")
+	provider.failOffers["BK-RETRY-suite"] = true
+
+	before := time.Now()
+	processDueSyncJobs(t, service, db)
+
+	job := bookingSyncJobs(t, service, bookingID)[0]
+	if job.Status != models.JobPending || job.Attempts != 1 || job.LastError == nil {
+		t.Fatalf("trabajo = %+v, se esperaba reprogramado con el error", job)
+	}
+	if job.NextRunAt.Before(before.Add(service.syncBaseBackoff - time.Second)) {
+		t.Fatalf("próximo intento = %v, se esperaba el backoff base", job.NextRunAt)
+	}
+
+	// Sin adelantar el trabajo, el backoff impide reintentarlo ya
+	if processed, err := service.processSyncJobs(context.Background()); err != nil || processed != 0 {
+		t.Fatalf("procesados = %d error = %v, el trabajo todavía no vence", processed, err)
+	}
+
+	delete(provider.failOffers, "BK-RETRY-suite")
+	processDueSyncJobs(t, service, db)
+
+	// La estándar quedó guardada en el primer intento: no se reserva dos veces
+	if fmt.Sprint(provider.booked) != "[BK-RETRY-standard BK-RETRY-suite]" {
+		t.Fatalf("reservas en el proveedor = %v, el reintento solo reserva lo que falta", provider.booked)
+	}
+	job = bookingSyncJobs(t, service, bookingID)[0]
+	if job.Status != models.JobSucceeded || job.Attempts != 2 {
+		t.Fatalf("trabajo = %+v, se esperaba completado en el segundo intento", job)
+	}
+}
+
+func TestProcessSyncJobsFailsAfterMaxAttempts(t *testing.T) {
+	service, db, provider := newSyncTestService(t)
+	bookingID := insertSyncBooking(t, service, db, "BK-FAIL")
+	provider.failOffers["BK-FAIL-standard"] = true
+
+	for i := 0; i < service.syncMaxAttempts; i++ {
+		processDueSyncJobs(t, service, db)
+	}
+
+	job := bookingSyncJobs(t, service, bookingID)[0]
+	if job.Status != models.JobFailed || job.Attempts != service.syncMaxAttempts || job.CompletedAt == nil {
+		t.Fatalf("trabajo = %+v, se esperaba fallido tras %d intentos", job, service.syncMaxAttempts)
+	}
+	booking, err := service.GetBookingByID(bookingID)
+	if err != nil {
+		t.Fatalf("error obteniendo reserva: %v", err)
+	}
+	if booking.SyncStatus != models.SyncFailed {
+		t.Fatalf("sync_status = %s, se esperaba fallida", booking.SyncStatus)
+	}
+	if processed := processDueSyncJobs(t, service, db); processed != 0 {
+		t.Fatalf("procesados = %d, un trabajo fallido no se reintenta solo", processed)
+	}
+
+	// El reintento manual vuelve a encolarlo con los intentos en cero
+	retried, err := service.RetrySyncJob(job.ID)
+	if err != nil {
+		t.Fatalf("error reintentando: %v", err)
+	}
+	if retried.Status != models.JobPending || retried.Attempts != 0 || retried.CompletedAt != nil {
+		t.Fatalf("trabajo reencolado = %+v", retried)
+	}
+	if _, err := service.RetrySyncJob(job.ID); err == nil {
+		t.Fatal("se esperaba error al reintentar un trabajo pendiente")
+	}
+
+	delete(provider.failOffers, "BK-FAIL-standard")
+	processDueSyncJobs(t, service, db)
+	if booking, _ := service.GetBookingByID(bookingID); booking.SyncStatus != models.SyncSynced {
+		t.Fatalf("sync_status = %s, se esperaba sincronizada tras el reintento", booking.SyncStatus)
+	}
+}
+
+func TestProcessSyncJobsRespectsLease(t *testing.T) {
+	service, db, provider := newSyncTestService(t)
+	liveID := insertSyncBooking(t, service, db, "BK-LIVE")
+	expiredID := insertSyncBooking(t, service, db, "BK-EXPIRED")
+
+	// Un worker tiene el primer trabajo con lease vigente; el del segundo se cayó
+	mustExec(t, db, "UPDATE provider_sync_jobs SET status = ?, locked_by = 'vivo', locked_until = ? WHERE booking_id = ?",
+		models.JobRunning, time.Now().Add(time.Minute), liveID)
+	mustExec(t, db, "UPDATE provider_sync_jobs SET status = ?, locked_by = 'caido', locked_until = ?, attempts = 1 WHERE booking_id = ?",
+		models.JobRunning, time.Now().Add(-time.Minute), expiredID)
+
+	if processed := processDueSyncJobs(t, service, db); processed != 1 {
+		t.Fatalf("procesados = %d, se esperaba solo el de lease vencido", processed)
+	}
+	if fmt.Sprint(provider.booked) != "[BK-EXPIRED-standard]" {
+		t.Fatalf("reservas en el proveedor = %v", provider.booked)
+	}
+	if job := bookingSyncJobs(t, service, expiredID)[0]; job.Status != models.JobSucceeded || job.Attempts != 2 {
+		t.Fatalf("trabajo retomado = %+v, se esperaba completado contando el intento anterior", job)
+	}
+	if job := bookingSyncJobs(t, service, liveID)[0]; job.Status != models.JobRunning {
+		t.Fatalf("trabajo con lease vigente = %s, no debía tocarse", job.Status)
+	}
+}
+
+func TestStoreProviderBookingOfCancelledRoom(t *testing.T) {
+	service, db, provider := newSyncTestService(t)
+	bookingID := insertBooking(t, db, testBooking{Reference: "BK-LATE", Provider: "fake", SyncStatus: models.SyncPending})
+	booking, err := service.GetBookingByID(bookingID)
+	if err != nil {
+		t.Fatalf("error obteniendo reserva: %v", err)
+	}
+	roomID := booking.Rooms[0].ID
+
+	// La habitación se canceló mientras el proveedor la creaba
+	mustExec(t, db, "UPDATE booking_rooms SET status = ? WHERE id = ?", models.RoomStatusCancelled, roomID)
+	if err := service.storeProviderBooking(bookingID, roomID, testHotelID, "PRV-LATE"); err != nil {
+		t.Fatalf("error guardando reserva del proveedor: %v", err)
+	}
+
+	jobs := bookingSyncJobs(t, service, bookingID)
+	if len(jobs) != 1 || jobs[0].Operation != models.SyncOperationCancel || jobs[0].BookingRoomID == nil || *jobs[0].BookingRoomID != roomID {
+		t.Fatalf("trabajos = %+v, se esperaba cancelar la habitación en el proveedor", jobs)
+	}
+
+	processDueSyncJobs(t, service, db)
+	if fmt.Sprint(provider.cancelled) != "[PRV-LATE]" {
+		t.Fatalf("cancelaciones en el proveedor = %v", provider.cancelled)
+	}
+}
+
+func TestSyncBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: time.Minute},
+		{attempts: 2, want: 2 * time.Minute},
+		{attempts: 4, want: 8 * time.Minute},
+		{attempts: 6, want: 32 * time.Minute},
+		{attempts: 7, want: syncMaxBackoff}, // 64 minutos supera el máximo
+		{attempts: 20, want: syncMaxBackoff},
+	}
+
+	for _, tt := range tests {
+		if got := syncBackoff(time.Minute, tt.attempts); got != tt.want {
+			t.Errorf("syncBackoff(1m, %d) = %v, se esperaba %v", tt.attempts, got, tt.want)
+		}
+	}
+}
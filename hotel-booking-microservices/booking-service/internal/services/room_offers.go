@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"booking-service/internal/models"
+)
+
+// localOfferPrefix identifica las ofertas cotizadas con las tarifas propias
+const localOfferPrefix = "LOC-"
+
+// isLocalOffer indica si la oferta fue cotizada con las tarifas propias
+func isLocalOffer(offerID string) bool {
+	return strings.HasPrefix(offerID, localOfferPrefix)
+}
+
+// localOfferID genera un ID estable para la misma búsqueda y habitación, así la
+// oferta elegida se reconoce al volver a cotizar en la creación de la reserva
+func localOfferID(req *models.AvailabilityRequest, roomType string) string {
+	sum := sha1.Sum([]byte(strings.Join([]string{
+		req.HotelID, strings.ToLower(roomType), req.CheckInDate.Format("20060102"), req.CheckOutDate.Format("20060102"), strconv.Itoa(req.Guests),
+	}, "|")))
+	return localOfferPrefix + strings.ToUpper(hex.EncodeToString(sum[:6]))
+}
+
+// findOffer busca una oferta por ID
+func findOffer(offers []models.RoomOffer, offerID string) *models.RoomOffer {
+	for i := range offers {
+		if offers[i].OfferID == offerID {
+			return &offers[i]
+		}
+	}
+	return nil
+}
+
+// offerRoomType busca la oferta elegida entre todas las habitaciones del hotel y
+// devuelve su tipo de habitación. Una oferta del proveedor cuyo tipo no existe
+// en hotel-service no fija la habitación (se asigna la de menor capacidad).
+func (s *BookingService) offerRoomType(ctx context.Context, req *models.CreateBookingRequest) (string, error) {
+	availability, err := s.CheckAvailability(ctx, &models.AvailabilityRequest{
+		HotelID:      req.HotelID,
+		CheckInDate:  req.CheckInDate,
+		CheckOutDate: req.CheckOutDate,
+		Guests:       req.Guests,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error verificando disponibilidad: %v", err)
+	}
+
+	offer := findOffer(availability.Offers, req.OfferID)
+	if offer == nil {
+		if isLocalOffer(req.OfferID) {
+			return "", fmt.Errorf("la oferta ya no está disponible")
+		}
+		return "", nil
+	}
+
+	if isLocalOffer(req.OfferID) {
+		return offer.RoomType, nil
+	}
+	if _, err := s.resolveRoomType(req.HotelID, offer.RoomType, req.Guests); err != nil {
+		return "", nil
+	}
+	return offer.RoomType, nil
+}
+
+// localOffers cotiza con las tarifas propias una oferta por tipo de habitación.
+// Si se pidió un tipo (room) solo se cotiza ese; si no, todos los que admiten a
+// los huéspedes. Las habitaciones agotadas se informan con rooms_available 0.
+func (s *BookingService) localOffers(req *models.AvailabilityRequest, room *models.RoomType) []models.RoomOffer {
+	var candidates []*models.RoomType
+	if strings.TrimSpace(req.RoomType) != "" || room == nil {
+		candidates = []*models.RoomType{room}
+	} else if rooms, err := s.getRoomTypes(req.HotelID); err == nil {
+		for i := range rooms {
+			if rooms[i].Capacity >= req.Guests {
+				candidates = append(candidates, &rooms[i])
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = []*models.RoomType{room}
+	}
+
+	nights := len(stayNights(req.CheckInDate, req.CheckOutDate))
+	offers := []models.RoomOffer{}
+	for _, candidate := range candidates {
+		roomType := normalizeRoomType(req.RoomType)
+		description := ""
+		if candidate != nil {
+			roomType = candidate.Name
+			description = candidate.Description
+			if description == "" {
+				description = candidate.BedConfiguration
+			}
+		}
+
+		quote, currency, err := s.quoteStay(req.HotelID, roomType, candidate, req.CheckInDate, req.CheckOutDate, req.Guests)
+		if err != nil {
+			fmt.Printf("⚠️ Warning: No se pudo cotizar %s en hotel %s: %v\n", roomType, req.HotelID, err)
+			continue
+		}
+
+		policy := s.roomCancellationPolicy(req.HotelID, candidate)
+		offer := models.RoomOffer{
+			OfferID:            localOfferID(req, roomType),
+			RoomType:           roomType,
+			Description:        description,
+			BoardType:          "ROOM_ONLY",
+			CancellationPolicy: cancellationPolicyText(policy),
+			CancellationTerms:  policy,
+			TotalPrice:         quote.Total,
+			Currency:           currency,
+			Nights:             quote.Nights,
+		}
+		if nights > 0 {
+			offer.NightlyPrice = math.Round(quote.Total/float64(nights)*100) / 100
+		}
+
+		rooms, err := s.GetRoomsAvailable(req.HotelID, roomType, req.CheckInDate, req.CheckOutDate, s.roomInventoryTotal(candidate))
+		if err != nil {
+			fmt.Printf("⚠️ Warning: No se pudo consultar inventario para hotel %s: %v\n", req.HotelID, err)
+		} else {
+			offer.RoomsAvailable = &rooms
+		}
+
+		offers = append(offers, offer)
+	}
+
+	return offers
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"booking-service/internal/models"
+)
+
+// offerStay búsqueda de dos noches en testHotelID desde dentro de un mes
+func offerStay(guests int) *models.AvailabilityRequest {
+	checkIn := dateOnly(time.Now().AddDate(0, 1, 0))
+	return &models.AvailabilityRequest{
+		HotelID:      testHotelID,
+		CheckInDate:  checkIn,
+		CheckOutDate: checkIn.AddDate(0, 0, 2),
+		Guests:       guests,
+	}
+}
+
+func TestCheckAvailabilityReturnsEveryOffer(t *testing.T) {
+	service, db := newBookingTestService(t)
+	ctx := context.Background()
+
+	response, err := service.CheckAvailability(ctx, offerStay(2))
+	if err != nil {
+		t.Fatalf("error consultando disponibilidad: %v", err)
+	}
+	if len(response.Offers) != 2 || response.Offers[0].RoomType != "standard" || response.Offers[1].RoomType != "suite" {
+		t.Fatalf("ofertas = %+v, se esperaba una por habitación de la más barata a la más cara", response.Offers)
+	}
+
+	standard, suite := response.Offers[0], response.Offers[1]
+	if standard.TotalPrice != 200 || standard.NightlyPrice != 100 || len(standard.Nights) != 2 || suite.TotalPrice != 500 {
+		t.Fatalf("precios = %v y %v, se esperaban las tarifas base por noche", standard.TotalPrice, suite.TotalPrice)
+	}
+	if *standard.RoomsAvailable != 5 || *suite.RoomsAvailable != 2 {
+		t.Fatalf("cupos = %d y %d, se esperaba el inventario de cada habitación", *standard.RoomsAvailable, *suite.RoomsAvailable)
+	}
+	if !isLocalOffer(standard.OfferID) || standard.OfferID == suite.OfferID {
+		t.Fatalf("IDs = %s y %s, se esperaban ofertas propias distintas", standard.OfferID, suite.OfferID)
+	}
+
+	// Los campos principales son los de la más barata
+	if !response.Available || response.OfferID != standard.OfferID || response.RoomType != "standard" || *response.Price != 200 {
+		t.Fatalf("respuesta = %+v, se esperaba la oferta estándar", response)
+	}
+
+	// La misma búsqueda genera los mismos IDs
+	if again := localOfferID(offerStay(2), "suite"); again != suite.OfferID {
+		t.Fatalf("ID = %s, se esperaba %s", again, suite.OfferID)
+	}
+
+	// Con tres huéspedes solo entra la suite
+	family, err := service.CheckAvailability(ctx, offerStay(3))
+	if err != nil {
+		t.Fatalf("error consultando disponibilidad: %v", err)
+	}
+	if len(family.Offers) != 1 || family.Offers[0].RoomType != "suite" {
+		t.Fatalf("ofertas = %+v, se esperaba solo la suite", family.Offers)
+	}
+
+	// La estándar agotada se informa sin cupo y se elige la suite
+	req := offerStay(1)
+	for night := req.CheckInDate; night.Before(req.CheckOutDate); night = night.AddDate(0, 0, 1) {
+		mustExec(t, db, "INSERT INTO room_inventory (hotel_id, room_type, stay_date, total_rooms, booked_rooms) VALUES (?, 'standard', ?, 5, 5)", testHotelID, night)
+	}
+	soldOut, err := service.CheckAvailability(ctx, req)
+	if err != nil {
+		t.Fatalf("error consultando disponibilidad: %v", err)
+	}
+	if len(soldOut.Offers) != 2 || *soldOut.Offers[0].RoomsAvailable != 0 {
+		t.Fatalf("ofertas = %+v, la estándar agotada se informa igual", soldOut.Offers)
+	}
+	if soldOut.RoomType != "suite" || soldOut.OfferID != soldOut.Offers[1].OfferID {
+		t.Fatalf("habitación elegida = %s, se esperaba la suite", soldOut.RoomType)
+	}
+}
+
+func TestCreateBookingWithChosenOffer(t *testing.T) {
+	service, db := newBookingTestService(t)
+	ctx := context.Background()
+	userID := insertUser(t, db, "ofertas@test.com")
+
+	stay := offerStay(2)
+	availability, err := service.CheckAvailability(ctx, stay)
+	if err != nil {
+		t.Fatalf("error consultando disponibilidad: %v", err)
+	}
+	suite := availability.Offers[1]
+
+	// La oferta elegida fija la habitación aunque no sea la más barata
+	booking, err := service.CreateBooking(ctx, userID, &models.CreateBookingRequest{
+		HotelID:      testHotelID,
+		CheckInDate:  stay.CheckInDate,
+		CheckOutDate: stay.CheckOutDate,
+		Guests:       2,
+		OfferID:      suite.OfferID,
+	})
+	if err != nil {
+		t.Fatalf("error reservando la oferta: %v", err)
+	}
+	if booking.RoomType != "suite" || booking.TotalPrice != suite.TotalPrice || len(booking.Rooms) != 1 {
+		t.Fatalf("reserva = %s por %v, se esperaba la suite por %v", booking.RoomType, booking.TotalPrice, suite.TotalPrice)
+	}
+	if booked := bookedRooms(t, db, "suite", stay.CheckInDate); booked != 1 {
+		t.Fatalf("suites ocupadas = %d", booked)
+	}
+
+	// Varias habitaciones, cada una con su oferta
+	multi, err := service.CreateBooking(ctx, userID, &models.CreateBookingRequest{
+		HotelID:      testHotelID,
+		CheckInDate:  stay.CheckInDate,
+		CheckOutDate: stay.CheckOutDate,
+		Rooms: []models.BookingRoomRequest{
+			{Guests: 2, OfferID: availability.Offers[0].OfferID},
+			{Guests: 2, OfferID: suite.OfferID},
+		},
+	})
+	if err != nil {
+		t.Fatalf("error reservando varias ofertas: %v", err)
+	}
+	if len(multi.Rooms) != 2 || multi.Rooms[0].RoomType != "standard" || multi.Rooms[1].RoomType != "suite" || multi.TotalPrice != 700 {
+		t.Fatalf("reserva = %d habitaciones por %v", len(multi.Rooms), multi.TotalPrice)
+	}
+
+	// Una oferta propia que ya no se cotiza no se reserva con otra habitación
+	_, err = service.CreateBooking(ctx, userID, &models.CreateBookingRequest{
+		HotelID:      testHotelID,
+		CheckInDate:  stay.CheckInDate,
+		CheckOutDate: stay.CheckOutDate,
+		Guests:       2,
+		OfferID:      localOfferPrefix + "000000000000",
+	})
+	if err == nil || !strings.Contains(err.Error(), "la oferta ya no está disponible") {
+		t.Fatalf("error = %v, se esperaba oferta no disponible", err)
+	}
+
+	// La oferta de otra búsqueda tampoco sirve
+	_, err = service.CreateBooking(ctx, userID, &models.CreateBookingRequest{
+		HotelID:      testHotelID,
+		CheckInDate:  stay.CheckInDate,
+		CheckOutDate: stay.CheckOutDate.AddDate(0, 0, 1),
+		Guests:       2,
+		OfferID:      suite.OfferID,
+	})
+	if err == nil || !strings.Contains(err.Error(), "la oferta ya no está disponible") {
+		t.Fatalf("error = %v, la oferta era para otras fechas", err)
+	}
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"booking-service/internal/models"
+	"booking-service/pkg/memcached"
+)
+
+// getRoomTypes obtiene los tipos de habitación de un hotel, con caché de un minuto
+func (s *BookingService) getRoomTypes(hotelID string) ([]models.RoomType, error) {
+	cacheKey := memcached.GenerateRoomTypesKey(hotelID)
+
+	var rooms []models.RoomType
+	if err := s.cache.Get(cacheKey, &rooms); err == nil {
+		return rooms, nil
+	}
+
+	rooms, err := s.hotelClient.GetRoomTypes(hotelID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.Set(cacheKey, rooms, time.Minute)
+	return rooms, nil
+}
+
+// resolveRoomType valida el tipo de habitación pedido contra los que ofrece el hotel.
+// Devuelve nil si el hotel no tiene tipos cargados (se acepta el tipo libre).
+// Si no se pide un tipo, se asigna el de menor capacidad que admita a los huéspedes.
+func (s *BookingService) resolveRoomType(hotelID, roomType string, guests int) (*models.RoomType, error) {
+	rooms, err := s.getRoomTypes(hotelID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rooms) == 0 {
+		return nil, nil
+	}
+
+	roomType = strings.TrimSpace(roomType)
+	if roomType == "" {
+		var best *models.RoomType
+		for i := range rooms {
+			room := &rooms[i]
+			if room.Capacity >= guests && (best == nil || room.Capacity < best.Capacity) {
+				best = room
+			}
+		}
+		if best == nil {
+			return nil, fmt.Errorf("capacidad insuficiente: ningún tipo de habitación admite %d huéspedes", guests)
+		}
+		return best, nil
+	}
+
+	for i := range rooms {
+		room := &rooms[i]
+		if !strings.EqualFold(room.Name, roomType) {
+			continue
+		}
+		if room.Capacity < guests {
+			return nil, fmt.Errorf("capacidad insuficiente: %s admite hasta %d huéspedes", room.Name, room.Capacity)
+		}
+		return room, nil
+	}
+
+	offered := make([]string, 0, len(rooms))
+	for _, room := range rooms {
+		offered = append(offered, room.Name)
+	}
+	return nil, fmt.Errorf("tipo de habitación inválido: el hotel no ofrece %s (disponibles: %s)", roomType, strings.Join(offered, ", "))
+}
+
+// isRoomTypeError indica si el error proviene de la validación del tipo de habitación
+func isRoomTypeError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "tipo de habitación inválido") || strings.Contains(msg, "capacidad insuficiente")
+}
+
+// roomInventoryTotal devuelve la cantidad de habitaciones con la que se inicializa el inventario
+func (s *BookingService) roomInventoryTotal(room *models.RoomType) int {
+	if room != nil && room.Count > 0 {
+		return room.Count
+	}
+	return s.defaultRoomInventory
+}
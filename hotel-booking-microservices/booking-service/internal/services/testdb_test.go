@@ -0,0 +1,83 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"booking-service/migrations"
+	"booking-service/pkg/mysql"
+)
+
+// newTestDB crea una base vacía con todas las migraciones aplicadas y la borra
+// al terminar el test. Los tests que dependen de los locks de InnoDB necesitan
+// un MySQL real: TEST_MYSQL_URI tiene el formato de MYSQL_URI, con un usuario
+// que pueda crear bases (por ejemplo root:root@tcp(localhost:3306)/). Sin esa
+// variable el test se omite.
+func newTestDB(t *testing.T) *mysql.DB {
+	t.Helper()
+
+	uri := os.Getenv("TEST_MYSQL_URI")
+	if uri == "" {
+		t.Skip("TEST_MYSQL_URI no configurada")
+	}
+	server := uri[:strings.LastIndex(uri, "/")+1]
+
+	admin, err := sql.Open("mysql", server)
+	if err != nil {
+		t.Fatalf("error conectando a MySQL: %v", err)
+	}
+	defer admin.Close()
+
+	name := fmt.Sprintf("booking_test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE DATABASE " + name); err != nil {
+		t.Fatalf("error creando base de prueba: %v", err)
+	}
+	t.Cleanup(func() {
+		admin, err := sql.Open("mysql", server)
+		if err != nil {
+			return
+		}
+		defer admin.Close()
+		admin.Exec("DROP DATABASE " + name)
+	})
+
+	db, err := mysql.Connect(server + name)
+	if err != nil {
+		t.Fatalf("error conectando a la base de prueba: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	all, err := mysql.LoadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatalf("error cargando migraciones: %v", err)
+	}
+	if _, err := db.MigrateUp(all); err != nil {
+		t.Fatalf("error aplicando migraciones: %v", err)
+	}
+
+	return db
+}
+
+// mustExec ejecuta una sentencia de preparación y devuelve el id insertado
+func mustExec(t *testing.T, db *mysql.DB, query string, args ...interface{}) int64 {
+	t.Helper()
+	result, err := db.Exec(query, args...)
+	if err != nil {
+		t.Fatalf("error ejecutando %q: %v", firstLine(query), err)
+	}
+	id, _ := result.LastInsertId()
+	return id
+}
+
+// firstLine primera línea no vacía de una sentencia, para los mensajes de error
+func firstLine(query string) string {
+	query = strings.TrimSpace(query)
+	if i := strings.Index(query, "\n"); i >= 0 {
+		return query[:i]
+	}
+	return query
+}
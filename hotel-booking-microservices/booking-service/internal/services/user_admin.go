@@ -0,0 +1,247 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"booking-service/internal/models"
+)
+
+// userColumns columnas leídas en las consultas de administración de usuarios
+const userColumns = `id, email, email_verified, password_hash, first_name, last_name, phone, date_of_birth, role, created_at, updated_at, is_active, deleted_at, token_version`
+
+// scanUser escanea una fila con las columnas de userColumns
+func scanUser(row rowScanner) (*models.User, error) {
+	var user models.User
+	err := row.Scan(
+		&user.ID, &user.Email, &user.EmailVerified, &user.PasswordHash, &user.FirstName, &user.LastName,
+		&user.Phone, &user.DateOfBirth, &user.Role, &user.CreatedAt, &user.UpdatedAt, &user.IsActive, &user.DeletedAt, &user.TokenVersion,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ListUsers lista usuarios (activos e inactivos) con filtros y paginación
+func (s *BookingService) ListUsers(filter models.UserFilter) ([]*models.User, int, error) {
+	where := []string{"1 = 1"}
+	var args []interface{}
+
+	if filter.Search != "" {
+		where = append(where, "(email LIKE ? OR first_name LIKE ? OR last_name LIKE ?)")
+		like := "%" + filter.Search + "%"
+		args = append(args, like, like, like)
+	}
+	if filter.Role != "" {
+		where = append(where, "role = ?")
+		args = append(args, filter.Role)
+	}
+	if filter.IsActive != nil {
+		where = append(where, "is_active = ?")
+		args = append(args, *filter.IsActive)
+	}
+	whereClause := strings.Join(where, " AND ")
+
+	var total int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM users WHERE "+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error contando usuarios: %v", err)
+	}
+
+	query := "SELECT " + userColumns + " FROM users WHERE " + whereClause + " ORDER BY id LIMIT ? OFFSET ?"
+	rows, err := s.db.Query(query, append(args, filter.Limit, (filter.Page-1)*filter.Limit)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error obteniendo usuarios: %v", err)
+	}
+	defer rows.Close()
+
+	users := []*models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error escaneando usuario: %v", err)
+		}
+		users = append(users, user)
+	}
+
+	return users, total, rows.Err()
+}
+
+// GetUserForAdmin obtiene un usuario aunque esté desactivado
+func (s *BookingService) GetUserForAdmin(userID int) (*models.User, error) {
+	user, err := scanUser(s.db.QueryRow("SELECT "+userColumns+" FROM users WHERE id = ?", userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("usuario no encontrado")
+		}
+		return nil, fmt.Errorf("error obteniendo usuario: %v", err)
+	}
+	return user, nil
+}
+
+// UpdateUser actualiza los datos personales de un usuario
+func (s *BookingService) UpdateUser(userID int, req *models.UpdateUserRequest) (*models.User, error) {
+	user, err := s.GetUserForAdmin(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.FirstName != nil {
+		user.FirstName = *req.FirstName
+	}
+	if req.LastName != nil {
+		user.LastName = *req.LastName
+	}
+	if req.Phone != nil {
+		user.Phone = *req.Phone
+	}
+	if req.DateOfBirth != nil {
+		user.DateOfBirth = req.DateOfBirth
+	}
+
+	_, err = s.db.Exec(`
+		UPDATE users SET first_name = ?, last_name = ?, phone = ?, date_of_birth = ?
+		WHERE id = ?
+	`, user.FirstName, user.LastName, user.Phone, user.DateOfBirth, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error actualizando usuario: %v", err)
+	}
+
+	return s.GetUserForAdmin(userID)
+}
+
+// SetUserRole promueve o degrada a un usuario. Invalida sus tokens para que
+// el nuevo rol aplique de inmediato.
+func (s *BookingService) SetUserRole(actorID, userID int, role models.UserRole) (*models.User, error) {
+	if role != models.RoleUser && role != models.RoleAdmin {
+		return nil, fmt.Errorf("rol inválido: %s", role)
+	}
+
+	user, err := s.GetUserForAdmin(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Role == role {
+		return user, nil
+	}
+
+	if user.Role == models.RoleAdmin {
+		if actorID == userID {
+			return nil, fmt.Errorf("no se puede quitar el rol admin a uno mismo")
+		}
+		if err := s.ensureOtherAdmin(userID); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := s.db.Exec("UPDATE users SET role = ? WHERE id = ?", role, userID); err != nil {
+		return nil, fmt.Errorf("error actualizando rol: %v", err)
+	}
+
+	if err := s.BumpTokenVersion(userID); err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("🔑 Usuario %d ahora tiene rol %s (modificado por %d)\n", userID, role, actorID)
+	return s.GetUserForAdmin(userID)
+}
+
+// SetUserActive activa o desactiva un usuario. Desactivar revoca sus sesiones.
+func (s *BookingService) SetUserActive(actorID, userID int, active bool) (*models.User, error) {
+	user, err := s.GetUserForAdmin(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsActive == active {
+		return user, nil
+	}
+
+	if active && user.DeletedAt != nil {
+		return nil, fmt.Errorf("no se puede reactivar una cuenta eliminada")
+	}
+
+	if !active {
+		if actorID == userID {
+			return nil, fmt.Errorf("no se puede desactivar la propia cuenta")
+		}
+		if user.Role == models.RoleAdmin {
+			if err := s.ensureOtherAdmin(userID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if _, err := s.db.Exec("UPDATE users SET is_active = ? WHERE id = ?", active, userID); err != nil {
+		return nil, fmt.Errorf("error actualizando usuario: %v", err)
+	}
+
+	if err := s.BumpTokenVersion(userID); err != nil {
+		return nil, err
+	}
+
+	return s.GetUserForAdmin(userID)
+}
+
+// ensureOtherAdmin evita quedarse sin administradores activos
+func (s *BookingService) ensureOtherAdmin(userID int) error {
+	var admins int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM users WHERE role = ? AND is_active = TRUE AND id <> ?", models.RoleAdmin, userID).Scan(&admins)
+	if err != nil {
+		return fmt.Errorf("error contando administradores: %v", err)
+	}
+	if admins == 0 {
+		return fmt.Errorf("no se puede dejar el sistema sin administradores activos")
+	}
+	return nil
+}
+
+// EnsureAdmin crea el administrador inicial o promueve a un usuario existente.
+// Devuelve true si el usuario fue creado.
+func (s *BookingService) EnsureAdmin(email, password, firstName, lastName string) (bool, error) {
+	if email == "" || len(password) < 8 {
+		return false, fmt.Errorf("se requiere email y una contraseña de al menos 8 caracteres")
+	}
+
+	var userID int
+	err := s.db.QueryRow("SELECT id FROM users WHERE email = ?", email).Scan(&userID)
+	if err != nil && err != sql.ErrNoRows {
+		return false, fmt.Errorf("error buscando usuario: %v", err)
+	}
+
+	if err == nil {
+		// El usuario ya existe: se promueve y reactiva sin tocar su contraseña
+		if _, err := s.db.Exec("UPDATE users SET role = ?, is_active = TRUE WHERE id = ?", models.RoleAdmin, userID); err != nil {
+			return false, fmt.Errorf("error promoviendo usuario: %v", err)
+		}
+		return false, s.BumpTokenVersion(userID)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return false, fmt.Errorf("error hasheando password: %v", err)
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO users (email, password_hash, first_name, last_name, phone, role)
+		VALUES (?, ?, ?, ?, '', ?)
+	`, email, string(hashedPassword), firstName, lastName, models.RoleAdmin)
+	if err != nil {
+		return false, fmt.Errorf("error creando administrador: %v", err)
+	}
+
+	return true, nil
+}
+
+// HasAdmin indica si existe al menos un administrador activo
+func (s *BookingService) HasAdmin() (bool, error) {
+	var admins int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM users WHERE role = ? AND is_active = TRUE", models.RoleAdmin).Scan(&admins); err != nil {
+		return false, fmt.Errorf("error contando administradores: %v", err)
+	}
+	return admins > 0, nil
+}
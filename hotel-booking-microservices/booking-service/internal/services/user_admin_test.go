@@ -0,0 +1,46 @@
+package services
+
+import (
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"booking-service/internal/models"
+	"booking-service/pkg/memcached/memcachedtest"
+)
+
+func TestEnsureAdmin(t *testing.T) {
+	db := newTestDB(t)
+	cache, _ := memcachedtest.New(t)
+	service := NewBookingService(db, cache, nil, Options{})
+
+	created, err := service.EnsureAdmin("admin@test.com", "contraseña-segura", "Admin", "Sistema")
+	if err != nil || !created {
+		t.Fatalf("EnsureAdmin = %v, %v; se esperaba crear el administrador", created, err)
+	}
+
+	// El administrador creado debe poder cargarse como cualquier usuario para iniciar sesión
+	admin, err := service.GetUserByEmail("admin@test.com")
+	if err != nil {
+		t.Fatalf("error obteniendo administrador: %v", err)
+	}
+	if admin.Role != models.RoleAdmin {
+		t.Fatalf("rol = %s, se esperaba %s", admin.Role, models.RoleAdmin)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(admin.PasswordHash), []byte("contraseña-segura")) != nil {
+		t.Fatalf("la contraseña guardada no corresponde")
+	}
+
+	// Correrlo de nuevo no crea otro usuario ni cambia la contraseña
+	created, err = service.EnsureAdmin("admin@test.com", "otra-contraseña", "Admin", "Sistema")
+	if err != nil || created {
+		t.Fatalf("EnsureAdmin = %v, %v; se esperaba reutilizar el usuario", created, err)
+	}
+	again, err := service.GetUserByEmail("admin@test.com")
+	if err != nil {
+		t.Fatalf("error obteniendo administrador: %v", err)
+	}
+	if again.ID != admin.ID || again.PasswordHash != admin.PasswordHash {
+		t.Fatalf("el segundo EnsureAdmin modificó el usuario: %+v", again)
+	}
+}
@@ -1,8 +1,5 @@
--- booking-service/sql/init.sql
-CREATE DATABASE IF NOT EXISTS booking_db;
-USE booking_db;
+-- Esquema inicial de booking-service (equivalente al init.sql original)
 
--- Tabla de usuarios
 CREATE TABLE IF NOT EXISTS users (
     id INT AUTO_INCREMENT PRIMARY KEY,
     email VARCHAR(255) UNIQUE NOT NULL,
@@ -64,11 +61,11 @@ CREATE TABLE IF NOT EXISTS bookings (
     INDEX idx_created_at (created_at)
 );
 
--- Insertar mapeos de hoteles de ejemplo  
+-- Mapeos de hoteles de ejemplo
 INSERT INTO hotel_mappings (internal_hotel_id, amadeus_hotel_id, hotel_name, city) VALUES
 ('68618f6b6113de8e4703ea1d', 'YXPARKPR', 'Hotel Test Córdoba', 'Córdoba'),
 ('684da3fe381f2aeebaec3d54', 'ADPARADI', 'Hotel Córdoba Plaza', 'Córdoba')
 ON DUPLICATE KEY UPDATE 
     amadeus_hotel_id = VALUES(amadeus_hotel_id),
     hotel_name = VALUES(hotel_name),
-    city = VALUES(city);
\ No newline at end of file
+    city = VALUES(city);
@@ -0,0 +1,14 @@
+-- El código ya usaba users.role pero el init.sql original no la creaba.
+-- Algunas bases la agregaron a mano, por eso solo se crea si no existe.
+SET @has_role := (
+    SELECT COUNT(*) FROM information_schema.columns
+    WHERE table_schema = DATABASE() AND table_name = 'users' AND column_name = 'role'
+);
+
+SET @ddl := IF(@has_role = 0,
+    'ALTER TABLE users ADD COLUMN role ENUM(''user'', ''admin'') NOT NULL DEFAULT ''user'' AFTER date_of_birth',
+    'DO 0');
+
+PREPARE add_role FROM @ddl;
+EXECUTE add_role;
+DEALLOCATE PREPARE add_role;
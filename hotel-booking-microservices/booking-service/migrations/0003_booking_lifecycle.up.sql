@@ -0,0 +1,4 @@
+-- Datos de cancelación de reservas
+ALTER TABLE bookings
+    ADD COLUMN cancelled_at TIMESTAMP NULL AFTER special_requests,
+    ADD COLUMN cancellation_reason TEXT AFTER cancelled_at;
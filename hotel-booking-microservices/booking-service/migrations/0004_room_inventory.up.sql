@@ -0,0 +1,13 @@
+-- Inventario de habitaciones por hotel, tipo de habitación y noche
+CREATE TABLE IF NOT EXISTS room_inventory (
+    hotel_id VARCHAR(100) NOT NULL,
+    room_type VARCHAR(100) NOT NULL,
+    stay_date DATE NOT NULL,
+    total_rooms INT NOT NULL,
+    booked_rooms INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    
+    PRIMARY KEY (hotel_id, room_type, stay_date),
+    INDEX idx_stay_date (stay_date),
+    CHECK (booked_rooms >= 0)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS booking_price_lines;
+DROP TABLE IF EXISTS pricing_rules;
+DROP TABLE IF EXISTS room_rates;
@@ -0,0 +1,45 @@
+-- Tarifas base por noche y tipo de habitación
+CREATE TABLE IF NOT EXISTS room_rates (
+    hotel_id VARCHAR(100) NOT NULL,
+    room_type VARCHAR(100) NOT NULL,
+    stay_date DATE NOT NULL,
+    base_rate DECIMAL(10,2) NOT NULL,
+    currency VARCHAR(3) DEFAULT 'ARS',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    
+    PRIMARY KEY (hotel_id, room_type, stay_date)
+);
+
+-- Reglas de precio (temporadas, fin de semana, estadía y ocupación)
+CREATE TABLE IF NOT EXISTS pricing_rules (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    hotel_id VARCHAR(100) NOT NULL,
+    room_type VARCHAR(100) NULL,
+    rule_type ENUM('season', 'weekend', 'length_of_stay', 'occupancy') NOT NULL,
+    start_date DATE NULL,
+    end_date DATE NULL,
+    min_nights INT NULL,
+    min_occupancy DECIMAL(5,2) NULL,
+    adjustment_pct DECIMAL(6,2) NOT NULL DEFAULT 0,
+    fixed_rate DECIMAL(10,2) NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    
+    INDEX idx_hotel_active (hotel_id, is_active)
+);
+
+-- Desglose de precio por noche de cada reserva
+CREATE TABLE IF NOT EXISTS booking_price_lines (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    booking_id INT NOT NULL,
+    stay_date DATE NOT NULL,
+    base_rate DECIMAL(10,2) NOT NULL,
+    adjustments JSON NOT NULL,
+    price DECIMAL(10,2) NOT NULL,
+    currency VARCHAR(3) DEFAULT 'ARS',
+    
+    FOREIGN KEY (booking_id) REFERENCES bookings(id) ON DELETE CASCADE,
+    INDEX idx_booking_id (booking_id)
+);
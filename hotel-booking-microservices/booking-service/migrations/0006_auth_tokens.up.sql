@@ -0,0 +1,15 @@
+-- Versión de tokens para revocar sesiones sin consultar MySQL en cada request
+ALTER TABLE users ADD COLUMN token_version INT NOT NULL DEFAULT 0 AFTER is_active;
+
+-- Refresh tokens (solo se guarda el hash SHA-256)
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_user_id (user_id)
+);
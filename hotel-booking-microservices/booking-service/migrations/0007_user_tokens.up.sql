@@ -0,0 +1,18 @@
+-- Verificación de email. Los usuarios existentes se consideran verificados.
+ALTER TABLE users ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT FALSE AFTER email;
+
+UPDATE users SET email_verified = TRUE;
+
+-- Tokens de un solo uso enviados por email (solo se guarda el hash SHA-256)
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    purpose ENUM('password_reset', 'email_verification') NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_user_purpose (user_id, purpose)
+);
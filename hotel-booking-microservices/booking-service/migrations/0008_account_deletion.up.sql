@@ -0,0 +1,3 @@
+-- Fecha de eliminación de la cuenta. Los datos personales se anonimizan pero la
+-- fila se conserva porque bookings la referencia con ON DELETE RESTRICT.
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP NULL AFTER is_active;
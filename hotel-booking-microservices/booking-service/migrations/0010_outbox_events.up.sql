@@ -0,0 +1,17 @@
+-- Transactional outbox: los eventos se escriben en la misma transacción que la
+-- reserva y un proceso aparte los publica en RabbitMQ
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    event_id CHAR(36) NOT NULL UNIQUE,
+    aggregate_type VARCHAR(50) NOT NULL,
+    aggregate_id VARCHAR(64) NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSON NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP NULL,
+    
+    INDEX idx_pending (published_at, id),
+    INDEX idx_aggregate (aggregate_type, aggregate_id)
+);
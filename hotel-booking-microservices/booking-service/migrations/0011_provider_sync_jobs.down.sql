@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS provider_sync_jobs;
+ALTER TABLE bookings DROP COLUMN sync_status;
@@ -0,0 +1,24 @@
+-- Estado de sincronización de la reserva con el proveedor externo (Amadeus)
+ALTER TABLE bookings ADD COLUMN sync_status ENUM('not_required', 'pending', 'synced', 'failed') NOT NULL DEFAULT 'not_required' AFTER amadeus_booking_id;
+
+-- Cola persistente de sincronización con el proveedor. Los workers toman
+-- trabajos con un lease (locked_until) para que un proceso caído no los bloquee.
+CREATE TABLE IF NOT EXISTS provider_sync_jobs (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    booking_id INT NOT NULL,
+    operation ENUM('create', 'cancel') NOT NULL,
+    status ENUM('pending', 'running', 'succeeded', 'failed') NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL,
+    next_run_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_by VARCHAR(64) NULL,
+    locked_until TIMESTAMP NULL,
+    last_error TEXT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP NULL,
+    
+    FOREIGN KEY (booking_id) REFERENCES bookings(id) ON DELETE CASCADE,
+    INDEX idx_status_next_run (status, next_run_at),
+    INDEX idx_booking_id (booking_id)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS booking_guests;
+ALTER TABLE bookings DROP COLUMN amadeus_offer_id;
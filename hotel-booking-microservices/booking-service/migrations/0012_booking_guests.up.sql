@@ -0,0 +1,17 @@
+-- Oferta de Amadeus elegida al reservar (se vuelve a cotizar antes de confirmar)
+ALTER TABLE bookings ADD COLUMN amadeus_offer_id VARCHAR(255) NULL AFTER amadeus_booking_id;
+
+-- Huéspedes de la reserva. El primero es el titular y recibe las comunicaciones.
+CREATE TABLE IF NOT EXISTS booking_guests (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    booking_id INT NOT NULL,
+    position INT NOT NULL,
+    title VARCHAR(10) NULL,
+    first_name VARCHAR(50) NOT NULL,
+    last_name VARCHAR(50) NOT NULL,
+    email VARCHAR(255) NULL,
+    phone VARCHAR(20) NULL,
+    
+    FOREIGN KEY (booking_id) REFERENCES bookings(id) ON DELETE CASCADE,
+    UNIQUE KEY uk_booking_position (booking_id, position)
+);
@@ -0,0 +1,5 @@
+ALTER TABLE bookings DROP COLUMN provider;
+
+DELETE FROM hotel_mappings WHERE amadeus_hotel_id IS NULL;
+ALTER TABLE hotel_mappings MODIFY amadeus_hotel_id VARCHAR(100) NOT NULL;
+ALTER TABLE hotel_mappings DROP COLUMN provider;
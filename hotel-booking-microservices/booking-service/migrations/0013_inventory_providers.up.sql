@@ -0,0 +1,9 @@
+-- Proveedor de inventario de cada hotel: amadeus, local o simulated. Los hoteles
+-- locales no tienen ID externo.
+ALTER TABLE hotel_mappings ADD COLUMN provider VARCHAR(20) NOT NULL DEFAULT 'amadeus' AFTER internal_hotel_id;
+ALTER TABLE hotel_mappings MODIFY amadeus_hotel_id VARCHAR(100) NULL;
+
+-- Proveedor con el que se hizo cada reserva. Las columnas amadeus_* guardan los
+-- IDs del proveedor externo, sea cual sea.
+ALTER TABLE bookings ADD COLUMN provider VARCHAR(20) NOT NULL DEFAULT 'local' AFTER internal_hotel_id;
+UPDATE bookings SET provider = 'amadeus' WHERE sync_status <> 'not_required' OR amadeus_booking_id IS NOT NULL;
@@ -0,0 +1,5 @@
+ALTER TABLE provider_sync_jobs DROP FOREIGN KEY fk_sync_jobs_room;
+ALTER TABLE provider_sync_jobs DROP COLUMN booking_room_id;
+ALTER TABLE booking_price_lines DROP FOREIGN KEY fk_price_lines_room;
+ALTER TABLE booking_price_lines DROP COLUMN booking_room_id;
+DROP TABLE IF EXISTS booking_rooms;
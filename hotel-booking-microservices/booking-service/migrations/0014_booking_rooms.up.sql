@@ -0,0 +1,36 @@
+-- Habitaciones de cada reserva. bookings queda como cabecera: sus huéspedes y
+-- su total son la suma de las habitaciones activas.
+CREATE TABLE IF NOT EXISTS booking_rooms (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    booking_id INT NOT NULL,
+    position INT NOT NULL,
+    room_type VARCHAR(100) NOT NULL,
+    guests INT NOT NULL,
+    total_price DECIMAL(10,2) NOT NULL DEFAULT 0.00,
+    currency VARCHAR(3) NOT NULL DEFAULT 'ARS',
+    provider_offer_id VARCHAR(255) NULL,
+    provider_booking_id VARCHAR(100) NULL,
+    status ENUM('active', 'cancelled') NOT NULL DEFAULT 'active',
+    cancelled_at TIMESTAMP NULL,
+    cancellation_reason TEXT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    
+    FOREIGN KEY (booking_id) REFERENCES bookings(id) ON DELETE CASCADE,
+    UNIQUE KEY uk_booking_room_position (booking_id, position)
+);
+
+-- Las reservas existentes pasan a tener una habitación
+INSERT INTO booking_rooms (booking_id, position, room_type, guests, total_price, currency, provider_offer_id, provider_booking_id, status, cancelled_at, cancellation_reason)
+SELECT id, 1, COALESCE(room_type, 'standard'), guests, total_price, currency, amadeus_offer_id, amadeus_booking_id,
+       IF(status = 'cancelled', 'cancelled', 'active'), cancelled_at, cancellation_reason
+FROM bookings;
+
+-- Desglose por noche de cada habitación
+ALTER TABLE booking_price_lines ADD COLUMN booking_room_id INT NULL AFTER booking_id;
+ALTER TABLE booking_price_lines ADD CONSTRAINT fk_price_lines_room FOREIGN KEY (booking_room_id) REFERENCES booking_rooms(id) ON DELETE CASCADE;
+UPDATE booking_price_lines pl JOIN booking_rooms r ON r.booking_id = pl.booking_id SET pl.booking_room_id = r.id;
+
+-- Las cancelaciones en el proveedor son por habitación
+ALTER TABLE provider_sync_jobs ADD COLUMN booking_room_id INT NULL AFTER booking_id;
+ALTER TABLE provider_sync_jobs ADD CONSTRAINT fk_sync_jobs_room FOREIGN KEY (booking_room_id) REFERENCES booking_rooms(id) ON DELETE CASCADE;
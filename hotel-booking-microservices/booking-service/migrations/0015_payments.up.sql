@@ -0,0 +1,44 @@
+-- Pagos de las reservas. La reserva queda pendiente hasta que la pasarela
+-- autoriza el cobro. amount_due es lo que el cliente debe pagar: baja con las
+-- cancelaciones y el worker de pagos captura, libera o reintegra hasta que lo
+-- cobrado (captured_amount - refunded_amount) coincida.
+CREATE TABLE IF NOT EXISTS payments (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    booking_id INT NOT NULL,
+    gateway VARCHAR(50) NOT NULL,
+    transaction_id VARCHAR(100) NULL,
+    status ENUM('pending', 'authorized', 'captured', 'refunded', 'voided', 'declined', 'failed') NOT NULL DEFAULT 'pending',
+    amount DECIMAL(10,2) NOT NULL,
+    amount_due DECIMAL(10,2) NOT NULL,
+    captured_amount DECIMAL(10,2) NOT NULL DEFAULT 0.00,
+    refunded_amount DECIMAL(10,2) NOT NULL DEFAULT 0.00,
+    currency VARCHAR(3) NOT NULL,
+    failure_reason VARCHAR(255) NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NULL,
+    last_error TEXT NULL,
+    locked_by VARCHAR(64) NULL,
+    locked_until TIMESTAMP NULL,
+    authorized_at TIMESTAMP NULL,
+    captured_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    
+    FOREIGN KEY (booking_id) REFERENCES bookings(id) ON DELETE CASCADE,
+    UNIQUE KEY uk_payment_transaction (gateway, transaction_id),
+    INDEX idx_payment_booking (booking_id),
+    INDEX idx_payment_next_attempt (next_attempt_at)
+);
+
+-- Webhooks recibidos de la pasarela; el ID único descarta las entregas repetidas
+CREATE TABLE IF NOT EXISTS payment_events (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    event_id VARCHAR(100) NOT NULL,
+    payment_id INT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload JSON NOT NULL,
+    received_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    
+    FOREIGN KEY (payment_id) REFERENCES payments(id) ON DELETE CASCADE,
+    UNIQUE KEY uk_payment_event (event_id)
+);
@@ -0,0 +1 @@
+ALTER TABLE booking_rooms DROP COLUMN cancellation_fee, DROP COLUMN cancellation_policy;
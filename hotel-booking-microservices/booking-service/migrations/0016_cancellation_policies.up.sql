@@ -0,0 +1,6 @@
+-- Política de cancelación de cada habitación, copiada al reservar: los cambios
+-- posteriores en hotel-service no afectan a las reservas existentes. Las
+-- reservas anteriores quedan sin política y conservan el plazo configurado.
+ALTER TABLE booking_rooms
+    ADD COLUMN cancellation_policy JSON NULL AFTER provider_booking_id,
+    ADD COLUMN cancellation_fee DECIMAL(10,2) NULL AFTER cancellation_reason;
@@ -0,0 +1,21 @@
+-- Claves de idempotencia de la creación de reservas. Un reintento con la misma
+-- clave repite la respuesta guardada en lugar de crear otra reserva.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    status ENUM('in_progress', 'completed') NOT NULL DEFAULT 'in_progress',
+    response_status INT NULL,
+    response_body MEDIUMTEXT NULL,
+    booking_id INT NULL,
+    locked_until TIMESTAMP NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (booking_id) REFERENCES bookings(id) ON DELETE SET NULL,
+    UNIQUE KEY uk_idempotency_user_key (user_id, idempotency_key),
+    INDEX idx_idempotency_expires (expires_at)
+);
@@ -0,0 +1,7 @@
+-- Motivo por el que se revocó cada refresh token. Solo reutilizar uno rotado
+-- indica robo; los cerrados por logout o por revocación de sesiones se rechazan
+-- sin cerrar el resto de las sesiones.
+ALTER TABLE refresh_tokens ADD COLUMN revoked_reason ENUM('rotated', 'logout', 'revoked') NULL AFTER revoked_at;
+
+-- El motivo de los ya revocados no se conoce: no se tratan como rotados
+UPDATE refresh_tokens SET revoked_reason = 'revoked' WHERE revoked_at IS NOT NULL;
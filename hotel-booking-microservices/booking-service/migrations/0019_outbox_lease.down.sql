@@ -0,0 +1,4 @@
+ALTER TABLE outbox_events
+    DROP INDEX idx_locked_by,
+    DROP COLUMN locked_until,
+    DROP COLUMN locked_by;
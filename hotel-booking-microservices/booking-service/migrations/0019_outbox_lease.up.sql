@@ -0,0 +1,7 @@
+-- Lease del relay de outbox: cada relay toma un lote con locked_by/locked_until
+-- y lo publica fuera de la transacción. Un relay caído no bloquea los eventos,
+-- otro los vuelve a tomar cuando vence el lease.
+ALTER TABLE outbox_events
+    ADD COLUMN locked_by VARCHAR(64) NULL AFTER last_error,
+    ADD COLUMN locked_until TIMESTAMP NULL AFTER locked_by,
+    ADD INDEX idx_locked_by (locked_by);
@@ -0,0 +1,10 @@
+// Package migrations contiene las migraciones versionadas del esquema de booking-service.
+// Cada versión tiene un archivo NNNN_nombre.up.sql y su NNNN_nombre.down.sql.
+package migrations
+
+import "embed"
+
+// FS archivos de migración embebidos en el binario
+//
+//go:embed *.sql
+var FS embed.FS
@@ -2,15 +2,18 @@ package amadeus
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"net/url"
-	"strings"
+	"sync"
 	"time"
 
+	"golang.org/x/sync/singleflight"
+
 	"booking-service/internal/models"
 )
 
@@ -20,124 +23,120 @@ type Client struct {
 	clientID     string
 	clientSecret string
 	httpClient   *http.Client
-	accessToken  string
-	tokenExpiry  time.Time
+	opts         Options
+	limiter      *rateLimiter
+	breaker      *circuitBreaker
+
+	// Token compartido por todas las goroutines; se renueva con singleflight
+	mu            sync.RWMutex
+	accessToken   string
+	tokenExpiry   time.Time
+	tokenGroup    singleflight.Group
+	tokenCache    TokenCache
+	tokenCacheKey string
 }
 
 // NewClient crea un nuevo cliente de Amadeus
-func NewClient(baseURL, clientID, clientSecret string) *Client {
+func NewClient(baseURL, clientID, clientSecret string, opts Options) *Client {
+	opts = opts.withDefaults()
 	return &Client{
 		baseURL:      baseURL,
 		clientID:     clientID,
 		clientSecret: clientSecret,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout: opts.Timeout,
 		},
+		opts:    opts,
+		limiter: newRateLimiter(opts.RateLimit, opts.RateBurst),
+		breaker: newCircuitBreaker(opts.BreakerThreshold, opts.BreakerCooldown),
 	}
 }
 
-// GetAccessToken obtiene un token de acceso de Amadeus
-func (c *Client) GetAccessToken() error {
-	// Si el token es válido y no ha expirado, no hacer nada
-	if c.accessToken != "" && time.Now().Before(c.tokenExpiry.Add(-5*time.Minute)) {
-		return nil
-	}
-
-	log.Println("🔑 Obteniendo nuevo token de Amadeus...")
-
-	// Preparar datos del formulario
-	data := url.Values{}
-	data.Set("grant_type", "client_credentials")
-	data.Set("client_id", c.clientID)
-	data.Set("client_secret", c.clientSecret)
+// SearchHotelsByCity busca hoteles en una ciudad
+func (c *Client) SearchHotelsByCity(ctx context.Context, cityCode string) ([]models.AmadeusHotel, error) {
+	// Construir URL
+	endpoint := fmt.Sprintf("%s/v1/reference-data/locations/hotels/by-city?cityCode=%s", c.baseURL, cityCode)
 
 	// Crear petición
-	req, err := http.NewRequest("POST", c.baseURL+"/v1/security/oauth2/token", strings.NewReader(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
-		return fmt.Errorf("error creando petición: %v", err)
+		return nil, fmt.Errorf("error creando petición: %v", err)
 	}
 
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
 	// Ejecutar petición
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return fmt.Errorf("error ejecutando petición: %v", err)
+		return nil, fmt.Errorf("error ejecutando petición: %v", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("error obteniendo token: %d - %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("error buscando hoteles: %d - %s", resp.StatusCode, string(body))
 	}
 
 	// Parsear respuesta
-	var tokenResp models.AmadeusTokenResponse
-	err = json.NewDecoder(resp.Body).Decode(&tokenResp)
+	var response struct {
+		Data []models.AmadeusHotel `json:"data"`
+	}
+	
+	err = json.NewDecoder(resp.Body).Decode(&response)
 	if err != nil {
-		return fmt.Errorf("error parseando respuesta: %v", err)
+		return nil, fmt.Errorf("error parseando respuesta: %v", err)
 	}
 
-	// Guardar token
-	c.accessToken = tokenResp.AccessToken
-	c.tokenExpiry = time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
-
-	log.Printf("✅ Token de Amadeus obtenido, expira en %d segundos", tokenResp.ExpiresIn)
-	return nil
+	return response.Data, nil
 }
 
-// SearchHotelsByCity busca hoteles en una ciudad
-func (c *Client) SearchHotelsByCity(cityCode string) ([]models.AmadeusHotel, error) {
-	// Asegurar que tenemos token válido
-	err := c.GetAccessToken()
-	if err != nil {
-		return nil, err
-	}
+// GetHotel busca un hotel por su ID de Amadeus. Si no existe, el error
+// contiene "no encontrado".
+func (c *Client) GetHotel(ctx context.Context, hotelID string) (*models.AmadeusHotel, error) {
+	params := url.Values{}
+	params.Add("hotelIds", hotelID)
 
-	// Construir URL
-	endpoint := fmt.Sprintf("%s/v1/reference-data/locations/hotels/by-city?cityCode=%s", c.baseURL, cityCode)
+	endpoint := fmt.Sprintf("%s/v1/reference-data/locations/hotels/by-hotels?%s", c.baseURL, params.Encode())
 
 	// Crear petición
-	req, err := http.NewRequest("GET", endpoint, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creando petición: %v", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.accessToken)
-
 	// Ejecutar petición
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("error ejecutando petición: %v", err)
 	}
 	defer resp.Body.Close()
 
+	// Amadeus responde 400 ("NOTHING FOUND") para IDs inexistentes
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusBadRequest {
+		return nil, fmt.Errorf("hotel de Amadeus %s no encontrado", hotelID)
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("error buscando hoteles: %d - %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("error buscando hotel: %d - %s", resp.StatusCode, string(body))
 	}
 
 	// Parsear respuesta
 	var response struct {
 		Data []models.AmadeusHotel `json:"data"`
 	}
-	
-	err = json.NewDecoder(resp.Body).Decode(&response)
-	if err != nil {
+
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
 		return nil, fmt.Errorf("error parseando respuesta: %v", err)
 	}
 
-	return response.Data, nil
+	if len(response.Data) == 0 {
+		return nil, fmt.Errorf("hotel de Amadeus %s no encontrado", hotelID)
+	}
+
+	return &response.Data[0], nil
 }
 
 // GetHotelOffers obtiene ofertas de un hotel específico
-func (c *Client) GetHotelOffers(hotelID, checkInDate, checkOutDate string, adults int) ([]models.AmadeusHotelOffer, error) {
-	// Asegurar que tenemos token válido
-	err := c.GetAccessToken()
-	if err != nil {
-		return nil, err
-	}
-
+func (c *Client) GetHotelOffers(ctx context.Context, hotelID, checkInDate, checkOutDate string, adults int) ([]models.AmadeusHotelOffer, error) {
 	// Construir URL con parámetros
 	params := url.Values{}
 	params.Add("hotelIds", hotelID)
@@ -148,15 +147,13 @@ func (c *Client) GetHotelOffers(hotelID, checkInDate, checkOutDate string, adult
 	endpoint := fmt.Sprintf("%s/v3/shopping/hotel-offers?%s", c.baseURL, params.Encode())
 
 	// Crear petición
-	req, err := http.NewRequest("GET", endpoint, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creando petición: %v", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.accessToken)
-
 	// Ejecutar petición
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("error ejecutando petición: %v", err)
 	}
@@ -180,20 +177,58 @@ func (c *Client) GetHotelOffers(hotelID, checkInDate, checkOutDate string, adult
 	return response.Data, nil
 }
 
-// CreateBooking crea una reserva en Amadeus
-func (c *Client) CreateBooking(offerID string, guestInfo map[string]interface{}) (string, error) {
-	// Asegurar que tenemos token válido
-	err := c.GetAccessToken()
+// GetOffer vuelve a cotizar una oferta antes de reservar. Amadeus responde 404
+// (o available=false) si la oferta ya no existe.
+func (c *Client) GetOffer(ctx context.Context, offerID string) (*models.AmadeusHotelOffer, error) {
+	endpoint := fmt.Sprintf("%s/v3/shopping/hotel-offers/%s", c.baseURL, url.PathEscape(offerID))
+
+	// Crear petición
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creando petición: %v", err)
+	}
+
+	// Ejecutar petición
+	resp, err := c.do(req)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("error ejecutando petición: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("oferta no disponible")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("error obteniendo oferta: %d - %s", resp.StatusCode, string(body))
+	}
+
+	// Parsear respuesta
+	var response struct {
+		Data models.AmadeusHotelOffer `json:"data"`
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return nil, fmt.Errorf("error parseando respuesta: %v", err)
 	}
 
+	if !response.Data.Available || len(response.Data.Offers) == 0 {
+		return nil, fmt.Errorf("oferta no disponible")
+	}
+
+	return &response.Data, nil
+}
+
+// CreateBooking crea una reserva en Amadeus a partir de una oferta vigente
+func (c *Client) CreateBooking(ctx context.Context, offerID string, guests []models.AmadeusBookingGuest, payment models.AmadeusPayment) (string, error) {
 	// Preparar datos de la reserva
 	bookingData := map[string]interface{}{
 		"data": map[string]interface{}{
-			"type":    "hotel-booking",
-			"hotelId": offerID,
-			"guests":  guestInfo,
+			"offerId":  offerID,
+			"guests":   guests,
+			"payments": []models.AmadeusPayment{payment},
 		},
 	}
 
@@ -205,16 +240,15 @@ func (c *Client) CreateBooking(offerID string, guestInfo map[string]interface{})
 
 	// Crear petición
 	endpoint := c.baseURL + "/v1/booking/hotel-bookings"
-	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", fmt.Errorf("error creando petición: %v", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.accessToken)
 	req.Header.Set("Content-Type", "application/json")
 
 	// Ejecutar petición
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return "", fmt.Errorf("error ejecutando petición: %v", err)
 	}
@@ -222,27 +256,50 @@ func (c *Client) CreateBooking(offerID string, guestInfo map[string]interface{})
 
 	body, _ := io.ReadAll(resp.Body)
 
-	if resp.StatusCode != http.StatusCreated {
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
 		return "", fmt.Errorf("error creando reserva: %d - %s", resp.StatusCode, string(body))
 	}
 
-	// Parsear respuesta para obtener ID de reserva
+	// La respuesta trae una reserva por habitación; se guarda la primera
 	var response struct {
-		Data struct {
+		Data []struct {
 			ID string `json:"id"`
 		} `json:"data"`
 	}
-	
+
 	err = json.Unmarshal(body, &response)
 	if err != nil {
 		return "", fmt.Errorf("error parseando respuesta: %v", err)
 	}
+	if len(response.Data) == 0 || response.Data[0].ID == "" {
+		return "", fmt.Errorf("respuesta de reserva sin ID: %s", string(body))
+	}
 
-	log.Printf("✅ Reserva creada en Amadeus: %s", response.Data.ID)
-	return response.Data.ID, nil
+	log.Printf("✅ Reserva creada en Amadeus: %s", response.Data[0].ID)
+	return response.Data[0].ID, nil
 }
 
-// IsTokenValid verifica si el token actual es válido
-func (c *Client) IsTokenValid() bool {
-	return c.accessToken != "" && time.Now().Before(c.tokenExpiry.Add(-5*time.Minute))
+// CancelBooking cancela una reserva existente en Amadeus
+func (c *Client) CancelBooking(ctx context.Context, bookingID string) error {
+	// Crear petición
+	endpoint := fmt.Sprintf("%s/v1/booking/hotel-bookings/%s", c.baseURL, url.PathEscape(bookingID))
+	req, err := http.NewRequestWithContext(ctx, "DELETE", endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("error creando petición: %v", err)
+	}
+
+	// Ejecutar petición
+	resp, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("error ejecutando petición: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("error cancelando reserva: %d - %s", resp.StatusCode, string(body))
+	}
+
+	log.Printf("🚫 Reserva cancelada en Amadeus: %s", bookingID)
+	return nil
 }
\ No newline at end of file
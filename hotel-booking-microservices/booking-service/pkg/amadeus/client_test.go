@@ -0,0 +1,187 @@
+package amadeus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"booking-service/internal/models"
+)
+
+// newTestClient levanta un Amadeus de prueba: responde el token y delega el
+// resto de las rutas en handler, verificando que lleguen autenticadas
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == tokenPath {
+			json.NewEncoder(w).Encode(models.AmadeusTokenResponse{AccessToken: "test-token", ExpiresIn: 1799})
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			http.Error(w, "sin token", http.StatusUnauthorized)
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return NewClient(server.URL, "id", "secret", Options{})
+}
+
+// offerResponse respuesta de GET /v3/shopping/hotel-offers/{offerId}
+func offerResponse(offerID, total string, available bool) map[string]interface{} {
+	return map[string]interface{}{
+		"data": map[string]interface{}{
+			"type":      "hotel-offers",
+			"available": available,
+			"hotel":     map[string]interface{}{"hotelId": "YXPARKPR"},
+			"offers": []map[string]interface{}{{
+				"id":    offerID,
+				"price": map[string]interface{}{"currency": "EUR", "total": total},
+			}},
+		},
+	}
+}
+
+func TestCreateBookingSendsOfferID(t *testing.T) {
+	guests := []models.AmadeusBookingGuest{{
+		Name:    models.AmadeusGuestName{Title: "MS", FirstName: "Ana", LastName: "Pérez"},
+		Contact: models.AmadeusGuestContact{Phone: "+5493510000000", Email: "ana@test.com"},
+	}}
+	payment := models.AmadeusPayment{
+		Method: "creditCard",
+		Card:   models.AmadeusPaymentCard{VendorCode: "VI", CardNumber: "4111111111111111", ExpiryDate: "2030-01"},
+	}
+
+	var received struct {
+		Data struct {
+			OfferID  string                       `json:"offerId"`
+			Guests   []models.AmadeusBookingGuest `json:"guests"`
+			Payments []models.AmadeusPayment      `json:"payments"`
+		} `json:"data"`
+	}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/booking/hotel-bookings" {
+			http.Error(w, "ruta inesperada", http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "content type", http.StatusBadRequest)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"data":[{"type":"hotel-booking","id":"BOOK-1"},{"type":"hotel-booking","id":"BOOK-2"}]}`))
+	})
+
+	bookingID, err := client.CreateBooking(context.Background(), "OFFER-1", guests, payment)
+	if err != nil {
+		t.Fatalf("error creando reserva: %v", err)
+	}
+	if bookingID != "BOOK-1" {
+		t.Fatalf("ID de reserva = %s, se esperaba BOOK-1", bookingID)
+	}
+
+	if received.Data.OfferID != "OFFER-1" {
+		t.Fatalf("offerId = %q, se esperaba OFFER-1", received.Data.OfferID)
+	}
+	if len(received.Data.Guests) != 1 || received.Data.Guests[0] != guests[0] {
+		t.Fatalf("huéspedes enviados = %+v", received.Data.Guests)
+	}
+	if len(received.Data.Payments) != 1 || received.Data.Payments[0] != payment {
+		t.Fatalf("pagos enviados = %+v", received.Data.Payments)
+	}
+}
+
+func TestCreateBookingErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{name: "oferta rechazada", status: http.StatusBadRequest, body: `{"errors":[{"code":3664}]}`, wantErr: "error creando reserva: 400"},
+		{name: "respuesta sin ID", status: http.StatusCreated, body: `{"data":[]}`, wantErr: "sin ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			_, err := client.CreateBooking(context.Background(), "OFFER-1", nil, models.AmadeusPayment{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, se esperaba que contenga %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetOfferRequotes(t *testing.T) {
+	var path string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		path = r.Method + " " + r.URL.EscapedPath()
+		json.NewEncoder(w).Encode(offerResponse("OFFER 1", "250.50", true))
+	})
+
+	offer, err := client.GetOffer(context.Background(), "OFFER 1")
+	if err != nil {
+		t.Fatalf("error obteniendo oferta: %v", err)
+	}
+	if path != "GET /v3/shopping/hotel-offers/OFFER%201" {
+		t.Fatalf("petición = %s", path)
+	}
+	if len(offer.Offers) != 1 || offer.Offers[0].ID != "OFFER 1" || offer.Offers[0].Price.Total != "250.50" {
+		t.Fatalf("oferta = %+v", offer.Offers)
+	}
+}
+
+func TestGetOfferUnavailable(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		wantErr string
+	}{
+		{
+			name: "oferta vencida",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, `{"errors":[{"code":1257}]}`, http.StatusNotFound)
+			},
+			wantErr: "oferta no disponible",
+		},
+		{
+			name: "sin disponibilidad",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(offerResponse("OFFER-1", "100.00", false))
+			},
+			wantErr: "oferta no disponible",
+		},
+		{
+			name: "error de Amadeus",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "bad request", http.StatusBadRequest)
+			},
+			wantErr: "error obteniendo oferta: 400",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, tt.handler)
+
+			_, err := client.GetOffer(context.Background(), "OFFER-1")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, se esperaba que contenga %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package amadeus
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Options parámetros de resiliencia del cliente. Los valores en cero toman un
+// default razonable; RateLimit y BreakerThreshold en cero deshabilitan el
+// limitador y el circuit breaker.
+type Options struct {
+	Timeout          time.Duration // por intento
+	MaxRetries       int
+	RetryBaseDelay   time.Duration
+	RetryMaxDelay    time.Duration // también es la espera máxima aceptada en Retry-After
+	RateLimit        float64       // peticiones por segundo permitidas por la cuota de la API
+	RateBurst        int
+	BreakerThreshold int // fallos seguidos que abren el circuito
+	BreakerCooldown  time.Duration
+}
+
+// ErrCircuitOpen se devuelve sin llamar a Amadeus mientras el circuito está abierto
+var ErrCircuitOpen = errors.New("circuito abierto: Amadeus no disponible temporalmente")
+
+func (o Options) withDefaults() Options {
+	if o.Timeout <= 0 {
+		o.Timeout = 30 * time.Second
+	}
+	if o.RetryBaseDelay <= 0 {
+		o.RetryBaseDelay = 500 * time.Millisecond
+	}
+	if o.RetryMaxDelay <= 0 {
+		o.RetryMaxDelay = 10 * time.Second
+	}
+	if o.RateBurst < 1 {
+		o.RateBurst = 1
+	}
+	if o.BreakerCooldown <= 0 {
+		o.BreakerCooldown = 30 * time.Second
+	}
+	return o
+}
+
+// send ejecuta la petición respetando el limitador y el circuit breaker, y la
+// reintenta ante 429 o errores del servidor. Los POST (salvo el token) solo se
+// reintentan con 429: ante un 5xx o un corte no se sabe si la reserva se creó.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	idempotent := req.Method != http.MethodPost || req.URL.Path == tokenPath
+
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+		probe, err := c.breaker.allow()
+		if err != nil {
+			return nil, err
+		}
+
+		try := req
+		if attempt > 0 {
+			var err error
+			if try, err = rewind(req); err != nil {
+				c.breaker.record(outcomeIgnored, probe)
+				return nil, err
+			}
+		}
+
+		resp, err := c.httpClient.Do(try)
+		switch {
+		case ctx.Err() != nil:
+			c.breaker.record(outcomeIgnored, probe)
+		case err != nil || resp.StatusCode >= 500:
+			c.breaker.record(outcomeFailure, probe)
+		default:
+			c.breaker.record(outcomeSuccess, probe)
+		}
+
+		retry := false
+		switch {
+		case ctx.Err() != nil:
+		case err != nil:
+			retry = idempotent
+		case resp.StatusCode == http.StatusTooManyRequests:
+			retry = true
+		case resp.StatusCode >= 500:
+			retry = idempotent
+		}
+		if !retry || attempt >= c.opts.MaxRetries {
+			return resp, err
+		}
+
+		delay, ok := c.retryDelay(attempt, resp)
+		if !ok {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		log.Printf("🔁 Reintentando %s %s en %v (intento %d/%d)", req.Method, req.URL.Path, delay, attempt+1, c.opts.MaxRetries)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// retryDelay calcula la espera antes del próximo intento: Retry-After si el
+// servidor lo informa, o backoff exponencial con jitter. Devuelve false si el
+// servidor pide esperar más que RetryMaxDelay.
+func (c *Client) retryDelay(attempt int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil {
+		if after, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return after, after <= c.opts.RetryMaxDelay
+		}
+	}
+
+	delay := c.opts.RetryBaseDelay << uint(attempt)
+	if delay <= 0 || delay > c.opts.RetryMaxDelay {
+		delay = c.opts.RetryMaxDelay
+	}
+	// Jitter: entre la mitad y el total, para que los clientes no reintenten a la vez
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1)), true
+}
+
+// parseRetryAfter interpreta Retry-After en segundos o como fecha HTTP
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// rewind prepara la petición para reenviarla con el cuerpo desde el inicio
+func rewind(req *http.Request) (*http.Request, error) {
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+	return retry, nil
+}
+
+// rateLimiter token bucket del lado del cliente para no superar la cuota de la API
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter crea el limitador. Con rate <= 0 no limita.
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	return &rateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// wait bloquea hasta que haya un token disponible o se cancele el contexto
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.last = now
+
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// Resultado de un intento, para el circuit breaker
+type outcome int
+
+const (
+	outcomeSuccess outcome = iota
+	outcomeFailure
+	outcomeIgnored // cancelado por el llamador: no dice nada de Amadeus
+)
+
+// circuitBreaker deja de llamar a Amadeus tras varios fallos seguidos. Pasado
+// el cooldown deja pasar una sola petición de prueba (half-open): si funciona se
+// cierra, si falla vuelve a abrirse.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	probing   bool
+	now       func() time.Time // reloj, reemplazable en los tests
+}
+
+// newCircuitBreaker crea el breaker. Con threshold <= 0 queda deshabilitado.
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	if threshold <= 0 {
+		return nil
+	}
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// allow indica si se puede llamar a Amadeus. probe es true para la petición de
+// prueba del circuito semiabierto, la única cuyo resultado lo cierra o reabre.
+func (b *circuitBreaker) allow() (probe bool, err error) {
+	if b == nil {
+		return false, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return false, nil
+	}
+	if b.now().Before(b.openUntil) || b.probing {
+		return false, ErrCircuitOpen
+	}
+	b.probing = true
+	return true, nil
+}
+
+// record registra el resultado de un intento autorizado por allow. Con el
+// circuito abierto solo cuenta la prueba: las peticiones admitidas antes de que
+// se abriera no dicen nada del estado actual de Amadeus, y si liberaran la
+// prueba dejarían pasar otra mientras la primera sigue en curso.
+func (b *circuitBreaker) record(result outcome, probe bool) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	wasOpen := b.failures >= b.threshold
+	if probe {
+		b.probing = false
+	} else if wasOpen {
+		return
+	}
+
+	switch result {
+	case outcomeSuccess:
+		if wasOpen {
+			log.Printf("✅ Circuito de Amadeus cerrado")
+		}
+		b.failures = 0
+	case outcomeFailure:
+		b.failures++
+		if b.failures >= b.threshold {
+			b.openUntil = b.now().Add(b.cooldown)
+			log.Printf("⚡ Circuito de Amadeus abierto por %v tras %d fallos seguidos", b.cooldown, b.failures)
+		}
+	}
+}
+
+// CircuitOpen indica si el circuito está abierto (Amadeus se considera caído)
+func (c *Client) CircuitOpen() bool {
+	b := c.breaker
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures >= b.threshold && b.now().Before(b.openUntil)
+}
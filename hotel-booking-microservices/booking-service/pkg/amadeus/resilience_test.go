@@ -0,0 +1,433 @@
+package amadeus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"booking-service/internal/models"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		min    time.Duration
+		max    time.Duration
+		wantOK bool
+	}{
+		{name: "vacío", value: ""},
+		{name: "segundos", value: "3", min: 3 * time.Second, max: 3 * time.Second, wantOK: true},
+		{name: "cero", value: "0", wantOK: true},
+		{name: "negativo", value: "-1"},
+		{name: "inválido", value: "pronto"},
+		{name: "fecha futura", value: time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat), min: 8 * time.Second, max: 10 * time.Second, wantOK: true},
+		{name: "fecha pasada", value: time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, se esperaba %v", ok, tt.wantOK)
+			}
+			if got < tt.min || got > tt.max {
+				t.Fatalf("espera = %v, se esperaba entre %v y %v", got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	client := NewClient("", "", "", Options{RetryBaseDelay: 100 * time.Millisecond, RetryMaxDelay: time.Second})
+
+	// Backoff exponencial con jitter, acotado por RetryMaxDelay
+	for attempt, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		delay, ok := client.retryDelay(attempt, nil)
+		if !ok || delay < want/2 || delay > want {
+			t.Fatalf("intento %d: espera = %v (%v), se esperaba entre %v y %v", attempt, delay, ok, want/2, want)
+		}
+	}
+
+	// Retry-After manda sobre el backoff, salvo que pida esperar demasiado
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"1"}}}
+	if delay, ok := client.retryDelay(0, resp); !ok || delay != time.Second {
+		t.Fatalf("espera = %v (%v), se esperaba 1s", delay, ok)
+	}
+	resp.Header.Set("Retry-After", "30")
+	if _, ok := client.retryDelay(0, resp); ok {
+		t.Fatalf("se aceptó un Retry-After mayor que RetryMaxDelay")
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	if newRateLimiter(0, 1) != nil {
+		t.Fatalf("con rate 0 el limitador debe quedar deshabilitado")
+	}
+	var disabled *rateLimiter
+	if err := disabled.wait(context.Background()); err != nil {
+		t.Fatalf("un limitador deshabilitado no debe bloquear: %v", err)
+	}
+
+	limiter := newRateLimiter(20, 2)
+	ctx := context.Background()
+
+	// La ráfaga pasa sin esperar
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if err := limiter.wait(ctx); err != nil {
+			t.Fatalf("error esperando: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Fatalf("la ráfaga esperó %v", elapsed)
+	}
+
+	// Agotada la ráfaga, el siguiente token tarda 1/rate
+	start = time.Now()
+	if err := limiter.wait(ctx); err != nil {
+		t.Fatalf("error esperando: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Fatalf("el tercer pedido esperó solo %v, se esperaban ~50ms", elapsed)
+	}
+
+	// La espera se corta con el contexto
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := limiter.wait(cancelled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v, se esperaba context.Canceled", err)
+	}
+}
+
+// fakeClock reloj manual para el circuit breaker
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, time.March, 2, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	clock := newFakeClock()
+	breaker := newCircuitBreaker(2, 30*time.Second)
+	breaker.now = clock.Now
+
+	mustAllow := func(wantProbe bool) bool {
+		t.Helper()
+		probe, err := breaker.allow()
+		if err != nil {
+			t.Fatalf("se esperaba permitir la petición: %v", err)
+		}
+		if probe != wantProbe {
+			t.Fatalf("probe = %v, se esperaba %v", probe, wantProbe)
+		}
+		return probe
+	}
+	mustReject := func() {
+		t.Helper()
+		if _, err := breaker.allow(); !errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("error = %v, se esperaba circuito abierto", err)
+		}
+	}
+
+	// Un éxito reinicia la cuenta de fallos seguidos
+	breaker.record(outcomeFailure, mustAllow(false))
+	breaker.record(outcomeSuccess, mustAllow(false))
+	breaker.record(outcomeFailure, mustAllow(false))
+	mustAllow(false)
+
+	// Dos peticiones en curso fallan: se abre el circuito. Una tercera,
+	// admitida antes de abrirse, sigue en curso.
+	inFlight := mustAllow(false)
+	breaker.record(outcomeFailure, false)
+	mustReject()
+
+	// Antes del cooldown sigue abierto; pasado, entra una sola prueba
+	clock.Advance(29 * time.Second)
+	mustReject()
+	clock.Advance(time.Second)
+	probe := mustAllow(true)
+	mustReject()
+
+	// La respuesta tardía de la petición vieja no libera la prueba ni cierra el circuito
+	breaker.record(outcomeSuccess, inFlight)
+	mustReject()
+	breaker.record(outcomeFailure, false)
+	mustReject()
+
+	// La prueba falla: vuelve a abrirse por otro cooldown
+	breaker.record(outcomeFailure, probe)
+	mustReject()
+	clock.Advance(29 * time.Second)
+	mustReject()
+	clock.Advance(time.Second)
+
+	// Una prueba cancelada por el llamador no dice nada: entra otra
+	breaker.record(outcomeIgnored, mustAllow(true))
+	probe = mustAllow(true)
+
+	// La prueba funciona: el circuito se cierra
+	breaker.record(outcomeSuccess, probe)
+	mustAllow(false)
+	mustAllow(false)
+
+	var disabled *circuitBreaker
+	if probe, err := disabled.allow(); probe || err != nil {
+		t.Fatalf("un breaker deshabilitado no debe rechazar: %v", err)
+	}
+}
+
+func TestSendOpensCircuit(t *testing.T) {
+	var requests int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.Error(w, "caído", http.StatusServiceUnavailable)
+	})
+	client.opts.MaxRetries = 0
+	client.breaker = newCircuitBreaker(2, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetOffer(context.Background(), "OFFER-1"); err == nil || !strings.Contains(err.Error(), "503") {
+			t.Fatalf("error = %v, se esperaba 503", err)
+		}
+	}
+	if !client.CircuitOpen() {
+		t.Fatalf("el circuito debía abrirse tras dos fallos")
+	}
+
+	_, err := client.GetOffer(context.Background(), "OFFER-1")
+	if err == nil || !strings.Contains(err.Error(), ErrCircuitOpen.Error()) {
+		t.Fatalf("error = %v, se esperaba circuito abierto", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Fatalf("peticiones a Amadeus = %d, con el circuito abierto no debía llamar", got)
+	}
+}
+
+func TestSendHalfOpenCircuit(t *testing.T) {
+	var requests int32
+	var failing atomic.Bool
+	failing.Store(true)
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var block atomic.Bool
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if block.Load() {
+			entered <- struct{}{}
+			<-release
+		}
+		if failing.Load() {
+			http.Error(w, "caído", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"data":{"available":true,"offers":[{"id":"OFFER-1","price":{"total":"10.00"}}]}}`))
+	})
+	// El token se pide antes de abrir el circuito
+	if err := client.GetAccessToken(context.Background()); err != nil {
+		t.Fatalf("error obteniendo token: %v", err)
+	}
+	clock := newFakeClock()
+	client.opts.MaxRetries = 0
+	client.breaker = newCircuitBreaker(2, time.Minute)
+	client.breaker.now = clock.Now
+
+	getOffer := func() error {
+		_, err := client.GetOffer(context.Background(), "OFFER-1")
+		return err
+	}
+	mustBeOpen := func() {
+		t.Helper()
+		before := atomic.LoadInt32(&requests)
+		if err := getOffer(); err == nil || !strings.Contains(err.Error(), ErrCircuitOpen.Error()) {
+			t.Fatalf("error = %v, se esperaba circuito abierto", err)
+		}
+		if atomic.LoadInt32(&requests) != before {
+			t.Fatalf("con el circuito abierto no debía llamar a Amadeus")
+		}
+	}
+
+	// Dos fallos abren el circuito
+	for i := 0; i < 2; i++ {
+		if err := getOffer(); err == nil || !strings.Contains(err.Error(), "503") {
+			t.Fatalf("error = %v, se esperaba 503", err)
+		}
+	}
+	mustBeOpen()
+
+	// La prueba del circuito semiabierto falla: vuelve a abrirse
+	clock.Advance(time.Minute)
+	if client.CircuitOpen() {
+		t.Fatalf("pasado el cooldown el circuito debía quedar semiabierto")
+	}
+	if err := getOffer(); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Fatalf("error = %v, se esperaba 503 de la prueba", err)
+	}
+	if !client.CircuitOpen() {
+		t.Fatalf("la prueba fallida debía reabrir el circuito")
+	}
+	mustBeOpen()
+
+	// Mientras la prueba está en curso las demás peticiones se rechazan
+	clock.Advance(time.Minute)
+	failing.Store(false)
+	block.Store(true)
+	probeErr := make(chan error, 1)
+	go func() { probeErr <- getOffer() }()
+	<-entered
+	block.Store(false)
+	mustBeOpen()
+	close(release)
+
+	// La prueba funciona: el circuito se cierra
+	if err := <-probeErr; err != nil {
+		t.Fatalf("error en la prueba: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := getOffer(); err != nil {
+			t.Fatalf("error con el circuito cerrado: %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(&requests); got != 7 {
+		t.Fatalf("peticiones a Amadeus = %d, se esperaban 7", got)
+	}
+}
+
+func TestSendRetries(t *testing.T) {
+	t.Run("429 con Retry-After", func(t *testing.T) {
+		var requests int32
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&requests, 1) == 1 {
+				w.Header().Set("Retry-After", "0")
+				http.Error(w, "cuota", http.StatusTooManyRequests)
+				return
+			}
+			w.Write([]byte(`{"data":[{"id":"BOOK-1"}]}`))
+		})
+		client.opts.MaxRetries = 2
+
+		// Con 429 Amadeus no procesó la petición: hasta un POST se reintenta
+		if _, err := client.CreateBooking(context.Background(), "OFFER-1", nil, models.AmadeusPayment{}); err != nil {
+			t.Fatalf("error creando reserva: %v", err)
+		}
+		if got := atomic.LoadInt32(&requests); got != 2 {
+			t.Fatalf("peticiones = %d, se esperaban 2", got)
+		}
+	})
+
+	t.Run("5xx en GET", func(t *testing.T) {
+		var requests int32
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&requests, 1) < 3 {
+				http.Error(w, "caído", http.StatusBadGateway)
+				return
+			}
+			w.Write([]byte(`{"data":{"available":true,"offers":[{"id":"OFFER-1","price":{"total":"10.00"}}]}}`))
+		})
+		client.opts.MaxRetries = 2
+		client.opts.RetryBaseDelay = time.Millisecond
+
+		if _, err := client.GetOffer(context.Background(), "OFFER-1"); err != nil {
+			t.Fatalf("error obteniendo oferta: %v", err)
+		}
+		if got := atomic.LoadInt32(&requests); got != 3 {
+			t.Fatalf("peticiones = %d, se esperaban 3", got)
+		}
+	})
+
+	t.Run("5xx en POST", func(t *testing.T) {
+		var requests int32
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			http.Error(w, "caído", http.StatusBadGateway)
+		})
+		client.opts.MaxRetries = 2
+		client.opts.RetryBaseDelay = time.Millisecond
+
+		// No se sabe si la reserva se creó: reintentar podría duplicarla
+		if _, err := client.CreateBooking(context.Background(), "OFFER-1", nil, models.AmadeusPayment{}); err == nil {
+			t.Fatalf("se esperaba error")
+		}
+		if got := atomic.LoadInt32(&requests); got != 1 {
+			t.Fatalf("peticiones = %d, un POST no debe reintentarse ante 5xx", got)
+		}
+	})
+
+	t.Run("corte en POST", func(t *testing.T) {
+		var requests int32
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			// Cerrar la conexión sin responder: la reserva pudo haberse creado
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+		})
+		client.opts.MaxRetries = 2
+		client.opts.RetryBaseDelay = time.Millisecond
+
+		if _, err := client.CreateBooking(context.Background(), "OFFER-1", nil, models.AmadeusPayment{}); err == nil {
+			t.Fatalf("se esperaba error")
+		}
+		if got := atomic.LoadInt32(&requests); got != 1 {
+			t.Fatalf("peticiones = %d, un POST no debe reintentarse ante un corte", got)
+		}
+	})
+
+	t.Run("429 agota los reintentos", func(t *testing.T) {
+		var requests int32
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "cuota", http.StatusTooManyRequests)
+		})
+		client.opts.MaxRetries = 2
+
+		_, err := client.CreateBooking(context.Background(), "OFFER-1", nil, models.AmadeusPayment{})
+		if err == nil || !strings.Contains(err.Error(), "429") {
+			t.Fatalf("error = %v, se esperaba 429", err)
+		}
+		if got := atomic.LoadInt32(&requests); got != 3 {
+			t.Fatalf("peticiones = %d, se esperaban 3", got)
+		}
+	})
+
+	t.Run("Retry-After mayor al máximo", func(t *testing.T) {
+		var requests int32
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.Header().Set("Retry-After", "60")
+			http.Error(w, "cuota", http.StatusTooManyRequests)
+		})
+		client.opts.MaxRetries = 2
+		client.opts.RetryMaxDelay = time.Second
+
+		if _, err := client.CreateBooking(context.Background(), "OFFER-1", nil, models.AmadeusPayment{}); err == nil {
+			t.Fatalf("se esperaba error")
+		}
+		if got := atomic.LoadInt32(&requests); got != 1 {
+			t.Fatalf("peticiones = %d, no debe esperar más que RetryMaxDelay", got)
+		}
+	})
+}
@@ -0,0 +1,214 @@
+package amadeus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"booking-service/internal/models"
+)
+
+// tokenRefreshMargin se renueva el token un poco antes de que venza
+const tokenRefreshMargin = 5 * time.Minute
+
+// tokenPath endpoint de client credentials
+const tokenPath = "/v1/security/oauth2/token"
+
+// TokenCache almacén compartido del token entre instancias (Memcached)
+type TokenCache interface {
+	Get(key string, result interface{}) error
+	Set(key string, value interface{}, expiration time.Duration) error
+	Delete(key string) error
+}
+
+// cachedToken token guardado en el almacén compartido
+type cachedToken struct {
+	AccessToken string    `json:"access_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// SetTokenCache comparte el token entre instancias para no pedir uno por proceso
+func (c *Client) SetTokenCache(cache TokenCache, key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokenCache = cache
+	c.tokenCacheKey = key
+}
+
+// GetAccessToken asegura que haya un token de acceso vigente
+func (c *Client) GetAccessToken(ctx context.Context) error {
+	_, err := c.token(ctx)
+	return err
+}
+
+// IsTokenValid verifica si el token actual es válido
+func (c *Client) IsTokenValid() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.validLocked()
+}
+
+// validLocked indica si el token en memoria sigue vigente. Requiere c.mu.
+func (c *Client) validLocked() bool {
+	return c.accessToken != "" && time.Now().Before(c.tokenExpiry.Add(-tokenRefreshMargin))
+}
+
+// token devuelve un token vigente. Si hay que renovarlo, una sola goroutine
+// hace la petición y el resto espera su resultado.
+func (c *Client) token(ctx context.Context) (string, error) {
+	c.mu.RLock()
+	if c.validLocked() {
+		token := c.accessToken
+		c.mu.RUnlock()
+		return token, nil
+	}
+	c.mu.RUnlock()
+
+	token, err, _ := c.tokenGroup.Do("token", func() (interface{}, error) {
+		// Otra goroutine pudo haberlo renovado mientras esperábamos
+		c.mu.RLock()
+		if c.validLocked() {
+			token := c.accessToken
+			c.mu.RUnlock()
+			return token, nil
+		}
+		cache, key := c.tokenCache, c.tokenCacheKey
+		c.mu.RUnlock()
+
+		// Token compartido por otra instancia
+		if cache != nil {
+			var cached cachedToken
+			if err := cache.Get(key, &cached); err == nil && time.Now().Before(cached.ExpiresAt.Add(-tokenRefreshMargin)) {
+				c.storeToken(cached.AccessToken, cached.ExpiresAt)
+				return cached.AccessToken, nil
+			}
+		}
+
+		// La petición es compartida: no debe cortarse si el primer llamador cancela
+		tokenResp, err := c.requestToken(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		expiresAt := time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+		c.storeToken(tokenResp.AccessToken, expiresAt)
+
+		if cache != nil {
+			ttl := time.Until(expiresAt)
+			if err := cache.Set(key, cachedToken{AccessToken: tokenResp.AccessToken, ExpiresAt: expiresAt}, ttl); err != nil {
+				log.Printf("⚠️  Warning: No se pudo compartir el token de Amadeus: %v", err)
+			}
+		}
+
+		return tokenResp.AccessToken, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token.(string), nil
+}
+
+// storeToken guarda el token en memoria
+func (c *Client) storeToken(token string, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.accessToken = token
+	c.tokenExpiry = expiresAt
+}
+
+// invalidateToken descarta un token rechazado por Amadeus. Solo lo borra si
+// sigue siendo el actual, para no descartar uno recién renovado.
+func (c *Client) invalidateToken(stale string) {
+	c.mu.Lock()
+	if c.accessToken == stale {
+		c.accessToken = ""
+		c.tokenExpiry = time.Time{}
+	}
+	cache, key := c.tokenCache, c.tokenCacheKey
+	c.mu.Unlock()
+
+	if cache != nil {
+		var cached cachedToken
+		if err := cache.Get(key, &cached); err == nil && cached.AccessToken == stale {
+			cache.Delete(key)
+		}
+	}
+}
+
+// requestToken pide un token nuevo con client credentials
+func (c *Client) requestToken(ctx context.Context) (*models.AmadeusTokenResponse, error) {
+	log.Println("🔑 Obteniendo nuevo token de Amadeus...")
+
+	// Preparar datos del formulario
+	data := url.Values{}
+	data.Set("grant_type", "client_credentials")
+	data.Set("client_id", c.clientID)
+	data.Set("client_secret", c.clientSecret)
+
+	// Crear petición
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+tokenPath, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("error creando petición: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	// Ejecutar petición
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, fmt.Errorf("error ejecutando petición: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("error obteniendo token: %d - %s", resp.StatusCode, string(body))
+	}
+
+	// Parsear respuesta
+	var tokenResp models.AmadeusTokenResponse
+	err = json.NewDecoder(resp.Body).Decode(&tokenResp)
+	if err != nil {
+		return nil, fmt.Errorf("error parseando respuesta: %v", err)
+	}
+
+	log.Printf("✅ Token de Amadeus obtenido, expira en %d segundos", tokenResp.ExpiresIn)
+	return &tokenResp, nil
+}
+
+// do ejecuta una petición autenticada. Si Amadeus rechaza el token (401, por
+// ejemplo porque se revocó antes de vencer) se renueva y se reintenta una vez.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	token, err := c.token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.send(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	resp.Body.Close()
+
+	c.invalidateToken(token)
+	token, err = c.token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	retry, err := rewind(req)
+	if err != nil {
+		return nil, fmt.Errorf("error reintentando petición: %v", err)
+	}
+	retry.Header.Set("Authorization", "Bearer "+token)
+
+	return c.send(retry)
+}
@@ -0,0 +1,183 @@
+package amadeus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"booking-service/internal/models"
+	"booking-service/pkg/memcached/memcachedtest"
+)
+
+// tokenServer Amadeus de prueba que cuenta los tokens emitidos. Cada token
+// nuevo se llama token-N; accept decide cuáles acepta la API.
+type tokenServer struct {
+	*httptest.Server
+	tokenRequests atomic.Int32
+	apiRequests   atomic.Int32
+	tokenDelay    time.Duration
+
+	mu     sync.Mutex
+	bodies []string // cuerpos recibidos por la API, en orden
+}
+
+func newTokenServer(t *testing.T, accept func(token string) bool) *tokenServer {
+	t.Helper()
+
+	s := &tokenServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == tokenPath {
+			n := s.tokenRequests.Add(1)
+			time.Sleep(s.tokenDelay)
+			json.NewEncoder(w).Encode(models.AmadeusTokenResponse{AccessToken: fmt.Sprintf("token-%d", n), ExpiresIn: 1799})
+			return
+		}
+
+		s.apiRequests.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.bodies = append(s.bodies, string(body))
+		s.mu.Unlock()
+
+		if !accept(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")) {
+			http.Error(w, `{"errors":[{"code":38191,"title":"Invalid access token"}]}`, http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"data":[{"type":"hotel-booking","id":"BOOK-1"}]}`))
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestTokenRequestedOnceConcurrently(t *testing.T) {
+	server := newTokenServer(t, func(string) bool { return true })
+	server.tokenDelay = 50 * time.Millisecond
+	client := NewClient(server.URL, "id", "secret", Options{})
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 20)
+	errs := make([]error, len(tokens))
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], errs[i] = client.token(context.Background())
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range tokens {
+		if errs[i] != nil {
+			t.Fatalf("error obteniendo token: %v", errs[i])
+		}
+		if tokens[i] != "token-1" {
+			t.Fatalf("token = %q, todas las goroutines debían compartir token-1", tokens[i])
+		}
+	}
+	if got := server.tokenRequests.Load(); got != 1 {
+		t.Fatalf("peticiones de token = %d, se esperaba 1", got)
+	}
+}
+
+func TestTokenSharedBetweenInstances(t *testing.T) {
+	server := newTokenServer(t, func(string) bool { return true })
+	server.tokenDelay = 20 * time.Millisecond
+	cache, cacheServer := memcachedtest.New(t)
+
+	first := NewClient(server.URL, "id", "secret", Options{})
+	first.SetTokenCache(cache, "amadeus_token")
+	if err := first.GetAccessToken(context.Background()); err != nil {
+		t.Fatalf("error obteniendo token: %v", err)
+	}
+	if !cacheServer.Has("amadeus_token") {
+		t.Fatalf("el token no se compartió en el caché")
+	}
+
+	// Las demás instancias toman el token del caché, aun pidiéndolo a la vez
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			other := NewClient(server.URL, "id", "secret", Options{})
+			other.SetTokenCache(cache, "amadeus_token")
+			token, err := other.token(context.Background())
+			if err == nil && token != "token-1" {
+				err = fmt.Errorf("token = %q, se esperaba el compartido token-1", token)
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := server.tokenRequests.Load(); got != 1 {
+		t.Fatalf("peticiones de token = %d, se esperaba 1", got)
+	}
+}
+
+func TestDoRefreshesTokenOnceOn401(t *testing.T) {
+	// Amadeus revocó el primer token antes de que venciera
+	server := newTokenServer(t, func(token string) bool { return token != "token-1" })
+	cache, _ := memcachedtest.New(t)
+	client := NewClient(server.URL, "id", "secret", Options{})
+	client.SetTokenCache(cache, "amadeus_token")
+
+	bookingID, err := client.CreateBooking(context.Background(), "OFFER-1", nil, models.AmadeusPayment{})
+	if err != nil {
+		t.Fatalf("error creando reserva: %v", err)
+	}
+	if bookingID != "BOOK-1" {
+		t.Fatalf("ID de reserva = %s, se esperaba BOOK-1", bookingID)
+	}
+
+	if got := server.tokenRequests.Load(); got != 2 {
+		t.Fatalf("peticiones de token = %d, se esperaban 2", got)
+	}
+	if got := server.apiRequests.Load(); got != 2 {
+		t.Fatalf("peticiones a la API = %d, se esperaban 2", got)
+	}
+	if server.bodies[0] == "" || server.bodies[1] != server.bodies[0] {
+		t.Fatalf("el reintento no repitió el cuerpo: %q y %q", server.bodies[0], server.bodies[1])
+	}
+
+	// El token renovado reemplaza al revocado también en el caché
+	var cached cachedToken
+	if err := cache.Get("amadeus_token", &cached); err != nil || cached.AccessToken != "token-2" {
+		t.Fatalf("token en caché = %q (%v), se esperaba token-2", cached.AccessToken, err)
+	}
+}
+
+func TestDoDoesNotLoopOn401(t *testing.T) {
+	// Credenciales sin permisos: Amadeus rechaza todos los tokens
+	server := newTokenServer(t, func(string) bool { return false })
+	client := NewClient(server.URL, "id", "secret", Options{})
+
+	_, err := client.CreateBooking(context.Background(), "OFFER-1", nil, models.AmadeusPayment{})
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("error = %v, se esperaba el 401 de Amadeus", err)
+	}
+
+	if got := server.tokenRequests.Load(); got != 2 {
+		t.Fatalf("peticiones de token = %d, se esperaban 2", got)
+	}
+	if got := server.apiRequests.Load(); got != 2 {
+		t.Fatalf("peticiones a la API = %d, se esperaban 2 (una renovación y un reintento)", got)
+	}
+}
@@ -0,0 +1,80 @@
+// Package bookingref genera y valida las referencias de reserva: códigos en
+// base32 de Crockford (sin I, L, O ni U, fáciles de dictar) con un símbolo de
+// control al final, con el formato BK-XXXXX-XXXXX.
+package bookingref
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+)
+
+// Prefix prefijo de todas las referencias
+const Prefix = "BK-"
+
+// alphabet base32 de Crockford
+const alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// randomLength símbolos aleatorios de la referencia (45 bits); el décimo es el de control
+const randomLength = 9
+
+// New genera una referencia aleatoria. La unicidad la garantiza el índice de
+// bookings.booking_reference: ante una colisión se genera otra.
+func New() (string, error) {
+	raw := make([]byte, randomLength)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("error generando referencia: %v", err)
+	}
+
+	code := make([]byte, randomLength, randomLength+1)
+	for i, b := range raw {
+		code[i] = alphabet[int(b)%len(alphabet)]
+	}
+	code = append(code, checkSymbol(code))
+
+	return Prefix + string(code[:5]) + "-" + string(code[5:]), nil
+}
+
+// Normalize lleva una referencia tipeada a su forma canónica: mayúsculas, sin
+// espacios ni guiones y con las letras ambiguas reemplazadas (I y L por 1, O por 0)
+func Normalize(ref string) string {
+	ref = strings.NewReplacer("-", "", " ", "").Replace(strings.ToUpper(ref))
+	ref = strings.NewReplacer("I", "1", "L", "1", "O", "0").Replace(strings.TrimPrefix(ref, "BK"))
+	if len(ref) != randomLength+1 {
+		return Prefix + ref
+	}
+	return Prefix + ref[:5] + "-" + ref[5:]
+}
+
+// Valid verifica el formato y el símbolo de control de una referencia
+// normalizada. Detecta cualquier símbolo mal copiado y la mayoría de las
+// transposiciones de símbolos vecinos.
+func Valid(ref string) bool {
+	code := strings.ReplaceAll(strings.TrimPrefix(ref, Prefix), "-", "")
+	if !strings.HasPrefix(ref, Prefix) || len(code) != randomLength+1 {
+		return false
+	}
+	for i := 0; i < len(code); i++ {
+		if strings.IndexByte(alphabet, code[i]) < 0 {
+			return false
+		}
+	}
+	return checkSymbol([]byte(code[:randomLength])) == code[randomLength]
+}
+
+// checkSymbol calcula el símbolo de control con Luhn mod 32
+func checkSymbol(code []byte) byte {
+	n := len(alphabet)
+	sum := 0
+	factor := 2
+	for i := len(code) - 1; i >= 0; i-- {
+		addend := factor * strings.IndexByte(alphabet, code[i])
+		sum += addend/n + addend%n
+		if factor == 2 {
+			factor = 1
+		} else {
+			factor = 2
+		}
+	}
+	return alphabet[(n-sum%n)%n]
+}
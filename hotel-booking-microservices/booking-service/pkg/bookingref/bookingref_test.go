@@ -0,0 +1,155 @@
+package bookingref
+
+import (
+	"regexp"
+	"testing"
+)
+
+var referencePattern = regexp.MustCompile(`^BK-[0-9A-HJKMNP-TV-Z]{5}-[0-9A-HJKMNP-TV-Z]{5}$`)
+
+func TestNew(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		ref, err := New()
+		if err != nil {
+			t.Fatalf("error generando referencia: %v", err)
+		}
+		if !referencePattern.MatchString(ref) {
+			t.Fatalf("referencia %q con formato inválido", ref)
+		}
+		if !Valid(ref) {
+			t.Fatalf("referencia generada %q no es válida", ref)
+		}
+		if Normalize(ref) != ref {
+			t.Fatalf("Normalize(%q) = %q, una referencia generada ya es canónica", ref, Normalize(ref))
+		}
+		if seen[ref] {
+			t.Fatalf("referencia %q repetida", ref)
+		}
+		seen[ref] = true
+	}
+}
+
+// withCode arma una referencia a partir de los diez símbolos del código
+func withCode(code []byte) string {
+	return Prefix + string(code[:5]) + "-" + string(code[5:])
+}
+
+func TestCheckSymbolDetectsSubstitution(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		ref, err := New()
+		if err != nil {
+			t.Fatalf("error generando referencia: %v", err)
+		}
+		code := []byte(ref[3:8] + ref[9:])
+
+		// Cualquier símbolo mal copiado, incluido el de control, invalida la referencia
+		for pos := range code {
+			for j := 0; j < len(alphabet); j++ {
+				if alphabet[j] == code[pos] {
+					continue
+				}
+				typo := append([]byte(nil), code...)
+				typo[pos] = alphabet[j]
+				if Valid(withCode(typo)) {
+					t.Fatalf("%s aceptada con el símbolo %d cambiado (original %s)", withCode(typo), pos, ref)
+				}
+			}
+		}
+	}
+}
+
+func TestCheckSymbolDetectsTransposition(t *testing.T) {
+	code := []byte("7R3QX2MZK")
+
+	// Luhn mod 32 detecta todas las transposiciones de símbolos vecinos salvo
+	// la de 0 con Z, igual que Luhn decimal no detecta la de 09 con 90
+	for pos := 0; pos < randomLength-1; pos++ {
+		for a := 0; a < len(alphabet); a++ {
+			for b := 0; b < len(alphabet); b++ {
+				if a == b {
+					continue
+				}
+				valid := append([]byte(nil), code...)
+				valid[pos], valid[pos+1] = alphabet[a], alphabet[b]
+				valid = append(valid, checkSymbol(valid))
+
+				swapped := append([]byte(nil), valid...)
+				swapped[pos], swapped[pos+1] = swapped[pos+1], swapped[pos]
+
+				undetectable := (a == 0 && b == len(alphabet)-1) || (a == len(alphabet)-1 && b == 0)
+				if Valid(withCode(swapped)) != undetectable {
+					t.Fatalf("transposición de %c%c en la posición %d: válida = %v", alphabet[a], alphabet[b], pos, !undetectable)
+				}
+			}
+		}
+	}
+
+	// Transposición con el símbolo de control
+	for i := 0; i < 50; i++ {
+		ref, err := New()
+		if err != nil {
+			t.Fatalf("error generando referencia: %v", err)
+		}
+		swapped := []byte(ref[3:8] + ref[9:])
+		if swapped[8] == swapped[9] {
+			continue
+		}
+		swapped[8], swapped[9] = swapped[9], swapped[8]
+		if Valid(withCode(swapped)) {
+			t.Fatalf("%s aceptada con el símbolo de control transpuesto (original %s)", withCode(swapped), ref)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "canónica", input: "BK-7R3QX-2MZK4", want: "BK-7R3QX-2MZK4"},
+		{name: "minúsculas", input: "bk-7r3qx-2mzk4", want: "BK-7R3QX-2MZK4"},
+		{name: "sin guiones", input: "BK7R3QX2MZK4", want: "BK-7R3QX-2MZK4"},
+		{name: "sin prefijo", input: "7R3QX2MZK4", want: "BK-7R3QX-2MZK4"},
+		{name: "con espacios", input: " BK 7R3QX 2MZK4 ", want: "BK-7R3QX-2MZK4"},
+		{name: "letras ambiguas", input: "BK-IL0OX-2MZK4", want: "BK-1100X-2MZK4"},
+		{name: "largo incorrecto", input: "BK-7R3QX", want: "BK-7R3QX"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.input); got != tt.want {
+				t.Fatalf("Normalize(%q) = %q, se esperaba %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValid(t *testing.T) {
+	ref, err := New()
+	if err != nil {
+		t.Fatalf("error generando referencia: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		ref  string
+		want bool
+	}{
+		{name: "generada", ref: ref, want: true},
+		{name: "sin prefijo", ref: ref[3:]},
+		{name: "corta", ref: ref[:len(ref)-1]},
+		{name: "larga", ref: ref + "0"},
+		{name: "símbolo fuera del alfabeto", ref: ref[:3] + "U" + ref[4:]},
+		{name: "vacía", ref: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Valid(tt.ref); got != tt.want {
+				t.Fatalf("Valid(%q) = %v, se esperaba %v", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package hotelservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"booking-service/internal/models"
+)
+
+// Client cliente HTTP para hotel-service
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient crea un nuevo cliente de hotel-service
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout: 5 * time.Second,
+		},
+	}
+}
+
+// GetRoomTypes obtiene los tipos de habitación que ofrece un hotel
+func (c *Client) GetRoomTypes(hotelID string) ([]models.RoomType, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/hotels/%s/rooms", c.baseURL, url.PathEscape(hotelID))
+
+	// Ejecutar petición
+	resp, err := c.httpClient.Get(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando hotel-service: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("hotel no encontrado")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("hotel-service respondió %d - %s", resp.StatusCode, string(body))
+	}
+
+	// Parsear respuesta
+	var response struct {
+		Data []models.RoomType `json:"data"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("error parseando respuesta: %v", err)
+	}
+
+	return response.Data, nil
+}
+
+// GetHotel obtiene un hotel del catálogo
+func (c *Client) GetHotel(ctx context.Context, hotelID string) (*models.CatalogHotel, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/hotels/%s", c.baseURL, url.PathEscape(hotelID))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creando petición: %v", err)
+	}
+
+	var response struct {
+		Data models.CatalogHotel `json:"data"`
+	}
+	if err := c.doJSON(req, http.StatusOK, &response); err != nil {
+		return nil, err
+	}
+
+	return &response.Data, nil
+}
+
+// ListHotels obtiene los hoteles activos del catálogo
+func (c *Client) ListHotels(ctx context.Context) ([]models.CatalogHotel, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/api/v1/hotels", nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creando petición: %v", err)
+	}
+
+	var response struct {
+		Data []models.CatalogHotel `json:"data"`
+	}
+	if err := c.doJSON(req, http.StatusOK, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Data, nil
+}
+
+// CreateHotel da de alta un hotel en el catálogo y devuelve su ID. authorization
+// es el header del administrador que dispara la operación: hotel-service exige
+// rol admin y lo registra en su auditoría.
+func (c *Client) CreateHotel(ctx context.Context, authorization string, hotel *models.CatalogHotel) (string, error) {
+	req, err := c.newJSONRequest(ctx, "POST", c.baseURL+"/api/v1/hotels", authorization, hotel)
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		Data models.CatalogHotel `json:"data"`
+	}
+	if err := c.doJSON(req, http.StatusCreated, &response); err != nil {
+		return "", err
+	}
+
+	if response.Data.ID == "" {
+		return "", fmt.Errorf("hotel-service no devolvió el ID del hotel creado")
+	}
+
+	return response.Data.ID, nil
+}
+
+// UpdateHotel reemplaza los datos de un hotel del catálogo
+func (c *Client) UpdateHotel(ctx context.Context, authorization, hotelID string, hotel *models.CatalogHotel) error {
+	endpoint := fmt.Sprintf("%s/api/v1/hotels/%s", c.baseURL, url.PathEscape(hotelID))
+
+	req, err := c.newJSONRequest(ctx, "PUT", endpoint, authorization, hotel)
+	if err != nil {
+		return err
+	}
+
+	return c.doJSON(req, http.StatusOK, nil)
+}
+
+// newJSONRequest arma una petición autenticada con cuerpo JSON
+func (c *Client) newJSONRequest(ctx context.Context, method, endpoint, authorization string, body interface{}) (*http.Request, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("error serializando petición: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("error creando petición: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", authorization)
+	return req, nil
+}
+
+// doJSON ejecuta la petición y parsea la respuesta en result (si no es nil)
+func (c *Client) doJSON(req *http.Request, expected int, result interface{}) error {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error consultando hotel-service: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("hotel no encontrado")
+	}
+
+	if resp.StatusCode != expected {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("hotel-service respondió %d - %s", resp.StatusCode, string(body))
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("error parseando respuesta: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LogMailer no envía emails: los escribe en el log y, si hay directorio,
+// en un archivo por mensaje. Pensado para desarrollo local.
+type LogMailer struct {
+	outputDir string
+}
+
+// NewLogMailer crea un mailer de log
+func NewLogMailer(outputDir string) *LogMailer {
+	return &LogMailer{outputDir: outputDir}
+}
+
+// Send registra el mensaje
+func (m *LogMailer) Send(msg Message) error {
+	log.Printf("📧 Email para %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+
+	if m.outputDir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(m.outputDir, 0755); err != nil {
+		return fmt.Errorf("error creando directorio de emails: %v", err)
+	}
+
+	recipient := strings.NewReplacer("@", "_at_", "/", "_").Replace(msg.To)
+	fileName := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102T150405.000000000"), recipient)
+	content := fmt.Sprintf("To: %s\nSubject: %s\n\n%s\n", msg.To, msg.Subject, msg.Body)
+
+	if err := os.WriteFile(filepath.Join(m.outputDir, fileName), []byte(content), 0644); err != nil {
+		return fmt.Errorf("error guardando email: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package mailer
+
+import (
+	"fmt"
+)
+
+// Message representa un email a enviar
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer envía emails transaccionales (verificación, recuperación de contraseña)
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// Config parámetros para construir un Mailer
+type Config struct {
+	Driver    string // "smtp" o "log"
+	Host      string
+	Port      int
+	Username  string
+	Password  string
+	From      string
+	OutputDir string // solo para el driver "log"
+}
+
+// New crea el Mailer indicado por la configuración
+func New(cfg Config) (Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		if cfg.Host == "" || cfg.From == "" {
+			return nil, fmt.Errorf("el driver smtp requiere host y remitente")
+		}
+		return NewSMTPMailer(cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.From), nil
+	case "", "log":
+		return NewLogMailer(cfg.OutputDir), nil
+	default:
+		return nil, fmt.Errorf("driver de mail desconocido: %s", cfg.Driver)
+	}
+}
@@ -0,0 +1,45 @@
+package mailer
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+)
+
+// SMTPMailer envía emails a través de un servidor SMTP
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPMailer crea un mailer SMTP. Sin usuario no se autentica.
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPMailer{
+		addr: fmt.Sprintf("%s:%d", host, port),
+		auth: auth,
+		from: from,
+	}
+}
+
+// Send envía el mensaje como texto plano UTF-8
+func (m *SMTPMailer) Send(msg Message) error {
+	headers := []string{
+		"From: " + m.from,
+		"To: " + msg.To,
+		"Subject: " + msg.Subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+	}
+	data := strings.Join(headers, "\r\n") + "\r\n\r\n" + msg.Body
+
+	if err := smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, []byte(data)); err != nil {
+		return fmt.Errorf("error enviando email a %s: %v", msg.To, err)
+	}
+	return nil
+}
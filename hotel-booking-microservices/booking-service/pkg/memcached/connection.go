@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/bradfitz/gomemcache/memcache"
@@ -91,6 +93,38 @@ func (c *Client) Delete(key string) error {
 	return nil
 }
 
+// Increment suma delta a un contador y devuelve el nuevo valor. Si la clave no
+// existe la crea con la expiración indicada; la expiración no se renueva en
+// incrementos posteriores, por lo que funciona como ventana fija.
+func (c *Client) Increment(key string, delta uint64, expiration time.Duration) (uint64, error) {
+	value, err := c.mc.Increment(key, delta)
+	if err == nil {
+		return value, nil
+	}
+	if err != memcache.ErrCacheMiss {
+		return 0, fmt.Errorf("error incrementando contador: %v", err)
+	}
+
+	err = c.mc.Add(&memcache.Item{
+		Key:        key,
+		Value:      []byte(strconv.FormatUint(delta, 10)),
+		Expiration: int32(expiration.Seconds()),
+	})
+	if err == nil {
+		return delta, nil
+	}
+	if err != memcache.ErrNotStored {
+		return 0, fmt.Errorf("error creando contador: %v", err)
+	}
+
+	// Otro proceso creó el contador entre el Increment y el Add
+	value, err = c.mc.Increment(key, delta)
+	if err != nil {
+		return 0, fmt.Errorf("error incrementando contador: %v", err)
+	}
+	return value, nil
+}
+
 // Exists verifica si una clave existe en el caché
 func (c *Client) Exists(key string) bool {
 	_, err := c.mc.Get(key)
@@ -104,16 +138,47 @@ func (c *Client) Close() error {
 }
 
 // GenerateAvailabilityKey genera una clave única para disponibilidad
-func GenerateAvailabilityKey(hotelID string, checkIn, checkOut time.Time, guests int) string {
-	return fmt.Sprintf("availability:%s:%s:%s:%d",
+func GenerateAvailabilityKey(hotelID string, checkIn, checkOut time.Time, guests int, roomType string) string {
+	return fmt.Sprintf("availability:%s:%s:%s:%d:%s",
 		hotelID,
 		checkIn.Format("2006-01-02"),
 		checkOut.Format("2006-01-02"),
 		guests,
+		strings.ReplaceAll(roomType, " ", "_"),
 	)
 }
 
-// GenerateTokenKey genera una clave para tokens de Amadeus
-func GenerateTokenKey() string {
-	return "amadeus:token"
-}
\ No newline at end of file
+// GenerateRoomTypesKey genera una clave para los tipos de habitación de un hotel
+func GenerateRoomTypesKey(hotelID string) string {
+	return fmt.Sprintf("room_types:%s", hotelID)
+}
+
+// GenerateHotelKey genera una clave para los datos de un hotel del catálogo
+func GenerateHotelKey(hotelID string) string {
+	return fmt.Sprintf("hotel:%s", hotelID)
+}
+
+// GenerateTokenVersionKey genera una clave para la versión de tokens de un usuario
+func GenerateTokenVersionKey(userID int) string {
+	return fmt.Sprintf("token_version:%d", userID)
+}
+
+// GenerateTokenKey genera una clave para el token de Amadeus de unas credenciales
+func GenerateTokenKey(clientID string) string {
+	return fmt.Sprintf("amadeus:token:%s", clientID)
+}
+// GenerateLoginFailuresKey genera una clave para los intentos de login fallidos
+// de una cuenta o IP (scope "account" o "ip")
+func GenerateLoginFailuresKey(scope, id string) string {
+	return fmt.Sprintf("login_failures:%s:%s", scope, id)
+}
+
+// GenerateLoginLockKey genera una clave para el bloqueo temporal de login
+func GenerateLoginLockKey(scope, id string) string {
+	return fmt.Sprintf("login_lock:%s:%s", scope, id)
+}
+
+// GenerateOIDCStateKey genera una clave para el estado de un login OpenID Connect
+func GenerateOIDCStateKey(state string) string {
+	return fmt.Sprintf("oidc_state:%s", state)
+}
@@ -0,0 +1,163 @@
+// Package memcachedtest levanta un Memcached en memoria para los tests. Atiende
+// los comandos de texto que usa el cliente (get, set, add, delete, incr y
+// version) e ignora la expiración.
+package memcachedtest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"booking-service/pkg/memcached"
+)
+
+// Server Memcached de prueba
+type Server struct {
+	listener net.Listener
+
+	mu    sync.Mutex
+	items map[string][]byte
+}
+
+// New levanta el servidor y devuelve un cliente conectado; se cierra al
+// terminar el test
+func New(t testing.TB) (*memcached.Client, *Server) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("memcachedtest: error escuchando: %v", err)
+	}
+	server := &Server{listener: listener, items: make(map[string][]byte)}
+	t.Cleanup(func() { listener.Close() })
+	go server.serve()
+
+	client, err := memcached.Connect(listener.Addr().String())
+	if err != nil {
+		t.Fatalf("memcachedtest: %v", err)
+	}
+	return client, server
+}
+
+// Has indica si la clave está guardada
+func (s *Server) Has(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.items[key]
+	return ok
+}
+
+func (s *Server) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+
+	for {
+		line, err := rw.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		var reply string
+		switch fields[0] {
+		case "get", "gets":
+			reply = s.get(fields[1:])
+		case "set", "add":
+			if len(fields) < 5 {
+				reply = "CLIENT_ERROR bad command line format\r\n"
+				break
+			}
+			size, _ := strconv.Atoi(fields[4])
+			data := make([]byte, size+2)
+			if _, err := io.ReadFull(rw, data); err != nil {
+				return
+			}
+			reply = s.store(fields[0], fields[1], data[:size])
+		case "delete":
+			reply = s.delete(fields[1])
+		case "incr":
+			delta, _ := strconv.ParseUint(fields[2], 10, 64)
+			reply = s.incr(fields[1], delta)
+		case "version":
+			reply = "VERSION memcachedtest\r\n"
+		default:
+			reply = "ERROR\r\n"
+		}
+
+		rw.WriteString(reply)
+		if rw.Flush() != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) get(keys []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var reply strings.Builder
+	for _, key := range keys {
+		if value, ok := s.items[key]; ok {
+			fmt.Fprintf(&reply, "VALUE %s 0 %d 1\r\n%s\r\n", key, len(value), value)
+		}
+	}
+	reply.WriteString("END\r\n")
+	return reply.String()
+}
+
+func (s *Server) store(verb, key string, value []byte) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.items[key]; exists && verb == "add" {
+		return "NOT_STORED\r\n"
+	}
+	s.items[key] = append([]byte(nil), value...)
+	return "STORED\r\n"
+}
+
+func (s *Server) delete(key string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.items[key]; !ok {
+		return "NOT_FOUND\r\n"
+	}
+	delete(s.items, key)
+	return "DELETED\r\n"
+}
+
+func (s *Server) incr(key string, delta uint64) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, ok := s.items[key]
+	if !ok {
+		return "NOT_FOUND\r\n"
+	}
+	current, err := strconv.ParseUint(string(value), 10, 64)
+	if err != nil {
+		return "CLIENT_ERROR cannot increment or decrement non-numeric value\r\n"
+	}
+	current += delta
+	s.items[key] = []byte(strconv.FormatUint(current, 10))
+	return strconv.FormatUint(current, 10) + "\r\n"
+}
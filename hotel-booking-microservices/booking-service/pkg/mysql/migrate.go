@@ -0,0 +1,337 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationFile reconoce archivos con formato NNNN_nombre.up.sql / NNNN_nombre.down.sql
+var migrationFile = regexp.MustCompile(`^(\d+)_([a-zA-Z0-9_]+)\.(up|down)\.sql$`)
+
+// migrationLockName nombre del lock de MySQL que evita correr migraciones en paralelo
+const migrationLockName = "booking_service_migrations"
+
+// Migration representa una versión del esquema
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus estado de una migración en la base
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// LoadMigrations lee las migraciones de un directorio y las ordena por versión.
+// Las versiones deben ser correlativas desde 0001.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo migraciones: %v", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("error leyendo migración %s: %v", entry.Name(), err)
+		}
+
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("versión de migración %d duplicada: %s y %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("la migración %04d_%s no tiene archivo up", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	// Un hueco suele ser un archivo sin commitear o una versión mal numerada:
+	// aplicar las siguientes dejaría el esquema en un estado que nadie probó
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, fmt.Errorf("falta la migración %04d (la siguiente es %04d_%s)", i+1, migration.Version, migration.Name)
+		}
+	}
+
+	return migrations, nil
+}
+
+// MigrateUp aplica todas las migraciones pendientes en orden.
+// Devuelve la cantidad de migraciones aplicadas.
+func (db *DB) MigrateUp(migrations []Migration) (int, error) {
+	applied := 0
+	err := db.withMigrationLock(func(conn *sql.Conn) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+
+			log.Printf("⬆️  Aplicando migración %04d_%s", migration.Version, migration.Name)
+			if err := execScript(conn, migration.Up); err != nil {
+				return fmt.Errorf("migración %04d_%s: %v", migration.Version, migration.Name, err)
+			}
+
+			_, err := conn.ExecContext(context.Background(),
+				"INSERT INTO schema_migrations (version, name) VALUES (?, ?)", migration.Version, migration.Name)
+			if err != nil {
+				return fmt.Errorf("error registrando migración %04d: %v", migration.Version, err)
+			}
+			applied++
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// MigrateDown revierte las últimas `steps` migraciones aplicadas
+func (db *DB) MigrateDown(migrations []Migration, steps int) (int, error) {
+	reverted := 0
+	err := db.withMigrationLock(func(conn *sql.Conn) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0 && reverted < steps; i-- {
+			migration := migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("la migración %04d_%s no tiene archivo down", migration.Version, migration.Name)
+			}
+
+			log.Printf("⬇️  Revirtiendo migración %04d_%s", migration.Version, migration.Name)
+			if err := execScript(conn, migration.Down); err != nil {
+				return fmt.Errorf("migración %04d_%s: %v", migration.Version, migration.Name, err)
+			}
+
+			_, err := conn.ExecContext(context.Background(), "DELETE FROM schema_migrations WHERE version = ?", migration.Version)
+			if err != nil {
+				return fmt.Errorf("error desregistrando migración %04d: %v", migration.Version, err)
+			}
+			reverted++
+		}
+		return nil
+	})
+
+	return reverted, err
+}
+
+// MigrationsStatus informa qué migraciones están aplicadas
+func (db *DB) MigrationsStatus(migrations []Migration) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := db.withMigrationLock(func(conn *sql.Conn) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+			if appliedAt, ok := done[migration.Version]; ok {
+				status.Applied = true
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+
+	return statuses, err
+}
+
+// withMigrationLock ejecuta fn en una conexión dedicada con un lock de MySQL,
+// para que dos instancias que arrancan a la vez no apliquen la misma migración
+func (db *DB) withMigrationLock(fn func(conn *sql.Conn) error) error {
+	ctx := context.Background()
+
+	conn, err := db.conn.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("error obteniendo conexión: %v", err)
+	}
+	defer conn.Close()
+
+	var locked sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 60)", migrationLockName).Scan(&locked); err != nil {
+		return fmt.Errorf("error obteniendo lock de migraciones: %v", err)
+	}
+	if !locked.Valid || locked.Int64 != 1 {
+		return fmt.Errorf("no se pudo obtener el lock de migraciones")
+	}
+	defer conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", migrationLockName)
+
+	_, err = conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("error creando tabla schema_migrations: %v", err)
+	}
+
+	return fn(conn)
+}
+
+// appliedVersions obtiene las versiones aplicadas y su fecha
+func appliedVersions(conn *sql.Conn) (map[int]time.Time, error) {
+	rows, err := conn.QueryContext(context.Background(), "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo migraciones aplicadas: %v", err)
+	}
+	defer rows.Close()
+
+	done := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("error escaneando migración: %v", err)
+		}
+		done[version] = appliedAt
+	}
+
+	return done, rows.Err()
+}
+
+// execScript ejecuta un archivo SQL sentencia por sentencia. MySQL no permite
+// DDL transaccional, por eso cada migración debe ser segura de reintentar.
+func execScript(conn *sql.Conn, script string) error {
+	for _, statement := range splitStatements(script) {
+		if _, err := conn.ExecContext(context.Background(), statement); err != nil {
+			return fmt.Errorf("error ejecutando %q: %v", firstLine(statement), err)
+		}
+	}
+	return nil
+}
+
+// splitStatements separa un script en sentencias terminadas en ';'. Ignora los
+// ';' dentro de literales ('...', "...", `...`) y descarta los comentarios
+// (-- , # y /* */), salvo los ejecutables /*! */ que MySQL interpreta.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+
+	flush := func() {
+		if statement := strings.TrimSpace(current.String()); statement != "" {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := quotedEnd(script, i)
+			current.WriteString(script[i:end])
+			i = end - 1
+		case c == '#' || (c == '-' && strings.HasPrefix(script[i:], "--") && (i+2 == len(script) || isSpace(script[i+2]))):
+			// Comentario hasta el fin de línea; el salto de línea se conserva
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end - 1
+			}
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				end = len(script)
+			} else {
+				end += i + 4
+			}
+			if strings.HasPrefix(script[i:], "/*!") {
+				current.WriteString(script[i:end])
+			} else {
+				current.WriteByte(' ')
+			}
+			i = end - 1
+		case c == ';':
+			flush()
+		default:
+			current.WriteByte(c)
+		}
+	}
+	flush()
+
+	return statements
+}
+
+// quotedEnd devuelve la posición siguiente al cierre del literal que empieza
+// en start. Dentro de '...' y "..." la barra invertida escapa el carácter
+// siguiente; la comilla duplicada se resuelve sola como dos literales seguidos.
+func quotedEnd(script string, start int) int {
+	quote := script[start]
+	for i := start + 1; i < len(script); i++ {
+		switch script[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			return i + 1
+		}
+	}
+	return len(script)
+}
+
+// isSpace indica si el carácter cuenta como espacio después de "--": MySQL
+// solo lo toma como comentario si lo sigue un espacio o un carácter de control
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// firstLine devuelve la primera línea de una sentencia para los mensajes de error
+func firstLine(statement string) string {
+	if i := strings.Index(statement, "\n"); i >= 0 {
+		return statement[:i]
+	}
+	return statement
+}
@@ -0,0 +1,194 @@
+package mysql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"booking-service/migrations"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "una sentencia por línea",
+			script: "CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);\n",
+			want:   []string{"CREATE TABLE a (id INT)", "CREATE TABLE b (id INT)"},
+		},
+		{
+			name:   "varias sentencias en una línea",
+			script: "SET @a := 1; SET @b := 2;",
+			want:   []string{"SET @a := 1", "SET @b := 2"},
+		},
+		{
+			name:   "sentencia en varias líneas",
+			script: "CREATE TABLE a (\n    id INT\n);",
+			want:   []string{"CREATE TABLE a (\n    id INT\n)"},
+		},
+		{
+			name:   "sin ';' final",
+			script: "DO 0",
+			want:   []string{"DO 0"},
+		},
+		{
+			name:   "';' dentro de comillas simples",
+			script: "INSERT INTO a VALUES ('x; y');\nDO 0;",
+			want:   []string{"INSERT INTO a VALUES ('x; y')", "DO 0"},
+		},
+		{
+			name:   "';' dentro de comillas dobles y backticks",
+			script: "SELECT \"a;b\" AS `c;d`;",
+			want:   []string{"SELECT \"a;b\" AS `c;d`"},
+		},
+		{
+			name:   "comilla duplicada",
+			script: "SET @ddl := 'DEFAULT ''user''; DO 0';\nDO 1;",
+			want:   []string{"SET @ddl := 'DEFAULT ''user''; DO 0'", "DO 1"},
+		},
+		{
+			name:   "comilla escapada con barra invertida",
+			script: "SELECT 'it\\'s; fine', \"say \\\"hi;\\\"\";",
+			want:   []string{"SELECT 'it\\'s; fine', \"say \\\"hi;\\\"\""},
+		},
+		{
+			name:   "comentarios de línea",
+			script: "-- crea la tabla; con cuidado\nCREATE TABLE a (id INT); -- listo; sigue\n# otro; comentario\nDO 0;",
+			want:   []string{"CREATE TABLE a (id INT)", "DO 0"},
+		},
+		{
+			name:   "comentario de bloque",
+			script: "/* primero;\n   segundo; */\nCREATE TABLE a (id INT /* clave; */);",
+			want:   []string{"CREATE TABLE a (id INT  )"},
+		},
+		{
+			name:   "comentario ejecutable",
+			script: "CREATE TABLE a (id INT) /*!50100 ENGINE=InnoDB */;",
+			want:   []string{"CREATE TABLE a (id INT) /*!50100 ENGINE=InnoDB */"},
+		},
+		{
+			name:   "comentarios dentro de literales",
+			script: "INSERT INTO a VALUES ('-- no; es comentario', '# tampoco', '/* ni esto; */');",
+			want:   []string{"INSERT INTO a VALUES ('-- no; es comentario', '# tampoco', '/* ni esto; */')"},
+		},
+		{
+			name:   "doble guion sin espacio no es comentario",
+			script: "SELECT 1--1;",
+			want:   []string{"SELECT 1--1"},
+		},
+		{
+			name:   "sentencias vacías",
+			script: ";\n;  ;\n-- solo comentarios\n",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitStatements(tt.script); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("splitStatements() = %q, se esperaba %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	file := func(content string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(content)} }
+
+	tests := []struct {
+		name     string
+		fsys     fstest.MapFS
+		versions []int
+		wantErr  string
+	}{
+		{
+			name: "ordena por versión numérica",
+			fsys: fstest.MapFS{
+				"0010_ten.up.sql":    file("DO 10;"),
+				"0002_two.up.sql":    file("DO 2;"),
+				"0001_one.up.sql":    file("DO 1;"),
+				"0001_one.down.sql":  file("DO -1;"),
+				"0003_three.up.sql":  file("DO 3;"),
+				"0004_four.up.sql":   file("DO 4;"),
+				"0005_five.up.sql":   file("DO 5;"),
+				"0006_six.up.sql":    file("DO 6;"),
+				"0007_seven.up.sql":  file("DO 7;"),
+				"0008_eight.up.sql":  file("DO 8;"),
+				"0009_nine.up.sql":   file("DO 9;"),
+				"README.md":          file("no es una migración"),
+				"0011_draft.sql.bak": file("tampoco"),
+			},
+			versions: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+		},
+		{
+			name: "hueco en las versiones",
+			fsys: fstest.MapFS{
+				"0001_one.up.sql":    file("DO 1;"),
+				"0002_two.up.sql":    file("DO 2;"),
+				"0004_four.up.sql":   file("DO 4;"),
+				"0004_four.down.sql": file("DO -4;"),
+			},
+			wantErr: "falta la migración 0003",
+		},
+		{
+			name:    "no empieza en 0001",
+			fsys:    fstest.MapFS{"0002_two.up.sql": file("DO 2;")},
+			wantErr: "falta la migración 0001",
+		},
+		{
+			name: "versión duplicada",
+			fsys: fstest.MapFS{
+				"0001_one.up.sql":   file("DO 1;"),
+				"0001_other.up.sql": file("DO 1;"),
+			},
+			wantErr: "duplicada",
+		},
+		{
+			name:    "sin archivo up",
+			fsys:    fstest.MapFS{"0001_one.down.sql": file("DO -1;")},
+			wantErr: "no tiene archivo up",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := LoadMigrations(tt.fsys)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, se esperaba que contenga %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+
+			var versions []int
+			for _, migration := range migrations {
+				versions = append(versions, migration.Version)
+			}
+			if !reflect.DeepEqual(versions, tt.versions) {
+				t.Fatalf("versiones = %v, se esperaba %v", versions, tt.versions)
+			}
+			if migrations[0].Up != "DO 1;" || migrations[0].Down != "DO -1;" {
+				t.Fatalf("contenido de 0001 = %+v", migrations[0])
+			}
+		})
+	}
+}
+
+func TestLoadEmbeddedMigrations(t *testing.T) {
+	loaded, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatalf("error cargando las migraciones del servicio: %v", err)
+	}
+	for _, migration := range loaded {
+		if len(splitStatements(migration.Up)) == 0 {
+			t.Fatalf("la migración %04d_%s no tiene sentencias", migration.Version, migration.Name)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package oidc
+
+import "time"
+
+// ExpireKeys simula que pasó el intervalo mínimo desde la última descarga del
+// JWKS, para probar la rotación de claves sin esperar
+func (p *Provider) ExpireKeys() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.keys != nil {
+		p.keys.fetchedAt = time.Now().Add(-jwksRefreshInterval)
+	}
+}
@@ -0,0 +1,162 @@
+package oidc
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// IDTokenClaims claims del id_token que usa el servicio
+type IDTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce         string   `json:"nonce"`
+	Email         string   `json:"email"`
+	EmailVerified flexBool `json:"email_verified"`
+	GivenName     string   `json:"given_name"`
+	FamilyName    string   `json:"family_name"`
+	Name          string   `json:"name"`
+}
+
+// flexBool acepta true/false y "true"/"false", porque algunos proveedores
+// envían email_verified como string
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	switch strings.Trim(string(data), `"`) {
+	case "true":
+		*b = true
+	case "false", "null", "":
+		*b = false
+	default:
+		return fmt.Errorf("valor booleano inválido: %s", string(data))
+	}
+	return nil
+}
+
+// keySet claves públicas RSA del proveedor indexadas por kid
+type keySet struct {
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+// jwksRefreshInterval tiempo mínimo entre descargas del JWKS ante un kid desconocido
+const jwksRefreshInterval = time.Minute
+
+// VerifyIDToken valida firma (RS256), issuer, audiencia, expiración y nonce del id_token
+func (p *Provider) VerifyIDToken(rawToken, nonce string) (*IDTokenClaims, error) {
+	doc, err := p.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(doc.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithLeeway(time.Minute),
+	)
+
+	claims := &IDTokenClaims{}
+	_, err = parser.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.publicKey(doc.JWKSURI, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("id_token inválido: %v", err)
+	}
+
+	if claims.ExpiresAt == nil {
+		return nil, fmt.Errorf("id_token inválido: sin expiración")
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("id_token inválido: sin subject")
+	}
+	if claims.Nonce != nonce {
+		return nil, fmt.Errorf("id_token inválido: nonce no coincide")
+	}
+
+	return claims, nil
+}
+
+// publicKey busca la clave por kid, descargando el JWKS si no está o si el
+// proveedor rotó sus claves
+func (p *Provider) publicKey(jwksURI, kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key := p.keys.lookup(kid); key != nil {
+		return key, nil
+	}
+
+	if p.keys != nil && time.Since(p.keys.fetchedAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("clave de firma desconocida: %s", kid)
+	}
+
+	keys, err := p.fetchKeys(jwksURI)
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+
+	if key := p.keys.lookup(kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("clave de firma desconocida: %s", kid)
+}
+
+// lookup busca una clave; si el token no trae kid y hay una sola clave, usa esa
+func (ks *keySet) lookup(kid string) *rsa.PublicKey {
+	if ks == nil {
+		return nil
+	}
+	if kid == "" && len(ks.keys) == 1 {
+		for _, key := range ks.keys {
+			return key
+		}
+	}
+	return ks.keys[kid]
+}
+
+// fetchKeys descarga el JWKS y convierte las claves RSA de firma
+func (p *Provider) fetchKeys(jwksURI string) (*keySet, error) {
+	var jwks struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := p.getJSON(jwksURI, &jwks); err != nil {
+		return nil, fmt.Errorf("error obteniendo JWKS: %v", err)
+	}
+
+	keys := &keySet{keys: make(map[string]*rsa.PublicKey), fetchedAt: time.Now()}
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("clave %s inválida: %v", jwk.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("clave %s inválida: %v", jwk.Kid, err)
+		}
+
+		keys.keys[jwk.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	return keys, nil
+}
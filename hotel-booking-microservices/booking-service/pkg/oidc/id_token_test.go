@@ -0,0 +1,194 @@
+package oidc_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"booking-service/pkg/oidc"
+	"booking-service/pkg/oidc/oidctest"
+)
+
+const clientID = "booking-service"
+
+func newProvider(idp *oidctest.IdP) *oidc.Provider {
+	return oidc.NewProvider(oidc.Config{
+		Name:        "test",
+		IssuerURL:   idp.URL,
+		ClientID:    clientID,
+		RedirectURL: "http://localhost:8003/api/auth/oidc/callback",
+	})
+}
+
+// idTokenClaims claims válidos para el proveedor de prueba
+func idTokenClaims(idp *oidctest.IdP, nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            idp.URL,
+		"aud":            clientID,
+		"sub":            "user-1",
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"nonce":          nonce,
+		"email":          "ana@test.com",
+		"email_verified": "true",
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	idp := oidctest.New(t)
+	provider := newProvider(idp)
+
+	unpublished, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("error generando clave: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   func(claims jwt.MapClaims) string
+		modify  func(claims jwt.MapClaims)
+		wantErr string
+	}{
+		{name: "válido"},
+		{name: "otro nonce", modify: func(c jwt.MapClaims) { c["nonce"] = "otro" }, wantErr: "nonce no coincide"},
+		{name: "sin nonce", modify: func(c jwt.MapClaims) { delete(c, "nonce") }, wantErr: "nonce no coincide"},
+		{name: "otro issuer", modify: func(c jwt.MapClaims) { c["iss"] = "https://otro.example.com" }, wantErr: "issuer"},
+		{name: "otra audiencia", modify: func(c jwt.MapClaims) { c["aud"] = "otra-app" }, wantErr: "audience"},
+		{name: "audiencia múltiple", modify: func(c jwt.MapClaims) { c["aud"] = []string{"otra-app", clientID} }},
+		{name: "vencido", modify: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-2 * time.Minute).Unix() }, wantErr: "expired"},
+		{name: "vencido dentro del margen", modify: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-30 * time.Second).Unix() }},
+		{name: "sin expiración", modify: func(c jwt.MapClaims) { delete(c, "exp") }, wantErr: "sin expiración"},
+		{name: "sin subject", modify: func(c jwt.MapClaims) { delete(c, "sub") }, wantErr: "sin subject"},
+		{
+			name:    "clave no publicada",
+			token:   func(c jwt.MapClaims) string { return oidctest.SignWith(unpublished, "key-otra", c) },
+			wantErr: "clave de firma desconocida",
+		},
+		{
+			name:    "firma falsa con kid publicado",
+			token:   func(c jwt.MapClaims) string { return oidctest.SignWith(unpublished, "key-1", c) },
+			wantErr: "verification error",
+		},
+		{
+			name: "HS256",
+			token: func(c jwt.MapClaims) string {
+				signed, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString([]byte("secreto"))
+				return signed
+			},
+			wantErr: "signing method",
+		},
+		{name: "token mal formado", token: func(jwt.MapClaims) string { return "no.es.jwt" }, wantErr: "id_token inválido"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := idTokenClaims(idp, "nonce-1")
+			if tt.modify != nil {
+				tt.modify(claims)
+			}
+			token := idp.Sign(claims)
+			if tt.token != nil {
+				token = tt.token(claims)
+			}
+
+			verified, err := provider.VerifyIDToken(token, "nonce-1")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, se esperaba que contenga %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if verified.Subject != "user-1" || verified.Email != "ana@test.com" || !bool(verified.EmailVerified) {
+				t.Fatalf("claims = %+v", verified)
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenKeyRotation(t *testing.T) {
+	idp := oidctest.New(t)
+	provider := newProvider(idp)
+
+	oldToken := idp.Sign(idTokenClaims(idp, "n"))
+	if _, err := provider.VerifyIDToken(oldToken, "n"); err != nil {
+		t.Fatalf("error verificando con la clave inicial: %v", err)
+	}
+	if got := idp.JWKSRequests(); got != 1 {
+		t.Fatalf("descargas de JWKS = %d, se esperaba 1", got)
+	}
+
+	// El proveedor rota la clave y retira la anterior
+	idp.RotateKey(true)
+	newToken := idp.Sign(idTokenClaims(idp, "n"))
+
+	// Recién descargado el JWKS, un kid desconocido no provoca otra descarga:
+	// un atacante no puede forzar pedidos al proveedor con kids inventados
+	if _, err := provider.VerifyIDToken(newToken, "n"); err == nil || !strings.Contains(err.Error(), "clave de firma desconocida") {
+		t.Fatalf("error = %v, se esperaba clave desconocida", err)
+	}
+	if got := idp.JWKSRequests(); got != 1 {
+		t.Fatalf("descargas de JWKS = %d, se esperaba 1", got)
+	}
+
+	// Pasado el intervalo se vuelve a descargar y se acepta la clave nueva
+	provider.ExpireKeys()
+	if _, err := provider.VerifyIDToken(newToken, "n"); err != nil {
+		t.Fatalf("error verificando con la clave rotada: %v", err)
+	}
+	if got := idp.JWKSRequests(); got != 2 {
+		t.Fatalf("descargas de JWKS = %d, se esperaban 2", got)
+	}
+
+	// La clave retirada ya no sirve
+	if _, err := provider.VerifyIDToken(oldToken, "n"); err == nil {
+		t.Fatalf("se aceptó un token firmado con una clave retirada")
+	}
+}
+
+func TestExchangeRequiresCodeVerifier(t *testing.T) {
+	idp := oidctest.New(t)
+	provider := newProvider(idp)
+
+	verifier, err := oidc.RandomString(32)
+	if err != nil {
+		t.Fatalf("error generando verifier: %v", err)
+	}
+	idToken := idp.Sign(idTokenClaims(idp, "n"))
+
+	code := idp.IssueCode(oidc.CodeChallenge(verifier), idToken)
+	if _, err := provider.Exchange(code, "otro-verifier"); err == nil || !strings.Contains(err.Error(), "rechazó el código") {
+		t.Fatalf("error = %v, se esperaba rechazo del código", err)
+	}
+
+	code = idp.IssueCode(oidc.CodeChallenge(verifier), idToken)
+	token, err := provider.Exchange(code, verifier)
+	if err != nil {
+		t.Fatalf("error canjeando código: %v", err)
+	}
+	if token.IDToken != idToken {
+		t.Fatalf("id_token inesperado")
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	idp := oidctest.New(t)
+	provider := newProvider(idp)
+
+	authURL, err := provider.AuthCodeURL("state-1", "nonce-1", "challenge-1")
+	if err != nil {
+		t.Fatalf("error armando URL: %v", err)
+	}
+	for _, param := range []string{"state=state-1", "nonce=nonce-1", "code_challenge=challenge-1", "code_challenge_method=S256", "client_id=" + clientID} {
+		if !strings.Contains(authURL, param) {
+			t.Fatalf("la URL %s no tiene %s", authURL, param)
+		}
+	}
+}
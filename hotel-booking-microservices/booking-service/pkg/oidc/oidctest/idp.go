@@ -0,0 +1,170 @@
+// Package oidctest levanta un proveedor OpenID Connect en memoria para los tests:
+// discovery, JWKS con rotación de claves y token endpoint con PKCE.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"booking-service/pkg/oidc"
+)
+
+// IdP proveedor de identidad de prueba
+type IdP struct {
+	URL string
+
+	mu           sync.Mutex
+	keys         map[string]*rsa.PrivateKey
+	current      string
+	generated    int
+	codes        map[string]grant
+	jwksRequests int
+}
+
+// grant código de autorización emitido y lo que entrega al canjearlo
+type grant struct {
+	codeChallenge string
+	idToken       string
+}
+
+// New levanta el proveedor con una clave de firma; se cierra al terminar el test
+func New(t testing.TB) *IdP {
+	t.Helper()
+
+	idp := &IdP{keys: make(map[string]*rsa.PrivateKey), codes: make(map[string]grant)}
+	idp.RotateKey(false)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/jwks", idp.jwks)
+	mux.HandleFunc("/token", idp.token)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	idp.URL = server.URL
+	return idp
+}
+
+// RotateKey genera una clave nueva y firma con ella desde ahora. Con
+// dropOld=true el JWKS deja de publicar las claves anteriores.
+func (p *IdP) RotateKey(dropOld bool) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(fmt.Sprintf("oidctest: error generando clave: %v", err))
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if dropOld {
+		p.keys = make(map[string]*rsa.PrivateKey)
+	}
+	p.generated++
+	p.current = fmt.Sprintf("key-%d", p.generated)
+	p.keys[p.current] = key
+	return p.current
+}
+
+// JWKSRequests cantidad de veces que se descargó el JWKS
+func (p *IdP) JWKSRequests() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.jwksRequests
+}
+
+// Sign firma los claims con la clave vigente (RS256)
+func (p *IdP) Sign(claims jwt.MapClaims) string {
+	p.mu.Lock()
+	kid, key := p.current, p.keys[p.current]
+	p.mu.Unlock()
+	return SignWith(key, kid, claims)
+}
+
+// SignWith firma los claims con una clave arbitraria, por ejemplo una que el
+// proveedor nunca publicó
+func SignWith(key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		panic(fmt.Sprintf("oidctest: error firmando token: %v", err))
+	}
+	return signed
+}
+
+// IssueCode emite un código de autorización atado al code_challenge: el token
+// endpoint solo lo canjea con el code_verifier correspondiente
+func (p *IdP) IssueCode(codeChallenge, idToken string) string {
+	code, err := oidc.RandomString(16)
+	if err != nil {
+		panic(err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.codes[code] = grant{codeChallenge: codeChallenge, idToken: idToken}
+	return code
+}
+
+func (p *IdP) discovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]string{
+		"issuer":                 p.URL,
+		"authorization_endpoint": p.URL + "/authorize",
+		"token_endpoint":         p.URL + "/token",
+		"jwks_uri":               p.URL + "/jwks",
+	})
+}
+
+func (p *IdP) jwks(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.jwksRequests++
+
+	keys := []map[string]string{}
+	for kid, key := range p.keys {
+		keys = append(keys, map[string]string{
+			"kid": kid,
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+}
+
+func (p *IdP) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		http.Error(w, `{"error":"invalid_request"}`, http.StatusBadRequest)
+		return
+	}
+
+	// Los códigos son de un solo uso
+	p.mu.Lock()
+	issued, ok := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	if !ok || oidc.CodeChallenge(r.PostForm.Get("code_verifier")) != issued.codeChallenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	json.NewEncoder(w).Encode(oidc.TokenResponse{
+		AccessToken: "access-token",
+		TokenType:   "Bearer",
+		IDToken:     issued.idToken,
+		ExpiresIn:   3600,
+	})
+}
@@ -0,0 +1,185 @@
+package oidc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config parámetros del proveedor de identidad
+type Config struct {
+	Name         string // identificador guardado en user_identities (ej: "google")
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Provider cliente OpenID Connect para el flujo authorization code + PKCE
+type Provider struct {
+	cfg        Config
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	discovery *discoveryDocument
+	keys      *keySet
+}
+
+// discoveryDocument campos usados de /.well-known/openid-configuration
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// TokenResponse respuesta del token endpoint
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// NewProvider crea el cliente. El documento de discovery se obtiene en el
+// primer uso, para que un proveedor caído no impida arrancar el servicio.
+func NewProvider(cfg Config) *Provider {
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	cfg.IssuerURL = strings.TrimRight(cfg.IssuerURL, "/")
+
+	return &Provider{
+		cfg: cfg,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Name devuelve el identificador del proveedor
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+// AuthCodeURL arma la URL de autorización con state, nonce y el challenge PKCE (S256)
+func (p *Provider) AuthCodeURL(state, nonce, codeChallenge string) (string, error) {
+	doc, err := p.getDiscovery()
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.cfg.ClientID)
+	params.Set("redirect_uri", p.cfg.RedirectURL)
+	params.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(doc.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return doc.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// Exchange canjea el código de autorización por tokens
+func (p *Provider) Exchange(code, codeVerifier string) (*TokenResponse, error) {
+	doc, err := p.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	if p.cfg.ClientSecret == "" {
+		// Cliente público: se identifica solo con client_id
+		form.Set("client_id", p.cfg.ClientID)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("error creando petición de token: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	if p.cfg.ClientSecret != "" {
+		// client_secret_basic (RFC 6749 2.3.1)
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error canjeando código: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("el proveedor rechazó el código: %d - %s", resp.StatusCode, string(body))
+	}
+
+	var token TokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("error parseando respuesta de token: %v", err)
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("el proveedor no devolvió id_token")
+	}
+
+	return &token, nil
+}
+
+// getDiscovery obtiene y cachea el documento de discovery
+func (p *Provider) getDiscovery() (*discoveryDocument, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var doc discoveryDocument
+	if err := p.getJSON(p.cfg.IssuerURL+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, fmt.Errorf("error obteniendo discovery: %v", err)
+	}
+
+	if strings.TrimRight(doc.Issuer, "/") != p.cfg.IssuerURL {
+		return nil, fmt.Errorf("issuer inesperado en discovery: %s", doc.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, fmt.Errorf("documento de discovery incompleto")
+	}
+
+	p.discovery = &doc
+	return p.discovery, nil
+}
+
+// getJSON hace un GET y decodifica la respuesta
+func (p *Provider) getJSON(endpoint string, result interface{}) error {
+	resp, err := p.httpClient.Get(endpoint)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s respondió %d - %s", endpoint, resp.StatusCode, string(body))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
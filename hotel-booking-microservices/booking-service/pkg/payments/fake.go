@@ -0,0 +1,164 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Medios de pago de prueba de la pasarela fake. Cualquier otro token "tok_" se
+// autoriza.
+const (
+	TokenDeclined        = "tok_declined"         // rechazo inmediato
+	TokenPending         = "tok_pending"          // se autoriza más tarde por webhook
+	TokenPendingDeclined = "tok_pending_declined" // se rechaza más tarde por webhook
+	TokenUnavailable     = "tok_unavailable"      // la pasarela no responde
+)
+
+// webhookAttempts reintentos de entrega de un webhook de la pasarela fake
+const webhookAttempts = 5
+
+// FakeGateway pasarela local determinística para desarrollo: el resultado
+// depende solo del token y los IDs se derivan de la referencia, así que un
+// reintento devuelve la misma transacción. No guarda estado.
+type FakeGateway struct {
+	webhookURL    string
+	webhookSecret string
+	webhookDelay  time.Duration
+	httpClient    *http.Client
+}
+
+// NewFakeGateway crea la pasarela fake. Sin webhookURL los pagos pendientes
+// nunca se resuelven.
+func NewFakeGateway(webhookURL, webhookSecret string, webhookDelay time.Duration) *FakeGateway {
+	return &FakeGateway{
+		webhookURL:    webhookURL,
+		webhookSecret: webhookSecret,
+		webhookDelay:  webhookDelay,
+		httpClient:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name nombre guardado en payments.gateway
+func (g *FakeGateway) Name() string {
+	return "fake"
+}
+
+// Authorize autoriza, rechaza o deja pendiente según el token
+func (g *FakeGateway) Authorize(ctx context.Context, req AuthorizeRequest) (*Result, error) {
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("monto inválido: %.2f", req.Amount)
+	}
+
+	result := &Result{
+		TransactionID: "fake_" + fakeID(req.Reference),
+		Amount:        req.Amount,
+	}
+
+	switch {
+	case req.PaymentMethod == TokenUnavailable:
+		return nil, fmt.Errorf("pasarela no disponible")
+	case req.PaymentMethod == TokenDeclined:
+		result.Status = StatusDeclined
+		result.Message = "fondos insuficientes"
+	case req.PaymentMethod == TokenPending:
+		result.Status = StatusPending
+		g.notifyLater(Event{Type: EventAuthorized, TransactionID: result.TransactionID, Amount: req.Amount, Currency: req.Currency})
+	case req.PaymentMethod == TokenPendingDeclined:
+		result.Status = StatusPending
+		g.notifyLater(Event{Type: EventDeclined, TransactionID: result.TransactionID, Amount: req.Amount, Currency: req.Currency, Message: "rechazado por el emisor"})
+	case strings.HasPrefix(req.PaymentMethod, "tok_"):
+		result.Status = StatusAuthorized
+	default:
+		result.Status = StatusDeclined
+		result.Message = "medio de pago inválido"
+	}
+
+	return result, nil
+}
+
+// Capture cobra el monto autorizado (o parte de él)
+func (g *FakeGateway) Capture(ctx context.Context, transactionID string, amount float64) (*Result, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("monto inválido: %.2f", amount)
+	}
+	return &Result{TransactionID: transactionID, Status: StatusCaptured, Amount: amount}, nil
+}
+
+// Void libera una autorización sin cobrar
+func (g *FakeGateway) Void(ctx context.Context, transactionID string) (*Result, error) {
+	return &Result{TransactionID: transactionID, Status: StatusVoided}, nil
+}
+
+// Refund devuelve parte o todo lo cobrado
+func (g *FakeGateway) Refund(ctx context.Context, transactionID string, amount float64, idempotencyKey string) (*Result, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("monto inválido: %.2f", amount)
+	}
+	return &Result{TransactionID: transactionID, Status: StatusRefunded, Amount: amount}, nil
+}
+
+// notifyLater envía el webhook firmado tras la demora configurada. Reintenta
+// mientras el receptor no responda 2xx, como haría una pasarela real.
+func (g *FakeGateway) notifyLater(event Event) {
+	if g.webhookURL == "" {
+		log.Printf("⚠️ Pasarela fake sin webhook configurado: %s de %s no se enviará", event.Type, event.TransactionID)
+		return
+	}
+
+	event.ID = "evt_" + fakeID(event.TransactionID+"|"+event.Type)
+	event.CreatedAt = time.Now().UTC()
+
+	go func() {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			log.Printf("⚠️ Error serializando webhook de pago: %v", err)
+			return
+		}
+
+		delay := g.webhookDelay
+		for attempt := 1; attempt <= webhookAttempts; attempt++ {
+			time.Sleep(delay)
+			if err := g.send(payload); err != nil {
+				log.Printf("⚠️ Webhook de pago %s (intento %d/%d): %v", event.ID, attempt, webhookAttempts, err)
+				delay = 2*delay + time.Second
+				continue
+			}
+			return
+		}
+	}()
+}
+
+// send entrega un webhook firmado
+func (g *FakeGateway) send(payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, g.webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(g.webhookSecret, time.Now().Unix(), payload))
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// fakeID deriva un ID estable de la semilla
+func fakeID(seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+	return hex.EncodeToString(sum[:10])
+}
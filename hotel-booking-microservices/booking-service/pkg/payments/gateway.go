@@ -0,0 +1,72 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Estados de una operación en la pasarela
+const (
+	StatusAuthorized = "authorized"
+	StatusPending    = "pending" // la pasarela confirma el resultado por webhook
+	StatusDeclined   = "declined"
+	StatusCaptured   = "captured"
+	StatusVoided     = "voided"
+	StatusRefunded   = "refunded"
+)
+
+// AuthorizeRequest datos para autorizar un cobro
+type AuthorizeRequest struct {
+	Reference     string // referencia de la reserva; la pasarela la usa como clave de idempotencia
+	Amount        float64
+	Currency      string
+	PaymentMethod string // token del medio de pago generado por el frontend
+}
+
+// Result resultado de una operación en la pasarela
+type Result struct {
+	TransactionID string
+	Status        string
+	Amount        float64
+	Message       string // motivo del rechazo
+}
+
+// PaymentGateway pasarela de pagos con autorización y captura separadas. Un
+// rechazo no es un error: se informa en Result.Status. Los errores indican que
+// la pasarela no respondió y la operación puede reintentarse.
+type PaymentGateway interface {
+	Name() string
+	Authorize(ctx context.Context, req AuthorizeRequest) (*Result, error)
+	Capture(ctx context.Context, transactionID string, amount float64) (*Result, error)
+	Void(ctx context.Context, transactionID string) (*Result, error)
+	Refund(ctx context.Context, transactionID string, amount float64, idempotencyKey string) (*Result, error)
+}
+
+// Config parámetros para construir una PaymentGateway
+type Config struct {
+	Driver        string // "fake" o "none"
+	WebhookURL    string // solo para el driver "fake"
+	WebhookSecret string
+	WebhookDelay  time.Duration
+}
+
+// New crea la pasarela indicada por la configuración. "none" deshabilita los
+// pagos y devuelve nil.
+func New(cfg Config) (PaymentGateway, error) {
+	if cfg.Driver == "none" || cfg.Driver == "" {
+		return nil, nil
+	}
+
+	// Sin secreto cualquiera podría firmar un webhook que confirme reservas impagas
+	if cfg.WebhookSecret == "" {
+		return nil, fmt.Errorf("la pasarela %s requiere PAYMENT_WEBHOOK_SECRET", cfg.Driver)
+	}
+
+	switch cfg.Driver {
+	case "fake":
+		return NewFakeGateway(cfg.WebhookURL, cfg.WebhookSecret, cfg.WebhookDelay), nil
+	default:
+		return nil, fmt.Errorf("pasarela de pagos desconocida: %s", cfg.Driver)
+	}
+}
@@ -0,0 +1,92 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader header con la firma de los webhooks: "t=<unix>,v1=<hmac>"
+const SignatureHeader = "X-Payment-Signature"
+
+// Tipos de eventos enviados por la pasarela
+const (
+	EventAuthorized = "payment.authorized"
+	EventDeclined   = "payment.declined"
+	EventCaptured   = "payment.captured"
+	EventVoided     = "payment.voided"
+	EventRefunded   = "payment.refunded"
+)
+
+// Event notificación asíncrona de la pasarela. La entrega es al menos una vez:
+// el receptor debe deduplicar por ID.
+type Event struct {
+	ID            string    `json:"id"`
+	Type          string    `json:"type"`
+	TransactionID string    `json:"transaction_id"`
+	Amount        float64   `json:"amount"`
+	Currency      string    `json:"currency"`
+	Message       string    `json:"message,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Sign firma el cuerpo de un webhook. El timestamp entra en la firma para que
+// un webhook capturado no pueda reenviarse pasada la tolerancia.
+func Sign(secret string, timestamp int64, payload []byte) string {
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, computeSignature(secret, timestamp, payload))
+}
+
+// VerifySignature valida la firma de un webhook y su antigüedad
+func VerifySignature(secret, header string, payload []byte, tolerance time.Duration, now time.Time) error {
+	if secret == "" {
+		return fmt.Errorf("firma inválida: secreto de webhooks no configurado")
+	}
+
+	var timestamp int64
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("firma inválida: timestamp mal formado")
+			}
+			timestamp = parsed
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == 0 || len(signatures) == 0 {
+		return fmt.Errorf("firma inválida: header %s incompleto", SignatureHeader)
+	}
+
+	age := now.Sub(time.Unix(timestamp, 0))
+	if age > tolerance || age < -tolerance {
+		return fmt.Errorf("firma inválida: webhook fuera de la tolerancia de %v", tolerance)
+	}
+
+	expected := computeSignature(secret, timestamp, payload)
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("firma inválida")
+}
+
+// computeSignature HMAC-SHA256 de "<timestamp>.<payload>" en hexadecimal
+func computeSignature(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,120 @@
+package payments
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "whsec-test"
+
+func TestVerifySignature(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	payload := []byte(`{"id":"evt_1","type":"payment.authorized"}`)
+	valid := Sign(testSecret, now.Unix(), payload)
+
+	tests := []struct {
+		name    string
+		secret  string
+		header  string
+		payload []byte
+		wantErr string
+	}{
+		{name: "firma válida", secret: testSecret, header: valid, payload: payload},
+		{name: "firma válida con espacios", secret: testSecret, header: strings.ReplaceAll(valid, ",", ", "), payload: payload},
+		{name: "varias firmas, una válida", secret: testSecret, header: valid + ",v1=deadbeef", payload: payload},
+		{name: "cuerpo alterado", secret: testSecret, header: valid, payload: []byte(`{"id":"evt_1","type":"payment.declined"}`), wantErr: "firma inválida"},
+		{name: "otro secreto", secret: "otro", header: valid, payload: payload, wantErr: "firma inválida"},
+		{name: "sin secreto configurado", secret: "", header: valid, payload: payload, wantErr: "secreto de webhooks no configurado"},
+		{name: "header vacío", secret: testSecret, header: "", payload: payload, wantErr: "incompleto"},
+		{name: "sin firma", secret: testSecret, header: fmt.Sprintf("t=%d", now.Unix()), payload: payload, wantErr: "incompleto"},
+		{name: "sin timestamp", secret: testSecret, header: "v1=" + computeSignature(testSecret, now.Unix(), payload), payload: payload, wantErr: "incompleto"},
+		{name: "timestamp mal formado", secret: testSecret, header: "t=abc,v1=00", payload: payload, wantErr: "timestamp mal formado"},
+		{
+			// La firma es del cuerpo con otro timestamp: no se puede mover t sin volver a firmar
+			name: "timestamp reemplazado", secret: testSecret, payload: payload,
+			header:  fmt.Sprintf("t=%d,v1=%s", now.Unix()+1, computeSignature(testSecret, now.Unix(), payload)),
+			wantErr: "firma inválida",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySignature(tt.secret, tt.header, tt.payload, 5*time.Minute, now)
+			checkError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestVerifySignatureTolerance(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	payload := []byte(`{}`)
+	tolerance := 5 * time.Minute
+
+	tests := []struct {
+		name    string
+		signed  time.Time
+		wantErr string
+	}{
+		{name: "recién firmado", signed: now},
+		{name: "en el límite", signed: now.Add(-tolerance)},
+		{name: "viejo", signed: now.Add(-tolerance - time.Second), wantErr: "fuera de la tolerancia"},
+		{name: "reloj del emisor adelantado", signed: now.Add(tolerance), wantErr: ""},
+		{name: "del futuro", signed: now.Add(tolerance + time.Second), wantErr: "fuera de la tolerancia"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := Sign(testSecret, tt.signed.Unix(), payload)
+			err := VerifySignature(testSecret, header, payload, tolerance, now)
+			checkError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestSignFormat(t *testing.T) {
+	header := Sign(testSecret, 42, []byte("x"))
+	if !strings.HasPrefix(header, "t=42,v1=") || len(header) != len("t=42,v1=")+64 {
+		t.Fatalf("formato de firma inesperado: %s", header)
+	}
+}
+
+func TestNewRequiresWebhookSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		enabled bool
+		wantErr string
+	}{
+		{name: "none", cfg: Config{Driver: "none"}},
+		{name: "vacío", cfg: Config{}},
+		{name: "fake sin secreto", cfg: Config{Driver: "fake"}, wantErr: "requiere PAYMENT_WEBHOOK_SECRET"},
+		{name: "fake con secreto", cfg: Config{Driver: "fake", WebhookSecret: testSecret}, enabled: true},
+		{name: "desconocida", cfg: Config{Driver: "otra", WebhookSecret: testSecret}, wantErr: "desconocida"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway, err := New(tt.cfg)
+			checkError(t, err, tt.wantErr)
+			if (gateway != nil) != tt.enabled {
+				t.Fatalf("pasarela = %v, se esperaba habilitada = %v", gateway, tt.enabled)
+			}
+		})
+	}
+}
+
+// checkError verifica que err contenga wantErr, o que sea nil si wantErr es vacío
+func checkError(t *testing.T, err error, wantErr string) {
+	t.Helper()
+	if wantErr == "" {
+		if err != nil {
+			t.Fatalf("error inesperado: %v", err)
+		}
+		return
+	}
+	if err == nil || !strings.Contains(err.Error(), wantErr) {
+		t.Fatalf("error = %v, se esperaba que contenga %q", err, wantErr)
+	}
+}
@@ -0,0 +1,131 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Publisher publica mensajes con confirmación del broker (publisher confirms).
+// Si la conexión se pierde, se reconecta en la siguiente publicación.
+type Publisher struct {
+	uri      string
+	exchange string
+
+	mu      sync.Mutex
+	conn    *amqp.Connection
+	channel *amqp.Channel
+}
+
+// NewPublisher crea el publisher. La conexión se abre en la primera publicación,
+// para que un broker caído no impida arrancar el servicio.
+func NewPublisher(uri, exchange string) *Publisher {
+	return &Publisher{uri: uri, exchange: exchange}
+}
+
+// Publish publica un mensaje persistente y espera la confirmación del broker
+func (p *Publisher) Publish(ctx context.Context, routingKey, messageID string, body []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.ensureChannel(); err != nil {
+		return err
+	}
+
+	confirmation, err := p.channel.PublishWithDeferredConfirmWithContext(ctx,
+		p.exchange, // exchange
+		routingKey, // routing key
+		false,      // mandatory
+		false,      // immediate
+		amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			MessageId:    messageID,
+			Timestamp:    time.Now(),
+			Body:         body,
+		},
+	)
+	if err != nil {
+		p.reset()
+		return fmt.Errorf("error publicando mensaje: %v", err)
+	}
+
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		p.reset()
+		return fmt.Errorf("error esperando confirmación: %v", err)
+	}
+	if !acked {
+		return fmt.Errorf("el broker rechazó el mensaje %s", messageID)
+	}
+
+	return nil
+}
+
+// Close cierra la conexión
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.reset()
+	return nil
+}
+
+// ensureChannel abre conexión y canal en modo confirmación si no están abiertos
+func (p *Publisher) ensureChannel() error {
+	if p.conn != nil && !p.conn.IsClosed() && p.channel != nil && !p.channel.IsClosed() {
+		return nil
+	}
+	p.reset()
+
+	conn, err := amqp.Dial(p.uri)
+	if err != nil {
+		return fmt.Errorf("error conectando a RabbitMQ: %v", err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("error creando canal: %v", err)
+	}
+
+	err = ch.ExchangeDeclare(
+		p.exchange, // nombre
+		"topic",    // tipo
+		true,       // durable
+		false,      // auto-deleted
+		false,      // internal
+		false,      // no-wait
+		nil,        // arguments
+	)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("error declarando exchange: %v", err)
+	}
+
+	if err := ch.Confirm(false); err != nil {
+		conn.Close()
+		return fmt.Errorf("error activando confirmaciones: %v", err)
+	}
+
+	p.conn = conn
+	p.channel = ch
+	log.Printf("✅ Conectado a RabbitMQ (exchange %s)", p.exchange)
+	return nil
+}
+
+// reset descarta la conexión actual
+func (p *Publisher) reset() {
+	if p.channel != nil {
+		p.channel.Close()
+		p.channel = nil
+	}
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn = nil
+	}
+}
@@ -6,6 +6,7 @@ import AdminDashboard from './pages/AdminDashboard';
 import Detail from './pages/Detail';
 import Results from './pages/Results';
 import Confirmation from './pages/Confirmation';
+import OAuthCallback from './pages/OAuthCallback';
 
 function App() {
   return (
@@ -30,6 +31,9 @@ function App() {
           {/* Confirmación de reserva */}
           <Route path="/confirmation" element={<Confirmation />} />
           
+          {/* Retorno del login con proveedor externo */}
+          <Route path="/oauth/callback" element={<OAuthCallback />} />
+          
           {/* Ruta por defecto - redirige a login */}
           <Route path="*" element={<Login />} />
         </Routes>
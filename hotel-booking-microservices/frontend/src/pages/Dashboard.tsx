@@ -130,6 +130,10 @@ const Dashboard: React.FC = () => {
   };
 
   const handleLogout = () => {
+    const refreshToken = localStorage.getItem('refreshToken');
+    if (refreshToken) {
+      bookingAPI.logout(refreshToken).catch((err) => console.error('Logout error:', err));
+    }
     localStorage.clear();
     navigate('/');
   };
@@ -44,6 +44,7 @@ const Login: React.FC = () => {
       
       // Guardar en localStorage
       localStorage.setItem('token', token);
+      localStorage.setItem('refreshToken', response.refresh_token);
       localStorage.setItem('userRole', user.role);
       localStorage.setItem('userName', `${user.first_name} ${user.last_name}`);
       localStorage.setItem('userEmail', user.email);
@@ -249,6 +250,24 @@ const Login: React.FC = () => {
               >
                 {loading ? '⏳ Iniciando sesión...' : '🚀 Iniciar Sesión'}
               </button>
+
+              <button
+                onClick={() => { window.location.href = bookingAPI.oidcLoginUrl(); }}
+                disabled={loading}
+                style={{
+                  width: '100%',
+                  marginTop: '12px',
+                  padding: '12px',
+                  backgroundColor: 'white',
+                  color: '#1976d2',
+                  border: '2px solid #1976d2',
+                  borderRadius: '10px',
+                  fontSize: '16px',
+                  cursor: loading ? 'not-allowed' : 'pointer'
+                }}
+              >
+                🔑 Ingresar con proveedor externo
+              </button>
             </div>
           ) : (
             /* Formulario de Registro */
@@ -0,0 +1,55 @@
+import React, { useEffect, useState } from 'react';
+import { useNavigate } from 'react-router-dom';
+import { bookingAPI } from '../services/api';
+
+// Recibe los tokens del login externo en el fragmento de la URL
+const OAuthCallback: React.FC = () => {
+  const navigate = useNavigate();
+  const [error, setError] = useState('');
+
+  useEffect(() => {
+    const params = new URLSearchParams(window.location.hash.substring(1));
+    // Limpiar el fragmento para que los tokens no queden en el historial
+    window.history.replaceState(null, '', window.location.pathname);
+
+    const token = params.get('token');
+    const refreshToken = params.get('refresh_token');
+    if (params.get('error') || !token || !refreshToken) {
+      setError('No se pudo iniciar sesión con el proveedor externo.');
+      return;
+    }
+
+    localStorage.setItem('token', token);
+    localStorage.setItem('refreshToken', refreshToken);
+
+    bookingAPI.getProfile()
+      .then((user) => {
+        localStorage.setItem('userRole', user.role);
+        localStorage.setItem('userName', `${user.first_name} ${user.last_name}`);
+        localStorage.setItem('userEmail', user.email);
+
+        const redirect = params.get('redirect');
+        if (redirect) {
+          navigate(redirect);
+        } else {
+          navigate(user.role === 'admin' ? '/admin' : '/dashboard');
+        }
+      })
+      .catch(() => setError('No se pudo obtener el perfil del usuario.'));
+  }, [navigate]);
+
+  return (
+    <div style={{ padding: '60px', textAlign: 'center' }}>
+      {error ? (
+        <>
+          <p>❌ {error}</p>
+          <button onClick={() => navigate('/')}>Volver al login</button>
+        </>
+      ) : (
+        <p>⏳ Iniciando sesión...</p>
+      )}
+    </div>
+  );
+};
+
+export default OAuthCallback;
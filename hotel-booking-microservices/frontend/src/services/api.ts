@@ -21,6 +21,31 @@ bookingApi.interceptors.request.use((config) => {
   return config;
 });
 
+// Interceptor para renovar el access token vencido con el refresh token
+bookingApi.interceptors.response.use(
+  (response) => response,
+  async (error) => {
+    const original = error.config;
+    const refreshToken = localStorage.getItem('refreshToken');
+    if (error.response?.status !== 401 || original._retry || !refreshToken) {
+      return Promise.reject(error);
+    }
+
+    original._retry = true;
+    try {
+      const auth = await bookingAPI.refresh(refreshToken);
+      localStorage.setItem('token', auth.token);
+      localStorage.setItem('refreshToken', auth.refresh_token);
+      original.headers.Authorization = `Bearer ${auth.token}`;
+      return bookingApi(original);
+    } catch (refreshError) {
+      localStorage.removeItem('token');
+      localStorage.removeItem('refreshToken');
+      return Promise.reject(refreshError);
+    }
+  }
+);
+
 export const hotelAPI = {
   // Obtener todos los hoteles
   getAllHotels: async () => {
@@ -125,6 +150,17 @@ export const bookingAPI = {
     return response.data.data;
   },
 
+  // Renovar access token
+  refresh: async (refreshToken: string): Promise<AuthResponse> => {
+    const response = await axios.post(`${API_BASE.booking}/auth/refresh`, { refresh_token: refreshToken });
+    return response.data.data;
+  },
+
+  // Logout: revoca el refresh token
+  logout: async (refreshToken: string) => {
+    await axios.post(`${API_BASE.booking}/auth/logout`, { refresh_token: refreshToken });
+  },
+
   // Verificar disponibilidad
   checkAvailability: async (hotelId: string, params: {
     checkin: string;
@@ -166,5 +202,8 @@ export const bookingAPI = {
   getProfile: async (): Promise<User> => {
     const response = await bookingApi.get('/profile');
     return response.data.data;
-  }
+  },
+
+  // URL para iniciar sesión con el proveedor externo (OpenID Connect)
+  oidcLoginUrl: (): string => `${API_BASE.booking}/auth/oidc/login`
 };
\ No newline at end of file
@@ -68,4 +68,6 @@ export interface Booking {
 export interface AuthResponse {
   user: User;
   token: string;
+  refresh_token: string;
+  expires_in: number;
 }
\ No newline at end of file
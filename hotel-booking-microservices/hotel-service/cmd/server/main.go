@@ -8,7 +8,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"hotel-service/internal/config"
 	"hotel-service/internal/handlers"
+	"hotel-service/internal/middleware"
 	"hotel-service/internal/services"
+	"hotel-service/pkg/bookingservice"
 	"hotel-service/pkg/mongodb"
 	"hotel-service/pkg/rabbitmq"
 )
@@ -16,6 +18,9 @@ import (
 func main() {
 	// Cargar configuración
 	cfg := config.Load()
+	if cfg.JWTSecret == "" {
+		log.Fatal("JWT_SECRET no configurada: debe ser el secreto con el que firma booking-service")
+	}
 
 	// Conectar a MongoDB
 	mongoClient, err := mongodb.Connect(cfg.MongoDBURI)
@@ -38,7 +43,7 @@ func main() {
 	hotelHandler := handlers.NewHotelHandler(hotelService)
 
 	// Configurar rutas
-	router := setupRoutes(hotelHandler)
+	router := setupRoutes(hotelHandler, cfg.JWTSecret, bookingservice.NewClient(cfg.BookingServiceURI))
 
 	// Obtener puerto
 	port := cfg.Port
@@ -49,7 +54,7 @@ func main() {
 	}
 }
 
-func setupRoutes(hotelHandler *handlers.HotelHandler) *gin.Engine {
+func setupRoutes(hotelHandler *handlers.HotelHandler, jwtSecret string, tokenVerifier middleware.TokenVerifier) *gin.Engine {
 	// Configurar Gin en modo release para producción
 	if os.Getenv("ENVIRONMENT") == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -113,22 +118,37 @@ func setupRoutes(hotelHandler *handlers.HotelHandler) *gin.Engine {
 	// API v1
 	v1 := router.Group("/api/v1")
 	{
+		// Los cambios requieren un JWT de booking-service con rol admin
+		adminOnly := []gin.HandlerFunc{middleware.AuthMiddleware(jwtSecret, tokenVerifier), middleware.AdminMiddleware()}
+
 		hotels := v1.Group("/hotels")
 		{
 			// Rutas públicas
 			hotels.GET("/:id", hotelHandler.GetHotelByID)
 			hotels.GET("", hotelHandler.GetAllHotels)
+			hotels.GET("/:id/rooms", hotelHandler.GetRoomTypes) // Listar tipos de habitación
+		}
+
+		// Rutas de administración
+		admin := v1.Group("/hotels", adminOnly...)
+		{
+			admin.POST("", hotelHandler.CreateHotel)           // Crear hotel
+			admin.PUT("/:id", hotelHandler.UpdateHotel)        // Actualizar hotel
+			admin.DELETE("/:id", hotelHandler.DeleteHotel)     // Eliminar hotel
+			admin.GET("/stats", hotelHandler.GetHotelStats)    // Estadísticas
 			
-			// Rutas de administración (deberías agregar middleware de auth aquí)
-			hotels.POST("", hotelHandler.CreateHotel)           // Crear hotel
-			hotels.PUT("/:id", hotelHandler.UpdateHotel)        // Actualizar hotel
-			hotels.DELETE("/:id", hotelHandler.DeleteHotel)     // Eliminar hotel
-			hotels.GET("/stats", hotelHandler.GetHotelStats)    // Estadísticas
-			
-			// NUEVAS RUTAS para upload de imágenes
-			hotels.POST("/upload-single", hotelHandler.UploadSingleImage)    // Subir imagen individual
-			hotels.POST("/upload-images", hotelHandler.UploadHotelImages)    // Subir múltiples imágenes
+			// Upload de imágenes
+			admin.POST("/upload-single", hotelHandler.UploadSingleImage)    // Subir imagen individual
+			admin.POST("/upload-images", hotelHandler.UploadHotelImages)    // Subir múltiples imágenes
+
+			// Tipos de habitación de cada hotel
+			admin.POST("/:id/rooms", hotelHandler.CreateRoomType)              // Crear tipo de habitación
+			admin.PUT("/:id/rooms/:roomId", hotelHandler.UpdateRoomType)       // Actualizar tipo de habitación
+			admin.DELETE("/:id/rooms/:roomId", hotelHandler.DeleteRoomType)    // Eliminar tipo de habitación
 		}
+
+		// Registro de auditoría de cambios
+		v1.GET("/audit", append(adminOnly, hotelHandler.GetAuditLog)...)
 	}
 
 	return router
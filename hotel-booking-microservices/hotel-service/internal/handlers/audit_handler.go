@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAuditLog lista las operaciones de administración registradas (Solo Admin)
+func (h *HotelHandler) GetAuditLog(c *gin.Context) {
+	limit, err := strconv.ParseInt(c.DefaultQuery("limit", "100"), 10, 64)
+	if err != nil || limit < 1 || limit > 1000 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "limit debe ser un número entre 1 y 1000",
+		})
+		return
+	}
+
+	entries, err := h.hotelService.GetAuditLog(c.Query("hotel_id"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error obteniendo auditoría",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  entries,
+		"count": len(entries),
+	})
+}
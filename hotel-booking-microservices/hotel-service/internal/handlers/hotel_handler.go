@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -9,6 +10,8 @@ import (
 	"strings"
 	"time"
 
+	"hotel-service/internal/middleware"
+	"hotel-service/internal/models"
 	"hotel-service/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -87,17 +90,18 @@ func (h *HotelHandler) GetAllHotels(c *gin.Context) {
 // CreateHotel crea un nuevo hotel (Solo Admin)
 func (h *HotelHandler) CreateHotel(c *gin.Context) {
 	var hotelRequest struct {
-		Name        string                 `json:"name" validate:"required"`
-		Description string                 `json:"description" validate:"required"`
-		City        string                 `json:"city" validate:"required"`
-		Country     string                 `json:"country"`
-		Address     string                 `json:"address" validate:"required"`
-		Amenities   []string               `json:"amenities"`
-		Images      []string               `json:"images"`
-		Thumbnail   string                 `json:"thumbnail"`
-		Rating      float64                `json:"rating"`
-		PriceRange  map[string]interface{} `json:"price_range"`
-		Contact     map[string]interface{} `json:"contact"`
+		Name               string                     `json:"name" validate:"required"`
+		Description        string                     `json:"description" validate:"required"`
+		City               string                     `json:"city" validate:"required"`
+		Country            string                     `json:"country"`
+		Address            string                     `json:"address" validate:"required"`
+		Amenities          []string                   `json:"amenities"`
+		Images             []string                   `json:"images"`
+		Thumbnail          string                     `json:"thumbnail"`
+		Rating             float64                    `json:"rating"`
+		PriceRange         map[string]interface{}     `json:"price_range"`
+		Contact            map[string]interface{}     `json:"contact"`
+		CancellationPolicy *models.CancellationPolicy `json:"cancellation_policy"`
 	}
 
 	// Bind JSON
@@ -119,21 +123,22 @@ func (h *HotelHandler) CreateHotel(c *gin.Context) {
 
 	// Convertir a map[string]interface{} para compatibilidad con el service
 	requestMap := map[string]interface{}{
-		"name":        hotelRequest.Name,
-		"description": hotelRequest.Description,
-		"city":        hotelRequest.City,
-		"country":     hotelRequest.Country,
-		"address":     hotelRequest.Address,
-		"amenities":   hotelRequest.Amenities,
-		"images":      hotelRequest.Images,
-		"thumbnail":   hotelRequest.Thumbnail,
-		"rating":      hotelRequest.Rating,
-		"price_range": hotelRequest.PriceRange,
-		"contact":     hotelRequest.Contact,
+		"name":                hotelRequest.Name,
+		"description":         hotelRequest.Description,
+		"city":                hotelRequest.City,
+		"country":             hotelRequest.Country,
+		"address":             hotelRequest.Address,
+		"amenities":           hotelRequest.Amenities,
+		"images":              hotelRequest.Images,
+		"thumbnail":           hotelRequest.Thumbnail,
+		"rating":              hotelRequest.Rating,
+		"price_range":         hotelRequest.PriceRange,
+		"contact":             hotelRequest.Contact,
+		"cancellation_policy": hotelRequest.CancellationPolicy,
 	}
 
 	// Crear hotel usando el service
-	hotel, err := h.hotelService.CreateHotel(requestMap)
+	hotel, err := h.hotelService.CreateHotel(requestMap, middleware.GetActorFromContext(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Error creando hotel",
@@ -159,17 +164,18 @@ func (h *HotelHandler) UpdateHotel(c *gin.Context) {
 	}
 
 	var hotelRequest struct {
-		Name        string                 `json:"name"`
-		Description string                 `json:"description"`
-		City        string                 `json:"city"`
-		Country     string                 `json:"country"`
-		Address     string                 `json:"address"`
-		Amenities   []string               `json:"amenities"`
-		Images      []string               `json:"images"`
-		Thumbnail   string                 `json:"thumbnail"`
-		Rating      float64                `json:"rating"`
-		PriceRange  map[string]interface{} `json:"price_range"`
-		Contact     map[string]interface{} `json:"contact"`
+		Name               string                 `json:"name"`
+		Description        string                 `json:"description"`
+		City               string                 `json:"city"`
+		Country            string                 `json:"country"`
+		Address            string                 `json:"address"`
+		Amenities          []string               `json:"amenities"`
+		Images             []string               `json:"images"`
+		Thumbnail          string                 `json:"thumbnail"`
+		Rating             float64                `json:"rating"`
+		PriceRange         map[string]interface{} `json:"price_range"`
+		Contact            map[string]interface{} `json:"contact"`
+		CancellationPolicy nullablePolicy         `json:"cancellation_policy"`
 	}
 
 	if err := c.ShouldBindJSON(&hotelRequest); err != nil {
@@ -194,9 +200,13 @@ func (h *HotelHandler) UpdateHotel(c *gin.Context) {
 		"price_range": hotelRequest.PriceRange,
 		"contact":     hotelRequest.Contact,
 	}
+	// Sin la clave se conserva la política; con null se elimina
+	if hotelRequest.CancellationPolicy.Set {
+		requestMap["cancellation_policy"] = hotelRequest.CancellationPolicy.Policy
+	}
 
 	// Actualizar hotel usando el service
-	hotel, err := h.hotelService.UpdateHotel(hotelID, requestMap)
+	hotel, err := h.hotelService.UpdateHotel(hotelID, requestMap, middleware.GetActorFromContext(c))
 	if err != nil {
 		if strings.Contains(err.Error(), "no encontrado") {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -229,7 +239,7 @@ func (h *HotelHandler) DeleteHotel(c *gin.Context) {
 	}
 
 	// Eliminar hotel (soft delete)
-	err := h.hotelService.DeleteHotel(hotelID)
+	err := h.hotelService.DeleteHotel(hotelID, middleware.GetActorFromContext(c))
 	if err != nil {
 		if strings.Contains(err.Error(), "no encontrado") {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -353,6 +363,8 @@ func (h *HotelHandler) UploadHotelImages(c *gin.Context) {
 		})
 	}
 
+	h.hotelService.RecordAudit("images.uploaded", "", "", middleware.GetActorFromContext(c), uploadedFiles)
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Archivos subidos exitosamente",
 		"files":   uploadedFiles,
@@ -420,6 +432,8 @@ func (h *HotelHandler) UploadSingleImage(c *gin.Context) {
 	// URL de acceso al archivo
 	fileURL := fmt.Sprintf("/uploads/hotels/%s", fileName)
 
+	h.hotelService.RecordAudit("image.uploaded", "", fileName, middleware.GetActorFromContext(c), gin.H{"url": fileURL})
+
 	c.JSON(http.StatusOK, gin.H{
 		"message":  "Archivo subido exitosamente",
 		"filename": fileName,
@@ -444,6 +458,23 @@ func (h *HotelHandler) ServeUploadedFile(c *gin.Context) {
 	c.File(filePath)
 }
 
+// nullablePolicy distingue una política ausente del JSON de una enviada como
+// null, que en una actualización significa eliminarla
+type nullablePolicy struct {
+	Set    bool
+	Policy *models.CancellationPolicy
+}
+
+// UnmarshalJSON solo se llama si la clave está presente
+func (p *nullablePolicy) UnmarshalJSON(data []byte) error {
+	p.Set = true
+	if string(data) == "null" {
+		p.Policy = nil
+		return nil
+	}
+	return json.Unmarshal(data, &p.Policy)
+}
+
 // isValidImageType valida que el archivo sea una imagen válida
 func isValidImageType(filename string) bool {
 	ext := strings.ToLower(filepath.Ext(filename))
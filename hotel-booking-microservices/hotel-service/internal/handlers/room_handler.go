@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"hotel-service/internal/middleware"
+	"hotel-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRoomTypes obtiene los tipos de habitación de un hotel
+func (h *HotelHandler) GetRoomTypes(c *gin.Context) {
+	rooms, err := h.hotelService.GetRoomTypes(c.Param("id"))
+	if err != nil {
+		h.respondRoomError(c, "Error obteniendo tipos de habitación", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": rooms,
+	})
+}
+
+// CreateRoomType agrega un tipo de habitación a un hotel (Solo Admin)
+func (h *HotelHandler) CreateRoomType(c *gin.Context) {
+	var req models.RoomTypeRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	room, err := h.hotelService.CreateRoomType(c.Param("id"), &req, middleware.GetActorFromContext(c))
+	if err != nil {
+		h.respondRoomError(c, "Error creando tipo de habitación", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Tipo de habitación creado exitosamente",
+		"data":    room,
+	})
+}
+
+// UpdateRoomType actualiza un tipo de habitación (Solo Admin)
+func (h *HotelHandler) UpdateRoomType(c *gin.Context) {
+	var req models.RoomTypeRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Datos inválidos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	room, err := h.hotelService.UpdateRoomType(c.Param("id"), c.Param("roomId"), &req, middleware.GetActorFromContext(c))
+	if err != nil {
+		h.respondRoomError(c, "Error actualizando tipo de habitación", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tipo de habitación actualizado exitosamente",
+		"data":    room,
+	})
+}
+
+// DeleteRoomType elimina un tipo de habitación (Solo Admin)
+func (h *HotelHandler) DeleteRoomType(c *gin.Context) {
+	if err := h.hotelService.DeleteRoomType(c.Param("id"), c.Param("roomId"), middleware.GetActorFromContext(c)); err != nil {
+		h.respondRoomError(c, "Error eliminando tipo de habitación", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tipo de habitación eliminado exitosamente",
+	})
+}
+
+// respondRoomError traduce errores del servicio de habitaciones a códigos HTTP
+func (h *HotelHandler) respondRoomError(c *gin.Context, message string, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "inválido"):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": msg,
+		})
+	case strings.Contains(msg, "no encontrado"):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": msg,
+		})
+	case strings.Contains(msg, "ya existe"):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": msg,
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": msg,
+		})
+	}
+}
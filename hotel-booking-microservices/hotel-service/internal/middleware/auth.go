@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"hotel-service/internal/models"
+	"hotel-service/pkg/bookingservice"
+)
+
+// TokenVerifier confirma con booking-service que un token no fue revocado
+type TokenVerifier interface {
+	VerifyToken(ctx context.Context, token string) error
+}
+
+// AuthMiddleware verifica el JWT emitido por booking-service con el secreto
+// compartido. Como la versión de tokens (tv) del usuario solo la conoce
+// booking-service, cada token se confirma también con verifier.
+func AuthMiddleware(jwtSecret string, verifier TokenVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Token de autorización requerido",
+			})
+			c.Abort()
+			return
+		}
+
+		// Verificar formato Bearer
+		parts := strings.Split(authHeader, " ")
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Formato de token inválido",
+			})
+			c.Abort()
+			return
+		}
+
+		actor, err := parseToken(parts[1], jwtSecret)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Token inválido",
+			})
+			c.Abort()
+			return
+		}
+
+		// Un token revocado (cambio de rol, baja, robo detectado) sigue bien firmado
+		if err := verifier.VerifyToken(c.Request.Context(), parts[1]); err != nil {
+			if errors.Is(err, bookingservice.ErrTokenRevoked) {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error": "Token inválido",
+				})
+				c.Abort()
+				return
+			}
+			log.Printf("⚠️ No se pudo verificar el token del usuario %d: %v", actor.UserID, err)
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error": "No se pudo verificar el token",
+			})
+			c.Abort()
+			return
+		}
+
+		// Guardar datos en contexto
+		c.Set("userID", actor.UserID)
+		c.Set("userRole", actor.Role)
+		c.Set("userEmail", actor.Email)
+		c.Next()
+	}
+}
+
+// AdminMiddleware verifica que el usuario sea admin
+func AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, exists := c.Get("userRole")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Token requerido",
+			})
+			c.Abort()
+			return
+		}
+
+		if role != "admin" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Acceso denegado: se requieren permisos de administrador",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// GetActorFromContext obtiene el usuario autenticado que realiza la operación
+func GetActorFromContext(c *gin.Context) models.Actor {
+	var actor models.Actor
+	if userID, ok := c.Get("userID"); ok {
+		actor.UserID, _ = userID.(int)
+	}
+	if email, ok := c.Get("userEmail"); ok {
+		actor.Email, _ = email.(string)
+	}
+	if role, ok := c.Get("userRole"); ok {
+		actor.Role, _ = role.(string)
+	}
+	return actor
+}
+
+// parseToken valida la firma del token y extrae los datos del usuario
+func parseToken(tokenString, jwtSecret string) (*models.Actor, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// Verificar método de firma
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("método de firma inesperado: %v", token.Header["alg"])
+		}
+		return []byte(jwtSecret), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error parseando token: %v", err)
+	}
+
+	if !token.Valid {
+		return nil, fmt.Errorf("token inválido")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("claims inválidos")
+	}
+
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("user_id inválido en token")
+	}
+
+	// Sin versión de tokens no hay forma de saber si fue revocado
+	if _, ok := claims["tv"].(float64); !ok {
+		return nil, fmt.Errorf("versión de token ausente")
+	}
+
+	// Los tokens anteriores no incluyen rol ni email: quedan sin permisos de admin
+	role, _ := claims["role"].(string)
+	email, _ := claims["email"].(string)
+
+	return &models.Actor{UserID: int(userID), Email: email, Role: role}, nil
+}
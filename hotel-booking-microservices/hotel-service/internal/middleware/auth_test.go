@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"hotel-service/pkg/bookingservice"
+)
+
+const testSecret = "test-secret"
+
+// fakeVerifier responde la verificación de tokens en lugar de booking-service
+type fakeVerifier struct {
+	err   error
+	calls int
+}
+
+func (v *fakeVerifier) VerifyToken(ctx context.Context, token string) error {
+	v.calls++
+	return v.err
+}
+
+// signToken firma un token como lo hace booking-service
+func signToken(t *testing.T, secret string, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("error firmando token: %v", err)
+	}
+	return token
+}
+
+// tokenClaims claims de un token vigente con el rol indicado
+func tokenClaims(role string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"user_id": 7,
+		"role":    role,
+		"email":   "admin@test.com",
+		"tv":      0,
+		"exp":     time.Now().Add(time.Minute).Unix(),
+		"iat":     time.Now().Unix(),
+	}
+}
+
+// newAdminRouter ruta de administración protegida como las de hotel-service,
+// que responde el usuario guardado en el contexto
+func newAdminRouter(verifier TokenVerifier) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/admin", AuthMiddleware(testSecret, verifier), AdminMiddleware(), func(c *gin.Context) {
+		actor := GetActorFromContext(c)
+		c.JSON(http.StatusOK, gin.H{"user_id": actor.UserID, "email": actor.Email, "role": actor.Role})
+	})
+	return router
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	expired := tokenClaims("admin")
+	expired["exp"] = time.Now().Add(-time.Minute).Unix()
+	withoutVersion := tokenClaims("admin")
+	delete(withoutVersion, "tv")
+
+	tests := []struct {
+		name        string
+		header      string
+		verifierErr error
+		wantStatus  int
+		wantVerify  bool // si debe consultarse a booking-service
+	}{
+		{name: "sin token", header: "", wantStatus: http.StatusUnauthorized},
+		{name: "formato inválido", header: "Token " + signToken(t, testSecret, tokenClaims("admin")), wantStatus: http.StatusUnauthorized},
+		{name: "expirado", header: "Bearer " + signToken(t, testSecret, expired), wantStatus: http.StatusUnauthorized},
+		{name: "otra clave", header: "Bearer " + signToken(t, "otra-clave", tokenClaims("admin")), wantStatus: http.StatusUnauthorized},
+		{name: "sin versión de token", header: "Bearer " + signToken(t, testSecret, withoutVersion), wantStatus: http.StatusUnauthorized},
+		{name: "revocado", header: "Bearer " + signToken(t, testSecret, tokenClaims("admin")), verifierErr: bookingservice.ErrTokenRevoked, wantStatus: http.StatusUnauthorized, wantVerify: true},
+		{name: "booking-service no responde", header: "Bearer " + signToken(t, testSecret, tokenClaims("admin")), verifierErr: errors.New("timeout"), wantStatus: http.StatusServiceUnavailable, wantVerify: true},
+		{name: "rol user", header: "Bearer " + signToken(t, testSecret, tokenClaims("user")), wantStatus: http.StatusForbidden, wantVerify: true},
+		{name: "sin rol", header: "Bearer " + signToken(t, testSecret, tokenClaims("")), wantStatus: http.StatusForbidden, wantVerify: true},
+		{name: "admin vigente", header: "Bearer " + signToken(t, testSecret, tokenClaims("admin")), wantStatus: http.StatusOK, wantVerify: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := &fakeVerifier{err: tt.verifierErr}
+			router := newAdminRouter(verifier)
+
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, se esperaba %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if (verifier.calls > 0) != tt.wantVerify {
+				t.Fatalf("consultas a booking-service = %d, se esperaba consulta = %v", verifier.calls, tt.wantVerify)
+			}
+		})
+	}
+}
+
+func TestAuthMiddlewareSetsActor(t *testing.T) {
+	router := newAdminRouter(&fakeVerifier{})
+
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.Header.Set("Authorization", "Bearer "+signToken(t, testSecret, tokenClaims("admin")))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	want := `{"email":"admin@test.com","role":"admin","user_id":7}`
+	if w.Code != http.StatusOK || w.Body.String() != want {
+		t.Fatalf("respuesta = %d %s, se esperaba 200 %s", w.Code, w.Body.String(), want)
+	}
+}
@@ -18,8 +18,13 @@ type Hotel struct {
 	Rating      float64           `json:"rating" bson:"rating"`
 	PriceRange  PriceRange        `json:"price_range" bson:"price_range"`
 	Contact     Contact           `json:"contact" bson:"contact"`
+	RoomTypes   []RoomType        `json:"room_types" bson:"room_types,omitempty"`
+	CancellationPolicy *CancellationPolicy `json:"cancellation_policy,omitempty" bson:"cancellation_policy,omitempty"`
 	CreatedAt   time.Time         `json:"created_at" bson:"created_at"`
 	UpdatedAt   time.Time         `json:"updated_at" bson:"updated_at"`
+	CreatedBy   *Actor            `json:"created_by,omitempty" bson:"created_by,omitempty"`
+	UpdatedBy   *Actor            `json:"updated_by,omitempty" bson:"updated_by,omitempty"`
+	DeletedBy   *Actor            `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
 	IsActive    bool              `json:"is_active" bson:"is_active"`
 }
 
@@ -30,6 +35,50 @@ type PriceRange struct {
 	Currency string `json:"currency" bson:"currency"`
 }
 
+// RoomType representa un tipo de habitación ofrecido por un hotel
+type RoomType struct {
+	ID               primitive.ObjectID `json:"id" bson:"_id"`
+	Name             string             `json:"name" bson:"name"`
+	Description      string             `json:"description" bson:"description"`
+	Capacity         int                `json:"capacity" bson:"capacity"`
+	BedConfiguration string             `json:"bed_configuration" bson:"bed_configuration"`
+	BaseRate         float64            `json:"base_rate" bson:"base_rate"`
+	Currency         string             `json:"currency" bson:"currency"`
+	Photos           []string           `json:"photos" bson:"photos"`
+	Count            int                `json:"count" bson:"count"`
+	// Política de la tarifa; sin ella rige la del hotel
+	CancellationPolicy *CancellationPolicy `json:"cancellation_policy,omitempty" bson:"cancellation_policy,omitempty"`
+	CreatedAt        time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// CancellationPolicy política de cancelación de un hotel o de una tarifa.
+// Cancelar hasta FreeUntilDays días antes del check-in no tiene cargo; después
+// se cobra PenaltyPercent del precio. Una tarifa no reembolsable cobra el total.
+type CancellationPolicy struct {
+	NonRefundable  bool    `json:"non_refundable" bson:"non_refundable"`
+	FreeUntilDays  int     `json:"free_until_days" bson:"free_until_days" binding:"min=0,max=365"`
+	PenaltyPercent float64 `json:"penalty_percent" bson:"penalty_percent" binding:"min=0,max=100"`
+}
+
+// Actor identifica al usuario autenticado que realiza una operación
+type Actor struct {
+	UserID int    `json:"user_id" bson:"user_id"`
+	Email  string `json:"email" bson:"email"`
+	Role   string `json:"role" bson:"role"`
+}
+
+// AuditEntry registra una operación de administración sobre hoteles
+type AuditEntry struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Action    string             `json:"action" bson:"action"`
+	HotelID   string             `json:"hotel_id,omitempty" bson:"hotel_id,omitempty"`
+	EntityID  string             `json:"entity_id,omitempty" bson:"entity_id,omitempty"`
+	Actor     Actor              `json:"actor" bson:"actor"`
+	Details   interface{}        `json:"details,omitempty" bson:"details,omitempty"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
 // Contact representa la información de contacto
 type Contact struct {
 	Phone   string `json:"phone" bson:"phone"`
@@ -48,6 +97,7 @@ type CreateHotelRequest struct {
 	PriceRange  PriceRange `json:"price_range" binding:"required"`
 	Contact     Contact    `json:"contact" binding:"required"`
 	Thumbnail   string     `json:"thumbnail"` // URL de la imagen subida
+	CancellationPolicy *CancellationPolicy `json:"cancellation_policy"`
 }
 
 // UpdateHotelRequest representa la solicitud para actualizar un hotel
@@ -61,6 +111,20 @@ type UpdateHotelRequest struct {
 	PriceRange  *PriceRange `json:"price_range,omitempty"`
 	Contact     *Contact   `json:"contact,omitempty"`
 	Thumbnail   string     `json:"thumbnail,omitempty"` // URL de la imagen
+	CancellationPolicy *CancellationPolicy `json:"cancellation_policy,omitempty"`
+}
+
+// RoomTypeRequest representa la solicitud para crear o actualizar un tipo de habitación
+type RoomTypeRequest struct {
+	Name             string   `json:"name" binding:"required,min=2,max=100"`
+	Description      string   `json:"description" binding:"max=1000"`
+	Capacity         int      `json:"capacity" binding:"required,min=1,max=20"`
+	BedConfiguration string   `json:"bed_configuration" binding:"max=100"`
+	BaseRate         float64  `json:"base_rate" binding:"min=0"`
+	Currency         string   `json:"currency" binding:"omitempty,len=3"`
+	Photos           []string `json:"photos"`
+	Count            int      `json:"count" binding:"min=0,max=10000"`
+	CancellationPolicy *CancellationPolicy `json:"cancellation_policy"`
 }
 
 // SearchHotelRequest representa los parámetros de búsqueda
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"hotel-service/internal/models"
+)
+
+// RecordAudit registra en audit_log qué administrador realizó una operación.
+// Un fallo al auditar se loguea pero no revierte la operación.
+func (s *HotelService) RecordAudit(action, hotelID, entityID string, actor models.Actor, details interface{}) {
+	entry := models.AuditEntry{
+		Action:    action,
+		HotelID:   hotelID,
+		EntityID:  entityID,
+		Actor:     actor,
+		Details:   details,
+		CreatedAt: time.Now(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := s.auditLog.InsertOne(ctx, entry); err != nil {
+		fmt.Printf("Error registrando auditoría %s: %v\n", action, err)
+	}
+}
+
+// GetAuditLog obtiene las últimas entradas de auditoría, opcionalmente de un hotel
+func (s *HotelService) GetAuditLog(hotelID string, limit int64) ([]*models.AuditEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if hotelID != "" {
+		filter["hotel_id"] = hotelID
+	}
+
+	findOptions := options.Find().SetSort(bson.M{"created_at": -1}).SetLimit(limit)
+	cursor, err := s.auditLog.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo auditoría: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	entries := []*models.AuditEntry{}
+	for cursor.Next(ctx) {
+		var entry models.AuditEntry
+		if err := cursor.Decode(&entry); err != nil {
+			return nil, fmt.Errorf("error decodificando auditoría: %v", err)
+		}
+		entries = append(entries, &entry)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("error en cursor: %v", err)
+	}
+
+	return entries, nil
+}
@@ -17,6 +17,7 @@ import (
 // HotelService maneja la lógica de negocio de hoteles
 type HotelService struct {
 	collection  *mongo.Collection
+	auditLog    *mongo.Collection
 	rabbit      *rabbitmq.Connection
 	mongoClient *mongo.Client  // ✅ AGREGADO para healthcheck
 }
@@ -24,9 +25,11 @@ type HotelService struct {
 // NewHotelService crea una nueva instancia del servicio
 func NewHotelService(mongoClient *mongo.Client, rabbitConn *rabbitmq.Connection) *HotelService {
 	collection := mongodb.GetCollection(mongoClient, "hotels_db", "hotels")
+	auditLog := mongodb.GetCollection(mongoClient, "hotels_db", "audit_log")
 	
 	return &HotelService{
 		collection:  collection,
+		auditLog:    auditLog,
 		rabbit:      rabbitConn,
 		mongoClient: mongoClient,  // ✅ AGREGADO
 	}
@@ -73,12 +76,14 @@ func (s *HotelService) GetHotelByID(id string) (*models.Hotel, error) {
 }
 
 // CreateHotel crea un nuevo hotel - VERSIÓN COMPATIBLE CON HANDLER
-func (s *HotelService) CreateHotel(req interface{}) (*models.Hotel, error) {
+func (s *HotelService) CreateHotel(req interface{}, actor models.Actor) (*models.Hotel, error) {
 	// Crear el hotel base
 	hotel := &models.Hotel{
 		ID:        primitive.NewObjectID(),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
+		CreatedBy: &actor,
+		UpdatedBy: &actor,
 		IsActive:  true,
 	}
 
@@ -127,6 +132,9 @@ func (s *HotelService) CreateHotel(req interface{}) (*models.Hotel, error) {
 				hotel.PriceRange.Currency = currency
 			}
 		}
+		if policy, exists := reqMap["cancellation_policy"].(*models.CancellationPolicy); exists {
+			hotel.CancellationPolicy = normalizeCancellationPolicy(policy)
+		}
 		if contact, exists := reqMap["contact"].(map[string]interface{}); exists {
 			if phone, ok := contact["phone"].(string); ok {
 				hotel.Contact.Phone = phone
@@ -150,6 +158,8 @@ func (s *HotelService) CreateHotel(req interface{}) (*models.Hotel, error) {
 		return nil, fmt.Errorf("error creando hotel: %v", err)
 	}
 
+	s.RecordAudit("hotel.created", hotel.ID.Hex(), "", actor, req)
+
 	// Publicar evento en RabbitMQ (simplificado para evitar errores)
 	err = s.publishSimpleEvent("hotel.created", hotel.ID.Hex())
 	if err != nil {
@@ -161,7 +171,7 @@ func (s *HotelService) CreateHotel(req interface{}) (*models.Hotel, error) {
 }
 
 // UpdateHotel actualiza un hotel existente - VERSIÓN COMPATIBLE CON HANDLER
-func (s *HotelService) UpdateHotel(id string, req interface{}) (*models.Hotel, error) {
+func (s *HotelService) UpdateHotel(id string, req interface{}, actor models.Actor) (*models.Hotel, error) {
 	// Convertir string a ObjectID
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -176,6 +186,7 @@ func (s *HotelService) UpdateHotel(id string, req interface{}) (*models.Hotel, e
 	updateDoc := bson.M{
 		"$set": bson.M{
 			"updated_at": time.Now(),
+			"updated_by": actor,
 		},
 	}
 
@@ -232,6 +243,14 @@ func (s *HotelService) UpdateHotel(id string, req interface{}) (*models.Hotel, e
 			}
 			setFields["price_range"] = priceRangeDoc
 		}
+		if value, exists := reqMap["cancellation_policy"]; exists {
+			if policy, _ := value.(*models.CancellationPolicy); policy != nil {
+				setFields["cancellation_policy"] = normalizeCancellationPolicy(policy)
+			} else {
+				// null explícito: el hotel vuelve a la regla general del servicio de reservas
+				updateDoc["$unset"] = bson.M{"cancellation_policy": ""}
+			}
+		}
 		if contact, exists := reqMap["contact"].(map[string]interface{}); exists {
 			contactDoc := bson.M{}
 			if phone, ok := contact["phone"].(string); ok {
@@ -248,7 +267,7 @@ func (s *HotelService) UpdateHotel(id string, req interface{}) (*models.Hotel, e
 	}
 
 	// Actualizar en MongoDB
-	_, err = s.collection.UpdateOne(
+	result, err := s.collection.UpdateOne(
 		ctx,
 		bson.M{"_id": objectID},
 		updateDoc,
@@ -257,6 +276,20 @@ func (s *HotelService) UpdateHotel(id string, req interface{}) (*models.Hotel, e
 		return nil, fmt.Errorf("error actualizando hotel: %v", err)
 	}
 
+	if result.MatchedCount == 0 {
+		return nil, fmt.Errorf("hotel no encontrado")
+	}
+
+	// La auditoría registra la política eliminada como null
+	changes := setFields
+	if _, unset := updateDoc["$unset"]; unset {
+		changes = bson.M{"cancellation_policy": nil}
+		for field, value := range setFields {
+			changes[field] = value
+		}
+	}
+	s.RecordAudit("hotel.updated", id, "", actor, changes)
+
 	// Obtener hotel actualizado
 	updatedHotel, err := s.GetHotelByID(id)
 	if err != nil {
@@ -274,7 +307,7 @@ func (s *HotelService) UpdateHotel(id string, req interface{}) (*models.Hotel, e
 }
 
 // DeleteHotel elimina un hotel (soft delete) - NUEVO MÉTODO
-func (s *HotelService) DeleteHotel(id string) error {
+func (s *HotelService) DeleteHotel(id string, actor models.Actor) error {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return fmt.Errorf("ID de hotel inválido: %v", err)
@@ -288,6 +321,7 @@ func (s *HotelService) DeleteHotel(id string) error {
 		"$set": bson.M{
 			"is_active":  false,
 			"updated_at": time.Now(),
+			"deleted_by": actor,
 		},
 	}
 
@@ -300,6 +334,8 @@ func (s *HotelService) DeleteHotel(id string) error {
 		return fmt.Errorf("hotel no encontrado")
 	}
 
+	s.RecordAudit("hotel.deleted", id, "", actor, nil)
+
 	// Publicar evento
 	err = s.publishSimpleEvent("hotel.deleted", id)
 	if err != nil {
@@ -337,6 +373,21 @@ func (s *HotelService) GetAllHotels() ([]*models.Hotel, error) {
 	return hotels, nil
 }
 
+// normalizeCancellationPolicy deja la política en su forma canónica: una
+// tarifa no reembolsable no tiene período gratuito y cobra el total
+func normalizeCancellationPolicy(policy *models.CancellationPolicy) *models.CancellationPolicy {
+	if policy == nil {
+		return nil
+	}
+
+	normalized := *policy
+	if normalized.NonRefundable {
+		normalized.FreeUntilDays = 0
+		normalized.PenaltyPercent = 100
+	}
+	return &normalized
+}
+
 // publishSimpleEvent publica un evento simple en RabbitMQ
 func (s *HotelService) publishSimpleEvent(eventType, hotelID string) error {
 	if s.rabbit == nil {
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hotel-service/internal/models"
+)
+
+// GetRoomTypes obtiene los tipos de habitación de un hotel
+func (s *HotelService) GetRoomTypes(hotelID string) ([]models.RoomType, error) {
+	hotel, err := s.GetHotelByID(hotelID)
+	if err != nil {
+		return nil, err
+	}
+
+	if hotel.RoomTypes == nil {
+		return []models.RoomType{}, nil
+	}
+	return hotel.RoomTypes, nil
+}
+
+// CreateRoomType agrega un tipo de habitación a un hotel
+func (s *HotelService) CreateRoomType(hotelID string, req *models.RoomTypeRequest, actor models.Actor) (*models.RoomType, error) {
+	hotel, err := s.GetHotelByID(hotelID)
+	if err != nil {
+		return nil, err
+	}
+
+	room := models.RoomType{
+		ID:        primitive.NewObjectID(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	applyRoomTypeRequest(&room, req)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Los nombres se usan como referencia desde booking-service, deben ser
+	// únicos. El filtro descarta el hotel si ya tiene uno con el mismo nombre
+	// (sin distinguir mayúsculas), así dos altas simultáneas no lo duplican.
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{
+			"_id":             hotel.ID,
+			"room_types.name": bson.M{"$not": roomNamePattern(room.Name)},
+		},
+		bson.M{
+			"$push": bson.M{"room_types": room},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creando tipo de habitación: %v", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return nil, fmt.Errorf("el tipo de habitación %s ya existe en el hotel", room.Name)
+	}
+
+	s.RecordAudit("room.created", hotelID, room.ID.Hex(), actor, req)
+
+	if err := s.publishRoomEvent("room.created", hotelID, &room); err != nil {
+		fmt.Printf("Error publicando evento: %v\n", err)
+	}
+
+	return &room, nil
+}
+
+// UpdateRoomType actualiza un tipo de habitación existente
+func (s *HotelService) UpdateRoomType(hotelID, roomID string, req *models.RoomTypeRequest, actor models.Actor) (*models.RoomType, error) {
+	hotel, err := s.GetHotelByID(hotelID)
+	if err != nil {
+		return nil, err
+	}
+
+	roomObjectID, err := primitive.ObjectIDFromHex(roomID)
+	if err != nil {
+		return nil, fmt.Errorf("ID de tipo de habitación inválido: %v", err)
+	}
+
+	var room *models.RoomType
+	for i := range hotel.RoomTypes {
+		existing := &hotel.RoomTypes[i]
+		if existing.ID == roomObjectID {
+			room = existing
+			continue
+		}
+		if strings.EqualFold(existing.Name, req.Name) {
+			return nil, fmt.Errorf("el tipo de habitación %s ya existe en el hotel", req.Name)
+		}
+	}
+
+	if room == nil {
+		return nil, fmt.Errorf("tipo de habitación no encontrado")
+	}
+
+	applyRoomTypeRequest(room, req)
+	room.UpdatedAt = time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = s.collection.UpdateOne(ctx,
+		bson.M{"_id": hotel.ID, "room_types._id": roomObjectID},
+		bson.M{"$set": bson.M{
+			"room_types.$": room,
+			"updated_at":   time.Now(),
+		}},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error actualizando tipo de habitación: %v", err)
+	}
+
+	s.RecordAudit("room.updated", hotelID, roomID, actor, req)
+
+	if err := s.publishRoomEvent("room.updated", hotelID, room); err != nil {
+		fmt.Printf("Error publicando evento: %v\n", err)
+	}
+
+	return room, nil
+}
+
+// DeleteRoomType elimina un tipo de habitación de un hotel
+func (s *HotelService) DeleteRoomType(hotelID, roomID string, actor models.Actor) error {
+	objectID, err := primitive.ObjectIDFromHex(hotelID)
+	if err != nil {
+		return fmt.Errorf("ID de hotel inválido: %v", err)
+	}
+
+	roomObjectID, err := primitive.ObjectIDFromHex(roomID)
+	if err != nil {
+		return fmt.Errorf("ID de tipo de habitación inválido: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "room_types._id": roomObjectID},
+		bson.M{
+			"$pull": bson.M{"room_types": bson.M{"_id": roomObjectID}},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("error eliminando tipo de habitación: %v", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("tipo de habitación no encontrado")
+	}
+
+	s.RecordAudit("room.deleted", hotelID, roomID, actor, nil)
+
+	if err := s.publishRoomEvent("room.deleted", hotelID, &models.RoomType{ID: roomObjectID}); err != nil {
+		fmt.Printf("Error publicando evento: %v\n", err)
+	}
+
+	return nil
+}
+
+// roomNamePattern expresión que coincide con el nombre exacto sin distinguir mayúsculas
+func roomNamePattern(name string) primitive.Regex {
+	return primitive.Regex{Pattern: "^" + regexp.QuoteMeta(name) + "$", Options: "i"}
+}
+
+// applyRoomTypeRequest copia los campos de la request al tipo de habitación
+func applyRoomTypeRequest(room *models.RoomType, req *models.RoomTypeRequest) {
+	room.Name = strings.TrimSpace(req.Name)
+	room.Description = req.Description
+	room.Capacity = req.Capacity
+	room.BedConfiguration = req.BedConfiguration
+	room.BaseRate = req.BaseRate
+	room.Currency = strings.ToUpper(req.Currency)
+	room.Photos = req.Photos
+	room.Count = req.Count
+	room.CancellationPolicy = normalizeCancellationPolicy(req.CancellationPolicy)
+
+	if room.Currency == "" {
+		room.Currency = "ARS"
+	}
+	if room.Photos == nil {
+		room.Photos = []string{}
+	}
+}
+
+// publishRoomEvent publica un evento de tipo de habitación en RabbitMQ
+func (s *HotelService) publishRoomEvent(eventType, hotelID string, room *models.RoomType) error {
+	if s.rabbit == nil {
+		return fmt.Errorf("conexión a RabbitMQ no disponible")
+	}
+
+	event := map[string]interface{}{
+		"type":      eventType,
+		"hotel_id":  hotelID,
+		"room_id":   room.ID.Hex(),
+		"room_type": room,
+		"timestamp": time.Now(),
+	}
+
+	return s.rabbit.PublishEvent(eventType, event)
+}
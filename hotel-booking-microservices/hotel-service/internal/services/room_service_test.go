@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"hotel-service/internal/models"
+	"hotel-service/pkg/mongodb"
+)
+
+// newTestService crea un servicio sobre una base vacía que se borra al
+// terminar el test. TEST_MONGO_URI tiene el formato de MONGO_URI (por ejemplo
+// mongodb://localhost:27017). Sin esa variable el test se omite.
+func newTestService(t *testing.T) *HotelService {
+	t.Helper()
+
+	uri := os.Getenv("TEST_MONGO_URI")
+	if uri == "" {
+		t.Skip("TEST_MONGO_URI no configurada")
+	}
+
+	client, err := mongodb.Connect(uri)
+	if err != nil {
+		t.Fatalf("error conectando a MongoDB: %v", err)
+	}
+
+	name := fmt.Sprintf("hotels_test_%d", time.Now().UnixNano())
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		client.Database(name).Drop(ctx)
+		mongodb.Disconnect(client)
+	})
+
+	return &HotelService{
+		collection:  mongodb.GetCollection(client, name, "hotels"),
+		auditLog:    mongodb.GetCollection(client, name, "audit_log"),
+		mongoClient: client,
+	}
+}
+
+// insertHotel carga un hotel sin tipos de habitación y devuelve su id
+func insertHotel(t *testing.T, s *HotelService) string {
+	t.Helper()
+
+	hotel := models.Hotel{
+		ID:        primitive.NewObjectID(),
+		Name:      "Hotel de prueba",
+		City:      "Córdoba",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		IsActive:  true,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := s.collection.InsertOne(ctx, hotel); err != nil {
+		t.Fatalf("error cargando hotel: %v", err)
+	}
+	return hotel.ID.Hex()
+}
+
+func TestCreateRoomTypeRejectsDuplicateName(t *testing.T) {
+	s := newTestService(t)
+	hotelID := insertHotel(t, s)
+
+	room, err := s.CreateRoomType(hotelID, &models.RoomTypeRequest{Name: "Suite", Capacity: 2, BaseRate: 100}, models.Actor{})
+	if err != nil {
+		t.Fatalf("error creando tipo de habitación: %v", err)
+	}
+	if room.Name != "Suite" || room.Currency != "ARS" {
+		t.Fatalf("tipo = %s moneda = %s, se esperaba Suite en ARS", room.Name, room.Currency)
+	}
+
+	// El nombre se compara sin distinguir mayúsculas ni espacios alrededor
+	for _, name := range []string{"Suite", "suite", "  SUITE "} {
+		_, err := s.CreateRoomType(hotelID, &models.RoomTypeRequest{Name: name, Capacity: 2}, models.Actor{})
+		if err == nil || !strings.Contains(err.Error(), "ya existe") {
+			t.Fatalf("%q: error = %v, se esperaba nombre duplicado", name, err)
+		}
+	}
+
+	// Los caracteres especiales del nombre no se interpretan como expresión
+	if _, err := s.CreateRoomType(hotelID, &models.RoomTypeRequest{Name: "Suit.", Capacity: 2}, models.Actor{}); err != nil {
+		t.Fatalf("error creando Suit.: %v", err)
+	}
+
+	rooms, err := s.GetRoomTypes(hotelID)
+	if err != nil {
+		t.Fatalf("error obteniendo tipos de habitación: %v", err)
+	}
+	if len(rooms) != 2 {
+		t.Fatalf("tipos de habitación = %d, se esperaban 2", len(rooms))
+	}
+}
+
+func TestCreateRoomTypeConcurrently(t *testing.T) {
+	s := newTestService(t)
+	hotelID := insertHotel(t, s)
+
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.CreateRoomType(hotelID, &models.RoomTypeRequest{Name: "Doble", Capacity: 2}, models.Actor{})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case !strings.Contains(err.Error(), "ya existe"):
+			t.Fatalf("error inesperado: %v", err)
+		}
+	}
+	if created != 1 {
+		t.Fatalf("altas exitosas = %d, se esperaba una sola", created)
+	}
+
+	rooms, err := s.GetRoomTypes(hotelID)
+	if err != nil {
+		t.Fatalf("error obteniendo tipos de habitación: %v", err)
+	}
+	if len(rooms) != 1 {
+		t.Fatalf("tipos de habitación = %d, el nombre quedó duplicado", len(rooms))
+	}
+}
+
+func TestCreateRoomTypeUnknownHotel(t *testing.T) {
+	s := newTestService(t)
+
+	_, err := s.CreateRoomType(primitive.NewObjectID().Hex(), &models.RoomTypeRequest{Name: "Suite", Capacity: 2}, models.Actor{})
+	if err == nil || !strings.Contains(err.Error(), "no encontrado") {
+		t.Fatalf("error = %v, se esperaba hotel no encontrado", err)
+	}
+}
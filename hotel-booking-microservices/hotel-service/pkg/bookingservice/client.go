@@ -0,0 +1,57 @@
+package bookingservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ErrTokenRevoked booking-service rechazó el token: expiró, fue revocado o el
+// usuario ya no está activo
+var ErrTokenRevoked = errors.New("token revocado")
+
+// Client cliente HTTP para booking-service
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient crea un nuevo cliente de booking-service
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout: 5 * time.Second,
+		},
+	}
+}
+
+// VerifyToken confirma con booking-service que un access token sigue vigente.
+// La firma se valida localmente; la versión de tokens del usuario solo la
+// conoce booking-service.
+func (c *Client) VerifyToken(ctx context.Context, token string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/auth/verify", nil)
+	if err != nil {
+		return fmt.Errorf("error creando petición: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error consultando booking-service: %v", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized:
+		return ErrTokenRevoked
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("booking-service respondió %d - %s", resp.StatusCode, string(body))
+	}
+}
@@ -6,10 +6,17 @@ echo "=========================================="
 # URL del hotel service
 HOTEL_SERVICE_URL="http://localhost:8001/api/v1/hotels"
 
+# Token JWT de un usuario admin (obtenerlo con POST /api/auth/login en booking-service)
+if [ -z "$ADMIN_TOKEN" ]; then
+  echo "❌ Definí ADMIN_TOKEN con el token de un usuario admin"
+  exit 1
+fi
+
 # 1. Hotel Córdoba Centro
 echo "1. Creando Hotel Córdoba Centro..."
 curl -X POST $HOTEL_SERVICE_URL \
   -H "Content-Type: application/json" \
+  -H "Authorization: Bearer $ADMIN_TOKEN" \
   -d '{
     "name": "Hotel Córdoba Centro",
     "description": "Hotel céntrico con excelente ubicación en el corazón de Córdoba",
@@ -35,6 +42,7 @@ curl -X POST $HOTEL_SERVICE_URL \
 echo "2. Creando Hotel Sierras de Córdoba..."
 curl -X POST $HOTEL_SERVICE_URL \
   -H "Content-Type: application/json" \
+  -H "Authorization: Bearer $ADMIN_TOKEN" \
   -d '{
     "name": "Hotel Sierras de Córdoba",
     "description": "Hotel boutique en las sierras con vistas espectaculares",
@@ -60,6 +68,7 @@ curl -X POST $HOTEL_SERVICE_URL \
 echo "3. Creando Hotel Buenos Aires Plaza..."
 curl -X POST $HOTEL_SERVICE_URL \
   -H "Content-Type: application/json" \
+  -H "Authorization: Bearer $ADMIN_TOKEN" \
   -d '{
     "name": "Hotel Buenos Aires Plaza",
     "description": "Hotel ejecutivo en el microcentro de Buenos Aires",
@@ -85,6 +94,7 @@ curl -X POST $HOTEL_SERVICE_URL \
 echo "4. Creando Hotel Puerto Madero..."
 curl -X POST $HOTEL_SERVICE_URL \
   -H "Content-Type: application/json" \
+  -H "Authorization: Bearer $ADMIN_TOKEN" \
   -d '{
     "name": "Hotel Puerto Madero",
     "description": "Hotel de lujo exclusivo con vista al río en Puerto Madero",
@@ -110,6 +120,7 @@ curl -X POST $HOTEL_SERVICE_URL \
 echo "5. Creando Hotel Mendoza Wine..."
 curl -X POST $HOTEL_SERVICE_URL \
   -H "Content-Type: application/json" \
+  -H "Authorization: Bearer $ADMIN_TOKEN" \
   -d '{
     "name": "Hotel Mendoza Wine",
     "description": "Hotel temático de vinos con cata incluida en el corazón de Mendoza",
@@ -135,6 +146,7 @@ curl -X POST $HOTEL_SERVICE_URL \
 echo "6. Creando Hotel Nahuel Huapi..."
 curl -X POST $HOTEL_SERVICE_URL \
   -H "Content-Type: application/json" \
+  -H "Authorization: Bearer $ADMIN_TOKEN" \
   -d '{
     "name": "Hotel Nahuel Huapi",
     "description": "Hotel premium con vista al lago Nahuel Huapi y actividades de montaña",
@@ -160,6 +172,7 @@ curl -X POST $HOTEL_SERVICE_URL \
 echo "7. Creando Hotel Salta Colonial..."
 curl -X POST $HOTEL_SERVICE_URL \
   -H "Content-Type: application/json" \
+  -H "Authorization: Bearer $ADMIN_TOKEN" \
   -d '{
     "name": "Hotel Salta Colonial",
     "description": "Hotel histórico con arquitectura colonial en el centro de Salta",
@@ -185,6 +198,7 @@ curl -X POST $HOTEL_SERVICE_URL \
 echo "8. Creando Hotel Costa Atlántica..."
 curl -X POST $HOTEL_SERVICE_URL \
   -H "Content-Type: application/json" \
+  -H "Authorization: Bearer $ADMIN_TOKEN" \
   -d '{
     "name": "Hotel Costa Atlántica",
     "description": "Hotel frente al mar con playa privada en Mar del Plata",
@@ -210,6 +224,7 @@ curl -X POST $HOTEL_SERVICE_URL \
 echo "9. Creando Hotel Rosario Centro..."
 curl -X POST $HOTEL_SERVICE_URL \
   -H "Content-Type: application/json" \
+  -H "Authorization: Bearer $ADMIN_TOKEN" \
   -d '{
     "name": "Hotel Rosario Centro",
     "description": "Hotel económico y cómodo en el centro de Rosario",